@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package flareinspect implements 'agent flare-inspect'.
+package flareinspect
+
+import (
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/pkg/flare/inspect"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for the subcommands
+type cliParams struct {
+	*command.GlobalParams
+
+	// args are the positional command-line arguments
+	args []string
+
+	// top is the number of errors and warnings printed by the summary
+	top int
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+
+	flareInspectCmd := &cobra.Command{
+		Use:   "flare-inspect",
+		Short: "Inspect flare archives offline",
+		Long:  ``,
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <old flare> <new flare>",
+		Short: "Print the differences between two flares",
+		Long: `Print the differences between two flares: versions, runtime configuration,
+scheduled checks, check statuses and workloadmeta entities. Flares are either
+zip archives or directories they were extracted to.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			return fxutil.OneShot(diffFlares, fx.Supply(cliParams))
+		},
+	}
+
+	summaryCmd := &cobra.Command{
+		Use:   "summary <flare>",
+		Short: "Print the top errors and warnings found in a flare",
+		Long: `Print the check instances that are not OK, and the most frequent errors and
+warnings found in the logs bundled in a flare.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			return fxutil.OneShot(summarizeFlare, fx.Supply(cliParams))
+		},
+	}
+	summaryCmd.Flags().IntVarP(&cliParams.top, "top", "n", 10, "Number of errors and warnings to print, 0 for all")
+
+	flareInspectCmd.AddCommand(diffCmd, summaryCmd)
+
+	return []*cobra.Command{flareInspectCmd}
+}
+
+func diffFlares(cliParams *cliParams) error {
+	oldFlare, err := inspect.Open(cliParams.args[0])
+	if err != nil {
+		return err
+	}
+
+	newFlare, err := inspect.Open(cliParams.args[1])
+	if err != nil {
+		return err
+	}
+
+	report, err := inspect.Diff(oldFlare, newFlare)
+	if err != nil {
+		return err
+	}
+
+	report.Write(color.Output)
+
+	return nil
+}
+
+func summarizeFlare(cliParams *cliParams) error {
+	f, err := inspect.Open(cliParams.args[0])
+	if err != nil {
+		return err
+	}
+
+	inspect.Summarize(f, cliParams.top).Write(color.Output)
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package flareinspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestDiffCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"flare-inspect", "diff", "old.zip", "new.zip"},
+		diffFlares,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"old.zip", "new.zip"}, cliParams.args)
+		})
+}
+
+func TestSummaryCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"flare-inspect", "summary", "-n", "5", "flare.zip"},
+		summarizeFlare,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"flare.zip"}, cliParams.args)
+			require.Equal(t, 5, cliParams.top)
+		})
+}
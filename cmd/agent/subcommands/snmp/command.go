@@ -132,6 +132,8 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		Long:  ``,
 	}
 	snmpCmd.AddCommand(snmpWalkCmd)
+	snmpCmd.AddCommand(profileCommand(globalParams))
+	snmpCmd.AddCommand(trapsCommand(globalParams))
 
 	return []*cobra.Command{snmpCmd}
 }
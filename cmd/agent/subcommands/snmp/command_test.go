@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	oidresolver "github.com/DataDog/datadog-agent/pkg/snmp/traps/oid_resolver"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -35,4 +36,59 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, []string{"1.2.3.4", "10.9.8.7"}, cliParams.args)
 			require.True(t, cliParams.unconnectedUDPSocket)
 		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "generate", "-d", "/mibs", "-m", "IF-MIB", "-n", "generic-if", "--object", "IF-MIB::ifTable,IF-MIB::ifXTable", "-o", "if.yaml"},
+		profileGenerate,
+		func(params *profileGenerateParams) {
+			require.Equal(t, []string{"/mibs"}, params.mibDirs)
+			require.Equal(t, []string{"IF-MIB"}, params.modules)
+			require.Equal(t, "generic-if", params.name)
+			require.Equal(t, []string{"IF-MIB::ifTable", "IF-MIB::ifXTable"}, params.objects)
+			require.Equal(t, "if.yaml", params.output)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "test", "acme.yaml", "--walk", "acme.walk", "--json"},
+		profileTest,
+		func(params *profileTestParams) {
+			require.Equal(t, []string{"acme.yaml"}, params.args)
+			require.Equal(t, "acme.walk", params.walkFile)
+			require.True(t, params.jsonOutput)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "test", "acme.yaml", "10.0.0.1:1161", "-v", "2c", "-C", "private"},
+		profileTest,
+		func(params *profileTestParams) {
+			require.Equal(t, []string{"acme.yaml", "10.0.0.1:1161"}, params.args)
+			require.Equal(t, "2c", params.snmpVersion)
+			require.Equal(t, "private", params.communityString)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "traps", "compile", "-d", "/mibs", "-m", "ACME-MIB,ACME-TRAP-MIB", "-o", "acme.json"},
+		trapsCompile,
+		func(params *trapsCompileParams) {
+			require.Equal(t, []string{"/mibs"}, params.mibDirs)
+			require.Equal(t, []string{"ACME-MIB", "ACME-TRAP-MIB"}, params.modules)
+			require.Equal(t, "acme.json", params.output)
+		})
+}
+
+func TestTrapsDBConflicts(t *testing.T) {
+	trapsDB := &oidresolver.TrapDBFileContent{
+		Traps: oidresolver.TrapSpec{
+			"1.3.6.1.6.3.1.1.5.4":      {Name: "linkUp", MIBName: "IF-MIB"},
+			"1.3.6.1.4.1.8072.2.3.0.1": {Name: "acmeHeartbeat", MIBName: "ACME-MIB"},
+			"1.3.6.1.4.1.99999.2.0.1":  {Name: "acmePortDown", MIBName: "ACME-MIB"},
+		},
+	}
+	require.Equal(t, []string{
+		"trap OID 1.3.6.1.4.1.8072.2.3.0.1 compiled as ACME-MIB::acmeHeartbeat is already defined as NET-SNMP-EXAMPLES-MIB::netSnmpExampleHeartbeatNotification in the installed traps DB files",
+	}, trapsDBConflicts(oidresolver.NewMockResolver(), trapsDB))
 }
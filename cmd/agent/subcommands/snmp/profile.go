@@ -0,0 +1,335 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/profiletest"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+	"github.com/DataDog/datadog-agent/pkg/snmp/profilegen"
+	parse "github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// profileGenerateParams are the command-line arguments of the profile generate subcommand
+type profileGenerateParams struct {
+	*command.GlobalParams
+
+	mibDirs      []string
+	modules      []string
+	objects      []string
+	name         string
+	description  string
+	sysObjectIDs []string
+	extends      []string
+	output       string
+}
+
+// profileTestParams are the command-line arguments of the profile test subcommand
+type profileTestParams struct {
+	*command.GlobalParams
+
+	// args are the positional command-line arguments: the profile and the device
+	args []string
+
+	walkFile   string
+	jsonOutput bool
+
+	// connection to the device, overriding the configuration of the SNMP check
+	snmpVersion     string
+	communityString string
+	user            string
+	authProt        string
+	authKey         string
+	privProt        string
+	privKey         string
+	snmpContext     string
+	retries         int
+	timeout         int
+}
+
+func profileCommand(globalParams *command.GlobalParams) *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "SNMP profiles tools",
+		Long:  ``,
+	}
+	profileCmd.AddCommand(profileGenerateCommand(globalParams))
+	profileCmd.AddCommand(profileTestCommand(globalParams))
+	return profileCmd
+}
+
+func profileGenerateCommand(globalParams *command.GlobalParams) *cobra.Command {
+	params := &profileGenerateParams{
+		GlobalParams: globalParams,
+	}
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a SNMP profile from MIB files",
+		Long: `Generate a SNMP profile from the MIB modules found in the given directories.
+The tables and scalars to collect are selected with --object, all the ones of
+the modules given with --module are collected otherwise. The metric types are
+inferred from the syntax of the objects, the table indexes are used as tags
+and the enumerations are mapped to their names.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(profileGenerate,
+				fx.Supply(params),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	generateCmd.Flags().StringSliceVarP(&params.mibDirs, "mib-dir", "d", nil, "Directory holding the MIB files, can be repeated")
+	generateCmd.Flags().StringSliceVarP(&params.modules, "module", "m", nil, "MIB module to load, can be repeated")
+	generateCmd.Flags().StringSliceVar(&params.objects, "object", nil, "Table, column, scalar or group to collect, like IF-MIB::ifXTable, can be repeated")
+	generateCmd.Flags().StringVarP(&params.name, "name", "n", "", "Name of the profile")
+	generateCmd.Flags().StringVar(&params.description, "description", "", "Description of the profile")
+	generateCmd.Flags().StringSliceVar(&params.sysObjectIDs, "sysobjectid", nil, "sysObjectID matched by the profile, can be repeated")
+	generateCmd.Flags().StringSliceVar(&params.extends, "extends", nil, "Profile extended by the profile, can be repeated")
+	generateCmd.Flags().StringVarP(&params.output, "output", "o", "", "Path of the generated profile, standard output by default")
+	_ = generateCmd.MarkFlagRequired("mib-dir")
+	_ = generateCmd.MarkFlagRequired("module")
+	_ = generateCmd.MarkFlagRequired("name")
+
+	return generateCmd
+}
+
+func profileGenerate(params *profileGenerateParams) error {
+	loader := mib.NewLoader(params.mibDirs...)
+	for _, module := range params.modules {
+		if _, err := loader.Load(module); err != nil {
+			return err
+		}
+	}
+
+	opts := profilegen.Options{
+		Name:         params.name,
+		Description:  params.description,
+		SysObjectIDs: params.sysObjectIDs,
+		Extends:      params.extends,
+		Objects:      params.objects,
+		Modules:      params.modules,
+	}
+	profile, warnings, err := profilegen.Generate(loader, opts)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if err != nil {
+		return err
+	}
+	if err := profilegen.Validate(profile); err != nil {
+		return fmt.Errorf("the generated profile is invalid: %w", err)
+	}
+
+	data, err := yaml.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	if params.output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(params.output, data, 0644)
+}
+
+func profileTestCommand(globalParams *command.GlobalParams) *cobra.Command {
+	params := &profileTestParams{
+		GlobalParams: globalParams,
+	}
+	testCmd := &cobra.Command{
+		Use:   "test <profile> [<ip_address>[:port]]",
+		Short: "Test a SNMP profile against a device or a recorded walk",
+		Long: `Run a SNMP profile once, like the SNMP check does, and print the metrics, tags
+and device metadata it produces. The values are fetched from the given device,
+or from the output of ` + "`agent snmp walk`" + ` given with --walk. The OIDs of the
+profile the device has no value for, and the values whose type doesn't match
+the way the profile uses them, are reported.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params.args = args
+			return fxutil.OneShot(profileTest,
+				fx.Supply(params),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	testCmd.Flags().StringVarP(&params.walkFile, "walk", "w", "", "Output of `agent snmp walk` to run the profile against")
+	testCmd.Flags().BoolVarP(&params.jsonOutput, "json", "j", false, "Print the result as JSON")
+
+	testCmd.Flags().StringVarP(&params.snmpVersion, "snmp-version", "v", "", "Specify SNMP version to use")
+	testCmd.Flags().StringVarP(&params.communityString, "community-string", "C", "", "Set the community string")
+	testCmd.Flags().StringVarP(&params.user, "user", "u", "", "Set the security name")
+	testCmd.Flags().StringVarP(&params.authProt, "auth-protocol", "a", "", "Set authentication protocol (MD5|SHA|SHA-224|SHA-256|SHA-384|SHA-512)")
+	testCmd.Flags().StringVarP(&params.authKey, "auth-key", "A", "", "Set authentication protocol pass phrase")
+	testCmd.Flags().StringVarP(&params.privProt, "priv-protocol", "x", "", "Set privacy protocol (DES|AES|AES192|AES192C|AES256|AES256C)")
+	testCmd.Flags().StringVarP(&params.privKey, "priv-key", "X", "", "Set privacy protocol pass phrase")
+	testCmd.Flags().StringVarP(&params.snmpContext, "context", "n", "", "Set context name")
+	testCmd.Flags().IntVarP(&params.retries, "retries", "r", 0, "Set the number of retries")
+	testCmd.Flags().IntVarP(&params.timeout, "timeout", "t", 0, "Set the request timeout (in seconds)")
+
+	return testCmd
+}
+
+func profileTest(_ config.Component, params *profileTestParams) error {
+	profilePath := params.args[0]
+
+	var result *profiletest.Result
+	if params.walkFile != "" {
+		if len(params.args) > 1 {
+			return fmt.Errorf("a device can't be given along with --walk")
+		}
+		walk, err := os.Open(params.walkFile)
+		if err != nil {
+			return err
+		}
+		defer walk.Close()
+		if result, err = profiletest.RunWithWalk(profilePath, walk); err != nil {
+			return err
+		}
+	} else {
+		if len(params.args) < 2 {
+			return fmt.Errorf("either a device or --walk must be given")
+		}
+		instance, err := profileTestInstance(params)
+		if err != nil {
+			return err
+		}
+		if result, err = profiletest.RunWithDevice(profilePath, instance); err != nil {
+			return err
+		}
+	}
+
+	if params.jsonOutput {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	return printProfileTestResult(result)
+}
+
+// profileTestInstance returns the instance config of the SNMP check to connect
+// to the device. The configuration of the device in the SNMP check is used,
+// the command-line arguments taking precedence.
+func profileTestInstance(params *profileTestParams) (map[string]interface{}, error) {
+	deviceIP, rawPort, hasPort := strings.Cut(params.args[1], ":")
+
+	snmpConfigList, err := parse.GetConfigCheckSnmp()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't parse the SNMP config: %v\n", err)
+	}
+	deviceConfig := parse.GetIPConfig(deviceIP, snmpConfigList)
+
+	instance := map[string]interface{}{"ip_address": deviceIP}
+	set := func(key string, flagValue string, configValue string) {
+		if flagValue != "" {
+			instance[key] = flagValue
+		} else if configValue != "" {
+			instance[key] = configValue
+		}
+	}
+	set("snmp_version", params.snmpVersion, deviceConfig.Version)
+	set("community_string", params.communityString, deviceConfig.CommunityString)
+	set("user", params.user, deviceConfig.Username)
+	set("authProtocol", params.authProt, deviceConfig.AuthProtocol)
+	set("authKey", params.authKey, deviceConfig.AuthKey)
+	set("privProtocol", params.privProt, deviceConfig.PrivProtocol)
+	set("privKey", params.privKey, deviceConfig.PrivKey)
+	set("context_name", params.snmpContext, deviceConfig.Context)
+
+	if hasPort {
+		port, err := strconv.ParseUint(rawPort, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %s: %w", rawPort, err)
+		}
+		instance["port"] = port
+	} else if deviceConfig.Port != 0 {
+		instance["port"] = deviceConfig.Port
+	}
+	if params.retries != 0 {
+		instance["retries"] = params.retries
+	} else if deviceConfig.Retries != 0 {
+		instance["retries"] = deviceConfig.Retries
+	}
+	if params.timeout != 0 {
+		instance["timeout"] = params.timeout
+	} else if deviceConfig.Timeout != 0 {
+		instance["timeout"] = deviceConfig.Timeout
+	}
+	if _, ok := instance["community_string"]; !ok {
+		if _, ok := instance["user"]; !ok {
+			instance["community_string"] = defaultCommunityString
+		}
+	}
+	return instance, nil
+}
+
+func printProfileTestResult(result *profiletest.Result) error {
+	fmt.Printf("Profile: %s\n", result.Profile)
+	if result.Error != "" {
+		fmt.Printf("Check error: %s\n", result.Error)
+	}
+
+	fmt.Printf("\nMetrics (%d):\n", len(result.Metrics))
+	for _, metric := range result.Metrics {
+		fmt.Printf("  %s %s %v\n", metric.Type, metric.Name, metric.Value)
+		if len(metric.Tags) > 0 {
+			fmt.Printf("    tags: %s\n", strings.Join(metric.Tags, ", "))
+		}
+	}
+
+	fmt.Printf("\nTags (%d):\n", len(result.Tags))
+	for _, tag := range result.Tags {
+		fmt.Printf("  %s\n", tag)
+	}
+
+	if result.Metadata != nil {
+		data, err := json.MarshalIndent(result.Metadata, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nMetadata:\n  %s\n", data)
+	}
+
+	if len(result.UnmatchedOIDs) > 0 {
+		fmt.Printf("\nUnmatched OIDs (%d):\n", len(result.UnmatchedOIDs))
+		for _, unmatched := range result.UnmatchedOIDs {
+			fmt.Printf("  %s %s (%s)\n", unmatched.OID, unmatched.Name, unmatched.Usage)
+		}
+	}
+	if len(result.TypeMismatches) > 0 {
+		fmt.Printf("\nType mismatches (%d):\n", len(result.TypeMismatches))
+		for _, mismatch := range result.TypeMismatches {
+			fmt.Printf("  %s %s (%s): %s, %s\n", mismatch.OID, mismatch.Name, mismatch.Usage, mismatch.Type, mismatch.Reason)
+		}
+	}
+	return nil
+}
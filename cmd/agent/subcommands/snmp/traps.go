@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+	oidresolver "github.com/DataDog/datadog-agent/pkg/snmp/traps/oid_resolver"
+	"github.com/DataDog/datadog-agent/pkg/snmp/trapsdbgen"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// trapsCompileParams are the command-line arguments of the traps compile subcommand
+type trapsCompileParams struct {
+	*command.GlobalParams
+
+	mibDirs []string
+	modules []string
+	output  string
+}
+
+func trapsCommand(globalParams *command.GlobalParams) *cobra.Command {
+	trapsCmd := &cobra.Command{
+		Use:   "traps",
+		Short: "SNMP traps tools",
+		Long:  ``,
+	}
+	trapsCmd.AddCommand(trapsCompileCommand(globalParams))
+	return trapsCmd
+}
+
+func trapsCompileCommand(globalParams *command.GlobalParams) *cobra.Command {
+	params := &trapsCompileParams{
+		GlobalParams: globalParams,
+	}
+	compileCmd := &cobra.Command{
+		Use:   "compile",
+		Short: "Compile the SNMP traps of MIB files into a traps DB file",
+		Long: `Compile the NOTIFICATION-TYPE and TRAP-TYPE definitions of the MIB modules
+found in the given directories into a traps DB file, along with the names and
+enumerations of their variables. The traps of the modules given with --module
+are compiled, the ones of all the modules found otherwise.
+
+The traps DB files are loaded from the snmp.d/traps_db directory of the
+configuration. The files provided by the user take precedence over the
+Datadog one, and among them the last one in alphabetical order takes
+precedence. The compiled traps already defined differently by the installed
+files are reported.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(trapsCompile,
+				fx.Supply(params),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	compileCmd.Flags().StringSliceVarP(&params.mibDirs, "mib-dir", "d", nil, "Directory holding the MIB files, can be repeated")
+	compileCmd.Flags().StringSliceVarP(&params.modules, "module", "m", nil, "MIB module to compile the traps of, can be repeated")
+	compileCmd.Flags().StringVarP(&params.output, "output", "o", "", "Path of the traps DB file, in JSON when ending with .json and YAML otherwise, standard output by default")
+	_ = compileCmd.MarkFlagRequired("mib-dir")
+
+	return compileCmd
+}
+
+func trapsCompile(conf config.Component, logger log.Component, params *trapsCompileParams) error {
+	loader := mib.NewLoader(params.mibDirs...)
+	modules := params.modules
+	if len(modules) == 0 {
+		loaded, err := loader.LoadAll()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+		}
+		for _, module := range loaded {
+			modules = append(modules, module.Name)
+		}
+	}
+
+	trapsDB, warnings, err := trapsdbgen.Generate(loader, modules)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the traps DB directory may not exist yet, the conflicts are reported on a best effort basis
+	if resolver, err := oidresolver.NewMultiFilesOIDResolver(conf.GetString("confd_path"), logger); err == nil {
+		for _, conflict := range trapsDBConflicts(resolver, trapsDB) {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", conflict)
+		}
+	}
+
+	var data []byte
+	if strings.HasSuffix(params.output, ".json") {
+		data, err = json.Marshal(trapsDB)
+	} else {
+		data, err = yaml.Marshal(trapsDB)
+	}
+	if err != nil {
+		return err
+	}
+	if params.output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(params.output, data, 0644)
+}
+
+// trapsDBConflicts reports the traps of a traps DB that are defined differently by a resolver
+func trapsDBConflicts(resolver oidresolver.OIDResolver, trapsDB *oidresolver.TrapDBFileContent) []string {
+	trapOIDs := make([]string, 0, len(trapsDB.Traps))
+	for trapOID := range trapsDB.Traps {
+		trapOIDs = append(trapOIDs, trapOID)
+	}
+	sort.Strings(trapOIDs)
+
+	var conflicts []string
+	for _, trapOID := range trapOIDs {
+		trap := trapsDB.Traps[trapOID]
+		installed, err := resolver.GetTrapMetadata(trapOID)
+		if err != nil || (installed.Name == trap.Name && installed.MIBName == trap.MIBName) {
+			continue
+		}
+		conflicts = append(conflicts, fmt.Sprintf("trap OID %s compiled as %s::%s is already defined as %s::%s in the installed traps DB files",
+			trapOID, trap.MIBName, trap.Name, installed.MIBName, installed.Name))
+	}
+	return conflicts
+}
@@ -17,6 +17,7 @@ import (
 	cmddogstatsdreplay "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdreplay"
 	cmddogstatsdstats "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdstats"
 	cmdflare "github.com/DataDog/datadog-agent/cmd/agent/subcommands/flare"
+	cmdflareinspect "github.com/DataDog/datadog-agent/cmd/agent/subcommands/flareinspect"
 	cmdhealth "github.com/DataDog/datadog-agent/cmd/agent/subcommands/health"
 	cmdhostname "github.com/DataDog/datadog-agent/cmd/agent/subcommands/hostname"
 	cmdimport "github.com/DataDog/datadog-agent/cmd/agent/subcommands/import"
@@ -50,6 +51,7 @@ func AgentSubcommands() []command.SubcommandFactory {
 		cmddogstatsdreplay.Commands,
 		cmddogstatsdstats.Commands,
 		cmdflare.Commands,
+		cmdflareinspect.Commands,
 		cmdhealth.Commands,
 		cmdhostname.Commands,
 		cmdimport.Commands,
@@ -43,6 +43,10 @@ const (
 
 	EvaluateLoadedPolicies = "loaded-policies" // EvaluateLoadedPolicies policy check subcommand
 
+	// Runtime Policy Test Subcommand
+
+	TestsDir = "tests-dir" // TestsDir policy test subcommand
+
 	// Runtime Activity Dump Subcommand
 
 	Name              = "name"               // Name activity dump subcommand
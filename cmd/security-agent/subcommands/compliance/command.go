@@ -35,6 +35,7 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 
 	complianceCmd.AddCommand(check.SecurityAgentCommands(globalParams)...)
 	complianceCmd.AddCommand(complianceEventCommand(globalParams))
+	complianceCmd.AddCommand(scanCommand(globalParams))
 
 	return []*cobra.Command{complianceCmd}
 }
@@ -45,7 +45,7 @@ func TestCommands(t *testing.T) {
 			subcommandNames = append(subcommandNames, subcommand.Use)
 		}
 
-		require.Equal(t, []string{"event"}, subcommandNames, "subcommand missing")
+		require.Equal(t, []string{"event", "scan"}, subcommandNames, "subcommand missing")
 
 		fxutil.TestOneShotSubcommand(t,
 			Commands(&command.GlobalParams{}),
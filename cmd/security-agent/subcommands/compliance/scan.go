@@ -0,0 +1,333 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/cmd/security-agent/flags"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	scanFormatText  = "text"
+	scanFormatJSON  = "json"
+	scanFormatSARIF = "sarif"
+
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// errScanFindings is returned when the scan reports failed checks, to exit
+// with a non-zero status in CI pipelines.
+var errScanFindings = errors.New("compliance scan found failing checks")
+
+type scanCliParams struct {
+	*command.GlobalParams
+
+	target     string
+	framework  string
+	file       string
+	format     string
+	outputPath string
+}
+
+func scanCommand(globalParams *command.GlobalParams) *cobra.Command {
+	scanArgs := &scanCliParams{
+		GlobalParams: globalParams,
+	}
+
+	scanCmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Run the compliance checks against a root filesystem or a container image tarball",
+		Long: `Run the file, package and configuration based compliance checks against an
+unpacked root filesystem or a container image tarball, as produced by
+"docker save" or holding an OCI image layout. The checks requiring a running
+system are reported as not applicable. The command exits with an error when
+a check fails.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scanArgs.target = args[0]
+			return fxutil.OneShot(scanRun,
+				fx.Supply(scanArgs),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true),
+				}),
+				core.Bundle,
+			)
+		},
+	}
+
+	scanCmd.Flags().StringVarP(&scanArgs.framework, flags.Framework, "", "", "Framework to run the checks from")
+	scanCmd.Flags().StringVarP(&scanArgs.file, flags.File, "f", "", "Compliance suite file to read rules from")
+	scanCmd.Flags().StringVarP(&scanArgs.format, flags.Format, "", scanFormatText, "Output format: text, json or sarif")
+	scanCmd.Flags().StringVarP(&scanArgs.outputPath, flags.OutputPath, "o", "", "Path to file where to write the results, standard output by default")
+
+	return scanCmd
+}
+
+func scanRun(log log.Component, config config.Component, scanArgs *scanCliParams) error {
+	var write func(io.Writer, []*compliance.Benchmark, []*compliance.CheckEvent) error
+	switch scanArgs.format {
+	case scanFormatText:
+		write = writeScanText
+	case scanFormatJSON:
+		write = writeScanJSON
+	case scanFormatSARIF:
+		write = writeScanSARIF
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of: text, json, sarif", scanArgs.format)
+	}
+
+	fi, err := os.Stat(scanArgs.target)
+	if err != nil {
+		return err
+	}
+	rootDir := scanArgs.target
+	if !fi.IsDir() {
+		rootDir, err = os.MkdirTemp("", "compliance-scan-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(rootDir)
+
+		log.Infof("Unpacking image %s", scanArgs.target)
+		if err := compliance.UnpackImage(scanArgs.target, rootDir); err != nil {
+			return err
+		}
+	}
+
+	configDir := config.GetString("compliance_config.dir")
+	var benchDir, benchGlob string
+	if scanArgs.file != "" {
+		benchDir, benchGlob = filepath.Dir(scanArgs.file), filepath.Base(scanArgs.file)
+	} else if scanArgs.framework != "" {
+		benchDir, benchGlob = configDir, fmt.Sprintf("%s.yaml", scanArgs.framework)
+	} else {
+		benchDir, benchGlob = configDir, "*.yaml"
+	}
+
+	// the rules are not filtered on the live host environment, the scanned
+	// root filesystem is unrelated to it
+	log.Infof("Loading compliance rules from %s", benchDir)
+	benchmarks, err := compliance.LoadBenchmarks(benchDir, benchGlob, nil)
+	if err != nil {
+		return fmt.Errorf("could not load benchmark files %q: %w", filepath.Join(benchDir, benchGlob), err)
+	}
+	if len(benchmarks) == 0 {
+		return fmt.Errorf("could not find any benchmark in %q", filepath.Join(benchDir, benchGlob))
+	}
+
+	resolver := compliance.NewResolver(context.Background(), compliance.ResolverOptions{
+		Hostname: filepath.Base(filepath.Clean(scanArgs.target)),
+		HostRoot: rootDir,
+		Offline:  true,
+	})
+	defer resolver.Close()
+
+	events := scanBenchmarks(context.Background(), resolver, benchmarks)
+
+	out := io.Writer(os.Stdout)
+	if scanArgs.outputPath != "" {
+		f, err := os.Create(scanArgs.outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := write(out, benchmarks, events); err != nil {
+		return fmt.Errorf("could not write scan results: %w", err)
+	}
+
+	for _, event := range events {
+		if event.Result == compliance.CheckFailed {
+			return errScanFindings
+		}
+	}
+	return nil
+}
+
+// scanBenchmarks evaluates the rules of the benchmarks with the given offline
+// resolver. The XCCDF rules evaluate the live host and are always reported as
+// not applicable.
+func scanBenchmarks(ctx context.Context, resolver compliance.Resolver, benchmarks []*compliance.Benchmark) []*compliance.CheckEvent {
+	var events []*compliance.CheckEvent
+	for _, benchmark := range benchmarks {
+		for _, rule := range benchmark.Rules {
+			switch {
+			case rule.IsXCCDF():
+				err := fmt.Errorf("%w: offline evaluation of XCCDF rule", compliance.ErrIncompatibleEnvironment)
+				events = append(events, compliance.CheckEventFromError(compliance.XCCDFEvaluator, rule, benchmark, err))
+			case rule.IsRego():
+				inputs, err := resolver.ResolveInputs(ctx, rule)
+				if err != nil {
+					events = append(events, compliance.CheckEventFromError(compliance.RegoEvaluator, rule, benchmark, err))
+				} else {
+					events = append(events, compliance.EvaluateRegoRule(ctx, inputs, benchmark, rule)...)
+				}
+			}
+		}
+	}
+	return events
+}
+
+func scanEventMessage(event *compliance.CheckEvent) string {
+	if event.Result == compliance.CheckSkipped {
+		return "not applicable"
+	}
+	if errMsg, ok := event.Data["error"].(string); ok {
+		return errMsg
+	}
+	if event.ResourceID != "" {
+		return fmt.Sprintf("%s %s", event.ResourceType, event.ResourceID)
+	}
+	return ""
+}
+
+func writeScanText(w io.Writer, _ []*compliance.Benchmark, events []*compliance.CheckEvent) error {
+	counts := make(map[compliance.CheckResult]int)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESULT\tFRAMEWORK\tRULE\tDETAILS")
+	for _, event := range events {
+		counts[event.Result]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", event.Result, event.FrameworkID, event.RuleID, scanEventMessage(event))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d passed, %d failed, %d errors, %d not applicable\n",
+		counts[compliance.CheckPassed], counts[compliance.CheckFailed], counts[compliance.CheckError], counts[compliance.CheckSkipped])
+	return err
+}
+
+func writeScanJSON(w io.Writer, _ []*compliance.Benchmark, events []*compliance.CheckEvent) error {
+	if events == nil {
+		events = []*compliance.CheckEvent{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(events)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID  string       `json:"ruleId"`
+	Level   string       `json:"level"`
+	Kind    string       `json:"kind"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// writeScanSARIF writes the results in the SARIF format understood by code
+// scanning tools. Passed and not applicable checks are reported with the pass
+// and notApplicable kinds.
+func writeScanSARIF(w io.Writer, benchmarks []*compliance.Benchmark, events []*compliance.CheckEvent) error {
+	rules := make(map[string]sarifRule)
+	for _, benchmark := range benchmarks {
+		for _, rule := range benchmark.Rules {
+			rules[rule.ID] = sarifRule{
+				ID:               rule.ID,
+				ShortDescription: sarifMessage{Text: rule.Description},
+			}
+		}
+	}
+	ruleIDs := make([]string, 0, len(rules))
+	for id := range rules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:    "datadog-security-agent",
+			Version: version.AgentVersion,
+			Rules:   make([]sarifRule, 0, len(ruleIDs)),
+		}},
+		Results: make([]sarifResult, 0, len(events)),
+	}
+	for _, id := range ruleIDs {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rules[id])
+	}
+
+	for _, event := range events {
+		result := sarifResult{
+			RuleID:  event.RuleID,
+			Level:   "none",
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %s", event.RuleID, event.Result)},
+		}
+		switch event.Result {
+		case compliance.CheckPassed:
+			result.Kind = "pass"
+		case compliance.CheckFailed:
+			result.Kind, result.Level = "fail", "error"
+		case compliance.CheckError:
+			result.Kind, result.Level = "fail", "warning"
+		case compliance.CheckSkipped:
+			result.Kind = "notApplicable"
+		}
+		if msg := scanEventMessage(event); msg != "" {
+			result.Message.Text += ": " + msg
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
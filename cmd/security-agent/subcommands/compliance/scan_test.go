@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+)
+
+const testScanBenchmark = `
+name: Test Benchmark
+framework: test-benchmark
+version: 1.0.0
+rules:
+  - id: ssh-root-login
+    description: SSH root login is disabled
+    input:
+      - file:
+          path: /etc/ssh/sshd_config
+          parser: raw
+  - id: sshd-running
+    description: SSH daemon runs with strict modes
+    input:
+      - process:
+          name: sshd
+`
+
+const testScanRego = `package datadog
+
+import data.datadog as dd
+
+findings[f] {
+	contains(input.file.content, "PermitRootLogin no")
+	f := dd.passed_finding("sshd_config", input.file.path, {})
+}
+
+findings[f] {
+	not contains(input.file.content, "PermitRootLogin no")
+	f := dd.failing_finding("sshd_config", input.file.path, {})
+}
+`
+
+func testScanEvents(t *testing.T, sshdConfig string) ([]*compliance.Benchmark, []*compliance.CheckEvent) {
+	benchDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(benchDir, "test.yaml"), []byte(testScanBenchmark), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(benchDir, "ssh-root-login.rego"), []byte(testScanRego), 0644))
+
+	rootDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, "etc", "ssh"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "etc", "ssh", "sshd_config"), []byte(sshdConfig), 0600))
+
+	benchmarks, err := compliance.LoadBenchmarks(benchDir, "*.yaml", nil)
+	require.NoError(t, err)
+
+	resolver := compliance.NewResolver(context.Background(), compliance.ResolverOptions{
+		Hostname: "rootfs",
+		HostRoot: rootDir,
+		Offline:  true,
+	})
+	defer resolver.Close()
+	return benchmarks, scanBenchmarks(context.Background(), resolver, benchmarks)
+}
+
+func TestScanBenchmarks(t *testing.T) {
+	_, events := testScanEvents(t, "PermitRootLogin no\n")
+	require.Len(t, events, 2)
+	assert.Equal(t, "ssh-root-login", events[0].RuleID)
+	assert.Equal(t, compliance.CheckPassed, events[0].Result)
+	assert.Equal(t, "/etc/ssh/sshd_config", events[0].ResourceID)
+	assert.Equal(t, "sshd-running", events[1].RuleID)
+	assert.Equal(t, compliance.CheckSkipped, events[1].Result)
+
+	_, events = testScanEvents(t, "PermitRootLogin yes\n")
+	require.Len(t, events, 2)
+	assert.Equal(t, compliance.CheckFailed, events[0].Result)
+}
+
+func TestScanWriters(t *testing.T) {
+	benchmarks, events := testScanEvents(t, "PermitRootLogin yes\n")
+
+	var b bytes.Buffer
+	require.NoError(t, writeScanText(&b, benchmarks, events))
+	assert.Contains(t, b.String(), "failed   test-benchmark  ssh-root-login  sshd_config /etc/ssh/sshd_config")
+	assert.Contains(t, b.String(), "skipped  test-benchmark  sshd-running    not applicable")
+	assert.Contains(t, b.String(), "0 passed, 1 failed, 0 errors, 1 not applicable")
+
+	b.Reset()
+	require.NoError(t, writeScanJSON(&b, benchmarks, events))
+	var jsonEvents []*compliance.CheckEvent
+	require.NoError(t, json.Unmarshal(b.Bytes(), &jsonEvents))
+	assert.Len(t, jsonEvents, 2)
+
+	b.Reset()
+	require.NoError(t, writeScanSARIF(&b, benchmarks, events))
+	var sarif sarifLog
+	require.NoError(t, json.Unmarshal(b.Bytes(), &sarif))
+	assert.Equal(t, sarifVersion, sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+	assert.Equal(t, []sarifRule{
+		{ID: "ssh-root-login", ShortDescription: sarifMessage{Text: "SSH root login is disabled"}},
+		{ID: "sshd-running", ShortDescription: sarifMessage{Text: "SSH daemon runs with strict modes"}},
+	}, sarif.Runs[0].Tool.Driver.Rules)
+	assert.Equal(t, []sarifResult{
+		{
+			RuleID:  "ssh-root-login",
+			Level:   "error",
+			Kind:    "fail",
+			Message: sarifMessage{Text: "ssh-root-login: failed: sshd_config /etc/ssh/sshd_config"},
+		},
+		{
+			RuleID:  "sshd-running",
+			Level:   "none",
+			Kind:    "notApplicable",
+			Message: sarifMessage{Text: "sshd-running: skipped: not applicable"},
+		},
+	}, sarif.Runs[0].Results)
+}
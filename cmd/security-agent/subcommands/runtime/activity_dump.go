@@ -9,9 +9,12 @@
 package runtime
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
@@ -48,6 +51,7 @@ type activityDumpCliParams struct {
 	remoteStorageFormats     []string
 	remoteStorageCompression bool
 	remoteRequest            bool
+	outputFile               string
 }
 
 func activityDumpCommands(globalParams *command.GlobalParams) []*cobra.Command {
@@ -131,6 +135,8 @@ func generateCommands(globalParams *command.GlobalParams) []*cobra.Command {
 
 	activityDumpGenerateCmd.AddCommand(generateDumpCommands(globalParams)...)
 	activityDumpGenerateCmd.AddCommand(generateEncodingCommands(globalParams)...)
+	activityDumpGenerateCmd.AddCommand(generateSeccompCommands(globalParams)...)
+	activityDumpGenerateCmd.AddCommand(generateAppArmorCommands(globalParams)...)
 
 	return []*cobra.Command{activityDumpGenerateCmd}
 }
@@ -280,6 +286,86 @@ func generateEncodingCommands(globalParams *command.GlobalParams) []*cobra.Comma
 	return []*cobra.Command{activityDumpGenerateEncodingCmd}
 }
 
+func generateSeccompCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &activityDumpCliParams{
+		GlobalParams: globalParams,
+	}
+
+	activityDumpGenerateSeccompCmd := &cobra.Command{
+		Use:   "seccomp",
+		Short: "generate a seccomp profile from the syscalls of an activity dump or a security profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(generateSeccompFromActivityDump,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "info", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	activityDumpGenerateSeccompCmd.Flags().StringVar(
+		&cliParams.file,
+		flags.Input,
+		"",
+		"path to the activity dump or security profile file",
+	)
+	_ = activityDumpGenerateSeccompCmd.MarkFlagRequired(flags.Input)
+	activityDumpGenerateSeccompCmd.Flags().StringVar(
+		&cliParams.outputFile,
+		flags.Output,
+		"",
+		"path to the generated seccomp profile, defaults to the standard output",
+	)
+
+	return []*cobra.Command{activityDumpGenerateSeccompCmd}
+}
+
+func generateAppArmorCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &activityDumpCliParams{
+		GlobalParams: globalParams,
+	}
+
+	activityDumpGenerateAppArmorCmd := &cobra.Command{
+		Use:   "apparmor",
+		Short: "generate an AppArmor profile skeleton from an activity dump or a security profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(generateAppArmorFromActivityDump,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "info", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	activityDumpGenerateAppArmorCmd.Flags().StringVar(
+		&cliParams.file,
+		flags.Input,
+		"",
+		"path to the activity dump or security profile file",
+	)
+	_ = activityDumpGenerateAppArmorCmd.MarkFlagRequired(flags.Input)
+	activityDumpGenerateAppArmorCmd.Flags().StringVar(
+		&cliParams.name,
+		flags.Name,
+		"",
+		"name of the AppArmor profile, defaults to the name of the activity dump",
+	)
+	activityDumpGenerateAppArmorCmd.Flags().StringVar(
+		&cliParams.outputFile,
+		flags.Output,
+		"",
+		"path to the generated AppArmor profile, defaults to the standard output",
+	)
+
+	return []*cobra.Command{activityDumpGenerateAppArmorCmd}
+}
+
 func diffCommands(globalParams *command.GlobalParams) []*cobra.Command {
 	cliParams := &activityDumpCliParams{
 		GlobalParams: globalParams,
@@ -458,6 +544,62 @@ func diffActivityDump(_ log.Component, _ config.Component, _ secrets.Component,
 	return nil
 }
 
+func writeActivityDumpOutput(outputFile string, content []byte) error {
+	if outputFile == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	return os.WriteFile(outputFile, content, 0644)
+}
+
+func generateSeccompFromActivityDump(_ log.Component, _ config.Component, _ secrets.Component, args *activityDumpCliParams) error {
+	ad := dump.NewEmptyActivityDump(nil)
+	if err := ad.Decode(args.file); err != nil {
+		return err
+	}
+
+	profile, err := ad.ActivityTree.ToSeccompProfile(ad.Metadata.Arch)
+	if err != nil {
+		return fmt.Errorf("couldn't generate seccomp profile: %w", err)
+	}
+
+	content, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeActivityDumpOutput(args.outputFile, append(content, '\n'))
+}
+
+// appArmorProfileName returns a valid AppArmor profile name out of an activity dump name
+func appArmorProfileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.", r) {
+			return r
+		}
+		return '-'
+	}, name)
+	if name == "" {
+		return "datadog-activity-dump"
+	}
+	return name
+}
+
+func generateAppArmorFromActivityDump(_ log.Component, _ config.Component, _ secrets.Component, args *activityDumpCliParams) error {
+	ad := dump.NewEmptyActivityDump(nil)
+	if err := ad.Decode(args.file); err != nil {
+		return err
+	}
+
+	name := args.name
+	if name == "" {
+		name = ad.Metadata.Name
+	}
+
+	profile := ad.ActivityTree.ToAppArmorProfile(appArmorProfileName(name), activity_tree.NewPathsReducer())
+	return writeActivityDumpOutput(args.outputFile, profile.Bytes())
+}
+
 func generateActivityDump(_ log.Component, _ config.Component, _ secrets.Component, activityDumpArgs *activityDumpCliParams) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
@@ -10,6 +10,8 @@ package runtime
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
@@ -53,3 +55,24 @@ func TestDiffActivityDumpCommand(t *testing.T) {
 		diffActivityDump,
 		func() {})
 }
+
+func TestGenerateSeccompFromActivityDumpCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"runtime", "activity-dump", "generate", "seccomp", "--input", "file"},
+		generateSeccompFromActivityDump,
+		func() {})
+}
+
+func TestGenerateAppArmorFromActivityDumpCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"runtime", "activity-dump", "generate", "apparmor", "--input", "file", "--name", "my-profile"},
+		generateAppArmorFromActivityDump,
+		func() {})
+}
+
+func TestAppArmorProfileName(t *testing.T) {
+	assert.Equal(t, "activity-dump-nginx-latest", appArmorProfileName("activity-dump nginx:latest"))
+	assert.Equal(t, "datadog-activity-dump", appArmorProfileName(""))
+}
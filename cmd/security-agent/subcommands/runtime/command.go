@@ -63,6 +63,7 @@ func commonPolicyCommands(globalParams *command.GlobalParams) []*cobra.Command {
 	}
 
 	commonPolicyCmd.AddCommand(evalCommands(globalParams)...)
+	commonPolicyCmd.AddCommand(policyTestCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(commonCheckPoliciesCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(commonReloadPoliciesCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(downloadPolicyCommands(globalParams)...)
@@ -467,6 +468,19 @@ type EventData struct {
 	Values map[string]interface{}
 }
 
+func newEventWithType(eventType eval.EventType) (eval.Event, error) {
+	kind := secconfig.ParseEvalEventType(eventType)
+	if kind == model.UnknownEventType {
+		return nil, errors.New("unknown event type")
+	}
+
+	m := &model.Model{}
+	event := m.NewDefaultEventWithType(kind)
+	event.Init()
+
+	return event, nil
+}
+
 func eventDataFromJSON(file string) (eval.Event, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -482,15 +496,11 @@ func eventDataFromJSON(file string) (eval.Event, error) {
 		return nil, err
 	}
 
-	kind := secconfig.ParseEvalEventType(eventData.Type)
-	if kind == model.UnknownEventType {
-		return nil, errors.New("unknown event type")
+	event, err := newEventWithType(eventData.Type)
+	if err != nil {
+		return nil, err
 	}
 
-	m := &model.Model{}
-	event := m.NewDefaultEventWithType(kind)
-	event.Init()
-
 	for k, v := range eventData.Values {
 		switch v := v.(type) {
 		case json.Number:
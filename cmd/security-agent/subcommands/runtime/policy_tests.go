@@ -0,0 +1,347 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux || windows
+
+// Package runtime holds runtime related files
+package runtime
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/cmd/security-agent/flags"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const (
+	policyTestsFormatText  = "text"
+	policyTestsFormatJUnit = "junit"
+)
+
+type policyTestCliParams struct {
+	*command.GlobalParams
+
+	dir        string
+	testsDir   string
+	format     string
+	outputPath string
+}
+
+func policyTestCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	policyTestArgs := &policyTestCliParams{
+		GlobalParams: globalParams,
+	}
+
+	policyTestCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run the event fixtures declared for the rules of the policies and report the rules without tests",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(runPolicyTests,
+				fx.Supply(policyTestArgs),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", false)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	policyTestCmd.Flags().StringVar(&policyTestArgs.dir, flags.PoliciesDir, pkgconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	policyTestCmd.Flags().StringVar(&policyTestArgs.testsDir, flags.TestsDir, "", "Path to the directory of the *"+rules.RuleTestsSuffix+" test files, defaults to the policies directory")
+	policyTestCmd.Flags().StringVar(&policyTestArgs.format, flags.Format, policyTestsFormatText, "Report format: text or junit")
+	policyTestCmd.Flags().StringVar(&policyTestArgs.outputPath, flags.OutputPath, "", "Report output file, defaults to the standard output")
+
+	return []*cobra.Command{policyTestCmd}
+}
+
+func runPolicyTests(_ log.Component, _ config.Component, _ secrets.Component, args *policyTestCliParams) error {
+	if args.format != policyTestsFormatText && args.format != policyTestsFormatJUnit {
+		return fmt.Errorf("unknown report format: %s", args.format)
+	}
+
+	ruleSet, err := loadPolicyTestsRuleSet(args.dir)
+	if err != nil {
+		return err
+	}
+
+	testsDir := args.testsDir
+	if testsDir == "" {
+		testsDir = args.dir
+	}
+
+	tests, err := loadRuleTestsDir(testsDir)
+	if err != nil {
+		return err
+	}
+
+	report := rules.RunRuleTests(ruleSet, newEventWithType, tests)
+
+	writer := io.Writer(os.Stdout)
+	if args.outputPath != "" {
+		f, err := os.Create(args.outputPath)
+		if err != nil {
+			return fmt.Errorf("unable to create report file: %w", err)
+		}
+		defer f.Close()
+		writer = f
+	}
+
+	if args.format == policyTestsFormatJUnit {
+		err = writePolicyTestsJUnit(writer, report)
+	} else {
+		err = writePolicyTestsText(writer, report)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write out report: %w", err)
+	}
+
+	if failures := report.Failures() + len(report.Unknown); failures > 0 {
+		return fmt.Errorf("%d rule test(s) failed", failures)
+	}
+
+	return nil
+}
+
+func loadPolicyTestsRuleSet(dir string) (*rules.RuleSet, error) {
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	ruleOpts, evalOpts := rules.NewEvalOpts(enabled)
+	ruleOpts.WithLogger(seclog.DefaultLogger)
+
+	agentVersionFilter, err := newAgentVersionFilter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent version filter: %w", err)
+	}
+
+	loaderOpts := rules.PolicyLoaderOpts{
+		MacroFilters: []rules.MacroFilter{
+			agentVersionFilter,
+		},
+		RuleFilters: []rules.RuleFilter{
+			agentVersionFilter,
+		},
+	}
+
+	provider, err := rules.NewPoliciesDirProvider(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := rules.NewPolicyLoader(provider)
+
+	ruleSet := rules.NewRuleSet(&model.Model{}, newDefaultEvent, ruleOpts, evalOpts)
+	evaluationSet, err := rules.NewEvaluationSet([]*rules.RuleSet{ruleSet})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := evaluationSet.LoadPolicies(loader, loaderOpts); err.ErrorOrNil() != nil {
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+func loadRuleTestsDir(dir string) ([]*rules.RuleTestsDefinition, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var tests []*rules.RuleTestsDefinition
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), rules.RuleTestsSuffix) {
+			continue
+		}
+
+		defs, err := loadRuleTestsFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to load rule tests from %s: %w", file.Name(), err)
+		}
+		tests = append(tests, defs...)
+	}
+
+	return tests, nil
+}
+
+func loadRuleTestsFile(filename string) ([]*rules.RuleTestsDefinition, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return rules.LoadRuleTests(f)
+}
+
+func writePolicyTestsText(writer io.Writer, report *rules.RuleTestsReport) error {
+	var b strings.Builder
+
+	var total int
+	for _, id := range report.RuleIDs() {
+		for i, result := range report.Results[id] {
+			total++
+			if result.Passed {
+				fmt.Fprintf(&b, "PASS %s %s\n", id, result.Name(i))
+				continue
+			}
+
+			fmt.Fprintf(&b, "FAIL %s %s: %v\n", id, result.Name(i), result.Error)
+			if result.FailedExpression != "" {
+				fmt.Fprintf(&b, "     failed expression: %s\n", result.FailedExpression)
+			}
+		}
+	}
+
+	tested := len(report.Results)
+	rulesCount := tested + len(report.Untested)
+
+	fmt.Fprintf(&b, "\n%d test(s), %d failure(s)\n", total, report.Failures())
+	if rulesCount > 0 {
+		fmt.Fprintf(&b, "Coverage: %d/%d rule(s) tested (%.1f%%)\n", tested, rulesCount, float64(tested)*100/float64(rulesCount))
+	}
+
+	if len(report.Untested) > 0 {
+		fmt.Fprintf(&b, "\nRules without tests:\n")
+		for _, id := range report.Untested {
+			fmt.Fprintf(&b, "  - %s\n", id)
+		}
+	}
+
+	if len(report.Unknown) > 0 {
+		fmt.Fprintf(&b, "\nTests of unknown rules:\n")
+		for _, id := range report.Unknown {
+			fmt.Fprintf(&b, "  - %s\n", id)
+		}
+	}
+
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func newJUnitTestSuite(name string, cases []junitTestCase) junitTestSuite {
+	suite := junitTestSuite{
+		Name:  name,
+		Tests: len(cases),
+		Cases: cases,
+	}
+	for _, c := range cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		if c.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	return suite
+}
+
+func writePolicyTestsJUnit(writer io.Writer, report *rules.RuleTestsReport) error {
+	suites := junitTestSuites{
+		Name: "policies",
+	}
+
+	for _, id := range report.RuleIDs() {
+		var cases []junitTestCase
+		for i, result := range report.Results[id] {
+			c := junitTestCase{
+				Name:      result.Name(i),
+				ClassName: id,
+			}
+			if !result.Passed {
+				c.Failure = &junitMessage{Message: result.Error.Error()}
+				if result.FailedExpression != "" {
+					c.Failure.Content = "failed expression: " + result.FailedExpression
+				}
+			}
+			cases = append(cases, c)
+		}
+		suites.Suites = append(suites.Suites, newJUnitTestSuite(id, cases))
+	}
+
+	for _, id := range report.Untested {
+		suites.Suites = append(suites.Suites, newJUnitTestSuite(id, []junitTestCase{{
+			Name:      "coverage",
+			ClassName: id,
+			Skipped:   &junitMessage{Message: "no tests"},
+		}}))
+	}
+
+	for _, id := range report.Unknown {
+		suites.Suites = append(suites.Suites, newJUnitTestSuite(id, []junitTestCase{{
+			Name:      "coverage",
+			ClassName: id,
+			Failure:   &junitMessage{Message: fmt.Sprintf("tests defined for unknown rule `%s`", id)},
+		}}))
+	}
+
+	for _, suite := range suites.Suites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const testPolicy = `
+rules:
+  - id: shadow_open
+    expression: open.file.path == "/etc/shadow" && process.uid != 0
+    tests:
+      positive:
+        - name: user
+          type: open
+          values:
+            open.file.path: /etc/shadow
+            process.uid: 1000
+        - name: root
+          type: open
+          values:
+            open.file.path: /etc/shadow
+            process.uid: 0
+  - id: passwd_open
+    expression: open.file.path == "/etc/passwd"
+  - id: tmp_exec
+    expression: exec.file.path =~ "/tmp/*"
+`
+
+const testRuleTestsFile = `
+tests:
+  - rule_id: tmp_exec
+    negative:
+      - type: exec
+        values:
+          exec.file.path: /usr/bin/ls
+`
+
+func TestPolicyTestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		policyTestCommands(&command.GlobalParams{}),
+		[]string{"test", "--policies-dir", "/tmp/policies", "--format", "junit"},
+		runPolicyTests,
+		func(cliParams *policyTestCliParams, params core.BundleParams) {
+			require.Equal(t, "/tmp/policies", cliParams.dir)
+			require.Equal(t, policyTestsFormatJUnit, cliParams.format)
+		},
+	)
+}
+
+func TestRunPolicyTests(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.policy"), []byte(testPolicy), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test"+rules.RuleTestsSuffix), []byte(testRuleTestsFile), 0644))
+
+	ruleSet, err := loadPolicyTestsRuleSet(dir)
+	require.NoError(t, err)
+	tests, err := loadRuleTestsDir(dir)
+	require.NoError(t, err)
+	require.Len(t, tests, 1)
+
+	report := rules.RunRuleTests(ruleSet, newEventWithType, tests)
+	assert.Equal(t, 1, report.Failures())
+	assert.Equal(t, []string{"passwd_open"}, report.Untested)
+
+	t.Run("text", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, writePolicyTestsText(&b, report))
+		assert.Equal(t, `PASS shadow_open positive/user
+FAIL shadow_open positive/root: rule `+"`shadow_open`"+` didn't match a positive fixture
+     failed expression: process.uid != 0
+PASS tmp_exec negative/0
+
+3 test(s), 1 failure(s)
+Coverage: 2/3 rule(s) tested (66.7%)
+
+Rules without tests:
+  - passwd_open
+`, b.String())
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, writePolicyTestsJUnit(&b, report))
+
+		var suites junitTestSuites
+		require.NoError(t, xml.Unmarshal(b.Bytes(), &suites))
+		assert.Equal(t, 4, suites.Tests)
+		assert.Equal(t, 1, suites.Failures)
+		assert.Equal(t, 1, suites.Skipped)
+		require.Len(t, suites.Suites, 3)
+		assert.Equal(t, "shadow_open", suites.Suites[0].Name)
+		require.NotNil(t, suites.Suites[0].Cases[1].Failure)
+		assert.Equal(t, "failed expression: process.uid != 0", suites.Suites[0].Cases[1].Failure.Content)
+		assert.Equal(t, "passwd_open", suites.Suites[2].Name)
+		assert.NotNil(t, suites.Suites[2].Cases[0].Skipped)
+	})
+}
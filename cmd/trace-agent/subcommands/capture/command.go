@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package capture implements 'trace-agent capture'.
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/trace-agent/subcommands"
+	coreconfig "github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/core/secrets/secretsimpl"
+	"github.com/DataDog/datadog-agent/comp/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for this subcommand.
+type cliParams struct {
+	duration time.Duration
+}
+
+// MakeCommand returns the capture subcommand for the 'trace-agent' command.
+func MakeCommand(globalParamsGetter func() *subcommands.GlobalParams) *cobra.Command {
+	cliParams := &cliParams{}
+	captureCmd := &cobra.Command{
+		Use:   "capture",
+		Short: "Capture the traces payloads received by the trace-agent.",
+		Long: `Use this to record the traces payloads received by the running trace-agent
+to a file, which can be replayed with 'trace-agent replay'.`,
+		RunE: func(*cobra.Command, []string) error {
+			params := globalParamsGetter()
+			return fxutil.OneShot(capture,
+				fx.Supply(cliParams),
+				config.Module,
+				fx.Supply(coreconfig.NewAgentParams(params.ConfPath)),
+				fx.Supply(secrets.NewEnabledParams()),
+				coreconfig.Module,
+				secretsimpl.Module,
+			)
+		},
+	}
+	captureCmd.Flags().DurationVarP(&cliParams.duration, "duration", "d", time.Minute, "Duration of the capture.")
+
+	return captureCmd
+}
+
+func capture(config config.Component, cliParams *cliParams) error {
+	tracecfg := config.Object()
+	if tracecfg == nil {
+		return fmt.Errorf("Unable to successfully parse config")
+	}
+	if tracecfg.DebugServerPort == 0 {
+		return fmt.Errorf("the trace-agent debug server is disabled (apm_config.debug.port: 0)")
+	}
+	u := fmt.Sprintf("http://127.0.0.1:%d/debug/capture?duration=%s", tracecfg.DebugServerPort, url.QueryEscape(cliParams.duration.String()))
+	resp, err := http.Post(u, "", nil)
+	if err != nil {
+		return fmt.Errorf("could not reach the trace-agent: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("could not start the capture: %s: %s", resp.Status, body)
+	}
+	var cr api.CaptureResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return err
+	}
+	fmt.Printf("Capturing the traces payloads for %s to %s\n", cr.Duration, cr.Path)
+	return nil
+}
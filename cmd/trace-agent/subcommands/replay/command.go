@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package replay implements 'trace-agent replay'.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/trace-agent/subcommands"
+	coreconfig "github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/core/secrets/secretsimpl"
+	"github.com/DataDog/datadog-agent/comp/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for this subcommand.
+type cliParams struct {
+	file   string
+	target string
+	speed  float64
+}
+
+// MakeCommand returns the replay subcommand for the 'trace-agent' command.
+func MakeCommand(globalParamsGetter func() *subcommands.GlobalParams) *cobra.Command {
+	cliParams := &cliParams{}
+	replayCmd := &cobra.Command{
+		Use:   "replay FILE",
+		Short: "Replay traces payloads captured with 'trace-agent capture'.",
+		Long: `Use this to send the traces payloads of a capture file to a trace-agent,
+at the rate at which they were captured or at an accelerated rate.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliParams.file = args[0]
+			params := globalParamsGetter()
+			return fxutil.OneShot(replayFile,
+				fx.Supply(cliParams),
+				config.Module,
+				fx.Supply(coreconfig.NewAgentParams(params.ConfPath)),
+				fx.Supply(secrets.NewEnabledParams()),
+				coreconfig.Module,
+				secretsimpl.Module,
+			)
+		},
+	}
+	replayCmd.Flags().StringVarP(&cliParams.target, "target", "t", "", "URL of the trace-agent to replay the payloads to. Defaults to the local trace-agent.")
+	replayCmd.Flags().Float64VarP(&cliParams.speed, "speed", "s", 1, "Replay speed relative to the captured rate, e.g. 2 to replay twice as fast. 0 replays as fast as possible.")
+
+	return replayCmd
+}
+
+func replayFile(config config.Component, cliParams *cliParams) error {
+	if cliParams.speed < 0 {
+		return fmt.Errorf("invalid replay speed: %v", cliParams.speed)
+	}
+	target := cliParams.target
+	if target == "" {
+		tracecfg := config.Object()
+		if tracecfg == nil {
+			return fmt.Errorf("Unable to successfully parse config")
+		}
+		if tracecfg.ReceiverPort == 0 {
+			return fmt.Errorf("the trace-agent receiver is disabled (apm_config.receiver_port: 0), use --target")
+		}
+		target = "http://" + net.JoinHostPort(tracecfg.ReceiverHost, strconv.Itoa(tracecfg.ReceiverPort))
+	}
+	f, err := os.Open(cliParams.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := replay.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cliParams.file, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("Replaying %s to %s...\n", cliParams.file, target)
+	rp := &replay.Replayer{Target: target, Speed: cliParams.speed}
+	stats, err := rp.Replay(ctx, r)
+	fmt.Printf("Sent %d payloads, %d failed\n", stats.Sent, stats.Failed)
+	return err
+}
@@ -59,6 +59,18 @@ func (e *cachedEntity) get(source Source) Entity {
 	return e.sources[source]
 }
 
+// hasLiveSource returns whether the entity is reported by at least one
+// source other than SourceSnapshot.
+func (e *cachedEntity) hasLiveSource() bool {
+	for source := range e.sources {
+		if source != SourceSnapshot {
+			return true
+		}
+	}
+
+	return false
+}
+
 // computeCache merges the entities in e.sources into one and caches the result
 // in e.cached. Priority is established by the string representation of the
 // source in alphabetical order, and data is considered missing if it's a zero
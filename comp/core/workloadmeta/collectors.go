@@ -30,6 +30,20 @@ type Collector interface {
 	GetTargetCatalog() AgentType
 }
 
+// StreamingCollector is implemented by collectors that stream the changes of
+// the entities instead of reporting them on Pull, which is then a no-op.
+type StreamingCollector interface {
+	Collector
+
+	// IsStreaming returns whether the collector streams the changes of the
+	// entities.
+	IsStreaming() bool
+
+	// HasSynced returns whether the collector reported every existing
+	// entity, that is whether its initial list or sync completed.
+	HasSynced() bool
+}
+
 // CollectorProvider is the collector fx value group
 type CollectorProvider struct {
 	fx.Out
@@ -173,6 +173,16 @@ func (c *collector) Pull(_ context.Context) error {
 	return nil
 }
 
+// IsStreaming returns true: the changes of the entities are streamed.
+func (c *collector) IsStreaming() bool {
+	return true
+}
+
+// HasSynced returns true: the existing entities are reported by Start.
+func (c *collector) HasSynced() bool {
+	return true
+}
+
 func (c *collector) GetID() string {
 	return c.id
 }
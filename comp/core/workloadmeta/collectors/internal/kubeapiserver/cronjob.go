@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package kubeapiserver
+
+import (
+	"context"
+	"regexp"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func newCronJobStore(ctx context.Context, wlm workloadmeta.Component, client kubernetes.Interface) (*cache.Reflector, *reflectorStore) {
+	cronJobListerWatcher := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.BatchV1().CronJobs(metav1.NamespaceAll).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.BatchV1().CronJobs(metav1.NamespaceAll).Watch(ctx, options)
+		},
+	}
+
+	cronJobStore := newCronJobReflectorStore(wlm)
+	cronJobReflector := cache.NewNamedReflector(
+		componentName,
+		cronJobListerWatcher,
+		&batchv1.CronJob{},
+		cronJobStore,
+		noResync,
+	)
+	log.Debug("cronjob reflector enabled")
+	return cronJobReflector, cronJobStore
+}
+
+func newCronJobReflectorStore(wlmetaStore workloadmeta.Component) *reflectorStore {
+	return &reflectorStore{
+		wlmetaStore: wlmetaStore,
+		seen:        make(map[string]workloadmeta.EntityID),
+		parser:      newCronJobParser(annotationsExcludeFilters()),
+	}
+}
+
+type cronJobParser struct {
+	annotationsFilter []*regexp.Regexp
+}
+
+func newCronJobParser(annotationsFilter []*regexp.Regexp) objectParser {
+	return cronJobParser{annotationsFilter: annotationsFilter}
+}
+
+func (p cronJobParser) Parse(obj interface{}) workloadmeta.Entity {
+	cronJob := obj.(*batchv1.CronJob)
+
+	return &workloadmeta.KubernetesCronJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesCronJob,
+			ID:   cronJob.Namespace + "/" + cronJob.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        cronJob.Name,
+			Namespace:   cronJob.Namespace,
+			Annotations: filterMapStringKey(cronJob.Annotations, p.annotationsFilter),
+			Labels:      cronJob.Labels,
+		},
+	}
+}
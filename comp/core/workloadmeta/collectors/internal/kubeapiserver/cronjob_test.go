@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver && test
+
+package kubeapiserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+)
+
+func TestCronJobParser_Parse(t *testing.T) {
+	filters, err := parseFilters([]string{"ignoreAnnotation"})
+	require.NoError(t, err)
+
+	parser := newCronJobParser(filters)
+	objectMeta := metav1.ObjectMeta{
+		Name:      "test-cronjob",
+		Namespace: "default",
+		Labels:    map[string]string{"test-label": "test-value"},
+		Annotations: map[string]string{
+			"test-annotation":  "test-value",
+			"ignoreAnnotation": "ignoreValue",
+		},
+	}
+	expected := &workloadmeta.KubernetesCronJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesCronJob,
+			ID:   objectMeta.Namespace + "/" + objectMeta.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        objectMeta.Name,
+			Namespace:   objectMeta.Namespace,
+			Labels:      objectMeta.Labels,
+			Annotations: map[string]string{"test-annotation": "test-value"},
+		},
+	}
+
+	entity := parser.Parse(&batchv1.CronJob{ObjectMeta: objectMeta})
+	stored, ok := entity.(*workloadmeta.KubernetesCronJob)
+	require.True(t, ok)
+	assert.Equal(t, expected, stored)
+}
+
+func Test_CronJobsFakeKubernetesClient(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{
+		Name:      "test-cronjob",
+		Namespace: "default",
+		Labels:    map[string]string{"test-label": "test-value"},
+	}
+
+	createResource := func(cl *fake.Clientset) error {
+		_, err := cl.BatchV1().CronJobs(objectMeta.Namespace).Create(context.TODO(), &batchv1.CronJob{ObjectMeta: objectMeta}, metav1.CreateOptions{})
+		return err
+	}
+	expected := workloadmeta.EventBundle{
+		Events: []workloadmeta.Event{
+			{
+				Type: workloadmeta.EventTypeSet,
+				Entity: &workloadmeta.KubernetesCronJob{
+					EntityID: workloadmeta.EntityID{
+						ID:   objectMeta.Namespace + "/" + objectMeta.Name,
+						Kind: workloadmeta.KindKubernetesCronJob,
+					},
+					EntityMeta: workloadmeta.EntityMeta{
+						Name:      objectMeta.Name,
+						Namespace: objectMeta.Namespace,
+						Labels:    objectMeta.Labels,
+					},
+				},
+			},
+		},
+	}
+	testCollectEvent(t, createResource, newCronJobStore, expected)
+}
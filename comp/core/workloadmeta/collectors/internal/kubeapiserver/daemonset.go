@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package kubeapiserver
+
+import (
+	"context"
+	"regexp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func newDaemonSetStore(ctx context.Context, wlm workloadmeta.Component, client kubernetes.Interface) (*cache.Reflector, *reflectorStore) {
+	daemonSetListerWatcher := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.AppsV1().DaemonSets(metav1.NamespaceAll).Watch(ctx, options)
+		},
+	}
+
+	daemonSetStore := newDaemonSetReflectorStore(wlm)
+	daemonSetReflector := cache.NewNamedReflector(
+		componentName,
+		daemonSetListerWatcher,
+		&appsv1.DaemonSet{},
+		daemonSetStore,
+		noResync,
+	)
+	log.Debug("daemonset reflector enabled")
+	return daemonSetReflector, daemonSetStore
+}
+
+func newDaemonSetReflectorStore(wlmetaStore workloadmeta.Component) *reflectorStore {
+	return &reflectorStore{
+		wlmetaStore: wlmetaStore,
+		seen:        make(map[string]workloadmeta.EntityID),
+		parser:      newDaemonSetParser(annotationsExcludeFilters()),
+	}
+}
+
+type daemonSetParser struct {
+	annotationsFilter []*regexp.Regexp
+}
+
+func newDaemonSetParser(annotationsFilter []*regexp.Regexp) objectParser {
+	return daemonSetParser{annotationsFilter: annotationsFilter}
+}
+
+func (p daemonSetParser) Parse(obj interface{}) workloadmeta.Entity {
+	daemonSet := obj.(*appsv1.DaemonSet)
+
+	return &workloadmeta.KubernetesDaemonSet{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesDaemonSet,
+			ID:   daemonSet.Namespace + "/" + daemonSet.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        daemonSet.Name,
+			Namespace:   daemonSet.Namespace,
+			Annotations: filterMapStringKey(daemonSet.Annotations, p.annotationsFilter),
+			Labels:      daemonSet.Labels,
+		},
+	}
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package kubeapiserver
+
+import (
+	"context"
+	"regexp"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func newJobStore(ctx context.Context, wlm workloadmeta.Component, client kubernetes.Interface) (*cache.Reflector, *reflectorStore) {
+	jobListerWatcher := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.BatchV1().Jobs(metav1.NamespaceAll).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.BatchV1().Jobs(metav1.NamespaceAll).Watch(ctx, options)
+		},
+	}
+
+	jobStore := newJobReflectorStore(wlm)
+	jobReflector := cache.NewNamedReflector(
+		componentName,
+		jobListerWatcher,
+		&batchv1.Job{},
+		jobStore,
+		noResync,
+	)
+	log.Debug("job reflector enabled")
+	return jobReflector, jobStore
+}
+
+func newJobReflectorStore(wlmetaStore workloadmeta.Component) *reflectorStore {
+	return &reflectorStore{
+		wlmetaStore: wlmetaStore,
+		seen:        make(map[string]workloadmeta.EntityID),
+		parser:      newJobParser(annotationsExcludeFilters()),
+	}
+}
+
+type jobParser struct {
+	annotationsFilter []*regexp.Regexp
+}
+
+func newJobParser(annotationsFilter []*regexp.Regexp) objectParser {
+	return jobParser{annotationsFilter: annotationsFilter}
+}
+
+func (p jobParser) Parse(obj interface{}) workloadmeta.Entity {
+	job := obj.(*batchv1.Job)
+
+	owners := make([]workloadmeta.KubernetesPodOwner, 0, len(job.OwnerReferences))
+	for _, o := range job.OwnerReferences {
+		owners = append(owners, workloadmeta.KubernetesPodOwner{
+			Kind: o.Kind,
+			Name: o.Name,
+			ID:   string(o.UID),
+		})
+	}
+
+	return &workloadmeta.KubernetesJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesJob,
+			ID:   job.Namespace + "/" + job.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        job.Name,
+			Namespace:   job.Namespace,
+			Annotations: filterMapStringKey(job.Annotations, p.annotationsFilter),
+			Labels:      job.Labels,
+		},
+		Owners: owners,
+	}
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver && test
+
+package kubeapiserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+)
+
+func TestJobParser_Parse(t *testing.T) {
+	filters, err := parseFilters([]string{"ignoreAnnotation"})
+	require.NoError(t, err)
+
+	parser := newJobParser(filters)
+	objectMeta := metav1.ObjectMeta{
+		Name:      "test-cronjob-28291020",
+		Namespace: "default",
+		Labels:    map[string]string{"test-label": "test-value"},
+		Annotations: map[string]string{
+			"test-annotation":  "test-value",
+			"ignoreAnnotation": "ignoreValue",
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				Kind: "CronJob",
+				Name: "test-cronjob",
+				UID:  "cronjobUID",
+			},
+		},
+	}
+	expected := &workloadmeta.KubernetesJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesJob,
+			ID:   objectMeta.Namespace + "/" + objectMeta.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        objectMeta.Name,
+			Namespace:   objectMeta.Namespace,
+			Labels:      objectMeta.Labels,
+			Annotations: map[string]string{"test-annotation": "test-value"},
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{
+			{
+				Kind: "CronJob",
+				Name: "test-cronjob",
+				ID:   "cronjobUID",
+			},
+		},
+	}
+
+	entity := parser.Parse(&batchv1.Job{ObjectMeta: objectMeta})
+	stored, ok := entity.(*workloadmeta.KubernetesJob)
+	require.True(t, ok)
+	assert.Equal(t, expected, stored)
+}
+
+func Test_JobsFakeKubernetesClient(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{
+		Name:      "test-cronjob-28291020",
+		Namespace: "default",
+		Labels:    map[string]string{"test-label": "test-value"},
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				Kind: "CronJob",
+				Name: "test-cronjob",
+				UID:  "cronjobUID",
+			},
+		},
+	}
+
+	createResource := func(cl *fake.Clientset) error {
+		_, err := cl.BatchV1().Jobs(objectMeta.Namespace).Create(context.TODO(), &batchv1.Job{ObjectMeta: objectMeta}, metav1.CreateOptions{})
+		return err
+	}
+	expected := workloadmeta.EventBundle{
+		Events: []workloadmeta.Event{
+			{
+				Type: workloadmeta.EventTypeSet,
+				Entity: &workloadmeta.KubernetesJob{
+					EntityID: workloadmeta.EntityID{
+						ID:   objectMeta.Namespace + "/" + objectMeta.Name,
+						Kind: workloadmeta.KindKubernetesJob,
+					},
+					EntityMeta: workloadmeta.EntityMeta{
+						Name:      objectMeta.Name,
+						Namespace: objectMeta.Namespace,
+						Labels:    objectMeta.Labels,
+					},
+					Owners: []workloadmeta.KubernetesPodOwner{
+						{
+							Kind: "CronJob",
+							Name: "test-cronjob",
+							ID:   "cronjobUID",
+						},
+					},
+				},
+			},
+		},
+	}
+	testCollectEvent(t, createResource, newJobStore, expected)
+}
@@ -43,12 +43,23 @@ func storeGenerators(cfg config.Reader) []storeGenerator {
 		generators = append(generators, newDeploymentStore)
 	}
 
+	if cfg.GetBool("cluster_agent.kubernetes_resources_collection.workloads_enabled") {
+		generators = append(generators, newStatefulSetStore, newDaemonSetStore, newJobStore, newCronJobStore)
+	}
+
+	if cfg.GetBool("cluster_agent.kubernetes_resources_collection.namespaces_enabled") {
+		generators = append(generators, newNamespaceStore)
+	}
+
 	return generators
 }
 
 type collector struct {
 	id      string
 	catalog workloadmeta.AgentType
+
+	// objectStores are set by Start
+	objectStores []*reflectorStore
 }
 
 // NewCollector returns a kubeapiserver CollectorProvider that instantiates its colletor
@@ -81,6 +92,7 @@ func (c *collector) Start(ctx context.Context, wlmetaStore workloadmeta.Componen
 		objectStores = append(objectStores, store)
 		go reflector.Run(ctx.Done())
 	}
+	c.objectStores = objectStores
 	go startReadiness(ctx, objectStores)
 	return nil
 }
@@ -89,6 +101,22 @@ func (c *collector) Pull(_ context.Context) error {
 	return nil
 }
 
+// IsStreaming returns true: the changes of the objects are streamed by the
+// reflectors.
+func (c *collector) IsStreaming() bool {
+	return true
+}
+
+// HasSynced returns whether every reflector completed its initial list.
+func (c *collector) HasSynced() bool {
+	for _, store := range c.objectStores {
+		if !store.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *collector) GetID() string {
 	return c.id
 }
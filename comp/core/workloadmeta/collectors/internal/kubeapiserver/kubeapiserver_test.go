@@ -54,6 +54,23 @@ func TestStoreGenerators(t *testing.T) {
 			},
 			expectedStoresGenerator: []storeGenerator{newNodeStore, newPodStore, newDeploymentStore},
 		},
+		{
+			name: "Workloads and namespaces enabled",
+			cfg: map[string]bool{
+				"cluster_agent.collect_kubernetes_tags":                            false,
+				"language_detection.enabled":                                       false,
+				"cluster_agent.kubernetes_resources_collection.workloads_enabled":  true,
+				"cluster_agent.kubernetes_resources_collection.namespaces_enabled": true,
+			},
+			expectedStoresGenerator: []storeGenerator{
+				newNodeStore,
+				newStatefulSetStore,
+				newDaemonSetStore,
+				newJobStore,
+				newCronJobStore,
+				newNamespaceStore,
+			},
+		},
 	}
 
 	// Run test for each testcase
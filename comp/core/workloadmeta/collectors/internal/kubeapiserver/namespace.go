@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package kubeapiserver
+
+import (
+	"context"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func newNamespaceStore(ctx context.Context, wlm workloadmeta.Component, client kubernetes.Interface) (*cache.Reflector, *reflectorStore) {
+	namespaceListerWatcher := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Namespaces().List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Namespaces().Watch(ctx, options)
+		},
+	}
+
+	namespaceStore := newNamespaceReflectorStore(wlm)
+	namespaceReflector := cache.NewNamedReflector(
+		componentName,
+		namespaceListerWatcher,
+		&corev1.Namespace{},
+		namespaceStore,
+		noResync,
+	)
+	log.Debug("namespace reflector enabled")
+	return namespaceReflector, namespaceStore
+}
+
+func newNamespaceReflectorStore(wlmetaStore workloadmeta.Component) *reflectorStore {
+	return &reflectorStore{
+		wlmetaStore: wlmetaStore,
+		seen:        make(map[string]workloadmeta.EntityID),
+		parser:      newNamespaceParser(annotationsExcludeFilters()),
+	}
+}
+
+type namespaceParser struct {
+	annotationsFilter []*regexp.Regexp
+}
+
+func newNamespaceParser(annotationsFilter []*regexp.Regexp) objectParser {
+	return namespaceParser{annotationsFilter: annotationsFilter}
+}
+
+func (p namespaceParser) Parse(obj interface{}) workloadmeta.Entity {
+	namespace := obj.(*corev1.Namespace)
+
+	return &workloadmeta.KubernetesNamespace{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesNamespace,
+			ID:   namespace.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        namespace.Name,
+			Annotations: filterMapStringKey(namespace.Annotations, p.annotationsFilter),
+			Labels:      namespace.Labels,
+		},
+	}
+}
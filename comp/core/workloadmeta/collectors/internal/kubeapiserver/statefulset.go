@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package kubeapiserver
+
+import (
+	"context"
+	"regexp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func newStatefulSetStore(ctx context.Context, wlm workloadmeta.Component, client kubernetes.Interface) (*cache.Reflector, *reflectorStore) {
+	statefulSetListerWatcher := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.AppsV1().StatefulSets(metav1.NamespaceAll).Watch(ctx, options)
+		},
+	}
+
+	statefulSetStore := newStatefulSetReflectorStore(wlm)
+	statefulSetReflector := cache.NewNamedReflector(
+		componentName,
+		statefulSetListerWatcher,
+		&appsv1.StatefulSet{},
+		statefulSetStore,
+		noResync,
+	)
+	log.Debug("statefulset reflector enabled")
+	return statefulSetReflector, statefulSetStore
+}
+
+func newStatefulSetReflectorStore(wlmetaStore workloadmeta.Component) *reflectorStore {
+	return &reflectorStore{
+		wlmetaStore: wlmetaStore,
+		seen:        make(map[string]workloadmeta.EntityID),
+		parser:      newStatefulSetParser(annotationsExcludeFilters()),
+	}
+}
+
+type statefulSetParser struct {
+	annotationsFilter []*regexp.Regexp
+}
+
+func newStatefulSetParser(annotationsFilter []*regexp.Regexp) objectParser {
+	return statefulSetParser{annotationsFilter: annotationsFilter}
+}
+
+func (p statefulSetParser) Parse(obj interface{}) workloadmeta.Entity {
+	statefulSet := obj.(*appsv1.StatefulSet)
+
+	return &workloadmeta.KubernetesStatefulSet{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesStatefulSet,
+			ID:   statefulSet.Namespace + "/" + statefulSet.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        statefulSet.Name,
+			Namespace:   statefulSet.Namespace,
+			Annotations: filterMapStringKey(statefulSet.Annotations, p.annotationsFilter),
+			Labels:      statefulSet.Labels,
+		},
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"regexp"
 
 	utilserror "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 func filterMapStringKey(mapInput map[string]string, keyFilters []*regexp.Regexp) map[string]string {
@@ -52,3 +55,15 @@ func filterToRegex(filter string) (*regexp.Regexp, error) {
 	}
 	return r, nil
 }
+
+// annotationsExcludeFilters returns the annotation filters configured for the
+// pods, which also apply to the other collected resources.
+func annotationsExcludeFilters() []*regexp.Regexp {
+	annotationsExclude := config.Datadog.GetStringSlice("cluster_agent.kubernetes_resources_collection.pod_annotations_exclude")
+	filters, err := parseFilters(annotationsExclude)
+	if err != nil {
+		_ = log.Errorf("unable to parse all pod_annotations_exclude: %v, err:", err)
+	}
+
+	return filters
+}
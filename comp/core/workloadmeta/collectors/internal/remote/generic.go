@@ -17,6 +17,7 @@ import (
 
 	"github.com/cenkalti/backoff"
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -70,6 +71,9 @@ type GenericCollector struct {
 
 	store        workloadmeta.Component
 	resyncNeeded bool
+	// synced is set once the entities of the first response, which are all
+	// the entities of the remote workloadmeta, were notified to the store
+	synced atomic.Bool
 
 	client GrpcClient
 	stream Stream
@@ -134,6 +138,18 @@ func (c *GenericCollector) Pull(context.Context) error {
 	return nil
 }
 
+// IsStreaming returns true: the changes of the entities are streamed by the
+// remote workloadmeta.
+func (c *GenericCollector) IsStreaming() bool {
+	return true
+}
+
+// HasSynced returns whether the entities of the first response of the stream
+// were notified to the store.
+func (c *GenericCollector) HasSynced() bool {
+	return c.synced.Load()
+}
+
 func (c *GenericCollector) startWorkloadmetaStream(maxElapsed time.Duration) error {
 	expBackoff := backoff.NewExponentialBackOff()
 	expBackoff.InitialInterval = 500 * time.Millisecond
@@ -232,6 +248,7 @@ func (c *GenericCollector) Run() {
 		}
 
 		c.store.Notify(collectorEvents)
+		c.synced.Store(true)
 	}
 }
 
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	tcpListenState = 0x0A // TCP_LISTEN
+	udpCloseState  = 0x07 // TCP_CLOSE, the state of bound unconnected UDP sockets
+)
+
+// listeningPorts returns the TCP ports the process listens on and the UDP
+// ports it is bound to.
+func listeningPorts(pid int) []workloadmeta.ContainerPort {
+	fs, err := procfs.NewFS(kernel.HostProc())
+	if err != nil {
+		log.Debugf("cannot open procfs: %s", err)
+		return nil
+	}
+
+	proc, err := fs.Proc(pid)
+	if err != nil {
+		log.Debugf("cannot open process %d: %s", pid, err)
+		return nil
+	}
+
+	targets, err := proc.FileDescriptorTargets()
+	if err != nil {
+		log.Debugf("cannot list file descriptors of process %d: %s", pid, err)
+		return nil
+	}
+
+	inodes := make(map[uint64]struct{})
+	for _, target := range targets {
+		if !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+		if err != nil {
+			continue
+		}
+		inodes[inode] = struct{}{}
+	}
+
+	if len(inodes) == 0 {
+		return nil
+	}
+
+	// the sockets tables of the process network namespace
+	netFS, err := procfs.NewFS(kernel.HostProc(strconv.Itoa(pid)))
+	if err != nil {
+		log.Debugf("cannot open procfs of process %d: %s", pid, err)
+		return nil
+	}
+
+	seen := make(map[workloadmeta.ContainerPort]struct{})
+
+	collect := func(lines procfs.NetIPSocket, protocol string, state uint64) {
+		for _, line := range lines {
+			if line.St != state {
+				continue
+			}
+			if _, ok := inodes[line.Inode]; !ok {
+				continue
+			}
+			seen[workloadmeta.ContainerPort{Port: int(line.LocalPort), Protocol: protocol}] = struct{}{}
+		}
+	}
+
+	if tcp, err := netFS.NetTCP(); err == nil {
+		collect(procfs.NetIPSocket(tcp), "tcp", tcpListenState)
+	}
+	if tcp6, err := netFS.NetTCP6(); err == nil {
+		collect(procfs.NetIPSocket(tcp6), "tcp", tcpListenState)
+	}
+	if udp, err := netFS.NetUDP(); err == nil {
+		collect(procfs.NetIPSocket(udp), "udp", udpCloseState)
+	}
+	if udp6, err := netFS.NetUDP6(); err == nil {
+		collect(procfs.NetIPSocket(udp6), "udp", udpCloseState)
+	}
+
+	ports := make([]workloadmeta.ContainerPort, 0, len(seen))
+	for port := range seen {
+		ports = append(ports, port)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+
+	return ports
+}
@@ -0,0 +1,465 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+// Package systemd implements the systemd Workloadmeta collector.
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/dbus"
+	"go.uber.org/fx"
+	"golang.org/x/exp/slices"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+const (
+	collectorID   = "systemd"
+	componentName = "workloadmeta-systemd"
+
+	serviceSuffix   = ".service"
+	unitActiveState = "active"
+
+	// resyncInterval is the interval at which the full list of units is
+	// compared with the collected ones, to recover from sub state updates
+	// dropped by the D-Bus client and from connection losses.
+	resyncInterval = 5 * time.Minute
+
+	// updateBufferSize is the size of the sub state updates channel. Updates
+	// are dropped by the D-Bus client when it is full.
+	updateBufferSize = 100
+)
+
+// unitProperties are the unit and service properties exposed on the
+// workloadmeta entities.
+var unitProperties = []string{"Description", "FragmentPath", "LoadState", "UnitFileState"}
+var serviceProperties = []string{"Type", "User", "Group", "ExecMainStartTimestamp"}
+
+// systemdConn is the subset of *dbus.Conn used by the collector.
+type systemdConn interface {
+	ListUnits() ([]dbus.UnitStatus, error)
+	GetUnitProperties(unit string) (map[string]interface{}, error)
+	GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error)
+	Subscribe() error
+	SetSubStateSubscriber(updateCh chan<- *dbus.SubStateUpdate, errCh chan<- error)
+	Close()
+}
+
+// unitState is the state of a collected unit, used to only query the
+// properties of units whose state changed.
+type unitState struct {
+	activeState string
+	subState    string
+}
+
+func stateOf(unit *workloadmeta.SystemdUnit) unitState {
+	return unitState{activeState: unit.ActiveState, subState: unit.SubState}
+}
+
+type collector struct {
+	id      string
+	store   workloadmeta.Component
+	catalog workloadmeta.AgentType
+
+	// units holds the last entity notified to the store for each collected
+	// unit.
+	units map[string]*workloadmeta.SystemdUnit
+
+	unitNames map[string]struct{}
+
+	conn      systemdConn
+	updatesCh chan *dbus.SubStateUpdate
+	errorsCh  chan error
+
+	connect        func() (systemdConn, error)
+	listeningPorts func(pid int) []workloadmeta.ContainerPort
+}
+
+// NewCollector returns a new systemd collector provider and an error
+func NewCollector() (workloadmeta.CollectorProvider, error) {
+	return workloadmeta.CollectorProvider{
+		Collector: &collector{
+			id:      collectorID,
+			units:   make(map[string]*workloadmeta.SystemdUnit),
+			catalog: workloadmeta.NodeAgent,
+		},
+	}, nil
+}
+
+// GetFxOptions returns the FX framework options for the collector
+func GetFxOptions() fx.Option {
+	return fx.Provide(NewCollector)
+}
+
+// Start the collector for the provided workloadmeta component
+func (c *collector) Start(ctx context.Context, store workloadmeta.Component) error {
+	if !config.Datadog.GetBool("systemd_unit_discovery.enabled") {
+		return dderrors.NewDisabled(componentName, "systemd unit discovery is disabled")
+	}
+
+	privateSocket := config.Datadog.GetString("systemd_unit_discovery.private_socket")
+	c.connect = func() (systemdConn, error) {
+		return systemdutil.Connect(privateSocket)
+	}
+	c.listeningPorts = listeningPorts
+	c.unitNames = make(map[string]struct{})
+	for _, name := range config.Datadog.GetStringSlice("systemd_unit_discovery.unit_names") {
+		c.unitNames[normalizeUnitName(name)] = struct{}{}
+	}
+	c.store = store
+
+	if err := c.subscribe(); err != nil {
+		return err
+	}
+
+	if err := c.resync(); err != nil {
+		c.conn.Close()
+		return err
+	}
+
+	go c.stream(ctx)
+
+	return nil
+}
+
+// Pull is a no-op: units are collected when systemd reports a change of
+// their sub state, and periodically resynced by stream.
+func (c *collector) Pull(_ context.Context) error {
+	return nil
+}
+
+// IsStreaming returns true: the changes of the entities are streamed.
+func (c *collector) IsStreaming() bool {
+	return true
+}
+
+// HasSynced returns true: the existing entities are reported by Start.
+func (c *collector) HasSynced() bool {
+	return true
+}
+
+func (c *collector) GetID() string {
+	return c.id
+}
+
+func (c *collector) GetTargetCatalog() workloadmeta.AgentType {
+	return c.catalog
+}
+
+func (c *collector) stream(ctx context.Context) {
+	health := health.RegisterLiveness(componentName)
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-health.C:
+
+		case update := <-c.updatesCh:
+			// updates may be left from a connection which failed to be
+			// replaced
+			if c.conn != nil {
+				c.handleUpdate(update.UnitName)
+			}
+
+		case err := <-c.errorsCh:
+			log.Debugf("error while receiving systemd unit updates: %s", err)
+
+		case <-ticker.C:
+			c.periodicResync()
+
+		case <-ctx.Done():
+			if c.conn != nil {
+				c.conn.Close()
+			}
+
+			err := health.Deregister()
+			if err != nil {
+				log.Warnf("error de-registering health check: %s", err)
+			}
+
+			return
+		}
+	}
+}
+
+// subscribe opens the connection to systemd and subscribes to the sub state
+// updates of the units.
+func (c *collector) subscribe() error {
+	conn, err := c.connect()
+	if err != nil {
+		return fmt.Errorf("cannot connect to systemd: %w", err)
+	}
+
+	if err := conn.Subscribe(); err != nil {
+		conn.Close()
+		return fmt.Errorf("cannot subscribe to systemd unit updates: %w", err)
+	}
+
+	c.updatesCh = make(chan *dbus.SubStateUpdate, updateBufferSize)
+	c.errorsCh = make(chan error, updateBufferSize)
+	conn.SetSubStateSubscriber(c.updatesCh, c.errorsCh)
+	c.conn = conn
+
+	return nil
+}
+
+// reconnect replaces the connection to systemd. When it fails, no connection
+// is left and a fresh one is dialed on the next tick.
+func (c *collector) reconnect() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+
+	if err := c.subscribe(); err != nil {
+		log.Warnf("cannot reconnect to systemd: %s", err)
+	}
+}
+
+// periodicResync resyncs the units, after dialing a fresh connection if the
+// previous reconnection failed. The connection is replaced when the resync
+// fails, and the units are resynced on the next tick.
+func (c *collector) periodicResync() {
+	if c.conn == nil {
+		c.reconnect()
+		if c.conn == nil {
+			return
+		}
+	}
+
+	if err := c.resync(); err != nil {
+		log.Warnf("cannot resync systemd units, reconnecting: %s", err)
+		c.reconnect()
+	}
+}
+
+// resync lists the units and notifies the store about the collected units
+// whose state or listening ports changed since they were last seen, and about
+// the ones that are gone. The properties of units whose state did not change
+// are not queried.
+func (c *collector) resync() error {
+	units, err := c.conn.ListUnits()
+	if err != nil {
+		return fmt.Errorf("cannot list systemd units: %w", err)
+	}
+
+	current := make(map[string]struct{}, len(units))
+	var events []workloadmeta.CollectorEvent
+
+	for _, unit := range units {
+		if !c.isCollected(unit.Name, unit.ActiveState) {
+			continue
+		}
+		current[unit.Name] = struct{}{}
+
+		state := unitState{activeState: unit.ActiveState, subState: unit.SubState}
+		if known, ok := c.units[unit.Name]; ok && stateOf(known) == state {
+			// a running unit can start or stop listening on ports
+			// without any change of its state
+			if event, ok := c.refreshPorts(known); ok {
+				events = append(events, event)
+			}
+			continue
+		}
+
+		unitProps, err := c.conn.GetUnitProperties(unit.Name)
+		if err != nil {
+			log.Debugf("cannot get properties of systemd unit %s: %s", unit.Name, err)
+		}
+
+		events = append(events, c.setOrUnset(unit.Name, state, unitProps)...)
+	}
+
+	for name := range c.units {
+		if _, ok := current[name]; !ok {
+			events = append(events, c.unset(name))
+		}
+	}
+
+	if len(events) > 0 {
+		c.store.Notify(events)
+	}
+
+	return nil
+}
+
+// handleUpdate notifies the store about a unit whose sub state changed.
+func (c *collector) handleUpdate(name string) {
+	_, known := c.units[name]
+	if !known && !c.isCollected(name, unitActiveState) {
+		return
+	}
+
+	unitProps, err := c.conn.GetUnitProperties(name)
+	if err != nil {
+		log.Debugf("cannot get properties of systemd unit %s: %s", name, err)
+		return
+	}
+
+	activeState, _ := unitProps["ActiveState"].(string)
+	subState, _ := unitProps["SubState"].(string)
+	state := unitState{activeState: activeState, subState: subState}
+
+	if known && stateOf(c.units[name]) == state {
+		return
+	}
+
+	events := c.setOrUnset(name, state, unitProps)
+	if len(events) > 0 {
+		c.store.Notify(events)
+	}
+}
+
+// setOrUnset returns the event for a unit in the given state: a set event if
+// it is collected, an unset event if it was collected before.
+func (c *collector) setOrUnset(name string, state unitState, unitProps map[string]interface{}) []workloadmeta.CollectorEvent {
+	if !c.isCollected(name, state.activeState) {
+		if _, ok := c.units[name]; ok {
+			return []workloadmeta.CollectorEvent{c.unset(name)}
+		}
+		return nil
+	}
+
+	entity, err := c.buildEntity(name, state, unitProps)
+	if err != nil {
+		log.Debugf("cannot collect systemd unit %s: %s", name, err)
+		return nil
+	}
+
+	c.units[name] = entity
+
+	return []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: entity,
+		},
+	}
+}
+
+// refreshPorts reads the listening ports of the main process of a collected
+// unit again, and returns a set event if they changed.
+func (c *collector) refreshPorts(known *workloadmeta.SystemdUnit) (workloadmeta.CollectorEvent, bool) {
+	if known.MainPID <= 0 {
+		return workloadmeta.CollectorEvent{}, false
+	}
+
+	ports := c.listeningPorts(known.MainPID)
+	if slices.Equal(ports, known.Ports) {
+		return workloadmeta.CollectorEvent{}, false
+	}
+
+	entity := *known
+	entity.Ports = ports
+	c.units[entity.ID] = &entity
+
+	return workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeSet,
+		Source: workloadmeta.SourceHost,
+		Entity: &entity,
+	}, true
+}
+
+func (c *collector) unset(name string) workloadmeta.CollectorEvent {
+	delete(c.units, name)
+
+	return workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeUnset,
+		Source: workloadmeta.SourceHost,
+		Entity: &workloadmeta.SystemdUnit{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindSystemdUnit,
+				ID:   name,
+			},
+		},
+	}
+}
+
+// isCollected returns whether an entity should be created for the unit: only
+// active services are collected, optionally restricted to the configured
+// unit names.
+func (c *collector) isCollected(name string, activeState string) bool {
+	if !strings.HasSuffix(name, serviceSuffix) || activeState != unitActiveState {
+		return false
+	}
+
+	if len(c.unitNames) == 0 {
+		return true
+	}
+
+	_, ok := c.unitNames[name]
+	return ok
+}
+
+func (c *collector) buildEntity(name string, state unitState, unitProps map[string]interface{}) (*workloadmeta.SystemdUnit, error) {
+	serviceProps, err := c.conn.GetUnitTypeProperties(name, "Service")
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]string)
+	copyProperties(properties, unitProps, unitProperties)
+	copyProperties(properties, serviceProps, serviceProperties)
+
+	var mainPID int
+	if pid, ok := serviceProps["MainPID"].(uint32); ok {
+		mainPID = int(pid)
+	}
+
+	var ports []workloadmeta.ContainerPort
+	if mainPID > 0 {
+		ports = c.listeningPorts(mainPID)
+	}
+
+	return &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: strings.TrimSuffix(name, serviceSuffix),
+		},
+		ActiveState: state.activeState,
+		SubState:    state.subState,
+		MainPID:     mainPID,
+		Ports:       ports,
+		Properties:  properties,
+	}, nil
+}
+
+func copyProperties(dst map[string]string, src map[string]interface{}, names []string) {
+	for _, name := range names {
+		value, ok := src[name]
+		if !ok {
+			continue
+		}
+
+		if s := fmt.Sprint(value); s != "" {
+			dst[name] = s
+		}
+	}
+}
+
+// normalizeUnitName appends the .service suffix to unit names configured
+// without it.
+func normalizeUnitName(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+
+	return name + serviceSuffix
+}
@@ -0,0 +1,322 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Component
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeSystemdConn struct {
+	units          []dbus.UnitStatus
+	unitProps      map[string]map[string]interface{}
+	serviceProps   map[string]map[string]interface{}
+	listUnitsError error
+	subscribeError error
+
+	queriedUnits []string
+	subscribed   bool
+	closed       bool
+}
+
+func (c *fakeSystemdConn) ListUnits() ([]dbus.UnitStatus, error) {
+	return c.units, c.listUnitsError
+}
+
+func (c *fakeSystemdConn) GetUnitProperties(unit string) (map[string]interface{}, error) {
+	c.queriedUnits = append(c.queriedUnits, unit)
+	return c.unitProps[unit], nil
+}
+
+func (c *fakeSystemdConn) GetUnitTypeProperties(unit string, _ string) (map[string]interface{}, error) {
+	props, ok := c.serviceProps[unit]
+	if !ok {
+		return nil, errors.New("unit not found")
+	}
+	return props, nil
+}
+
+func (c *fakeSystemdConn) Subscribe() error {
+	if c.subscribeError != nil {
+		return c.subscribeError
+	}
+	c.subscribed = true
+	return nil
+}
+
+func (c *fakeSystemdConn) SetSubStateSubscriber(_ chan<- *dbus.SubStateUpdate, _ chan<- error) {}
+
+func (c *fakeSystemdConn) Close() {
+	c.closed = true
+}
+
+func newTestCollector(conn *fakeSystemdConn, store workloadmeta.Component) *collector {
+	return &collector{
+		store: store,
+		units: make(map[string]*workloadmeta.SystemdUnit),
+		connect: func() (systemdConn, error) {
+			return conn, nil
+		},
+		listeningPorts: func(pid int) []workloadmeta.ContainerPort {
+			if pid == 1234 {
+				return []workloadmeta.ContainerPort{{Port: 5432, Protocol: "tcp"}}
+			}
+			return nil
+		},
+	}
+}
+
+func TestResync(t *testing.T) {
+	conn := &fakeSystemdConn{
+		units: []dbus.UnitStatus{
+			{Name: "postgresql.service", ActiveState: "active", SubState: "running"},
+			{Name: "redis.service", ActiveState: "active", SubState: "running"},
+			{Name: "cron.service", ActiveState: "inactive", SubState: "dead"},
+			{Name: "postgresql.socket", ActiveState: "active", SubState: "listening"},
+		},
+		unitProps: map[string]map[string]interface{}{
+			"postgresql.service": {
+				"Description":  "PostgreSQL RDBMS",
+				"FragmentPath": "/lib/systemd/system/postgresql.service",
+			},
+		},
+		serviceProps: map[string]map[string]interface{}{
+			"postgresql.service": {
+				"MainPID": uint32(1234),
+				"User":    "postgres",
+				"Type":    "forking",
+			},
+			"redis.service": {
+				"MainPID": uint32(0),
+			},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := newTestCollector(conn, store)
+
+	require.NoError(t, c.subscribe())
+	assert.True(t, conn.subscribed)
+	require.NoError(t, c.resync())
+
+	expected := []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindSystemdUnit,
+					ID:   "postgresql.service",
+				},
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: "postgresql",
+				},
+				ActiveState: "active",
+				SubState:    "running",
+				MainPID:     1234,
+				Ports:       []workloadmeta.ContainerPort{{Port: 5432, Protocol: "tcp"}},
+				Properties: map[string]string{
+					"Description":  "PostgreSQL RDBMS",
+					"FragmentPath": "/lib/systemd/system/postgresql.service",
+					"User":         "postgres",
+					"Type":         "forking",
+				},
+			},
+		},
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindSystemdUnit,
+					ID:   "redis.service",
+				},
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: "redis",
+				},
+				ActiveState: "active",
+				SubState:    "running",
+				Properties:  map[string]string{},
+			},
+		},
+	}
+	assert.Equal(t, expected, store.notifiedEvents)
+
+	// nothing changed, no unit is queried again
+	conn.queriedUnits = nil
+	store.notifiedEvents = nil
+
+	require.NoError(t, c.resync())
+	assert.Empty(t, conn.queriedUnits)
+	assert.Empty(t, store.notifiedEvents)
+
+	// postgresql listens on another port, without any change of its state
+	ports := []workloadmeta.ContainerPort{{Port: 5432, Protocol: "tcp"}, {Port: 5433, Protocol: "tcp"}}
+	c.listeningPorts = func(pid int) []workloadmeta.ContainerPort {
+		if pid == 1234 {
+			return ports
+		}
+		return nil
+	}
+
+	require.NoError(t, c.resync())
+	assert.Empty(t, conn.queriedUnits)
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, workloadmeta.EventTypeSet, store.notifiedEvents[0].Type)
+	postgresql := store.notifiedEvents[0].Entity.(*workloadmeta.SystemdUnit)
+	assert.Equal(t, "postgresql.service", postgresql.ID)
+	assert.Equal(t, ports, postgresql.Ports)
+	assert.Equal(t, "postgres", postgresql.Properties["User"])
+	store.notifiedEvents = nil
+
+	// redis is stopped, an unset event is expected
+	conn.units = conn.units[:1]
+
+	require.NoError(t, c.resync())
+	assert.Empty(t, conn.queriedUnits)
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindSystemdUnit,
+					ID:   "redis.service",
+				},
+			},
+		},
+	}, store.notifiedEvents)
+}
+
+func TestHandleUpdate(t *testing.T) {
+	conn := &fakeSystemdConn{
+		unitProps: map[string]map[string]interface{}{
+			"redis.service": {
+				"ActiveState": "active",
+				"SubState":    "running",
+			},
+			"cron.service": {
+				"ActiveState": "inactive",
+				"SubState":    "dead",
+			},
+		},
+		serviceProps: map[string]map[string]interface{}{
+			"redis.service": {},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := newTestCollector(conn, store)
+	require.NoError(t, c.subscribe())
+
+	// sockets are never queried
+	c.handleUpdate("redis.socket")
+	assert.Empty(t, conn.queriedUnits)
+
+	// an inactive unit that was not collected is ignored
+	c.handleUpdate("cron.service")
+	assert.Empty(t, store.notifiedEvents)
+
+	// redis starts
+	c.handleUpdate("redis.service")
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, workloadmeta.EventTypeSet, store.notifiedEvents[0].Type)
+	assert.Equal(t, "redis.service", store.notifiedEvents[0].Entity.GetID().ID)
+
+	// same state, no event
+	store.notifiedEvents = nil
+	c.handleUpdate("redis.service")
+	assert.Empty(t, store.notifiedEvents)
+
+	// redis stops
+	conn.unitProps["redis.service"] = map[string]interface{}{
+		"ActiveState": "deactivating",
+		"SubState":    "stop-sigterm",
+	}
+	c.handleUpdate("redis.service")
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, workloadmeta.EventTypeUnset, store.notifiedEvents[0].Type)
+	assert.Empty(t, c.units)
+}
+
+func TestResyncUnitNames(t *testing.T) {
+	conn := &fakeSystemdConn{
+		units: []dbus.UnitStatus{
+			{Name: "postgresql.service", ActiveState: "active"},
+			{Name: "redis.service", ActiveState: "active"},
+		},
+		serviceProps: map[string]map[string]interface{}{
+			"postgresql.service": {},
+			"redis.service":      {},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := newTestCollector(conn, store)
+	c.unitNames = map[string]struct{}{normalizeUnitName("redis"): {}}
+	require.NoError(t, c.subscribe())
+
+	require.NoError(t, c.resync())
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, "redis.service", store.notifiedEvents[0].Entity.GetID().ID)
+	assert.Equal(t, []string{"redis.service"}, conn.queriedUnits)
+}
+
+func TestResyncError(t *testing.T) {
+	c := newTestCollector(&fakeSystemdConn{listUnitsError: errors.New("boom")}, &fakeWorkloadmetaStore{})
+	require.NoError(t, c.subscribe())
+
+	assert.Error(t, c.resync())
+}
+
+func TestReconnect(t *testing.T) {
+	conn := &fakeSystemdConn{
+		units: []dbus.UnitStatus{
+			{Name: "redis.service", ActiveState: "active", SubState: "running"},
+		},
+		serviceProps: map[string]map[string]interface{}{
+			"redis.service": {},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := newTestCollector(conn, store)
+	require.NoError(t, c.subscribe())
+
+	// the subscription fails, the closed connection is not kept
+	conn.subscribeError = errors.New("boom")
+	c.reconnect()
+	assert.True(t, conn.closed)
+	assert.Nil(t, c.conn)
+
+	c.periodicResync()
+	assert.Nil(t, c.conn)
+	assert.Empty(t, store.notifiedEvents)
+
+	// a fresh connection is dialed on the next tick, and the units resynced
+	conn.subscribeError = nil
+	c.periodicResync()
+	assert.NotNil(t, c.conn)
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, "redis.service", store.notifiedEvents[0].Entity.GetID().ID)
+}
@@ -73,6 +73,10 @@ type Component interface {
 	// when the pod actually exists.
 	GetKubernetesPodForContainer(containerID string) (*KubernetesPod, error)
 
+	// ListKubernetesPods returns metadata about all known pods, equivalent
+	// to all entities with kind KindKubernetesPod.
+	ListKubernetesPods() []*KubernetesPod
+
 	// GetKubernetesPodByName returns the first pod whose name and namespace matches those passed in
 	// to this function.
 	GetKubernetesPodByName(podName, podNamespace string) (*KubernetesPod, error)
@@ -85,6 +89,26 @@ type Component interface {
 	// the entity with kind KindKubernetesDeployment and the given ID.
 	GetKubernetesDeployment(id string) (*KubernetesDeployment, error)
 
+	// GetKubernetesStatefulSet returns metadata about a Kubernetes statefulset. It fetches
+	// the entity with kind KindKubernetesStatefulSet and the given ID.
+	GetKubernetesStatefulSet(id string) (*KubernetesStatefulSet, error)
+
+	// GetKubernetesDaemonSet returns metadata about a Kubernetes daemonset. It fetches
+	// the entity with kind KindKubernetesDaemonSet and the given ID.
+	GetKubernetesDaemonSet(id string) (*KubernetesDaemonSet, error)
+
+	// GetKubernetesJob returns metadata about a Kubernetes job. It fetches
+	// the entity with kind KindKubernetesJob and the given ID.
+	GetKubernetesJob(id string) (*KubernetesJob, error)
+
+	// GetKubernetesCronJob returns metadata about a Kubernetes cronjob. It fetches
+	// the entity with kind KindKubernetesCronJob and the given ID.
+	GetKubernetesCronJob(id string) (*KubernetesCronJob, error)
+
+	// GetKubernetesNamespace returns metadata about a Kubernetes namespace. It fetches
+	// the entity with kind KindKubernetesNamespace and the given ID.
+	GetKubernetesNamespace(id string) (*KubernetesNamespace, error)
+
 	// GetECSTask returns metadata about an ECS task.  It fetches the entity with
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
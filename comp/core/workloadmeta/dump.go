@@ -63,6 +63,18 @@ func (w *workloadmeta) Dump(verbose bool) WorkloadDumpResponse {
 			info = e.String(verbose)
 		case *KubernetesDeployment:
 			info = e.String(verbose)
+		case *KubernetesStatefulSet:
+			info = e.String(verbose)
+		case *KubernetesDaemonSet:
+			info = e.String(verbose)
+		case *KubernetesJob:
+			info = e.String(verbose)
+		case *KubernetesCronJob:
+			info = e.String(verbose)
+		case *KubernetesNamespace:
+			info = e.String(verbose)
+		case *SystemdUnit:
+			info = e.String(verbose)
 		default:
 			return "", fmt.Errorf("unsupported type %T", e)
 		}
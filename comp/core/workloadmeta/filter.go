@@ -10,16 +10,18 @@ package workloadmeta
 //
 // A nil filter matches all events.
 type Filter struct {
-	kinds     map[Kind]struct{}
-	source    Source
-	eventType EventType
+	kinds           map[Kind]struct{}
+	source          Source
+	eventType       EventType
+	includeSnapshot bool
 }
 
 // FilterParams are the parameters used to create a Filter
 type FilterParams struct {
-	Kinds     []Kind
-	Source    Source
-	EventType EventType
+	Kinds           []Kind
+	Source          Source
+	EventType       EventType
+	IncludeSnapshot bool
 }
 
 // NewFilter creates a new filter for subscribing to workloadmeta events.
@@ -36,6 +38,11 @@ type FilterParams struct {
 //
 // Only events of the given type will be delivered. Use EventTypeAll to collect
 // data from all the event types. EventTypeAll is the default.
+//
+// Stale entities restored from a snapshot, which are only known to
+// SourceSnapshot, are delivered only if IncludeSnapshot is set or source is
+// SourceSnapshot, so that subscribers acting on running workloads, such as the
+// autodiscovery listeners, never see them.
 func NewFilter(filterParams *FilterParams) *Filter {
 	var kindSet map[Kind]struct{}
 	kinds := filterParams.Kinds
@@ -52,9 +59,10 @@ func NewFilter(filterParams *FilterParams) *Filter {
 	}
 
 	return &Filter{
-		kinds:     kindSet,
-		source:    filterParams.Source,
-		eventType: filterParams.EventType,
+		kinds:           kindSet,
+		source:          filterParams.Source,
+		eventType:       filterParams.EventType,
+		includeSnapshot: filterParams.IncludeSnapshot,
 	}
 }
 
@@ -71,8 +79,13 @@ func (f *Filter) MatchKind(k Kind) bool {
 }
 
 // MatchSource returns true if the filter matches the passed source. If the
-// filter is nil, or has SourceAll, it always matches.
+// filter is nil, or has SourceAll, it matches every source but
+// SourceSnapshot, which is only matched if the filter includes snapshots.
 func (f *Filter) MatchSource(source Source) bool {
+	if source == SourceSnapshot && f.Source() == SourceAll {
+		return f.IncludeSnapshot()
+	}
+
 	return f.Source() == SourceAll || f.Source() == source
 }
 
@@ -101,3 +114,13 @@ func (f *Filter) EventType() EventType {
 
 	return f.eventType
 }
+
+// IncludeSnapshot returns whether this filter matches stale entities restored
+// from a snapshot. If the filter is nil, it returns false.
+func (f *Filter) IncludeSnapshot() bool {
+	if f == nil {
+		return false
+	}
+
+	return f.includeSnapshot
+}
@@ -0,0 +1,256 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+)
+
+const (
+	snapshotCacheKey = "workloadmeta_snapshot"
+	snapshotVersion  = 1
+)
+
+// snapshot is the on-disk representation of the store. Only the kinds needed
+// to tag the telemetry of containers are persisted.
+type snapshot struct {
+	Version        int
+	Timestamp      time.Time
+	Containers     []*Container
+	KubernetesPods []*KubernetesPod
+	ECSTasks       []*ECSTask
+}
+
+func (w *workloadmeta) snapshotEnabled() bool {
+	return w.config.GetBool("workloadmeta.snapshot.enabled")
+}
+
+// startSnapshots restores the snapshot persisted by a previous run, if any,
+// and periodically persists a new one until ctx is done.
+func (w *workloadmeta) startSnapshots(ctx context.Context) {
+	maxAge := w.config.GetDuration("workloadmeta.snapshot.max_age")
+
+	if restoredAt, ok := w.restoreSnapshot(maxAge); ok {
+		// stale entities are never kept longer than max_age, even if
+		// some collectors never report
+		expiration := time.NewTimer(maxAge - time.Since(restoredAt))
+		go func() {
+			select {
+			case <-expiration.C:
+				w.reconcileSnapshot()
+			case <-ctx.Done():
+				expiration.Stop()
+			}
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.config.GetDuration("workloadmeta.snapshot.interval"))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.persistSnapshot()
+			case <-ctx.Done():
+				w.persistSnapshot()
+				return
+			}
+		}
+	}()
+}
+
+// restoreSnapshot loads the persisted snapshot in the store under
+// SourceSnapshot. It returns the time the snapshot was taken, and whether it
+// was restored.
+func (w *workloadmeta) restoreSnapshot(maxAge time.Duration) (time.Time, bool) {
+	raw, err := persistentcache.Read(snapshotCacheKey)
+	if err != nil {
+		w.log.Warnf("cannot read workloadmeta snapshot: %s", err)
+		return time.Time{}, false
+	}
+
+	if raw == "" {
+		return time.Time{}, false
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal([]byte(raw), &snap); err != nil {
+		w.log.Warnf("cannot decode workloadmeta snapshot: %s", err)
+		return time.Time{}, false
+	}
+
+	if snap.Version != snapshotVersion {
+		w.log.Infof("ignoring workloadmeta snapshot with unsupported version %d", snap.Version)
+		return time.Time{}, false
+	}
+
+	if age := time.Since(snap.Timestamp); age > maxAge {
+		w.log.Infof("ignoring workloadmeta snapshot taken %s ago", age.Truncate(time.Second))
+		return time.Time{}, false
+	}
+
+	events := make([]CollectorEvent, 0, len(snap.Containers)+len(snap.KubernetesPods)+len(snap.ECSTasks))
+	for _, container := range snap.Containers {
+		events = append(events, snapshotEvent(container))
+	}
+	for _, pod := range snap.KubernetesPods {
+		events = append(events, snapshotEvent(pod))
+	}
+	for _, task := range snap.ECSTasks {
+		events = append(events, snapshotEvent(task))
+	}
+
+	if len(events) == 0 {
+		return time.Time{}, false
+	}
+
+	w.snapshotMut.Lock()
+	w.snapshotPending = true
+	w.snapshotMut.Unlock()
+
+	// the snapshot is restored before any collector starts, so its events
+	// are queued before theirs
+	w.Notify(events)
+
+	w.log.Infof("restored %d stale entities from the workloadmeta snapshot", len(events))
+
+	return snap.Timestamp, true
+}
+
+func snapshotEvent(entity Entity) CollectorEvent {
+	return CollectorEvent{
+		Type:   EventTypeSet,
+		Source: SourceSnapshot,
+		Entity: entity,
+	}
+}
+
+// persistSnapshot writes the entities currently reported by the collectors
+// to disk. Nothing is written while a restored snapshot is pending
+// reconciliation, so that the previous snapshot isn't lost if the Agent
+// restarts again before every collector reported.
+func (w *workloadmeta) persistSnapshot() {
+	w.snapshotMut.Lock()
+	pending := w.snapshotPending
+	w.snapshotMut.Unlock()
+
+	if pending {
+		return
+	}
+
+	snap := w.buildSnapshot()
+
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		w.log.Warnf("cannot encode workloadmeta snapshot: %s", err)
+		return
+	}
+
+	if err := persistentcache.Write(snapshotCacheKey, string(raw)); err != nil {
+		w.log.Warnf("cannot write workloadmeta snapshot: %s", err)
+	}
+}
+
+func (w *workloadmeta) buildSnapshot() snapshot {
+	w.storeMut.RLock()
+	defer w.storeMut.RUnlock()
+
+	snap := snapshot{
+		Version:   snapshotVersion,
+		Timestamp: time.Now(),
+	}
+
+	for _, kind := range []Kind{KindContainer, KindKubernetesPod, KindECSTask} {
+		for _, cachedEntity := range w.store[kind] {
+			if !cachedEntity.hasLiveSource() {
+				continue
+			}
+
+			switch entity := cachedEntity.cached.(type) {
+			case *Container:
+				snap.Containers = append(snap.Containers, entity)
+			case *KubernetesPod:
+				snap.KubernetesPods = append(snap.KubernetesPods, entity)
+			case *ECSTask:
+				snap.ECSTasks = append(snap.ECSTasks, entity)
+			}
+		}
+	}
+
+	return snap
+}
+
+// collectorReported records that a collector resynced, that is reported
+// every existing entity: pulling collectors on their first successful Pull,
+// streaming collectors once their initial sync completed. The restored
+// snapshot is reconciled once all of them did.
+func (w *workloadmeta) collectorReported(id string) {
+	w.snapshotMut.Lock()
+	if !w.snapshotPending {
+		w.snapshotMut.Unlock()
+		return
+	}
+	w.reportedCollectors[id] = struct{}{}
+	w.snapshotMut.Unlock()
+
+	w.collectorMut.RLock()
+	allReported := len(w.candidates) == 0
+	if allReported {
+		w.snapshotMut.Lock()
+		for collectorID := range w.collectors {
+			if _, ok := w.reportedCollectors[collectorID]; !ok {
+				allReported = false
+				break
+			}
+		}
+		w.snapshotMut.Unlock()
+	}
+	w.collectorMut.RUnlock()
+
+	if allReported {
+		w.reconcileSnapshot()
+	}
+}
+
+// reconcileSnapshot removes the entities still only known from the restored
+// snapshot, as no collector reported them again.
+func (w *workloadmeta) reconcileSnapshot() {
+	w.snapshotMut.Lock()
+	if !w.snapshotPending {
+		w.snapshotMut.Unlock()
+		return
+	}
+	w.snapshotPending = false
+	w.snapshotMut.Unlock()
+
+	var events []CollectorEvent
+
+	w.storeMut.RLock()
+	for _, entitiesOfKind := range w.store {
+		for _, cachedEntity := range entitiesOfKind {
+			entity, ok := cachedEntity.sources[SourceSnapshot]
+			if !ok {
+				continue
+			}
+
+			events = append(events, CollectorEvent{
+				Type:   EventTypeUnset,
+				Source: SourceSnapshot,
+				Entity: entity,
+			})
+		}
+	}
+	w.storeMut.RUnlock()
+
+	w.log.Infof("workloadmeta snapshot reconciled, %d stale entities are no longer used", len(events))
+
+	w.Notify(events)
+}
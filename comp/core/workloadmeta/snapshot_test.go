@@ -0,0 +1,360 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func newSnapshotTestStore(t *testing.T, runPath string) *workloadmeta {
+	deps := fxutil.Test[dependencies](t, fx.Options(
+		log.MockModule,
+		config.MockModule,
+		fx.Replace(config.MockParams{Overrides: map[string]interface{}{
+			"run_path":                      runPath,
+			"workloadmeta.snapshot.enabled": true,
+		}}),
+		fx.Supply(NewParams()),
+	))
+
+	return newWorkloadMeta(deps).(*workloadmeta)
+}
+
+// drainEvents handles the events queued by Notify, as the store goroutine
+// would.
+func drainEvents(s *workloadmeta) {
+	for {
+		select {
+		case evs := <-s.eventCh:
+			s.handleEvents(evs)
+		default:
+			return
+		}
+	}
+}
+
+func TestSnapshotRestoreAndReconcile(t *testing.T) {
+	runPath := t.TempDir()
+
+	container := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "foo",
+		},
+		EntityMeta: EntityMeta{
+			Name: "foo",
+		},
+		Image: ContainerImage{
+			Name: "datadog/agent",
+			Tag:  "7",
+		},
+		State: ContainerState{
+			Running: true,
+		},
+	}
+
+	pod := &KubernetesPod{
+		EntityID: EntityID{
+			Kind: KindKubernetesPod,
+			ID:   "bar",
+		},
+		EntityMeta: EntityMeta{
+			Name:      "bar",
+			Namespace: "default",
+		},
+		Containers: []OrchestratorContainer{
+			{ID: "foo", Name: "agent"},
+		},
+	}
+
+	previous := newSnapshotTestStore(t, runPath)
+	previous.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: container},
+		{Type: EventTypeSet, Source: SourceNodeOrchestrator, Entity: pod},
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: &ContainerImageMetadata{
+			EntityID: EntityID{Kind: KindContainerImageMetadata, ID: "sha256:1234"},
+		}},
+	})
+	previous.persistSnapshot()
+
+	s := newSnapshotTestStore(t, runPath)
+	_, restored := s.restoreSnapshot(time.Minute)
+	require.True(t, restored)
+	drainEvents(s)
+
+	gotContainer, err := s.GetContainer("foo")
+	require.NoError(t, err)
+	assert.Equal(t, container, gotContainer)
+
+	gotPod, err := s.GetKubernetesPod("bar")
+	require.NoError(t, err)
+	assert.Equal(t, pod, gotPod)
+
+	_, err = s.GetImage("sha256:1234")
+	assert.True(t, errors.IsNotFound(err), "only containers, pods and tasks are persisted")
+
+	// no snapshot is written until the restored one is reconciled
+	require.NoError(t, persistentcache.Write(snapshotCacheKey, "unchanged"))
+	s.persistSnapshot()
+	raw, err := persistentcache.Read(snapshotCacheKey)
+	require.NoError(t, err)
+	assert.Equal(t, "unchanged", raw)
+
+	// the container is reported again, the pod is gone
+	s.collectors["runtime"] = nil
+	s.collectors["kubelet"] = nil
+	s.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: container},
+	})
+
+	s.collectorReported("runtime")
+	drainEvents(s)
+	_, err = s.GetKubernetesPod("bar")
+	assert.NoError(t, err, "snapshot must not be reconciled before every collector reported")
+
+	s.collectorReported("kubelet")
+	drainEvents(s)
+
+	gotContainer, err = s.GetContainer("foo")
+	require.NoError(t, err)
+	assert.Equal(t, container, gotContainer)
+
+	_, err = s.GetKubernetesPod("bar")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSnapshotRestoreTooOld(t *testing.T) {
+	runPath := t.TempDir()
+
+	previous := newSnapshotTestStore(t, runPath)
+	previous.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: &Container{
+			EntityID: EntityID{Kind: KindContainer, ID: "foo"},
+		}},
+	})
+	previous.persistSnapshot()
+
+	s := newSnapshotTestStore(t, runPath)
+	_, restored := s.restoreSnapshot(0)
+	assert.False(t, restored)
+	drainEvents(s)
+
+	_, err := s.GetContainer("foo")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSnapshotPrecedence(t *testing.T) {
+	s := newSnapshotTestStore(t, t.TempDir())
+
+	stale := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "foo",
+		},
+		EntityMeta: EntityMeta{
+			Name:   "foo",
+			Labels: map[string]string{"app": "foo"},
+		},
+		State: ContainerState{
+			Running: true,
+		},
+	}
+
+	live := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "foo",
+		},
+		EntityMeta: EntityMeta{
+			Name: "foo",
+		},
+	}
+
+	// live data is not completed with stale data
+	s.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceSnapshot, Entity: stale},
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: live},
+	})
+
+	got, err := s.GetContainer("foo")
+	require.NoError(t, err)
+	assert.Equal(t, live, got)
+	assert.NotContains(t, s.store[KindContainer]["foo"].sources, SourceSnapshot)
+
+	// stale data is ignored for entities known to a live source
+	s.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceSnapshot, Entity: stale},
+	})
+
+	got, err = s.GetContainer("foo")
+	require.NoError(t, err)
+	assert.Equal(t, live, got)
+	assert.NotContains(t, s.store[KindContainer]["foo"].sources, SourceSnapshot)
+}
+
+func TestSnapshotSubscribers(t *testing.T) {
+	s := newSnapshotTestStore(t, t.TempDir())
+
+	stale := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "foo",
+		},
+	}
+
+	s.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceSnapshot, Entity: stale},
+	})
+
+	listenerCh := s.Subscribe("listener", NormalPriority, nil)
+	taggerCh := s.Subscribe("tagger", NormalPriority, NewFilter(&FilterParams{IncludeSnapshot: true}))
+
+	bundle := <-listenerCh
+	close(bundle.Ch)
+	assert.Empty(t, bundle.Events)
+
+	bundle = <-taggerCh
+	close(bundle.Ch)
+	assert.Equal(t, []Event{{Type: EventTypeSet, Entity: stale}}, bundle.Events)
+
+	receive := func(ch chan EventBundle) []Event {
+		select {
+		case bundle := <-ch:
+			close(bundle.Ch)
+			return bundle.Events
+		default:
+			return nil
+		}
+	}
+
+	// the stale entity is removed, only the tagger is notified
+	go s.handleEvents([]CollectorEvent{
+		{Type: EventTypeUnset, Source: SourceSnapshot, Entity: stale},
+	})
+
+	var taggerEvents []Event
+	require.Eventually(t, func() bool {
+		taggerEvents = append(taggerEvents, receive(taggerCh)...)
+		return len(taggerEvents) > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, EventTypeUnset, taggerEvents[0].Type)
+	assert.Empty(t, receive(listenerCh))
+}
+
+type fakeCollector struct {
+	streaming bool
+	synced    atomic.Bool
+}
+
+func (c *fakeCollector) Start(context.Context, Component) error { return nil }
+func (c *fakeCollector) Pull(context.Context) error             { return nil }
+func (c *fakeCollector) GetID() string                          { return "fake" }
+func (c *fakeCollector) GetTargetCatalog() AgentType            { return NodeAgent }
+func (c *fakeCollector) IsStreaming() bool                      { return c.streaming }
+func (c *fakeCollector) HasSynced() bool                        { return c.synced.Load() }
+
+// pullAndWait pulls from the collectors of s and waits for the pulls to
+// complete.
+func pullAndWait(t *testing.T, s *workloadmeta) {
+	s.pull(context.TODO())
+	require.Eventually(t, func() bool {
+		s.ongoingPullsMut.Lock()
+		defer s.ongoingPullsMut.Unlock()
+		for _, start := range s.ongoingPulls {
+			if !start.IsZero() {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSnapshotReconcileStreamingCollector(t *testing.T) {
+	runPath := t.TempDir()
+
+	previous := newSnapshotTestStore(t, runPath)
+	previous.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: &Container{
+			EntityID: EntityID{Kind: KindContainer, ID: "foo"},
+		}},
+	})
+	previous.persistSnapshot()
+
+	s := newSnapshotTestStore(t, runPath)
+	_, restored := s.restoreSnapshot(time.Minute)
+	require.True(t, restored)
+	drainEvents(s)
+
+	runtime := &fakeCollector{streaming: true}
+	runtime.synced.Store(true)
+	s.candidates = map[string]Collector{
+		"runtime": runtime,
+		"kubelet": &fakeCollector{},
+	}
+	require.True(t, s.startCandidates(context.TODO()))
+
+	// the streaming collector reported when it started, the other one
+	// has not pulled yet
+	drainEvents(s)
+	_, err := s.GetContainer("foo")
+	assert.NoError(t, err, "snapshot must not be reconciled before every collector reported")
+
+	s.collectorReported("kubelet")
+	drainEvents(s)
+
+	_, err = s.GetContainer("foo")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSnapshotReconcileUnsyncedStreamingCollector(t *testing.T) {
+	runPath := t.TempDir()
+
+	previous := newSnapshotTestStore(t, runPath)
+	previous.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceClusterOrchestrator, Entity: &KubernetesPod{
+			EntityID: EntityID{Kind: KindKubernetesPod, ID: "foo"},
+		}},
+	})
+	previous.persistSnapshot()
+
+	s := newSnapshotTestStore(t, runPath)
+	_, restored := s.restoreSnapshot(time.Minute)
+	require.True(t, restored)
+	drainEvents(s)
+
+	// the Pull of the collector is a no-op, its entities are reported once
+	// its informers synced
+	kubeapiserver := &fakeCollector{streaming: true}
+	s.candidates = map[string]Collector{
+		"kubeapiserver": kubeapiserver,
+	}
+	require.True(t, s.startCandidates(context.TODO()))
+
+	pullAndWait(t, s)
+	drainEvents(s)
+	_, err := s.GetKubernetesPod("foo")
+	assert.NoError(t, err, "snapshot must not be reconciled before the collector synced")
+
+	kubeapiserver.synced.Store(true)
+	pullAndWait(t, s)
+	drainEvents(s)
+
+	_, err = s.GetKubernetesPod("foo")
+	assert.True(t, errors.IsNotFound(err))
+}
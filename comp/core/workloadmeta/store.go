@@ -39,6 +39,10 @@ type subscriber struct {
 
 // Start starts the workload metadata store.
 func (w *workloadmeta) Start(ctx context.Context) {
+	if w.snapshotEnabled() {
+		w.startSnapshots(ctx)
+	}
+
 	go func() {
 		health := health.RegisterLiveness("workloadmeta-store")
 		for {
@@ -130,6 +134,10 @@ func (w *workloadmeta) Subscribe(name string, priority SubscriberPriority, filte
 			}
 
 			for _, cachedEntity := range entitiesOfKind {
+				if !cachedEntity.hasLiveSource() && !sub.filter.MatchSource(SourceSnapshot) {
+					continue
+				}
+
 				entity := cachedEntity.get(sub.filter.Source())
 				if entity != nil {
 					events = append(events, Event{
@@ -227,6 +235,18 @@ func (w *workloadmeta) GetKubernetesPod(id string) (*KubernetesPod, error) {
 	return entity.(*KubernetesPod), nil
 }
 
+// ListKubernetesPods implements Store#ListKubernetesPods
+func (w *workloadmeta) ListKubernetesPods() []*KubernetesPod {
+	entities := w.listEntitiesByKind(KindKubernetesPod)
+
+	pods := make([]*KubernetesPod, 0, len(entities))
+	for _, entity := range entities {
+		pods = append(pods, entity.(*KubernetesPod))
+	}
+
+	return pods
+}
+
 // GetKubernetesPodByName implements Store#GetKubernetesPodByName
 func (w *workloadmeta) GetKubernetesPodByName(podName, podNamespace string) (*KubernetesPod, error) {
 	entities := w.listEntitiesByKind(KindKubernetesPod)
@@ -338,6 +358,56 @@ func (w *workloadmeta) GetKubernetesDeployment(id string) (*KubernetesDeployment
 	return entity.(*KubernetesDeployment), nil
 }
 
+// GetKubernetesStatefulSet implements Store#GetKubernetesStatefulSet
+func (w *workloadmeta) GetKubernetesStatefulSet(id string) (*KubernetesStatefulSet, error) {
+	entity, err := w.getEntityByKind(KindKubernetesStatefulSet, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesStatefulSet), nil
+}
+
+// GetKubernetesDaemonSet implements Store#GetKubernetesDaemonSet
+func (w *workloadmeta) GetKubernetesDaemonSet(id string) (*KubernetesDaemonSet, error) {
+	entity, err := w.getEntityByKind(KindKubernetesDaemonSet, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesDaemonSet), nil
+}
+
+// GetKubernetesJob implements Store#GetKubernetesJob
+func (w *workloadmeta) GetKubernetesJob(id string) (*KubernetesJob, error) {
+	entity, err := w.getEntityByKind(KindKubernetesJob, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesJob), nil
+}
+
+// GetKubernetesCronJob implements Store#GetKubernetesCronJob
+func (w *workloadmeta) GetKubernetesCronJob(id string) (*KubernetesCronJob, error) {
+	entity, err := w.getEntityByKind(KindKubernetesCronJob, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesCronJob), nil
+}
+
+// GetKubernetesNamespace implements Store#GetKubernetesNamespace
+func (w *workloadmeta) GetKubernetesNamespace(id string) (*KubernetesNamespace, error) {
+	entity, err := w.getEntityByKind(KindKubernetesNamespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesNamespace), nil
+}
+
 // GetECSTask implements Store#GetECSTask
 func (w *workloadmeta) GetECSTask(id string) (*ECSTask, error) {
 	entity, err := w.getEntityByKind(KindECSTask, id)
@@ -478,7 +548,9 @@ func (w *workloadmeta) startCandidatesWithRetry(ctx context.Context) error {
 
 func (w *workloadmeta) startCandidates(ctx context.Context) bool {
 	w.collectorMut.Lock()
-	defer w.collectorMut.Unlock()
+
+	// streaming collectors which synced when they started
+	var reported []string
 
 	for id, c := range w.candidates {
 		err := c.Start(ctx, w)
@@ -494,6 +566,10 @@ func (w *workloadmeta) startCandidates(ctx context.Context) bool {
 		if err == nil {
 			w.log.Infof("workloadmeta collector %q started successfully", id)
 			w.collectors[id] = c
+
+			if isStreaming(c) && hasSynced(c) {
+				reported = append(reported, id)
+			}
 		} else {
 			w.log.Infof("workloadmeta collector %q could not start. error: %s", id, err)
 		}
@@ -504,7 +580,15 @@ func (w *workloadmeta) startCandidates(ctx context.Context) bool {
 		delete(w.candidates, id)
 	}
 
-	return len(w.candidates) == 0
+	started := len(w.candidates) == 0
+
+	w.collectorMut.Unlock()
+
+	for _, id := range reported {
+		w.collectorReported(id)
+	}
+
+	return started
 }
 
 func (w *workloadmeta) pull(ctx context.Context) {
@@ -539,6 +623,8 @@ func (w *workloadmeta) pull(ctx context.Context) {
 			if err != nil {
 				w.log.Warnf("error pulling from collector %q: %s", id, err.Error())
 				telemetry.PullErrors.Inc(id)
+			} else if hasSynced(c) {
+				w.collectorReported(id)
 			}
 
 			w.ongoingPullsMut.Lock()
@@ -580,6 +666,19 @@ func (w *workloadmeta) handleEvents(evs []CollectorEvent) {
 				cachedEntity = entitiesOfKind[entityID.ID]
 			}
 
+			// stale data restored from a snapshot is only used for
+			// entities no live source knows about
+			if ev.Source == SourceSnapshot {
+				if cachedEntity.hasLiveSource() {
+					continue
+				}
+			} else if cachedEntity.unset(SourceSnapshot) {
+				telemetry.StoredEntities.Dec(
+					string(entityID.Kind),
+					string(SourceSnapshot),
+				)
+			}
+
 			found, changed := cachedEntity.set(ev.Source, ev.Entity)
 
 			if !found {
@@ -681,6 +780,21 @@ func (w *workloadmeta) handleEvents(evs []CollectorEvent) {
 	}
 }
 
+func isStreaming(c Collector) bool {
+	streamingCollector, ok := c.(StreamingCollector)
+	return ok && streamingCollector.IsStreaming()
+}
+
+// hasSynced returns whether a collector which pulled successfully reported
+// every existing entity. The Pull of streaming collectors is a no-op, they
+// reported once their initial sync completed.
+func hasSynced(c Collector) bool {
+	if !isStreaming(c) {
+		return true
+	}
+	return c.(StreamingCollector).HasSynced()
+}
+
 func (w *workloadmeta) getEntityByKind(kind Kind, id string) (Entity, error) {
 	w.storeMut.RLock()
 	defer w.storeMut.RUnlock()
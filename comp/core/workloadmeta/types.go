@@ -41,9 +41,15 @@ const (
 	KindKubernetesPod          Kind = "kubernetes_pod"
 	KindKubernetesNode         Kind = "kubernetes_node"
 	KindKubernetesDeployment   Kind = "kubernetes_deployment"
+	KindKubernetesStatefulSet  Kind = "kubernetes_statefulset"
+	KindKubernetesDaemonSet    Kind = "kubernetes_daemonset"
+	KindKubernetesJob          Kind = "kubernetes_job"
+	KindKubernetesCronJob      Kind = "kubernetes_cronjob"
+	KindKubernetesNamespace    Kind = "kubernetes_namespace"
 	KindECSTask                Kind = "ecs_task"
 	KindContainerImageMetadata Kind = "container_image_metadata"
 	KindProcess                Kind = "process"
+	KindSystemdUnit            Kind = "systemd_unit"
 )
 
 // Source is the source name of an entity.
@@ -77,6 +83,17 @@ const (
 	// SourceRemoteProcessCollector reprents processes entities detected
 	// by the RemoteProcessCollector.
 	SourceRemoteProcessCollector Source = "remote_process_collector"
+
+	// SourceHost represents entities detected on the host, outside of
+	// any container runtime or orchestrator. `systemd` uses this.
+	SourceHost Source = "host"
+
+	// SourceSnapshot represents stale entities restored at startup from
+	// the snapshot persisted by a previous run of the Agent. They are
+	// removed once every collector has reported. Snapshot data is never
+	// merged with live data: it is dropped as soon as another source
+	// reports the entity, and ignored if one already did.
+	SourceSnapshot Source = "snapshot"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -727,6 +744,219 @@ func (d KubernetesDeployment) String(verbose bool) string {
 
 var _ Entity = &KubernetesDeployment{}
 
+// KubernetesStatefulSet is an Entity representing a Kubernetes StatefulSet.
+type KubernetesStatefulSet struct {
+	EntityID
+	EntityMeta
+}
+
+// GetID implements Entity#GetID.
+func (s *KubernetesStatefulSet) GetID() EntityID {
+	return s.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (s *KubernetesStatefulSet) Merge(e Entity) error {
+	ss, ok := e.(*KubernetesStatefulSet)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesStatefulSet with different kind %T", e)
+	}
+
+	return merge(s, ss)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (s KubernetesStatefulSet) DeepCopy() Entity {
+	cs := deepcopy.Copy(s).(KubernetesStatefulSet)
+	return &cs
+}
+
+// String implements Entity#String
+func (s KubernetesStatefulSet) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, s.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, s.EntityMeta.String(verbose))
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesStatefulSet{}
+
+// KubernetesDaemonSet is an Entity representing a Kubernetes DaemonSet.
+type KubernetesDaemonSet struct {
+	EntityID
+	EntityMeta
+}
+
+// GetID implements Entity#GetID.
+func (d *KubernetesDaemonSet) GetID() EntityID {
+	return d.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (d *KubernetesDaemonSet) Merge(e Entity) error {
+	dd, ok := e.(*KubernetesDaemonSet)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesDaemonSet with different kind %T", e)
+	}
+
+	return merge(d, dd)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (d KubernetesDaemonSet) DeepCopy() Entity {
+	cd := deepcopy.Copy(d).(KubernetesDaemonSet)
+	return &cd
+}
+
+// String implements Entity#String
+func (d KubernetesDaemonSet) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, d.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, d.EntityMeta.String(verbose))
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesDaemonSet{}
+
+// KubernetesJob is an Entity representing a Kubernetes Job.
+type KubernetesJob struct {
+	EntityID
+	EntityMeta
+	Owners []KubernetesPodOwner
+}
+
+// GetID implements Entity#GetID.
+func (j *KubernetesJob) GetID() EntityID {
+	return j.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (j *KubernetesJob) Merge(e Entity) error {
+	jj, ok := e.(*KubernetesJob)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesJob with different kind %T", e)
+	}
+
+	return merge(j, jj)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (j KubernetesJob) DeepCopy() Entity {
+	cj := deepcopy.Copy(j).(KubernetesJob)
+	return &cj
+}
+
+// String implements Entity#String
+func (j KubernetesJob) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, j.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, j.EntityMeta.String(verbose))
+
+	if len(j.Owners) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Owners -----------")
+		for _, o := range j.Owners {
+			_, _ = fmt.Fprint(&sb, o.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesJob{}
+
+// KubernetesCronJob is an Entity representing a Kubernetes CronJob.
+type KubernetesCronJob struct {
+	EntityID
+	EntityMeta
+}
+
+// GetID implements Entity#GetID.
+func (c *KubernetesCronJob) GetID() EntityID {
+	return c.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (c *KubernetesCronJob) Merge(e Entity) error {
+	cc, ok := e.(*KubernetesCronJob)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesCronJob with different kind %T", e)
+	}
+
+	return merge(c, cc)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (c KubernetesCronJob) DeepCopy() Entity {
+	cc := deepcopy.Copy(c).(KubernetesCronJob)
+	return &cc
+}
+
+// String implements Entity#String
+func (c KubernetesCronJob) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, c.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, c.EntityMeta.String(verbose))
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesCronJob{}
+
+// KubernetesNamespace is an Entity representing a Kubernetes Namespace.
+type KubernetesNamespace struct {
+	EntityID
+	EntityMeta
+}
+
+// GetID implements Entity#GetID.
+func (n *KubernetesNamespace) GetID() EntityID {
+	return n.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (n *KubernetesNamespace) Merge(e Entity) error {
+	nn, ok := e.(*KubernetesNamespace)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesNamespace with different kind %T", e)
+	}
+
+	return merge(n, nn)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (n KubernetesNamespace) DeepCopy() Entity {
+	cn := deepcopy.Copy(n).(KubernetesNamespace)
+	return &cn
+}
+
+// String implements Entity#String
+func (n KubernetesNamespace) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, n.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, n.EntityMeta.String(verbose))
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesNamespace{}
+
 // ECSTask is an Entity representing an ECS Task.
 type ECSTask struct {
 	EntityID
@@ -1025,3 +1255,65 @@ type EventBundle struct {
 	// Ch should be closed once the subscriber has handled the event.
 	Ch chan struct{}
 }
+
+// SystemdUnit is an Entity representing a running systemd service unit.
+type SystemdUnit struct {
+	EntityID // EntityID.ID is the unit name, e.g. postgresql.service
+	EntityMeta
+	ActiveState string
+	SubState    string
+	MainPID     int
+	Ports       []ContainerPort
+	Properties  map[string]string
+}
+
+var _ Entity = &SystemdUnit{}
+
+// GetID implements Entity#GetID.
+func (u *SystemdUnit) GetID() EntityID {
+	return u.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (u *SystemdUnit) Merge(e Entity) error {
+	uu, ok := e.(*SystemdUnit)
+	if !ok {
+		return fmt.Errorf("cannot merge SystemdUnit with different kind %T", e)
+	}
+
+	return merge(u, uu)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (u SystemdUnit) DeepCopy() Entity {
+	cu := deepcopy.Copy(u).(SystemdUnit)
+	return &cu
+}
+
+// String implements Entity#String.
+func (u SystemdUnit) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, u.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Unit Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Active State:", u.ActiveState)
+	_, _ = fmt.Fprintln(&sb, "Sub State:", u.SubState)
+	_, _ = fmt.Fprintln(&sb, "Main PID:", u.MainPID)
+
+	if len(u.Ports) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Ports -----------")
+		for _, p := range u.Ports {
+			_, _ = fmt.Fprintln(&sb, p.String(verbose))
+		}
+	}
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Properties:", mapToString(u.Properties))
+	}
+
+	return sb.String()
+}
@@ -13,9 +13,14 @@ import (
 )
 
 var kubeKindToWorkloadmetaKindMap = map[string]Kind{
-	"Pod":        KindKubernetesPod,
-	"Deployment": KindKubernetesDeployment,
-	"Node":       KindKubernetesNode,
+	"Pod":         KindKubernetesPod,
+	"Deployment":  KindKubernetesDeployment,
+	"Node":        KindKubernetesNode,
+	"StatefulSet": KindKubernetesStatefulSet,
+	"DaemonSet":   KindKubernetesDaemonSet,
+	"Job":         KindKubernetesJob,
+	"CronJob":     KindKubernetesCronJob,
+	"Namespace":   KindKubernetesNamespace,
 }
 
 // KubernetesKindToWorkloadMetaKind maps a Kubernetes Kind to a workloadmeta Kind.
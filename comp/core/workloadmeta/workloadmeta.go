@@ -39,6 +39,10 @@ type workloadmeta struct {
 
 	ongoingPullsMut sync.Mutex
 	ongoingPulls    map[string]time.Time // collector ID => time when last pull started
+
+	snapshotMut        sync.Mutex
+	snapshotPending    bool                // a restored snapshot awaits reconciliation
+	reportedCollectors map[string]struct{} // collectors that reported since the snapshot was restored
 }
 
 // InitHelper this should be provided as a helper to allow passing the component into
@@ -74,6 +78,8 @@ func newWorkloadMeta(deps dependencies) Component {
 		collectors:   make(map[string]Collector),
 		eventCh:      make(chan []CollectorEvent, eventChBufferSize),
 		ongoingPulls: make(map[string]time.Time),
+
+		reportedCollectors: make(map[string]struct{}),
 	}
 
 	// Set global
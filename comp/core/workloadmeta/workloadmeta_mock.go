@@ -162,6 +162,18 @@ func (w *workloadMetaMock) GetKubernetesPodForContainer(containerID string) (*Ku
 	return pod.(*KubernetesPod), nil
 }
 
+// ListKubernetesPods implements Store#ListKubernetesPods
+func (w *workloadMetaMock) ListKubernetesPods() []*KubernetesPod {
+	entities := w.listEntitiesByKind(KindKubernetesPod)
+
+	pods := make([]*KubernetesPod, 0, len(entities))
+	for _, entity := range entities {
+		pods = append(pods, entity.(*KubernetesPod))
+	}
+
+	return pods
+}
+
 // GetKubernetesPodByName implements Store#GetKubernetesPodByName
 func (w *workloadMetaMock) GetKubernetesPodByName(podName, podNamespace string) (*KubernetesPod, error) {
 	entities := w.listEntitiesByKind(KindKubernetesPod)
@@ -197,6 +209,56 @@ func (w *workloadMetaMock) GetKubernetesDeployment(id string) (*KubernetesDeploy
 	return entity.(*KubernetesDeployment), nil
 }
 
+// GetKubernetesStatefulSet implements Component#GetKubernetesStatefulSet
+func (w *workloadMetaMock) GetKubernetesStatefulSet(id string) (*KubernetesStatefulSet, error) {
+	entity, err := w.getEntityByKind(KindKubernetesStatefulSet, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesStatefulSet), nil
+}
+
+// GetKubernetesDaemonSet implements Component#GetKubernetesDaemonSet
+func (w *workloadMetaMock) GetKubernetesDaemonSet(id string) (*KubernetesDaemonSet, error) {
+	entity, err := w.getEntityByKind(KindKubernetesDaemonSet, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesDaemonSet), nil
+}
+
+// GetKubernetesJob implements Component#GetKubernetesJob
+func (w *workloadMetaMock) GetKubernetesJob(id string) (*KubernetesJob, error) {
+	entity, err := w.getEntityByKind(KindKubernetesJob, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesJob), nil
+}
+
+// GetKubernetesCronJob implements Component#GetKubernetesCronJob
+func (w *workloadMetaMock) GetKubernetesCronJob(id string) (*KubernetesCronJob, error) {
+	entity, err := w.getEntityByKind(KindKubernetesCronJob, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesCronJob), nil
+}
+
+// GetKubernetesNamespace implements Component#GetKubernetesNamespace
+func (w *workloadMetaMock) GetKubernetesNamespace(id string) (*KubernetesNamespace, error) {
+	entity, err := w.getEntityByKind(KindKubernetesNamespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesNamespace), nil
+}
+
 // GetECSTask returns metadata about an ECS task.
 func (w *workloadMetaMock) GetECSTask(id string) (*ECSTask, error) {
 	entity, err := w.getEntityByKind(KindECSTask, id)
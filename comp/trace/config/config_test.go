@@ -587,6 +587,8 @@ func TestDefaultConfig(t *testing.T) {
 
 	assert.Equal(t, true, cfg.Enabled)
 
+	assert.False(t, cfg.Obfuscation.CQL.Enabled)
+
 	assert.False(t, cfg.InstallSignature.Found)
 }
 
@@ -711,6 +713,52 @@ func TestFullYamlConfig(t *testing.T) {
 
 	assert.EqualValues(t, []string{"/health", "/500"}, cfg.Ignore["resource"])
 
+	assert.True(t, cfg.ZipkinReceiverEnabled)
+	assert.True(t, cfg.JaegerReceiverEnabled)
+
+	assert.Equal(t, []string{"customer_tier", "region"}, cfg.StatsCustomTags)
+	assert.Equal(t, 50, cfg.StatsCustomTagsMaxValues)
+
+	assert.Equal(t, []traceconfig.SpanFilterRule{
+		{Name: "health-checks", Service: "web", Resource: "^GET /health"},
+		{Name: "cache-hits", Tags: []string{"cache.hit:true"}, MaxDuration: time.Millisecond},
+	}, cfg.SpanFilters)
+
+	assert.Equal(t, []traceconfig.SpanMetric{
+		{
+			SpanFilterRule: traceconfig.SpanFilterRule{Name: "checkout.requests", Service: "web"},
+			Type:           "count",
+			GroupBy:        []string{"customer.tier"},
+		},
+		{
+			SpanFilterRule: traceconfig.SpanFilterRule{Name: "upload.payload_size"},
+			Type:           "distribution",
+			Value:          "payload.size",
+		},
+	}, cfg.SpanMetrics)
+
+	assert.Equal(t, traceconfig.TailSamplingConfig{
+		Enabled:      true,
+		DecisionWait: 30 * time.Second,
+		MaxTraces:    1000,
+		MaxSpans:     1000000,
+		Policies: []traceconfig.TailSamplingPolicy{
+			{Name: "errors", Type: "error"},
+			{Name: "slow-checkout", Type: "latency", Service: "web", Resource: "POST /checkout", Threshold: 2 * time.Second},
+		},
+	}, cfg.TailSampling)
+
+	assert.Equal(t, traceconfig.OTLPExportConfig{
+		Enabled:    true,
+		Endpoint:   "https://collector.internal:4318",
+		Protocol:   "http",
+		Headers:    map[string]string{"x-api-key": "secret"},
+		Timeout:    5 * time.Second,
+		QueueSize:  20,
+		MaxRetries: 5,
+	}, cfg.OTLPExport)
+	assert.Equal(t, "/var/run/datadog/captures", cfg.CapturePath)
+
 	o := cfg.Obfuscation
 	assert.NotNil(t, o)
 	assert.True(t, o.ES.Enabled)
@@ -723,6 +771,11 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(t, o.Redis.Enabled)
 	assert.True(t, o.Memcached.Enabled)
 	assert.True(t, o.Memcached.KeepCommand)
+	assert.True(t, o.GraphQL.Enabled)
+	assert.True(t, o.GraphQL.KeepNull)
+	assert.False(t, o.GraphQL.KeepBoolean)
+	assert.False(t, o.CQL.Enabled)
+	assert.True(t, o.CQL.KeepBoolean)
 	assert.True(t, o.CreditCards.Enabled)
 	assert.True(t, o.CreditCards.Luhn)
 
@@ -1859,6 +1912,45 @@ func TestLoadEnv(t *testing.T) {
 		assert.True(t, cfg.Obfuscation.Memcached.KeepCommand)
 	})
 
+	env = "DD_APM_OBFUSCATION_GRAPHQL_KEEP_BOOLEAN"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "true")
+
+		c := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule,
+			fx.Replace(corecomp.MockParams{
+				Params:      corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+				SetupConfig: true,
+			}),
+			MockModule,
+		))
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.True(t, coreconfig.Datadog.GetBool("apm_config.obfuscation.graphql.keep_boolean"))
+		assert.True(t, cfg.Obfuscation.GraphQL.Enabled)
+		assert.True(t, cfg.Obfuscation.GraphQL.KeepBoolean)
+	})
+
+	env = "DD_APM_OBFUSCATION_CQL_ENABLED"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "true")
+
+		c := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule,
+			fx.Replace(corecomp.MockParams{
+				Params:      corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+				SetupConfig: true,
+			}),
+			MockModule,
+		))
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.True(t, coreconfig.Datadog.GetBool("apm_config.obfuscation.cql.enabled"))
+		assert.True(t, cfg.Obfuscation.CQL.Enabled)
+	})
+
 	env = "DD_APM_OBFUSCATION_MONGODB_ENABLED"
 	t.Run(env, func(t *testing.T) {
 		t.Setenv(env, "true")
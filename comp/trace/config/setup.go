@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -231,6 +232,12 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	if core.IsSet("apm_config.peer_tags") {
 		c.PeerTags = core.GetStringSlice("apm_config.peer_tags")
 	}
+	if core.IsSet("apm_config.stats_custom_tags") {
+		c.StatsCustomTags = core.GetStringSlice("apm_config.stats_custom_tags")
+	}
+	if core.IsSet("apm_config.stats_custom_tags_max_values") {
+		c.StatsCustomTagsMaxValues = core.GetInt("apm_config.stats_custom_tags_max_values")
+	}
 	if core.IsSet("apm_config.extra_sample_rate") {
 		c.ExtraSampleRate = core.GetFloat64("apm_config.extra_sample_rate")
 	}
@@ -256,6 +263,49 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.RareSamplerCardinality = core.GetInt("apm_config.rare_sampler.cardinality")
 	}
 
+	if core.IsSet("apm_config.tail_sampling.enabled") {
+		c.TailSampling.Enabled = core.GetBool("apm_config.tail_sampling.enabled")
+	}
+	if core.IsSet("apm_config.tail_sampling.decision_wait") {
+		c.TailSampling.DecisionWait = core.GetDuration("apm_config.tail_sampling.decision_wait")
+	}
+	if core.IsSet("apm_config.tail_sampling.max_traces") {
+		c.TailSampling.MaxTraces = core.GetInt("apm_config.tail_sampling.max_traces")
+	}
+	if core.IsSet("apm_config.tail_sampling.max_spans") {
+		c.TailSampling.MaxSpans = core.GetInt("apm_config.tail_sampling.max_spans")
+	}
+	if k := "apm_config.tail_sampling.policies"; core.IsSet(k) {
+		if err := coreconfig.Datadog.UnmarshalKey(k, &c.TailSampling.Policies); err != nil {
+			log.Errorf("Error reading tail sampling policies %q: %v", k, err)
+		}
+	}
+
+	if core.IsSet("apm_config.otlp_export.enabled") {
+		c.OTLPExport.Enabled = core.GetBool("apm_config.otlp_export.enabled")
+	}
+	if core.IsSet("apm_config.otlp_export.endpoint") {
+		c.OTLPExport.Endpoint = core.GetString("apm_config.otlp_export.endpoint")
+	}
+	if core.IsSet("apm_config.otlp_export.protocol") {
+		c.OTLPExport.Protocol = core.GetString("apm_config.otlp_export.protocol")
+	}
+	if core.IsSet("apm_config.otlp_export.headers") {
+		c.OTLPExport.Headers = core.GetStringMapString("apm_config.otlp_export.headers")
+	}
+	if core.IsSet("apm_config.otlp_export.insecure") {
+		c.OTLPExport.Insecure = core.GetBool("apm_config.otlp_export.insecure")
+	}
+	if core.IsSet("apm_config.otlp_export.timeout") {
+		c.OTLPExport.Timeout = core.GetDuration("apm_config.otlp_export.timeout")
+	}
+	if core.IsSet("apm_config.otlp_export.queue_size") {
+		c.OTLPExport.QueueSize = core.GetInt("apm_config.otlp_export.queue_size")
+	}
+	if core.IsSet("apm_config.otlp_export.max_retries") {
+		c.OTLPExport.MaxRetries = core.GetInt("apm_config.otlp_export.max_retries")
+	}
+
 	if core.IsSet("apm_config.max_remote_traces_per_second") {
 		c.MaxRemoteTPS = core.GetFloat64("apm_config.max_remote_traces_per_second")
 	}
@@ -292,6 +342,12 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	} else {
 		c.DecoderTimeout = 1000
 	}
+	if core.IsSet("apm_config.zipkin_receiver.enabled") {
+		c.ZipkinReceiverEnabled = core.GetBool("apm_config.zipkin_receiver.enabled")
+	}
+	if core.IsSet("apm_config.jaeger_receiver.enabled") {
+		c.JaegerReceiverEnabled = core.GetBool("apm_config.jaeger_receiver.enabled")
+	}
 
 	if k := "apm_config.replace_tags"; core.IsSet(k) {
 		rt := make([]*config.ReplaceRule, 0)
@@ -384,6 +440,9 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.Obfuscation.Mongo.Enabled = true
 		c.Obfuscation.Memcached.Enabled = true
 		c.Obfuscation.Redis.Enabled = true
+		c.Obfuscation.GraphQL.Enabled = true
+		// CQL obfuscation is the exception: it changes the resource names of spans of type "cassandra", which are
+		// otherwise obfuscated as SQL queries, so it must be enabled explicitly.
 
 		// TODO(x): There is an issue with coreconfig.Datadog.IsSet("apm_config.obfuscation"), probably coming from Viper,
 		// where it returns false even is "apm_config.obfuscation.credit_cards.enabled" is set via an environment
@@ -394,6 +453,15 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		if core.IsSet("apm_config.obfuscation.credit_cards.luhn") {
 			c.Obfuscation.CreditCards.Luhn = core.GetBool("apm_config.obfuscation.credit_cards.luhn")
 		}
+		if coreconfig.Datadog.IsSet("apm_config.obfuscation.cql.enabled") {
+			c.Obfuscation.CQL.Enabled = coreconfig.Datadog.GetBool("apm_config.obfuscation.cql.enabled")
+		}
+		if coreconfig.Datadog.IsSet("apm_config.obfuscation.cql.keep_null") {
+			c.Obfuscation.CQL.KeepNull = coreconfig.Datadog.GetBool("apm_config.obfuscation.cql.keep_null")
+		}
+		if coreconfig.Datadog.IsSet("apm_config.obfuscation.cql.keep_boolean") {
+			c.Obfuscation.CQL.KeepBoolean = coreconfig.Datadog.GetBool("apm_config.obfuscation.cql.keep_boolean")
+		}
 		if coreconfig.Datadog.IsSet("apm_config.obfuscation.elasticsearch.enabled") {
 			c.Obfuscation.ES.Enabled = coreconfig.Datadog.GetBool("apm_config.obfuscation.elasticsearch.enabled")
 		}
@@ -403,6 +471,15 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		if coreconfig.Datadog.IsSet("apm_config.obfuscation.elasticsearch.obfuscate_sql_values") {
 			c.Obfuscation.ES.ObfuscateSQLValues = coreconfig.Datadog.GetStringSlice("apm_config.obfuscation.elasticsearch.obfuscate_sql_values")
 		}
+		if coreconfig.Datadog.IsSet("apm_config.obfuscation.graphql.enabled") {
+			c.Obfuscation.GraphQL.Enabled = coreconfig.Datadog.GetBool("apm_config.obfuscation.graphql.enabled")
+		}
+		if coreconfig.Datadog.IsSet("apm_config.obfuscation.graphql.keep_null") {
+			c.Obfuscation.GraphQL.KeepNull = coreconfig.Datadog.GetBool("apm_config.obfuscation.graphql.keep_null")
+		}
+		if coreconfig.Datadog.IsSet("apm_config.obfuscation.graphql.keep_boolean") {
+			c.Obfuscation.GraphQL.KeepBoolean = coreconfig.Datadog.GetBool("apm_config.obfuscation.graphql.keep_boolean")
+		}
 		if coreconfig.Datadog.IsSet("apm_config.obfuscation.http.remove_query_string") {
 			c.Obfuscation.HTTP.RemoveQueryString = coreconfig.Datadog.GetBool("apm_config.obfuscation.http.remove_query_string")
 		}
@@ -489,6 +566,18 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		}
 	}
 
+	if k := "apm_config.span_filters"; core.IsSet(k) {
+		if err := coreconfig.Datadog.UnmarshalKey(k, &c.SpanFilters); err != nil {
+			log.Errorf("Error reading span filters %q: %v", k, err)
+		}
+	}
+
+	if k := "apm_config.span_metrics"; core.IsSet(k) {
+		if err := coreconfig.Datadog.UnmarshalKey(k, &c.SpanMetrics); err != nil {
+			log.Errorf("Error reading span metrics %q: %v", k, err)
+		}
+	}
+
 	// undocumented writers
 	for key, cfg := range map[string]*config.WriterConfig{
 		"apm_config.trace_writer": c.TraceWriter,
@@ -611,6 +700,10 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.EVPProxy.MaxPayloadSize = core.GetInt64(k)
 	}
 	c.DebugServerPort = core.GetInt("apm_config.debug.port")
+	c.CapturePath = core.GetString("apm_config.capture_path")
+	if c.CapturePath == "" {
+		c.CapturePath = filepath.Join(core.GetString("run_path"), "trace_capture")
+	}
 	return nil
 }
 
@@ -1,6 +1,6 @@
 module github.com/DataDog/datadog-agent/cmd/independent-lint
 
-go 1.20
+go 1.21
 
 require golang.org/x/mod v0.5.1
 
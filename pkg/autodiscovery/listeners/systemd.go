@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+)
+
+func init() {
+	Register("systemd", NewSystemdListener)
+}
+
+// SystemdListener listens to systemd unit events through a subscription to
+// the workloadmeta store, so that checks can be scheduled against services
+// running directly on the host.
+type SystemdListener struct {
+	workloadmetaListener
+}
+
+// NewSystemdListener returns a new SystemdListener.
+func NewSystemdListener(Config) (ServiceListener, error) {
+	const name = "ad-systemdlistener"
+
+	l := &SystemdListener{}
+	filterParams := workloadmeta.FilterParams{
+		Kinds:     []workloadmeta.Kind{workloadmeta.KindSystemdUnit},
+		Source:    workloadmeta.SourceHost,
+		EventType: workloadmeta.EventTypeAll,
+	}
+	f := workloadmeta.NewFilter(&filterParams)
+
+	var err error
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, f, l.createSystemdService)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *SystemdListener) createSystemdService(entity workloadmeta.Entity) {
+	unit := entity.(*workloadmeta.SystemdUnit)
+
+	ports := make([]ContainerPort, 0, len(unit.Ports))
+	for _, port := range unit.Ports {
+		ports = append(ports, ContainerPort{
+			Port: port.Port,
+			Name: port.Name,
+		})
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+
+	svc := &service{
+		entity: unit,
+		adIdentifiers: []string{
+			fmt.Sprintf("systemd:%s", unit.Name),
+			fmt.Sprintf("systemd:%s", unit.ID),
+		},
+		hosts: map[string]string{"host": "127.0.0.1"},
+		ports: ports,
+		pid:   unit.MainPID,
+		ready: true,
+	}
+
+	svcID := buildSvcID(unit.GetID())
+	l.AddService(svcID, svc, "")
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+)
+
+func TestSystemdCreateService(t *testing.T) {
+	unit := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "postgresql.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "postgresql",
+		},
+		ActiveState: "active",
+		SubState:    "running",
+		MainPID:     1234,
+		Ports: []workloadmeta.ContainerPort{
+			{
+				Port:     9187,
+				Protocol: "tcp",
+			},
+			{
+				Port:     5432,
+				Protocol: "tcp",
+			},
+		},
+	}
+
+	listener, wlm := newSystemdListener(t)
+
+	listener.createSystemdService(unit)
+
+	wlm.assertServices(map[string]wlmListenerSvc{
+		"systemd_unit://postgresql.service": {
+			service: &service{
+				entity: unit,
+				adIdentifiers: []string{
+					"systemd:postgresql",
+					"systemd:postgresql.service",
+				},
+				hosts: map[string]string{
+					"host": "127.0.0.1",
+				},
+				ports: []ContainerPort{
+					{Port: 5432},
+					{Port: 9187},
+				},
+				pid:   1234,
+				ready: true,
+			},
+		},
+	})
+}
+
+func newSystemdListener(t *testing.T) (*SystemdListener, *testWorkloadmetaListener) {
+	wlm := newTestWorkloadmetaListener(t)
+
+	return &SystemdListener{workloadmetaListener: wlm}, wlm
+}
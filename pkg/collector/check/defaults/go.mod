@@ -1,3 +1,3 @@
 module github.com/DataDog/datadog-agent/pkg/collector/check/defaults
 
-go 1.20
+go 1.21
@@ -73,6 +73,7 @@ type InitConfig struct {
 	BulkMaxRepetitions           Number                            `yaml:"bulk_max_repetitions"`
 	CollectDeviceMetadata        Boolean                           `yaml:"collect_device_metadata"`
 	CollectTopology              Boolean                           `yaml:"collect_topology"`
+	CollectFDBTopology           Boolean                           `yaml:"collect_fdb_topology"`
 	UseDeviceIDAsHostname        Boolean                           `yaml:"use_device_id_as_hostname"`
 	MinCollectionInterval        int                               `yaml:"min_collection_interval"`
 	Namespace                    string                            `yaml:"namespace"`
@@ -101,6 +102,7 @@ type InstanceConfig struct {
 	UseGlobalMetrics      bool                                `yaml:"use_global_metrics"`
 	CollectDeviceMetadata *Boolean                            `yaml:"collect_device_metadata"`
 	CollectTopology       *Boolean                            `yaml:"collect_topology"`
+	CollectFDBTopology    *Boolean                            `yaml:"collect_fdb_topology"`
 	UseDeviceIDAsHostname *Boolean                            `yaml:"use_device_id_as_hostname"`
 
 	// ExtraTags is a workaround to pass tags from snmp listener to snmp integration via AD template
@@ -177,6 +179,7 @@ type CheckConfig struct {
 	InstanceTags          []string
 	CollectDeviceMetadata bool
 	CollectTopology       bool
+	CollectFDBTopology    bool
 	UseDeviceIDAsHostname bool
 	DeviceID              string
 	DeviceIDTags          []string
@@ -242,11 +245,11 @@ func (c *CheckConfig) RebuildMetadataMetricsAndTags() {
 	c.Metrics = c.RequestedMetrics
 	c.MetricTags = c.RequestedMetricTags
 	if c.ProfileDef != nil {
-		c.Metadata = updateMetadataDefinitionWithDefaults(c.ProfileDef.Metadata, c.CollectTopology)
+		c.Metadata = updateMetadataDefinitionWithDefaults(c.ProfileDef.Metadata, c.CollectTopology, c.CollectFDBTopology)
 		c.Metrics = append(c.Metrics, c.ProfileDef.Metrics...)
 		c.MetricTags = append(c.MetricTags, c.ProfileDef.MetricTags...)
 	} else {
-		c.Metadata = updateMetadataDefinitionWithDefaults(nil, c.CollectTopology)
+		c.Metadata = updateMetadataDefinitionWithDefaults(nil, c.CollectTopology, c.CollectFDBTopology)
 	}
 	c.OidConfig.clean()
 	c.OidConfig.addScalarOids(c.parseScalarOids(c.Metrics, c.MetricTags, c.Metadata))
@@ -371,6 +374,12 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		c.CollectTopology = bool(initConfig.CollectTopology)
 	}
 
+	if instance.CollectFDBTopology != nil {
+		c.CollectFDBTopology = bool(*instance.CollectFDBTopology)
+	} else {
+		c.CollectFDBTopology = bool(initConfig.CollectFDBTopology)
+	}
+
 	if instance.DetectMetricsEnabled != nil {
 		c.DetectMetricsEnabled = bool(*instance.DetectMetricsEnabled)
 	} else {
@@ -636,6 +645,7 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.InstanceTags = common.CopyStrings(c.InstanceTags)
 	newConfig.CollectDeviceMetadata = c.CollectDeviceMetadata
 	newConfig.CollectTopology = c.CollectTopology
+	newConfig.CollectFDBTopology = c.CollectFDBTopology
 	newConfig.UseDeviceIDAsHostname = c.UseDeviceIDAsHostname
 	newConfig.DeviceID = c.DeviceID
 
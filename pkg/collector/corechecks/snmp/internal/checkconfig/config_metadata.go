@@ -227,14 +227,78 @@ var TopologyMetadataConfig = profiledefinition.MetadataConfig{
 	},
 }
 
+// FDBTopologyMetadataConfig represent the metadata needed for the topology
+// links to the hosts learned in the bridge forwarding database
+var FDBTopologyMetadataConfig = profiledefinition.MetadataConfig{
+	"fdb": {
+		Fields: map[string]profiledefinition.MetadataField{
+			// The BRIDGE-MIB table is indexed by MAC address, the Q-BRIDGE-MIB
+			// one by FDB ID and MAC address.
+			"bridge_port": {
+				Symbols: []profiledefinition.SymbolConfig{
+					{
+						OID:  "1.3.6.1.2.1.17.4.3.1.2",
+						Name: "dot1dTpFdbPort",
+					},
+					{
+						OID:  "1.3.6.1.2.1.17.7.1.2.2.1.2",
+						Name: "dot1qTpFdbPort",
+					},
+				},
+			},
+			"status": {
+				Symbols: []profiledefinition.SymbolConfig{
+					{
+						OID:  "1.3.6.1.2.1.17.4.3.1.3",
+						Name: "dot1dTpFdbStatus",
+					},
+					{
+						OID:  "1.3.6.1.2.1.17.7.1.2.2.1.3",
+						Name: "dot1qTpFdbStatus",
+					},
+				},
+			},
+		},
+	},
+	"bridge_port": {
+		Fields: map[string]profiledefinition.MetadataField{
+			"interface_index": {
+				Symbol: profiledefinition.SymbolConfig{
+					OID:  "1.3.6.1.2.1.17.1.4.1.2",
+					Name: "dot1dBasePortIfIndex",
+				},
+			},
+		},
+	},
+	"arp": {
+		Fields: map[string]profiledefinition.MetadataField{
+			"mac_address": {
+				Symbol: profiledefinition.SymbolConfig{
+					OID:  "1.3.6.1.2.1.4.22.1.2",
+					Name: "ipNetToMediaPhysAddress",
+				},
+			},
+			"type": {
+				Symbol: profiledefinition.SymbolConfig{
+					OID:  "1.3.6.1.2.1.4.22.1.4",
+					Name: "ipNetToMediaType",
+				},
+			},
+		},
+	},
+}
+
 // updateMetadataDefinitionWithDefaults will add metadata config for resources
 // that does not have metadata definitions
-func updateMetadataDefinitionWithDefaults(metadataConfig profiledefinition.MetadataConfig, collectTopology bool) profiledefinition.MetadataConfig {
+func updateMetadataDefinitionWithDefaults(metadataConfig profiledefinition.MetadataConfig, collectTopology bool, collectFDBTopology bool) profiledefinition.MetadataConfig {
 	newConfig := make(profiledefinition.MetadataConfig)
 	mergeMetadata(newConfig, metadataConfig)
 	mergeMetadata(newConfig, LegacyMetadataConfig)
 	if collectTopology {
 		mergeMetadata(newConfig, TopologyMetadataConfig)
+		if collectFDBTopology {
+			mergeMetadata(newConfig, FDBTopologyMetadataConfig)
+		}
 	}
 	return newConfig
 }
@@ -1428,6 +1428,61 @@ collect_topology: true
 	assert.Equal(t, false, config.CollectTopology)
 }
 
+func Test_buildConfig_collectFDBTopology(t *testing.T) {
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_device_metadata: true
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+oid_batch_size: 10
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, false, config.CollectFDBTopology)
+	assert.NotContains(t, config.OidConfig.ColumnOids, "1.3.6.1.2.1.17.4.3.1.2")
+
+	// language=yaml
+	rawInitConfig = []byte(`
+oid_batch_size: 10
+collect_fdb_topology: true
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectFDBTopology)
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.3.6.1.2.1.17.4.3.1.2")
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.3.6.1.2.1.17.7.1.2.2.1.2")
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.3.6.1.2.1.17.1.4.1.2")
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.3.6.1.2.1.4.22.1.2")
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_device_metadata: true
+collect_fdb_topology: false
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, false, config.CollectFDBTopology)
+
+	// the fdb is only collected along with the topology
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_device_metadata: true
+collect_topology: false
+collect_fdb_topology: true
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectFDBTopology)
+	assert.NotContains(t, config.OidConfig.ColumnOids, "1.3.6.1.2.1.17.4.3.1.2")
+}
+
 func Test_buildConfig_namespace(t *testing.T) {
 	defer coreconfig.Datadog.SetWithoutSource("network_devices.namespace", "default")
 
@@ -2202,6 +2257,7 @@ func TestCheckConfig_Copy(t *testing.T) {
 		InstanceTags:          []string{"InstanceTags:tag"},
 		CollectDeviceMetadata: true,
 		CollectTopology:       true,
+		CollectFDBTopology:    true,
 		UseDeviceIDAsHostname: true,
 		DeviceID:              "123",
 		DeviceIDTags:          []string{"DeviceIDTags:tag"},
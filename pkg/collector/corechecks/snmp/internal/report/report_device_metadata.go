@@ -30,6 +30,9 @@ import (
 const interfaceStatusMetric = "snmp.interface.status"
 const topologyLinkSourceTypeLLDP = "lldp"
 const topologyLinkSourceTypeCDP = "cdp"
+const topologyLinkSourceTypeFDB = "fdb"
+const fdbStatusLearned = 3
+const arpTypeInvalid = 2
 const ciscoNetworkProtocolIPv4 = "1"
 const ciscoNetworkProtocolIPv6 = "20"
 
@@ -304,6 +307,7 @@ func buildNetworkTopologyMetadata(deviceID string, store *metadata.Store, interf
 	if len(links) == 0 {
 		links = buildNetworkTopologyMetadataWithCDP(deviceID, store, interfaces)
 	}
+	links = append(links, buildNetworkTopologyMetadataWithFDB(deviceID, store, links)...)
 	return links
 }
 
@@ -440,6 +444,141 @@ func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store,
 	return links
 }
 
+// buildNetworkTopologyMetadataWithFDB builds links to the hosts learned in the
+// bridge forwarding database, for the ports without LLDP or CDP neighbor.
+// Only ports with a single learned MAC address are considered, ports learning
+// several addresses lead to another bridge rather than to a host.
+func buildNetworkTopologyMetadataWithFDB(deviceID string, store *metadata.Store, neighborLinks []devicemetadata.TopologyLinkMetadata) []devicemetadata.TopologyLinkMetadata {
+	indexes := store.GetColumnIndexes("fdb.bridge_port")
+	if len(indexes) == 0 {
+		log.Debugf("Unable to build links metadata: no fdb indexes found")
+		return nil
+	}
+
+	interfacesWithNeighbor := make(map[string]struct{})
+	for _, link := range neighborLinks {
+		if link.Local != nil && link.Local.Interface != nil && link.Local.Interface.DDID != "" {
+			interfacesWithNeighbor[link.Local.Interface.DDID] = struct{}{}
+		}
+	}
+
+	ipAddressByMacAddress := getIPAddressByMacAddressFromARP(store)
+
+	macAddressesByIfIndex := make(map[string]map[string]struct{})
+	for _, strIndex := range indexes {
+		if int(store.GetColumnAsFloat("fdb.status", strIndex)) != fdbStatusLearned {
+			continue
+		}
+		indexElems := strings.Split(strIndex, ".")
+
+		// The dot1dTpFdbEntry index is the MAC address (6 elements), the
+		// dot1qTpFdbEntry index is prefixed by dot1qFdbId
+		if len(indexElems) != 6 && len(indexElems) != 7 {
+			log.Debugf("Expected 6 or 7 index elements, but got %d, index=`%s`", len(indexElems), strIndex)
+			continue
+		}
+		macAddress := formatMacAddressFromIndex(indexElems[len(indexElems)-6:])
+		if macAddress == "" {
+			continue
+		}
+
+		bridgePort := strconv.Itoa(int(store.GetColumnAsFloat("fdb.bridge_port", strIndex)))
+		ifIndex := store.GetColumnAsString("bridge_port.interface_index", bridgePort)
+		if ifIndex == "" {
+			continue
+		}
+		if macAddressesByIfIndex[ifIndex] == nil {
+			macAddressesByIfIndex[ifIndex] = make(map[string]struct{})
+		}
+		macAddressesByIfIndex[ifIndex][macAddress] = struct{}{}
+	}
+
+	ifIndexes := make([]string, 0, len(macAddressesByIfIndex))
+	for ifIndex := range macAddressesByIfIndex {
+		ifIndexes = append(ifIndexes, ifIndex)
+	}
+	sort.Strings(ifIndexes)
+
+	var links []devicemetadata.TopologyLinkMetadata
+	for _, ifIndex := range ifIndexes {
+		localInterfaceID := deviceID + ":" + ifIndex
+		if _, ok := interfacesWithNeighbor[localInterfaceID]; ok {
+			continue
+		}
+		macAddresses := macAddressesByIfIndex[ifIndex]
+		if len(macAddresses) != 1 {
+			log.Tracef("Skipping fdb links of interface %s: %d mac addresses learned", localInterfaceID, len(macAddresses))
+			continue
+		}
+		for macAddress := range macAddresses {
+			links = append(links, devicemetadata.TopologyLinkMetadata{
+				ID:         deviceID + ":" + topologyLinkSourceTypeFDB + ":" + ifIndex + ":" + macAddress,
+				SourceType: topologyLinkSourceTypeFDB,
+				Remote: &devicemetadata.TopologyLinkSide{
+					Device: &devicemetadata.TopologyLinkDevice{
+						ID:        macAddress,
+						IDType:    devicemetadata.IDTypeMacAddress,
+						IPAddress: ipAddressByMacAddress[macAddress],
+					},
+					Interface: &devicemetadata.TopologyLinkInterface{
+						ID:     macAddress,
+						IDType: devicemetadata.IDTypeMacAddress,
+					},
+				},
+				Local: &devicemetadata.TopologyLinkSide{
+					Interface: &devicemetadata.TopologyLinkInterface{
+						DDID: localInterfaceID,
+					},
+					Device: &devicemetadata.TopologyLinkDevice{
+						DDID: deviceID,
+					},
+				},
+			})
+		}
+	}
+	return links
+}
+
+// getIPAddressByMacAddressFromARP returns the IPv4 address of the MAC
+// addresses of the ARP table. The ipNetToMediaEntry index is composed of
+// ipNetToMediaIfIndex and the 4 elements of ipNetToMediaNetAddress.
+func getIPAddressByMacAddressFromARP(store *metadata.Store) map[string]string {
+	indexes := store.GetColumnIndexes("arp.mac_address")
+	sort.Strings(indexes)
+	ipAddressByMacAddress := make(map[string]string)
+	for _, strIndex := range indexes {
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) != 5 {
+			continue
+		}
+		if int(store.GetColumnAsFloat("arp.type", strIndex)) == arpTypeInvalid {
+			continue
+		}
+		macAddress := formatColonSepBytes(store.GetColumnAsByteArray("arp.mac_address", strIndex))
+		if macAddress == "" {
+			continue
+		}
+		if _, ok := ipAddressByMacAddress[macAddress]; !ok {
+			ipAddressByMacAddress[macAddress] = strings.Join(indexElems[1:], ".")
+		}
+	}
+	return ipAddressByMacAddress
+}
+
+// formatMacAddressFromIndex formats a MAC address encoded as 6 OID index elements
+func formatMacAddressFromIndex(indexElems []string) string {
+	macAddress := make([]byte, 0, len(indexElems))
+	for _, elem := range indexElems {
+		octet, err := strconv.ParseUint(elem, 10, 8)
+		if err != nil {
+			log.Debugf("Invalid mac address index element `%s`: %s", elem, err)
+			return ""
+		}
+		macAddress = append(macAddress, byte(octet))
+	}
+	return formatColonSepBytes(macAddress)
+}
+
 func getRemDeviceAddressByCDPRemIndex(store *metadata.Store, strIndex string) string {
 	remoteDeviceAddressType := store.GetColumnAsString("cdp_remote.device_address_type", strIndex)
 	if remoteDeviceAddressType == ciscoNetworkProtocolIPv4 || remoteDeviceAddressType == ciscoNetworkProtocolIPv6 {
@@ -536,6 +536,111 @@ func TestComputeInterfaceStatus(t *testing.T) {
 	}
 }
 
+func Test_buildNetworkTopologyMetadataWithFDB(t *testing.T) {
+	values := &valuestore.ResultValueStore{
+		ColumnValues: valuestore.ColumnResultValuesType{
+			// dot1dTpFdbPort
+			"1.3.6.1.2.1.17.4.3.1.2": {
+				"0.17.34.51.68.85": valuestore.ResultValue{Value: float64(1)},
+				"0.17.34.51.68.86": valuestore.ResultValue{Value: float64(2)},
+				"0.17.34.51.68.87": valuestore.ResultValue{Value: float64(2)},
+				"0.17.34.51.68.88": valuestore.ResultValue{Value: float64(1)},
+			},
+			// dot1dTpFdbStatus
+			"1.3.6.1.2.1.17.4.3.1.3": {
+				"0.17.34.51.68.85": valuestore.ResultValue{Value: float64(3)},
+				"0.17.34.51.68.86": valuestore.ResultValue{Value: float64(3)},
+				"0.17.34.51.68.87": valuestore.ResultValue{Value: float64(3)},
+				"0.17.34.51.68.88": valuestore.ResultValue{Value: float64(4)}, // self
+			},
+			// dot1qTpFdbPort
+			"1.3.6.1.2.1.17.7.1.2.2.1.2": {
+				"10.170.187.204.221.238.255": valuestore.ResultValue{Value: float64(3)},
+				"20.0.17.34.51.68.89":        valuestore.ResultValue{Value: float64(4)},
+			},
+			// dot1qTpFdbStatus
+			"1.3.6.1.2.1.17.7.1.2.2.1.3": {
+				"10.170.187.204.221.238.255": valuestore.ResultValue{Value: float64(3)},
+				"20.0.17.34.51.68.89":        valuestore.ResultValue{Value: float64(3)},
+			},
+			// dot1dBasePortIfIndex
+			"1.3.6.1.2.1.17.1.4.1.2": {
+				"1": valuestore.ResultValue{Value: float64(101)},
+				"2": valuestore.ResultValue{Value: float64(102)},
+				"3": valuestore.ResultValue{Value: float64(103)},
+				"4": valuestore.ResultValue{Value: float64(104)},
+			},
+			// ipNetToMediaPhysAddress
+			"1.3.6.1.2.1.4.22.1.2": {
+				"101.10.0.0.5": valuestore.ResultValue{Value: []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}},
+				"101.10.0.0.4": valuestore.ResultValue{Value: []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}},
+			},
+			// ipNetToMediaType
+			"1.3.6.1.2.1.4.22.1.4": {
+				"101.10.0.0.5": valuestore.ResultValue{Value: float64(3)},
+				"101.10.0.0.4": valuestore.ResultValue{Value: float64(2)}, // invalid
+			},
+		},
+	}
+	store := buildMetadataStore(checkconfig.FDBTopologyMetadataConfig, values)
+
+	neighborLinks := []metadata.TopologyLinkMetadata{
+		{
+			ID:         "default:1.2.3.4:3.1",
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Interface: &metadata.TopologyLinkInterface{DDID: "default:1.2.3.4:103"},
+				Device:    &metadata.TopologyLinkDevice{DDID: "default:1.2.3.4"},
+			},
+		},
+	}
+
+	links := buildNetworkTopologyMetadataWithFDB("default:1.2.3.4", store, neighborLinks)
+
+	// interface 102 is skipped since it learned several mac addresses,
+	// interface 103 since it has a LLDP neighbor
+	expectedLinks := []metadata.TopologyLinkMetadata{
+		{
+			ID:         "default:1.2.3.4:fdb:101:00:11:22:33:44:55",
+			SourceType: "fdb",
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:        "00:11:22:33:44:55",
+					IDType:    "mac_address",
+					IPAddress: "10.0.0.5",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     "00:11:22:33:44:55",
+					IDType: "mac_address",
+				},
+			},
+			Local: &metadata.TopologyLinkSide{
+				Interface: &metadata.TopologyLinkInterface{DDID: "default:1.2.3.4:101"},
+				Device:    &metadata.TopologyLinkDevice{DDID: "default:1.2.3.4"},
+			},
+		},
+		{
+			ID:         "default:1.2.3.4:fdb:104:00:11:22:33:44:59",
+			SourceType: "fdb",
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:     "00:11:22:33:44:59",
+					IDType: "mac_address",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     "00:11:22:33:44:59",
+					IDType: "mac_address",
+				},
+			},
+			Local: &metadata.TopologyLinkSide{
+				Interface: &metadata.TopologyLinkInterface{DDID: "default:1.2.3.4:104"},
+				Device:    &metadata.TopologyLinkDevice{DDID: "default:1.2.3.4"},
+			},
+		},
+	}
+	assert.Equal(t, expectedLinks, links)
+}
+
 func Test_getRemManIPAddrByLLDPRemIndex(t *testing.T) {
 	indexes := []string{
 		// IPv4
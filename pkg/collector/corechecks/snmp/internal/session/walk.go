@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// walkLineRegexp matches the lines of a walk starting a new variable: an OID,
+// an equal sign and the value, possibly prefixed by its type
+var walkLineRegexp = regexp.MustCompile(`^\s*\.?(\d+(?:\.\d+)*)\s+=\s*(.*)$`)
+
+// walkNumberRegexp matches the number of the values formatted as `name(number)`
+// or `(number) text`, like the enumerations and the time ticks of net-snmp
+var walkNumberRegexp = regexp.MustCompile(`\((-?\d+)\)`)
+
+// ParseWalk parses the output of `agent snmp walk` into PDUs. The numeric
+// output of the net-snmp `snmpwalk` command (`-On`) is also supported.
+func ParseWalk(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	// quoted is true while reading a multi-line string quoted by net-snmp,
+	// hexString while reading a Hex-STRING wrapped over several lines
+	quoted, hexString := false, false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		matches := walkLineRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			// multi-line strings are continued on the following lines
+			if len(pdus) == 0 || pdus[len(pdus)-1].Type != gosnmp.OctetString {
+				continue
+			}
+			last := &pdus[len(pdus)-1]
+			if hexString {
+				data, err := hex.DecodeString(strings.Join(strings.Fields(line), ""))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid Hex-STRING value of %s: %w", lineNumber, last.Name, err)
+				}
+				last.Value = append(last.Value.([]byte), data...)
+				continue
+			}
+			if quoted {
+				line = strings.TrimSuffix(line, `"`)
+			}
+			last.Value = append(last.Value.([]byte), []byte("\n"+line)...)
+			continue
+		}
+		pdu, err := parseWalkValue(matches[1], matches[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if pdu != nil {
+			pdus = append(pdus, *pdu)
+		}
+		value := strings.TrimSpace(matches[2])
+		quoted = strings.Contains(value, `"`) && !strings.HasSuffix(value, `"`)
+		hexString = strings.HasPrefix(strings.ToLower(value), "hex-string:")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+// parseWalkValue parses the value of an OID in a walk. A nil PDU is returned
+// for the values not representing a variable, like `No Such Object`.
+func parseWalkValue(oid string, rawValue string) (*gosnmp.SnmpPDU, error) {
+	typ, value, hasType := strings.Cut(rawValue, ":")
+	if !hasType || strings.ContainsAny(typ, `"`) {
+		typ, value = "", rawValue
+	}
+	typ = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(typ), " ", ""))
+	value = strings.TrimSpace(value)
+
+	pdu := &gosnmp.SnmpPDU{Name: oid}
+	switch typ {
+	case "string":
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = []byte(unquoteWalkString(value))
+	case "hex-string":
+		data, err := hex.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid Hex-STRING value of %s: %w", oid, err)
+		}
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = data
+	case "oid":
+		pdu.Type = gosnmp.ObjectIdentifier
+		pdu.Value = strings.TrimLeft(value, ".")
+	case "ipaddress":
+		pdu.Type = gosnmp.IPAddress
+		pdu.Value = value
+	case "integer", "integer32":
+		number, err := strconv.ParseInt(walkNumber(value), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INTEGER value of %s: %w", oid, err)
+		}
+		pdu.Type = gosnmp.Integer
+		pdu.Value = int(number)
+	case "counter32", "gauge32", "unsigned32", "uinteger32":
+		number, err := strconv.ParseUint(walkNumber(value), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value of %s: %w", typ, oid, err)
+		}
+		pdu.Type = map[string]gosnmp.Asn1BER{
+			"counter32":  gosnmp.Counter32,
+			"gauge32":    gosnmp.Gauge32,
+			"unsigned32": gosnmp.Gauge32,
+			"uinteger32": gosnmp.Uinteger32,
+		}[typ]
+		pdu.Value = uint(number)
+	case "counter64":
+		number, err := strconv.ParseUint(walkNumber(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Counter64 value of %s: %w", oid, err)
+		}
+		pdu.Type = gosnmp.Counter64
+		pdu.Value = number
+	case "":
+		// strings are printed without type by net-snmp when empty, and the
+		// TimeTicks as a bare number by `agent snmp walk`
+		if value == "" || strings.HasPrefix(value, `"`) {
+			pdu.Type = gosnmp.OctetString
+			pdu.Value = []byte(unquoteWalkString(value))
+			break
+		}
+		number, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			// messages like `No more variables left in this MIB View`
+			return nil, nil
+		}
+		pdu.Type = gosnmp.TimeTicks
+		pdu.Value = uint32(number)
+	case "timeticks":
+		number, err := strconv.ParseUint(walkNumber(value), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TimeTicks value of %s: %w", oid, err)
+		}
+		pdu.Type = gosnmp.TimeTicks
+		pdu.Value = uint32(number)
+	case "nosuchobject", "nosuchinstance", "null":
+		return nil, nil
+	default:
+		if strings.HasPrefix(typ, "type") {
+			// types printed by their number are not supported by the check
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unsupported type %q of %s", typ, oid)
+	}
+	return pdu, nil
+}
+
+// walkNumber returns the number of a value, possibly formatted as
+// `name(number)` or `(number) text`
+func walkNumber(value string) string {
+	if matches := walkNumberRegexp.FindStringSubmatch(value); matches != nil {
+		return matches[1]
+	}
+	if fields := strings.Fields(value); len(fields) > 0 {
+		return fields[0]
+	}
+	return value
+}
+
+// unquoteWalkString removes the quotes net-snmp puts around strings
+func unquoteWalkString(value string) string {
+	if strings.HasPrefix(value, `"`) {
+		return strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`)
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWalk_AgentOutput(t *testing.T) {
+	walk := `.1.3.6.1.2.1.1.1.0 = STRING: Cisco IOS: version 15
+multi-line description
+.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.9.1.1
+.1.3.6.1.2.1.1.3.0 = 123456
+.1.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 00 1A 2B 3C 4D 5E
+.1.3.6.1.2.1.2.2.1.7.1 = INTEGER: 1
+.1.3.6.1.2.1.2.2.1.10.1 = Counter 32: 4294967295
+.1.3.6.1.2.1.31.1.1.1.6.1 = Counter 64: 18446744073709551615
+.1.3.6.1.2.1.2.2.1.5.1 = Gauge 32: 1000000000
+.1.3.6.1.2.1.4.20.1.1.10.0.0.1 = IpAddress: 10.0.0.1
+.1.3.6.1.2.1.99.1 = TYPE 5: 0
+`
+	pdus, err := ParseWalk(strings.NewReader(walk))
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		StrPDU("1.3.6.1.2.1.1.1.0", "Cisco IOS: version 15\nmulti-line description"),
+		ObjPDU("1.3.6.1.2.1.1.2.0", "1.3.6.1.4.1.9.1.1"),
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(123456)},
+		BytePDU("1.3.6.1.2.1.2.2.1.6.1", []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}),
+		{Name: "1.3.6.1.2.1.2.2.1.7.1", Type: gosnmp.Integer, Value: 1},
+		{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(4294967295)},
+		{Name: "1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: "1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(1000000000)},
+		{Name: "1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+	}, pdus)
+}
+
+func TestParseWalk_NetSNMPOutput(t *testing.T) {
+	walk := `.1.3.6.1.2.1.1.1.0 = STRING: "Linux router
+2.6.32"
+.1.3.6.1.2.1.1.3.0 = Timeticks: (8642) 0:01:26.42
+.1.3.6.1.2.1.1.4.0 = ""
+.1.3.6.1.2.1.2.2.1.6.2 = Hex-STRING: 00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F
+10 11
+.1.3.6.1.2.1.2.2.1.8.2 = INTEGER: down(2)
+.1.3.6.1.2.1.2.2.1.10.2 = Counter32: 42
+.1.3.6.1.2.1.2.2.1.5.2 = Gauge32: 100
+.1.3.6.1.2.1.2.2.1.99.2 = No Such Object available on this agent at this OID
+.1.3.6.1.2.1.99 = No more variables left in this MIB View (It is past the end of the MIB tree)
+`
+	pdus, err := ParseWalk(strings.NewReader(walk))
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		StrPDU("1.3.6.1.2.1.1.1.0", "Linux router\n2.6.32"),
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(8642)},
+		StrPDU("1.3.6.1.2.1.1.4.0", ""),
+		BytePDU("1.3.6.1.2.1.2.2.1.6.2", []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}),
+		{Name: "1.3.6.1.2.1.2.2.1.8.2", Type: gosnmp.Integer, Value: 2},
+		{Name: "1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(42)},
+		{Name: "1.3.6.1.2.1.2.2.1.5.2", Type: gosnmp.Gauge32, Value: uint(100)},
+	}, pdus)
+}
+
+func TestParseWalk_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		walk          string
+		expectedError string
+	}{
+		{
+			name:          "invalid integer",
+			walk:          "1.3.6.1.2.1.2.2.1.7.1 = INTEGER: up\n",
+			expectedError: "line 1: invalid INTEGER value of 1.3.6.1.2.1.2.2.1.7.1",
+		},
+		{
+			name:          "invalid hex string",
+			walk:          "1.3.6.1.2.1.1.1.0 = STRING: a\n1.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 0G\n",
+			expectedError: "line 2: invalid Hex-STRING value of 1.3.6.1.2.1.2.2.1.6.1",
+		},
+		{
+			name:          "unsupported type",
+			walk:          "1.3.6.1.2.1.1.1.0 = Opaque: Float: 1.5\n",
+			expectedError: `line 1: unsupported type "opaque" of 1.3.6.1.2.1.1.1.0`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWalk(strings.NewReader(tt.walk))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
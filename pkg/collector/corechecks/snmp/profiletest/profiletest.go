@@ -0,0 +1,291 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package profiletest runs a SNMP profile against a device or a recorded walk
+// with the code of the SNMP check, and reports what the profile produces.
+package profiletest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/devicecheck"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+// walkIPAddress is the IP address of the device a walk is attributed to
+const walkIPAddress = "127.0.0.1"
+
+// telemetryMetrics are the metrics submitted by the check about itself rather
+// than about the device
+var telemetryMetrics = map[string]bool{
+	"snmp.device.reachable":          true,
+	"snmp.device.unreachable":        true,
+	"snmp.devices_monitored":         true,
+	"datadog.snmp.check_interval":    true,
+	"datadog.snmp.check_duration":    true,
+	"datadog.snmp.submitted_metrics": true,
+}
+
+// Metric is a metric sample submitted by the check
+type Metric struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Value float64  `json:"value"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// UnmatchedOID is an OID of the profile the device has no value for
+type UnmatchedOID struct {
+	OID  string `json:"oid"`
+	Name string `json:"name"`
+	// Usage is either `metric` or `tag`
+	Usage string `json:"usage"`
+}
+
+// TypeMismatch is an OID whose value type can't be used the way the profile
+// uses it
+type TypeMismatch struct {
+	OID   string `json:"oid"`
+	Name  string `json:"name"`
+	Usage string `json:"usage"`
+	// Type is the SNMP type of the value
+	Type string `json:"type"`
+	// Reason explains why the type doesn't match
+	Reason string `json:"reason"`
+}
+
+// Result is what a profile produces for a device
+type Result struct {
+	Profile        string                           `json:"profile"`
+	Metrics        []Metric                         `json:"metrics"`
+	Tags           []string                         `json:"tags"`
+	Metadata       *metadata.NetworkDevicesMetadata `json:"metadata,omitempty"`
+	UnmatchedOIDs  []UnmatchedOID                   `json:"unmatched_oids,omitempty"`
+	TypeMismatches []TypeMismatch                   `json:"type_mismatches,omitempty"`
+	// Error is the error of the check run, if any
+	Error string `json:"error,omitempty"`
+}
+
+// RunWithWalk runs a profile against the output of `agent snmp walk`, served
+// by an in-memory stand-in of the device
+func RunWithWalk(profilePath string, walk io.Reader) (*Result, error) {
+	pdus, err := session.ParseWalk(walk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the walk: %w", err)
+	}
+	config, err := newCheckConfig(profilePath, map[string]interface{}{"ip_address": walkIPAddress})
+	if err != nil {
+		return nil, err
+	}
+	sess := session.CreateFakeSession()
+	sess.SetMany(pdus...)
+	return run(config, sess)
+}
+
+// RunWithDevice runs a profile against a device. The instance configures the
+// connection to the device like the instances of the SNMP check.
+func RunWithDevice(profilePath string, instance map[string]interface{}) (*Result, error) {
+	config, err := newCheckConfig(profilePath, instance)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := session.NewGosnmpSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure session: %w", err)
+	}
+	return run(config, sess)
+}
+
+// newCheckConfig builds the config of a check instance using the profile
+func newCheckConfig(profilePath string, instance map[string]interface{}) (*checkconfig.CheckConfig, error) {
+	profilePath, err := filepath.Abs(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	// the check only logs the profiles failing to load, read it first to report the error
+	buf, err := os.ReadFile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(buf, profiledefinition.NewProfileDefinition()); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", profilePath, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(profilePath), filepath.Ext(profilePath))
+	rawInitConfig, err := yaml.Marshal(map[string]interface{}{
+		"profiles": map[string]interface{}{
+			name: map[string]string{"definition_file": profilePath},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rawInstance := make(map[string]interface{}, len(instance)+2)
+	for key, value := range instance {
+		rawInstance[key] = value
+	}
+	rawInstance["profile"] = name
+	if _, ok := rawInstance["collect_device_metadata"]; !ok {
+		rawInstance["collect_device_metadata"] = true
+	}
+	rawInstanceConfig, err := yaml.Marshal(rawInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkconfig.NewCheckConfig(integration.Data(rawInstanceConfig), integration.Data(rawInitConfig))
+}
+
+// run runs the device check once and matches the PDUs it fetched against the
+// profile
+func run(config *checkconfig.CheckConfig, sess session.Session) (*Result, error) {
+	recordingSess := newRecordingSession(sess)
+	deviceCk, err := devicecheck.NewDeviceCheck(config, config.IPAddress, func(*checkconfig.CheckConfig) (session.Session, error) {
+		return recordingSess, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rec := &recorder{}
+	deviceCk.SetSender(report.NewMetricSender(rec, "", config.InterfaceConfigs))
+
+	result := &Result{Profile: config.Profile}
+	if err := deviceCk.Run(time.Now()); err != nil {
+		result.Error = err.Error()
+	}
+
+	for _, metric := range rec.sortedMetrics() {
+		if !telemetryMetrics[metric.Name] {
+			result.Metrics = append(result.Metrics, metric)
+		}
+	}
+	result.Tags = rec.serviceTags
+	result.Metadata = rec.metadata
+	result.UnmatchedOIDs, result.TypeMismatches = matchProfile(config, recordingSess)
+	return result, nil
+}
+
+// matchProfile reports the metrics and tags of the profile the device has no
+// value for, or a value of the wrong type
+func matchProfile(config *checkconfig.CheckConfig, sess *recordingSession) ([]UnmatchedOID, []TypeMismatch) {
+	var unmatched []UnmatchedOID
+	var mismatches []TypeMismatch
+	seen := make(map[string]bool)
+
+	check := func(symbol profiledefinition.SymbolConfig, usage string, isColumn bool, metricType profiledefinition.ProfileMetricType) {
+		if symbol.OID == "" || seen[usage+symbol.OID] {
+			return
+		}
+		seen[usage+symbol.OID] = true
+
+		var pdus []gosnmp.SnmpPDU
+		if isColumn {
+			pdus = sess.column(symbol.OID)
+		} else if pdu, ok := sess.scalar(symbol.OID); ok {
+			pdus = []gosnmp.SnmpPDU{pdu}
+		}
+		if len(pdus) == 0 {
+			unmatched = append(unmatched, UnmatchedOID{OID: symbol.OID, Name: symbol.Name, Usage: usage})
+			return
+		}
+		for _, pdu := range pdus {
+			if reason := typeMismatch(pdu, symbol, usage, metricType); reason != "" {
+				mismatches = append(mismatches, TypeMismatch{
+					OID:    symbol.OID,
+					Name:   symbol.Name,
+					Usage:  usage,
+					Type:   pdu.Type.String(),
+					Reason: reason,
+				})
+				return
+			}
+		}
+	}
+
+	for _, metric := range config.Metrics {
+		if metric.IsScalar() {
+			check(metric.Symbol, "metric", false, metricType(metric, metric.Symbol))
+			continue
+		}
+		for _, symbol := range metric.Symbols {
+			check(symbol, "metric", true, metricType(metric, symbol))
+		}
+		for _, metricTag := range metric.MetricTags {
+			check(profiledefinition.SymbolConfig(metricTag.Symbol), "tag", true, "")
+		}
+	}
+	for _, metricTag := range config.MetricTags {
+		check(profiledefinition.SymbolConfig(metricTag.Symbol), "tag", false, "")
+	}
+
+	sort.SliceStable(unmatched, func(i, j int) bool {
+		return unmatched[i].OID < unmatched[j].OID
+	})
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		return mismatches[i].OID < mismatches[j].OID
+	})
+	return unmatched, mismatches
+}
+
+// metricType returns the metric type forced by the profile for a symbol
+func metricType(metric profiledefinition.MetricsConfig, symbol profiledefinition.SymbolConfig) profiledefinition.ProfileMetricType {
+	if symbol.MetricType != "" {
+		return symbol.MetricType
+	}
+	return metric.MetricType
+}
+
+// typeMismatch returns why the value of a PDU can't be used for a symbol, or
+// an empty string if it can
+func typeMismatch(pdu gosnmp.SnmpPDU, symbol profiledefinition.SymbolConfig, usage string, metricType profiledefinition.ProfileMetricType) string {
+	isString := pdu.Type == gosnmp.OctetString || pdu.Type == gosnmp.BitString
+	if symbol.Format != "" && !isString {
+		return fmt.Sprintf("format `%s` requires an OctetString", symbol.Format)
+	}
+	if usage != "metric" {
+		return ""
+	}
+	if metricType == profiledefinition.ProfileMetricTypeFlagStream {
+		if !isString {
+			return "metric type `flag_stream` requires an OctetString"
+		}
+		return ""
+	}
+
+	switch pdu.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32, gosnmp.OpaqueFloat, gosnmp.OpaqueDouble:
+		return ""
+	case gosnmp.OctetString, gosnmp.BitString:
+		if symbol.ExtractValue != "" || symbol.MatchPattern != "" {
+			return ""
+		}
+		value, ok := pdu.Value.([]byte)
+		if !ok {
+			return ""
+		}
+		if _, err := strconv.ParseFloat(string(value), 64); err != nil {
+			return fmt.Sprintf("value `%s` is not numeric, use `extract_value` or a tag", value)
+		}
+		return ""
+	}
+	return "metrics require a numeric value, use a tag instead"
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestRunWithWalk(t *testing.T) {
+	config.Datadog.SetWithoutSource("confd_path", t.TempDir())
+
+	walk, err := os.Open(filepath.Join("testdata", "acme.walk"))
+	require.NoError(t, err)
+	defer walk.Close()
+
+	result, err := RunWithWalk(filepath.Join("testdata", "acme.yaml"), walk)
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", result.Profile)
+	assert.Empty(t, result.Error)
+	assert.Contains(t, result.Tags, "snmp_host:switch-1")
+	assert.Contains(t, result.Tags, "snmp_profile:acme")
+
+	var names []string
+	for _, metric := range result.Metrics {
+		names = append(names, metric.Type+" "+metric.Name)
+	}
+	assert.Equal(t, []string{
+		"rate snmp.acmePortInOctets",
+		"rate snmp.acmePortInOctets",
+		"gauge snmp.acmeTemperature",
+		"gauge snmp.sysUpTimeInstance",
+	}, names)
+	assert.Equal(t, 42.0, result.Metrics[2].Value)
+	assert.Contains(t, result.Metrics[0].Tags, "port_index:1")
+	assert.Contains(t, result.Metrics[0].Tags, "port_name:eth0")
+	assert.Contains(t, result.Metrics[1].Tags, "port_name:eth1")
+
+	require.NotNil(t, result.Metadata)
+	require.Len(t, result.Metadata.Devices, 1)
+	assert.Equal(t, "switch-1", result.Metadata.Devices[0].Name)
+	assert.Equal(t, "acme", result.Metadata.Devices[0].Vendor)
+	assert.Equal(t, "1.3.6.1.4.1.99999.1.1", result.Metadata.Devices[0].SysObjectID)
+
+	assert.Equal(t, []UnmatchedOID{
+		{OID: "1.3.6.1.4.1.99999.2.1.10.1.6", Name: "acmePortErrors", Usage: "metric"},
+		{OID: "1.3.6.1.4.1.99999.2.1.9.0", Name: "acmeFanSpeed", Usage: "metric"},
+	}, result.UnmatchedOIDs)
+	assert.Equal(t, []TypeMismatch{
+		{
+			OID:    "1.3.6.1.4.1.99999.2.1.10.1.3",
+			Name:   "acmePortMac",
+			Usage:  "tag",
+			Type:   "Integer",
+			Reason: "format `mac_address` requires an OctetString",
+		},
+		{
+			OID:    "1.3.6.1.4.1.99999.2.1.3.0",
+			Name:   "acmeFirmware",
+			Usage:  "metric",
+			Type:   "OctetString",
+			Reason: "value `v1.2.3` is not numeric, use `extract_value` or a tag",
+		},
+	}, result.TypeMismatches)
+}
+
+func TestRunWithWalk_Errors(t *testing.T) {
+	config.Datadog.SetWithoutSource("confd_path", t.TempDir())
+
+	walk, err := os.Open(filepath.Join("testdata", "acme.walk"))
+	require.NoError(t, err)
+	defer walk.Close()
+
+	_, err = RunWithWalk(filepath.Join("testdata", "missing.yaml"), walk)
+	assert.ErrorContains(t, err, "missing.yaml")
+
+	invalidProfile := filepath.Join(t.TempDir(), "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalidProfile, []byte("metrics: {"), 0644))
+	_, err = RunWithWalk(invalidProfile, walk)
+	assert.ErrorContains(t, err, "failed to parse profile")
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/serializer/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// recorder is a sender.Sender recording the metrics and the device metadata
+// submitted by the check instead of forwarding them
+type recorder struct {
+	metrics     []Metric
+	serviceTags []string
+	metadata    *metadata.NetworkDevicesMetadata
+}
+
+func (r *recorder) record(metricType string, name string, value float64, tags []string) {
+	tags = append([]string(nil), tags...)
+	sort.Strings(tags)
+	r.metrics = append(r.metrics, Metric{Name: name, Type: metricType, Value: value, Tags: tags})
+}
+
+// sortedMetrics returns the recorded metrics sorted by name and tags
+func (r *recorder) sortedMetrics() []Metric {
+	sort.SliceStable(r.metrics, func(i, j int) bool {
+		if r.metrics[i].Name != r.metrics[j].Name {
+			return r.metrics[i].Name < r.metrics[j].Name
+		}
+		return strings.Join(r.metrics[i].Tags, ",") < strings.Join(r.metrics[j].Tags, ",")
+	})
+	return r.metrics
+}
+
+func (r *recorder) Commit() {}
+
+func (r *recorder) Gauge(metric string, value float64, _ string, tags []string) {
+	r.record("gauge", metric, value, tags)
+}
+
+func (r *recorder) GaugeNoIndex(metric string, value float64, _ string, tags []string) {
+	r.record("gauge", metric, value, tags)
+}
+
+func (r *recorder) Rate(metric string, value float64, _ string, tags []string) {
+	r.record("rate", metric, value, tags)
+}
+
+func (r *recorder) Count(metric string, value float64, _ string, tags []string) {
+	r.record("count", metric, value, tags)
+}
+
+func (r *recorder) MonotonicCount(metric string, value float64, _ string, tags []string) {
+	r.record("monotonic_count", metric, value, tags)
+}
+
+func (r *recorder) MonotonicCountWithFlushFirstValue(metric string, value float64, _ string, tags []string, _ bool) {
+	r.record("monotonic_count", metric, value, tags)
+}
+
+func (r *recorder) Counter(metric string, value float64, _ string, tags []string) {
+	r.record("counter", metric, value, tags)
+}
+
+func (r *recorder) Histogram(metric string, value float64, _ string, tags []string) {
+	r.record("histogram", metric, value, tags)
+}
+
+func (r *recorder) Historate(metric string, value float64, _ string, tags []string) {
+	r.record("historate", metric, value, tags)
+}
+
+func (r *recorder) Distribution(metric string, value float64, _ string, tags []string) {
+	r.record("distribution", metric, value, tags)
+}
+
+func (r *recorder) ServiceCheck(_ string, _ servicecheck.ServiceCheckStatus, _ string, tags []string, _ string) {
+	r.serviceTags = append([]string(nil), tags...)
+	sort.Strings(r.serviceTags)
+}
+
+func (r *recorder) HistogramBucket(string, int64, float64, float64, bool, string, []string, bool) {}
+
+func (r *recorder) Event(event.Event) {}
+
+// EventPlatformEvent records the device metadata, merging the batches of the
+// payload
+func (r *recorder) EventPlatformEvent(rawEvent []byte, eventType string) {
+	if eventType != epforwarder.EventTypeNetworkDevicesMetadata {
+		return
+	}
+	var payload metadata.NetworkDevicesMetadata
+	if err := json.Unmarshal(rawEvent, &payload); err != nil {
+		log.Warnf("failed to decode device metadata: %s", err)
+		return
+	}
+	if r.metadata == nil {
+		r.metadata = &payload
+		return
+	}
+	r.metadata.Devices = append(r.metadata.Devices, payload.Devices...)
+	r.metadata.Interfaces = append(r.metadata.Interfaces, payload.Interfaces...)
+	r.metadata.IPAddresses = append(r.metadata.IPAddresses, payload.IPAddresses...)
+	r.metadata.Links = append(r.metadata.Links, payload.Links...)
+	r.metadata.NetflowExporters = append(r.metadata.NetflowExporters, payload.NetflowExporters...)
+	r.metadata.Diagnoses = append(r.metadata.Diagnoses, payload.Diagnoses...)
+}
+
+func (r *recorder) GetSenderStats() stats.SenderStats {
+	return stats.NewSenderStats()
+}
+
+func (r *recorder) DisableDefaultHostname(bool) {}
+
+func (r *recorder) SetCheckCustomTags([]string) {}
+
+func (r *recorder) SetCheckService(string) {}
+
+func (r *recorder) SetNoIndex(bool) {}
+
+func (r *recorder) FinalizeCheckServiceTag() {}
+
+func (r *recorder) OrchestratorMetadata([]types.ProcessMessageBody, string, int) {}
+
+func (r *recorder) OrchestratorManifest([]types.ProcessMessageBody, string) {}
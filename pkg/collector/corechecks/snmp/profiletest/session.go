@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+// recordingSession is a session.Session keeping the PDUs returned by the
+// wrapped session, so that they can be matched against the profile
+type recordingSession struct {
+	session.Session
+	pdus map[string]gosnmp.SnmpPDU
+}
+
+func newRecordingSession(sess session.Session) *recordingSession {
+	return &recordingSession{
+		Session: sess,
+		pdus:    make(map[string]gosnmp.SnmpPDU),
+	}
+}
+
+func (s *recordingSession) record(packet *gosnmp.SnmpPacket, err error) (*gosnmp.SnmpPacket, error) {
+	if err != nil || packet == nil {
+		return packet, err
+	}
+	for _, pdu := range packet.Variables {
+		switch pdu.Type {
+		case gosnmp.EndOfContents, gosnmp.EndOfMibView, gosnmp.NoSuchInstance, gosnmp.NoSuchObject:
+			continue
+		}
+		s.pdus[strings.TrimLeft(pdu.Name, ".")] = pdu
+	}
+	return packet, nil
+}
+
+// Get records the PDUs returned by the wrapped session
+func (s *recordingSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	return s.record(s.Session.Get(oids))
+}
+
+// GetBulk records the PDUs returned by the wrapped session
+func (s *recordingSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	return s.record(s.Session.GetBulk(oids, bulkMaxRepetitions))
+}
+
+// GetNext records the PDUs returned by the wrapped session
+func (s *recordingSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	return s.record(s.Session.GetNext(oids))
+}
+
+// scalar returns the PDU of a scalar OID
+func (s *recordingSession) scalar(oid string) (gosnmp.SnmpPDU, bool) {
+	pdu, ok := s.pdus[strings.TrimLeft(oid, ".")]
+	return pdu, ok
+}
+
+// column returns the PDUs of the rows of a column OID
+func (s *recordingSession) column(oid string) []gosnmp.SnmpPDU {
+	prefix := strings.TrimLeft(oid, ".") + "."
+	var pdus []gosnmp.SnmpPDU
+	for name, pdu := range s.pdus {
+		if strings.HasPrefix(name, prefix) {
+			pdus = append(pdus, pdu)
+		}
+	}
+	sort.Slice(pdus, func(i, j int) bool {
+		return pdus[i].Name < pdus[j].Name
+	})
+	return pdus
+}
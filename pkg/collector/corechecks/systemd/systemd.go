@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
 
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 )
@@ -175,7 +176,7 @@ type systemdStats interface {
 type defaultSystemdStats struct{}
 
 func (s *defaultSystemdStats) PrivateSocketConnection(privateSocket string) (*dbus.Conn, error) {
-	return NewSystemdConnection(privateSocket)
+	return systemdutil.NewSystemdConnection(privateSocket)
 }
 
 func (s *defaultSystemdStats) SystemBusSocketConnection() (*dbus.Conn, error) {
@@ -268,7 +269,7 @@ func (c *SystemdCheck) getDbusConnection() (*dbus.Conn, error) {
 	if c.config.instance.PrivateSocket != "" {
 		conn, err = c.getPrivateSocketConnection(c.config.instance.PrivateSocket)
 	} else {
-		defaultPrivateSocket := "/run/systemd/private"
+		defaultPrivateSocket := systemdutil.DefaultPrivateSocket
 		if config.IsContainerized() {
 			conn, err = c.getPrivateSocketConnection("/host" + defaultPrivateSocket)
 		} else {
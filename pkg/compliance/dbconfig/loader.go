@@ -32,6 +32,13 @@ const (
 
 	mongoDBResourceType = "db_mongodb"
 	mongoDBConfigPath   = "/etc/mongod.conf"
+
+	mysqlResourceType = "db_mysql"
+
+	redisResourceType = "db_redis"
+
+	// redactedValue replaces the secrets found in configuration files
+	redactedValue = "<redacted>"
 )
 
 func relPath(hostroot, configPath string) string {
@@ -66,6 +73,10 @@ func getProcResourceType(proc *process.Process) (string, bool) {
 		return postgresqlResourceType, true
 	case "mongod":
 		return mongoDBResourceType, true
+	case "mysqld", "mariadbd":
+		return mysqlResourceType, true
+	case "redis-server":
+		return redisResourceType, true
 	case "java":
 		cmdline, _ := proc.CmdlineSlice()
 		if len(cmdline) > 0 && cmdline[len(cmdline)-1] == "org.apache.cassandra.service.CassandraDaemon" {
@@ -136,6 +147,10 @@ func LoadDBResourceFromPID(ctx context.Context, pid int32) (resource *DBResource
 		conf, _ = LoadMongoDBConfig(ctx, hostroot, proc)
 	case cassandraResourceType:
 		conf, _ = LoadCassandraConfig(ctx, hostroot, proc)
+	case mysqlResourceType:
+		conf, _ = LoadMySQLConfig(ctx, hostroot, proc)
+	case redisResourceType:
+		conf, _ = LoadRedisConfig(ctx, hostroot, proc)
 	}
 	if conf == nil {
 		return
@@ -379,6 +394,356 @@ func parsePGConfig(hostroot, configPath string, includeDepth int) (map[string]in
 	return config, true
 }
 
+// LoadMySQLConfig loads and extracts the MySQL or MariaDB configuration data
+// found on the system. Options are grouped by section ("mysqld", "client",
+// ...) and merged in the order MySQL reads the option files.
+func LoadMySQLConfig(ctx context.Context, hostroot string, proc *process.Process) (*DBConfig, bool) {
+	var result DBConfig
+
+	// --defaults-file replaces the default option files while
+	// --defaults-extra-file is read after them.
+	var defaultsFile, extraFile string
+	if proc != nil {
+		result.ProcessUser, _ = proc.UsernameWithContext(ctx)
+		result.ProcessName, _ = proc.NameWithContext(ctx)
+
+		cmdline, _ := proc.CmdlineSlice()
+		for _, arg := range cmdline {
+			if strings.HasPrefix(arg, "--defaults-file=") {
+				defaultsFile = filepath.Clean(strings.TrimPrefix(arg, "--defaults-file="))
+			} else if strings.HasPrefix(arg, "--defaults-extra-file=") {
+				extraFile = filepath.Clean(strings.TrimPrefix(arg, "--defaults-extra-file="))
+			}
+		}
+	}
+
+	configPaths := mysqlConfigPaths
+	if defaultsFile != "" {
+		configPaths = []string{defaultsFile}
+	} else if extraFile != "" {
+		configPaths = append(configPaths[:len(configPaths):len(configPaths)], extraFile)
+	}
+
+	configData := make(map[string]interface{})
+	for _, configPath := range configPaths {
+		fi, err := os.Stat(filepath.Join(hostroot, configPath))
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if result.ConfigFilePath == "" {
+			result.ConfigFileUser = utils.GetFileUser(fi)
+			result.ConfigFileGroup = utils.GetFileGroup(fi)
+			result.ConfigFileMode = uint32(fi.Mode())
+			result.ConfigFilePath = configPath
+		}
+		parseMySQLConfig(hostroot, configPath, configData, 0)
+	}
+
+	if result.ConfigFilePath == "" {
+		return nil, false
+	}
+	result.ConfigData = configData
+	return &result, true
+}
+
+// mysqlConfigPaths lists the global option files read by MySQL and MariaDB,
+// in order.
+var mysqlConfigPaths = []string{
+	"/etc/my.cnf",
+	"/etc/mysql/my.cnf",
+}
+
+// mysqlSecretOptions lists the options whose values are redacted.
+var mysqlSecretOptions = map[string]bool{
+	"password":                  true,
+	"ssl_key_password":          true,
+	"admin_ssl_key_password":    true,
+	"master_password":           true,
+	"replication_user_password": true,
+}
+
+// parseMySQLConfig parses the given option file into the config map of
+// sections. Option names are normalized with underscores, as MySQL considers
+// dashes and underscores equivalent, and options given without value are set
+// to "on". The "!include" and "!includedir" directives are followed.
+//
+// reference: https://dev.mysql.com/doc/refman/8.0/en/option-files.html
+func parseMySQLConfig(hostroot, configPath string, config map[string]interface{}, includeDepth int) bool {
+	// Let's protect ourselves from circular includes.
+	if includeDepth > 10 {
+		return false
+	}
+
+	b, err := readFileLimit(filepath.Join(hostroot, configPath))
+	if err != nil {
+		return false
+	}
+
+	resolvePath := func(path string) string {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(configPath), path)
+		}
+		return filepath.Clean(path)
+	}
+
+	var section map[string]interface{}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Split(bufio.ScanLines)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if directive, ok := strings.CutPrefix(line, "!includedir"); ok {
+			includedDir := resolvePath(strings.TrimSpace(directive))
+			// only the .cnf files of the directory are read, in lexical order
+			matches, _ := filepath.Glob(filepath.Join(hostroot, includedDir, "*.cnf"))
+			for _, match := range matches {
+				parseMySQLConfig(hostroot, relPath(hostroot, match), config, includeDepth+1)
+			}
+			continue
+		}
+		if directive, ok := strings.CutPrefix(line, "!include"); ok {
+			parseMySQLConfig(hostroot, resolvePath(strings.TrimSpace(directive)), config, includeDepth+1)
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				section = nil
+				continue
+			}
+			name := strings.ToLower(strings.TrimSpace(line[1:end]))
+			section, _ = config[name].(map[string]interface{})
+			if section == nil {
+				section = make(map[string]interface{})
+				config[name] = section
+			}
+			continue
+		}
+
+		// options outside of any section are ignored by MySQL
+		if section == nil {
+			continue
+		}
+
+		key, val, hasValue := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.TrimSpace(key), "-", "_")
+		if key == "" {
+			continue
+		}
+		if !hasValue {
+			section[key] = "on"
+			continue
+		}
+
+		val = strings.TrimSpace(val)
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		} else if i := strings.IndexByte(val, '#'); i >= 0 {
+			val = strings.TrimSpace(val[:i])
+		}
+		if mysqlSecretOptions[key] && val != "" {
+			val = redactedValue
+		}
+		section[key] = val
+	}
+
+	return true
+}
+
+// LoadRedisConfig loads and extracts the Redis configuration data found on
+// the system.
+func LoadRedisConfig(ctx context.Context, hostroot string, proc *process.Process) (*DBConfig, bool) {
+	var result DBConfig
+
+	// The configuration file is the first argument of redis-server, when
+	// the process title has not been rewritten.
+	var hintPath string
+	if proc != nil {
+		result.ProcessUser, _ = proc.UsernameWithContext(ctx)
+		result.ProcessName, _ = proc.NameWithContext(ctx)
+
+		cmdline, _ := proc.CmdlineSlice()
+		if len(cmdline) > 1 && strings.HasSuffix(cmdline[1], ".conf") {
+			hintPath = filepath.Clean(cmdline[1])
+		}
+	}
+
+	configPaths := redisConfigPaths
+	if hintPath != "" {
+		configPaths = append([]string{hintPath}, configPaths...)
+	}
+
+	for _, configPath := range configPaths {
+		fi, err := os.Stat(filepath.Join(hostroot, configPath))
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		configData := make(map[string]interface{})
+		if !parseRedisConfig(hostroot, configPath, configData, 0) {
+			continue
+		}
+		result.ConfigFileUser = utils.GetFileUser(fi)
+		result.ConfigFileGroup = utils.GetFileGroup(fi)
+		result.ConfigFileMode = uint32(fi.Mode())
+		result.ConfigFilePath = configPath
+		result.ConfigData = configData
+		return &result, true
+	}
+	return nil, false
+}
+
+// redisConfigPaths lists the usual locations of the Redis configuration file.
+var redisConfigPaths = []string{
+	"/etc/redis/redis.conf",
+	"/etc/redis.conf",
+	"/usr/local/etc/redis/redis.conf",
+}
+
+// redisMultiDirectives lists the directives that can be repeated, their
+// values are exported as lists.
+var redisMultiDirectives = map[string]bool{
+	"save":                       true,
+	"rename-command":             true,
+	"client-output-buffer-limit": true,
+	"user":                       true,
+	"loadmodule":                 true,
+}
+
+// parseRedisConfig parses the given configuration file into the config map.
+// Directive names are lowercased and their arguments are joined with a
+// single space, empty arguments being kept as "". The "include" directive is followed and the passwords are
+// redacted.
+//
+// reference: https://redis.io/docs/management/config-file/
+func parseRedisConfig(hostroot, configPath string, config map[string]interface{}, includeDepth int) bool {
+	// Let's protect ourselves from circular includes.
+	if includeDepth > 10 {
+		return false
+	}
+
+	b, err := readFileLimit(filepath.Join(hostroot, configPath))
+	if err != nil {
+		return false
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Split(bufio.ScanLines)
+	for s.Scan() {
+		args, ok := splitRedisConfigLine(s.Text())
+		if !ok || len(args) == 0 {
+			continue
+		}
+
+		key := strings.ToLower(args[0])
+		args = args[1:]
+
+		switch key {
+		case "include":
+			for _, includedPath := range args {
+				if !filepath.IsAbs(includedPath) {
+					includedPath = filepath.Join(filepath.Dir(configPath), includedPath)
+				}
+				// include directives accept glob patterns
+				matches, _ := filepath.Glob(filepath.Join(hostroot, includedPath))
+				for _, match := range matches {
+					parseRedisConfig(hostroot, relPath(hostroot, match), config, includeDepth+1)
+				}
+			}
+			continue
+		case "requirepass", "masterauth":
+			if len(args) > 0 && args[0] != "" {
+				args = []string{redactedValue}
+			}
+		case "user":
+			// passwords are given as ">password" and hashes as "#hash"
+			for i, arg := range args {
+				if strings.HasPrefix(arg, ">") || strings.HasPrefix(arg, "#") {
+					args[i] = arg[:1] + redactedValue
+				}
+			}
+		}
+
+		// empty arguments are kept quoted, as in `rename-command CONFIG ""`
+		for i, arg := range args {
+			if arg == "" {
+				args[i] = `""`
+			}
+		}
+		val := strings.Join(args, " ")
+		if redisMultiDirectives[key] {
+			values, _ := config[key].([]interface{})
+			config[key] = append(values, val)
+		} else {
+			config[key] = val
+		}
+	}
+
+	return true
+}
+
+// splitRedisConfigLine splits a line of a Redis configuration file into its
+// arguments, handling comments and quoted arguments.
+func splitRedisConfigLine(line string) ([]string, bool) {
+	var args []string
+	for i := 0; i < len(line); {
+		c := line[i]
+		if isWhiteSpace(c) {
+			i++
+			continue
+		}
+		if c == '#' && len(args) == 0 {
+			break
+		}
+
+		var arg strings.Builder
+		if c == '"' || c == '\'' {
+			quote := c
+			closed := false
+			for i++; i < len(line); i++ {
+				c := line[i]
+				if c == '\\' && quote == '"' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						arg.WriteByte('\n')
+					case 't':
+						arg.WriteByte('\t')
+					case 'r':
+						arg.WriteByte('\r')
+					default:
+						arg.WriteByte(line[i])
+					}
+					continue
+				}
+				if c == '\\' && quote == '\'' && i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					arg.WriteByte('\'')
+					continue
+				}
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				arg.WriteByte(c)
+			}
+			if !closed {
+				return nil, false
+			}
+		} else {
+			for ; i < len(line) && !isWhiteSpace(line[i]); i++ {
+				arg.WriteByte(line[i])
+			}
+		}
+		args = append(args, arg.String())
+	}
+	return args, true
+}
+
 // Simple ASCII lexer for postgresql configuration files (aka. GUC)
 type pgConfLexer struct {
 	buf []byte
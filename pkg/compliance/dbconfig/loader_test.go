@@ -218,6 +218,80 @@ func TestMongoDBConfParsing(t *testing.T) {
 	assert.Equal(t, "/var/log/mongodb/mongod.log", *configData.SystemLog.Path)
 }
 
+func TestMySQLConfParsing(t *testing.T) {
+	c, ok := LoadMySQLConfig(context.Background(), "testdata/mysql", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "/etc/mysql/my.cnf", c.ConfigFilePath)
+	assert.NotEmpty(t, c.ConfigFileUser)
+	assert.NotZero(t, c.ConfigFileMode)
+
+	configData := c.ConfigData.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"port":     "3306",
+		"socket":   "/var/run/mysqld/mysqld.sock",
+		"password": redactedValue,
+	}, configData["client"])
+	assert.Equal(t, map[string]interface{}{
+		"user":                "mysql",
+		"bind_address":        "0.0.0.0",
+		"local_infile":        "0",
+		"secure_file_priv":    "/var/lib/mysql-files",
+		"skip_symbolic_links": "on",
+		"pid_file":            "/var/run/mysqld/mysqld.pid",
+		"datadir":             "/var/lib/mysql",
+		"log_error":           "/var/log/mysql/error.log",
+		"ssl_ca":              "/etc/mysql/certs/ca.pem",
+	}, configData["mysqld"])
+	assert.Equal(t, map[string]interface{}{"nice": "0"}, configData["mysqld_safe"])
+
+	_, ok = LoadMySQLConfig(context.Background(), t.TempDir(), nil)
+	assert.False(t, ok)
+}
+
+func TestRedisConfParsing(t *testing.T) {
+	c, ok := LoadRedisConfig(context.Background(), "testdata/redis", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "/etc/redis/redis.conf", c.ConfigFilePath)
+	assert.NotEmpty(t, c.ConfigFileUser)
+	assert.NotZero(t, c.ConfigFileMode)
+
+	assert.Equal(t, map[string]interface{}{
+		"bind":             "127.0.0.1 -::1",
+		"protected-mode":   "no",
+		"port":             "6379",
+		"tcp-keepalive":    "300",
+		"save":             []interface{}{"3600 1", "300 100"},
+		"requirepass":      redactedValue,
+		"rename-command":   []interface{}{`CONFIG ""`, `FLUSHALL ""`},
+		"user":             []interface{}{"default on >" + redactedValue + " ~* &* +@all"},
+		"tls-port":         "6380",
+		"tls-cert-file":    "/etc/redis/tls/redis.crt",
+		"tls-auth-clients": "yes",
+	}, c.ConfigData)
+
+	_, ok = LoadRedisConfig(context.Background(), t.TempDir(), nil)
+	assert.False(t, ok)
+}
+
+func TestRedisConfLineSplitting(t *testing.T) {
+	for line, want := range map[string][]string{
+		``:                                    nil,
+		`# comment`:                           nil,
+		`port 6379`:                           {"port", "6379"},
+		"  bind\t127.0.0.1   ::1 ":            {"bind", "127.0.0.1", "::1"},
+		`requirepass "with \"quotes\" and\n"`: {"requirepass", "with \"quotes\" and\n"},
+		`rename-command CONFIG ''`:            {"rename-command", "CONFIG", ""},
+		`masterauth 'it\'s'`:                  {"masterauth", "it's"},
+	} {
+		args, ok := splitRedisConfigLine(line)
+		assert.True(t, ok, line)
+		assert.Equal(t, want, args, line)
+	}
+
+	_, ok := splitRedisConfigLine(`requirepass "unterminated`)
+	assert.False(t, ok)
+}
+
 const pgConfigCommon = `
 # -----------------------------
 # PostgreSQL configuration file
@@ -0,0 +1,317 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	dockerManifestFile = "manifest.json"
+	ociIndexFile       = "index.json"
+
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+
+	maxImageManifestSize = 4 * 1024 * 1024
+)
+
+type dockerManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// UnpackImage unpacks the root filesystem of a container image tarball, as
+// produced by "docker save" or holding an OCI image layout, into the given
+// directory. The layers are applied in order, honoring the whiteout files.
+// Files ownership is only preserved when running as root.
+func UnpackImage(archivePath, rootDir string) error {
+	blobsDir, err := os.MkdirTemp("", "compliance-image-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(blobsDir)
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	if err := extractTar(archive, blobsDir, false); err != nil {
+		return fmt.Errorf("could not read image archive %q: %w", archivePath, err)
+	}
+
+	layers, err := imageLayers(blobsDir)
+	if err != nil {
+		return fmt.Errorf("could not read image archive %q: %w", archivePath, err)
+	}
+
+	for _, layer := range layers {
+		if err := applyLayer(layer, rootDir); err != nil {
+			return fmt.Errorf("could not apply image layer %q: %w", filepath.Base(layer), err)
+		}
+	}
+	return nil
+}
+
+// imageLayers returns the paths of the layers of the image extracted in the
+// given directory, from the lowest to the uppermost one.
+func imageLayers(dir string) ([]string, error) {
+	if data, err := readFileLimitSize(filepath.Join(dir, dockerManifestFile), maxImageManifestSize); err == nil {
+		var manifests []dockerManifest
+		if err := json.Unmarshal(data, &manifests); err != nil {
+			return nil, fmt.Errorf("invalid docker manifest: %w", err)
+		}
+		if len(manifests) == 0 {
+			return nil, errors.New("no image in docker manifest")
+		}
+		layers := make([]string, 0, len(manifests[0].Layers))
+		for _, layer := range manifests[0].Layers {
+			layerPath, err := securejoin.SecureJoin(dir, layer)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, layerPath)
+		}
+		return layers, nil
+	}
+
+	data, err := readFileLimitSize(filepath.Join(dir, ociIndexFile), maxImageManifestSize)
+	if err != nil {
+		return nil, errors.New("neither a docker archive nor an OCI image layout")
+	}
+
+	var index ocispec.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid OCI index: %w", err)
+	}
+
+	// nested indexes are found in multi-platform images
+	for depth := 0; depth < 4; depth++ {
+		desc, ok := selectManifest(index.Manifests)
+		if !ok {
+			return nil, errors.New("no image manifest in OCI index")
+		}
+		data, err := readOCIBlob(dir, desc)
+		if err != nil {
+			return nil, err
+		}
+
+		if desc.MediaType == ocispec.MediaTypeImageIndex || desc.MediaType == "application/vnd.docker.distribution.manifest.list.v2+json" {
+			index = ocispec.Index{}
+			if err := json.Unmarshal(data, &index); err != nil {
+				return nil, fmt.Errorf("invalid OCI index: %w", err)
+			}
+			continue
+		}
+
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid OCI manifest: %w", err)
+		}
+		layers := make([]string, 0, len(manifest.Layers))
+		for _, layer := range manifest.Layers {
+			layerPath, err := ociBlobPath(dir, layer)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, layerPath)
+		}
+		return layers, nil
+	}
+	return nil, errors.New("too many nested OCI indexes")
+}
+
+// selectManifest returns the manifest matching the current platform, or the
+// first one when none match.
+func selectManifest(manifests []ocispec.Descriptor) (ocispec.Descriptor, bool) {
+	if len(manifests) == 0 {
+		return ocispec.Descriptor{}, false
+	}
+	for _, desc := range manifests {
+		if p := desc.Platform; p != nil && p.OS == "linux" && p.Architecture == runtime.GOARCH {
+			return desc, true
+		}
+	}
+	return manifests[0], true
+}
+
+func ociBlobPath(dir string, desc ocispec.Descriptor) (string, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("invalid OCI blob digest %q: %w", desc.Digest, err)
+	}
+	return filepath.Join(dir, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded()), nil
+}
+
+func readOCIBlob(dir string, desc ocispec.Descriptor) ([]byte, error) {
+	blobPath, err := ociBlobPath(dir, desc)
+	if err != nil {
+		return nil, err
+	}
+	return readFileLimitSize(blobPath, maxImageManifestSize)
+}
+
+func readFileLimitSize(name string, size int64) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, size))
+}
+
+func applyLayer(layerPath, rootDir string) error {
+	f, err := os.Open(layerPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic, _ := r.Peek(2)
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return extractTar(gz, rootDir, true)
+	}
+	return extractTar(r, rootDir, true)
+}
+
+// extractTar extracts the given tar stream in the root directory. The entries
+// can't escape it, symbolic links being resolved relatively to the root
+// directory.
+func extractTar(r io.Reader, rootDir string, isLayer bool) error {
+	// entries of the current layer must survive its opaque whiteouts
+	added := make(map[string]bool)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean("/" + hdr.Name)
+		if name == "/" {
+			continue
+		}
+		dir, base := path.Split(name)
+
+		if isLayer && strings.HasPrefix(base, whiteoutPrefix) {
+			parent, err := securejoin.SecureJoin(rootDir, dir)
+			if err != nil {
+				return err
+			}
+			if base == whiteoutOpaque {
+				entries, _ := os.ReadDir(parent)
+				for _, entry := range entries {
+					if added[filepath.Join(parent, entry.Name())] {
+						continue
+					}
+					if err := os.RemoveAll(filepath.Join(parent, entry.Name())); err != nil {
+						return err
+					}
+				}
+			} else if err := os.RemoveAll(filepath.Join(parent, strings.TrimPrefix(base, whiteoutPrefix))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// the parent directories are resolved within the root directory
+		// while the entry itself replaces any existing file
+		parent, err := securejoin.SecureJoin(rootDir, dir)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return err
+		}
+		target := filepath.Join(parent, base)
+		added[target] = true
+
+		if hdr.Typeflag != tar.TypeDir {
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+		} else if fi, err := os.Lstat(target); err == nil && !fi.IsDir() {
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		}
+
+		mode := os.FileMode(hdr.Mode) & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			lchown(target, hdr)
+			if err := os.Chmod(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck // TypeRegA is found in old archives
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			// changing the owner clears the setuid and setgid bits
+			lchown(target, hdr)
+			if err := os.Chmod(target, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+			lchown(target, hdr)
+		case tar.TypeLink:
+			source, err := securejoin.SecureJoin(rootDir, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		default:
+			// devices and fifos are not relevant to compliance checks
+		}
+	}
+}
+
+func lchown(target string, hdr *tar.Header) {
+	if os.Geteuid() == 0 {
+		_ = os.Lchown(target, hdr.Uid, hdr.Gid)
+	}
+}
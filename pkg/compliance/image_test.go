@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package compliance
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	mode     int64
+	data     []byte
+	linkname string
+}
+
+func buildTar(t *testing.T, entries ...tarEntry) []byte {
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Mode:     mode,
+			Size:     int64(len(e.data)),
+			Linkname: e.linkname,
+		}))
+		_, err := tw.Write(e.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return b.Bytes()
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	_, err := gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return b.Bytes()
+}
+
+func jsonData(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func testImageLayers(t *testing.T) [][]byte {
+	lower := buildTar(t,
+		tarEntry{name: "etc/", typeflag: tar.TypeDir, mode: 0755},
+		tarEntry{name: "etc/passwd", typeflag: tar.TypeReg, data: []byte("root:x:0:0:root:/root:/bin/sh\n")},
+		tarEntry{name: "etc/shadow", typeflag: tar.TypeReg, mode: 0640, data: []byte("root:*:19000::::::\n")},
+		tarEntry{name: "etc/ssh/sshd_config", typeflag: tar.TypeReg, data: []byte("PermitRootLogin yes\n")},
+		tarEntry{name: "var/cache/apt/pkgcache.bin", typeflag: tar.TypeReg, data: []byte("cache")},
+		tarEntry{name: "etc/localtime", typeflag: tar.TypeSymlink, linkname: "/usr/share/zoneinfo/UTC"},
+	)
+	upper := buildTar(t,
+		tarEntry{name: "etc/.wh.shadow", typeflag: tar.TypeReg},
+		tarEntry{name: "etc/ssh/sshd_config", typeflag: tar.TypeReg, mode: 0600, data: []byte("PermitRootLogin no\n")},
+		tarEntry{name: "var/cache/apt/new.bin", typeflag: tar.TypeReg, data: []byte("new")},
+		tarEntry{name: "var/cache/apt/.wh..wh..opq", typeflag: tar.TypeReg},
+		// the symlink must not allow writing out of the root directory
+		tarEntry{name: "etc/localtime/escape", typeflag: tar.TypeReg, data: []byte("escape")},
+		tarEntry{name: "../../escape", typeflag: tar.TypeReg, data: []byte("escape")},
+	)
+	return [][]byte{lower, upper}
+}
+
+func assertUnpackedImage(t *testing.T, rootDir string) {
+	data, err := os.ReadFile(filepath.Join(rootDir, "etc/passwd"))
+	require.NoError(t, err)
+	assert.Equal(t, "root:x:0:0:root:/root:/bin/sh\n", string(data))
+
+	assert.NoFileExists(t, filepath.Join(rootDir, "etc/shadow"))
+
+	data, err = os.ReadFile(filepath.Join(rootDir, "etc/ssh/sshd_config"))
+	require.NoError(t, err)
+	assert.Equal(t, "PermitRootLogin no\n", string(data))
+	fi, err := os.Stat(filepath.Join(rootDir, "etc/ssh/sshd_config"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	assert.NoFileExists(t, filepath.Join(rootDir, "var/cache/apt/pkgcache.bin"))
+	assert.FileExists(t, filepath.Join(rootDir, "var/cache/apt/new.bin"))
+
+	link, err := os.Readlink(filepath.Join(rootDir, "etc/localtime"))
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/share/zoneinfo/UTC", link)
+	assert.FileExists(t, filepath.Join(rootDir, "usr/share/zoneinfo/UTC/escape"))
+	assert.FileExists(t, filepath.Join(rootDir, "escape"))
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(rootDir), "escape"))
+}
+
+func TestUnpackDockerArchive(t *testing.T) {
+	layers := testImageLayers(t)
+	archive := buildTar(t,
+		tarEntry{name: "manifest.json", typeflag: tar.TypeReg, data: jsonData(t, []dockerManifest{{
+			Config:   "config.json",
+			RepoTags: []string{"test:latest"},
+			Layers:   []string{"lower/layer.tar", "upper/layer.tar"},
+		}})},
+		tarEntry{name: "lower/layer.tar", typeflag: tar.TypeReg, data: layers[0]},
+		tarEntry{name: "upper/layer.tar", typeflag: tar.TypeReg, data: layers[1]},
+	)
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "image.tar")
+	require.NoError(t, os.WriteFile(archivePath, archive, 0600))
+
+	rootDir := filepath.Join(dir, "rootfs")
+	require.NoError(t, UnpackImage(archivePath, rootDir))
+	assertUnpackedImage(t, rootDir)
+}
+
+func TestUnpackOCIArchive(t *testing.T) {
+	var entries []tarEntry
+	addBlob := func(mediaType string, data []byte) ocispec.Descriptor {
+		sum := sha256.Sum256(data)
+		entries = append(entries, tarEntry{name: "blobs/sha256/" + hex.EncodeToString(sum[:]), typeflag: tar.TypeReg, data: data})
+		return ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.NewDigestFromBytes(digest.SHA256, sum[:]),
+			Size:      int64(len(data)),
+		}
+	}
+
+	var layers []ocispec.Descriptor
+	for _, layer := range testImageLayers(t) {
+		layers = append(layers, addBlob(ocispec.MediaTypeImageLayerGzip, gzipData(t, layer)))
+	}
+	manifest := addBlob(ocispec.MediaTypeImageManifest, jsonData(t, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Layers:    layers,
+	}))
+	other := addBlob(ocispec.MediaTypeImageManifest, jsonData(t, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+	}))
+	other.Platform = &ocispec.Platform{OS: "linux", Architecture: "unknown"}
+	manifest.Platform = &ocispec.Platform{OS: "linux", Architecture: runtime.GOARCH}
+	nested := addBlob(ocispec.MediaTypeImageIndex, jsonData(t, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{other, manifest},
+	}))
+	entries = append(entries, tarEntry{name: "index.json", typeflag: tar.TypeReg, data: jsonData(t, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{nested},
+	})})
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "image.tar")
+	require.NoError(t, os.WriteFile(archivePath, buildTar(t, entries...), 0600))
+
+	rootDir := filepath.Join(dir, "rootfs")
+	require.NoError(t, UnpackImage(archivePath, rootDir))
+	assertUnpackedImage(t, rootDir)
+}
+
+func TestUnpackInvalidArchive(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "image.tar")
+	require.NoError(t, os.WriteFile(archivePath, buildTar(t, tarEntry{name: "README", typeflag: tar.TypeReg}), 0600))
+	assert.Error(t, UnpackImage(archivePath, filepath.Join(dir, "rootfs")))
+}
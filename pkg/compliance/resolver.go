@@ -87,6 +87,11 @@ type ResolverOptions struct {
 	// ID (optional)
 	HostRootPID int32
 
+	// Offline is set when HostRoot is a root filesystem that is not running,
+	// like an unpacked container image. The inputs requiring running
+	// processes or API clients are then reported as not applicable.
+	Offline bool
+
 	// StatsdClient is the statsd client used internally by the compliance
 	// resolver (optional)
 	StatsdClient statsd.ClientInterface
@@ -187,6 +192,14 @@ func (r *defaultResolver) ResolveInputs(ctx context.Context, rule *Rule) (Resolv
 		return nil, fmt.Errorf("no inputs for rule %s", rule.ID)
 	}
 
+	if r.opts.Offline {
+		for _, spec := range rule.InputSpecs {
+			if !isOfflineInputSpec(spec) {
+				return nil, fmt.Errorf("%w: offline resolution of a live system input", ErrIncompatibleEnvironment)
+			}
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, inputsResolveTimeout)
 	defer cancel()
 
@@ -194,7 +207,7 @@ func (r *defaultResolver) ResolveInputs(ctx context.Context, rule *Rule) (Resolv
 	// resolve the image metadata associated with the container to be part of
 	// the resolved inputs.
 	rootPath := r.opts.HostRoot
-	if pid := r.opts.HostRootPID; pid > 0 {
+	if pid := r.opts.HostRootPID; pid > 0 && !r.opts.Offline {
 		containerID, ok := utils.GetProcessContainerID(pid)
 		if ok {
 			rootPath, ok = utils.GetProcessRootPath(pid)
@@ -289,6 +302,20 @@ func (r *defaultResolver) ResolveInputs(ctx context.Context, rule *Rule) (Resolv
 	return NewResolvedInputs(resolvingContext, resolved)
 }
 
+// isOfflineInputSpec returns whether the input spec only depends on the
+// content of the root filesystem.
+func isOfflineInputSpec(spec *InputSpec) bool {
+	switch {
+	case spec.File != nil:
+		// files located from the command line of a process require it to run
+		return !processFlagBuiltinReg.MatchString(spec.File.Path) && !processFlagBuiltinReg.MatchString(spec.File.Glob)
+	case spec.Group != nil, spec.Package != nil, spec.Constants != nil:
+		return true
+	default:
+		return false
+	}
+}
+
 func (r *defaultResolver) pathNormalize(rootPath, path string) string {
 	if rootPath != "" {
 		return filepath.Join(rootPath, path)
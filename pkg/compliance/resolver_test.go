@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineResolver(t *testing.T) {
+	hostroot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(hostroot, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostroot, "etc", "group"), []byte("docker:x:999:alice,bob\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(hostroot, "etc", "motd"), []byte("hello"), 0644))
+
+	resolver := NewResolver(context.Background(), ResolverOptions{
+		Hostname: "image",
+		HostRoot: hostroot,
+		Offline:  true,
+	})
+	defer resolver.Close()
+
+	inputs, err := resolver.ResolveInputs(context.Background(), &Rule{
+		ID: "offline",
+		InputSpecs: []*InputSpec{
+			{File: &InputSpecFile{Path: "/etc/motd"}},
+			{Group: &InputSpecGroup{Name: "docker"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, inputs, "file")
+	assert.Contains(t, inputs, "group")
+
+	for name, spec := range map[string]*InputSpec{
+		"process":      {Process: &InputSpecProcess{Name: "sshd"}},
+		"process_flag": {File: &InputSpecFile{Path: `process.flag("kubelet", "--config")`}},
+		"docker":       {Docker: &InputSpecDocker{Kind: "info"}},
+	} {
+		_, err := resolver.ResolveInputs(context.Background(), &Rule{
+			ID:         name,
+			InputSpecs: []*InputSpec{{File: &InputSpecFile{Path: "/etc/motd"}}, spec},
+		})
+		assert.True(t, errors.Is(err, ErrIncompatibleEnvironment), name)
+	}
+}
@@ -39,6 +39,12 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.obfuscation.redis.remove_all_args", "DD_APM_OBFUSCATION_REDIS_REMOVE_ALL_ARGS")
 	config.BindEnv("apm_config.obfuscation.memcached.enabled", "DD_APM_OBFUSCATION_MEMCACHED_ENABLED")
 	config.BindEnv("apm_config.obfuscation.memcached.keep_command", "DD_APM_OBFUSCATION_MEMCACHED_KEEP_COMMAND")
+	config.BindEnv("apm_config.obfuscation.graphql.enabled", "DD_APM_OBFUSCATION_GRAPHQL_ENABLED")
+	config.BindEnv("apm_config.obfuscation.graphql.keep_null", "DD_APM_OBFUSCATION_GRAPHQL_KEEP_NULL")
+	config.BindEnv("apm_config.obfuscation.graphql.keep_boolean", "DD_APM_OBFUSCATION_GRAPHQL_KEEP_BOOLEAN")
+	config.BindEnv("apm_config.obfuscation.cql.enabled", "DD_APM_OBFUSCATION_CQL_ENABLED")
+	config.BindEnv("apm_config.obfuscation.cql.keep_null", "DD_APM_OBFUSCATION_CQL_KEEP_NULL")
+	config.BindEnv("apm_config.obfuscation.cql.keep_boolean", "DD_APM_OBFUSCATION_CQL_KEEP_BOOLEAN")
 	config.SetKnown("apm_config.filter_tags.require")
 	config.SetKnown("apm_config.filter_tags.reject")
 	config.SetKnown("apm_config.filter_tags_regex.require")
@@ -93,6 +99,14 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.errors_per_second", "DD_APM_ERROR_TPS")
 	config.BindEnv("apm_config.enable_rare_sampler", "DD_APM_ENABLE_RARE_SAMPLER")
 	config.BindEnv("apm_config.disable_rare_sampler", "DD_APM_DISABLE_RARE_SAMPLER") //Deprecated
+	config.BindEnv("apm_config.zipkin_receiver.enabled", "DD_APM_ZIPKIN_RECEIVER_ENABLED")
+	config.BindEnv("apm_config.jaeger_receiver.enabled", "DD_APM_JAEGER_RECEIVER_ENABLED")
+	config.BindEnv("apm_config.tail_sampling.enabled", "DD_APM_TAIL_SAMPLING_ENABLED")
+	config.BindEnv("apm_config.tail_sampling.decision_wait", "DD_APM_TAIL_SAMPLING_DECISION_WAIT")
+	config.BindEnv("apm_config.otlp_export.enabled", "DD_APM_OTLP_EXPORT_ENABLED")
+	config.BindEnv("apm_config.otlp_export.endpoint", "DD_APM_OTLP_EXPORT_ENDPOINT")
+	config.BindEnv("apm_config.otlp_export.protocol", "DD_APM_OTLP_EXPORT_PROTOCOL")
+	config.BindEnv("apm_config.otlp_export.insecure", "DD_APM_OTLP_EXPORT_INSECURE")
 	config.BindEnv("apm_config.max_remote_traces_per_second", "DD_APM_MAX_REMOTE_TPS")
 
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")
@@ -132,6 +146,7 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.obfuscation.credit_cards.enabled", "DD_APM_OBFUSCATION_CREDIT_CARDS_ENABLED")
 	config.BindEnv("apm_config.obfuscation.credit_cards.luhn", "DD_APM_OBFUSCATION_CREDIT_CARDS_LUHN")
 	config.BindEnvAndSetDefault("apm_config.debug.port", 5012, "DD_APM_DEBUG_PORT")
+	config.BindEnv("apm_config.capture_path", "DD_APM_CAPTURE_PATH")
 	config.BindEnv("apm_config.features", "DD_APM_FEATURES")
 	config.SetEnvKeyTransformer("apm_config.features", func(s string) interface{} {
 		// Either commas or spaces can be used as separators.
@@ -190,6 +205,16 @@ func setupAPM(config Config) {
 		}
 		return out
 	})
+
+	config.BindEnv("apm_config.stats_custom_tags", "DD_APM_STATS_CUSTOM_TAGS")
+	config.SetEnvKeyTransformer("apm_config.stats_custom_tags", func(in string) interface{} {
+		var out []string
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.stats_custom_tags" can not be parsed: %v`, err)
+		}
+		return out
+	})
+	config.BindEnv("apm_config.stats_custom_tags_max_values", "DD_APM_STATS_CUSTOM_TAGS_MAX_VALUES")
 }
 
 func parseKVList(key string) func(string) interface{} {
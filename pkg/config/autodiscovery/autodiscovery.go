@@ -67,6 +67,12 @@ func DiscoverComponentsFromConfig() ([]config.ConfigurationProviders, []config.L
 		}
 	}
 
+	// Auto-add the systemd listener based on `systemd_unit_discovery.enabled`
+	if config.Datadog.GetBool("systemd_unit_discovery.enabled") && flavor.GetFlavor() != flavor.ClusterAgent {
+		log.Info("Systemd unit discovery is enabled: Adding the systemd listener")
+		detectedListeners = append(detectedListeners, config.Listeners{Name: "systemd"})
+	}
+
 	return detectedProviders, detectedListeners
 }
 
@@ -179,6 +179,17 @@ type MetricMapping struct {
 	Tags      map[string]string `mapstructure:"tags" json:"tags" yaml:"tags"`
 }
 
+// TagDerivationRule represents a rule computing a tag from the fields of a
+// workloadmeta entity
+type TagDerivationRule struct {
+	Tag         string   `mapstructure:"tag" json:"tag" yaml:"tag"`
+	From        []string `mapstructure:"from" json:"from" yaml:"from"`
+	Match       string   `mapstructure:"match" json:"match" yaml:"match"`
+	Value       string   `mapstructure:"value" json:"value" yaml:"value"`
+	Cardinality string   `mapstructure:"cardinality" json:"cardinality" yaml:"cardinality"`
+	Kinds       []string `mapstructure:"kinds" json:"kinds" yaml:"kinds"`
+}
+
 // Endpoint represent a datadog endpoint
 type Endpoint struct {
 	Site   string `mapstructure:"site" json:"site" yaml:"site"`
@@ -617,6 +628,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_node_label_as_cluster_name", "")
 	config.BindEnvAndSetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("container_cgroup_prefix", "")
+	config.BindEnv("tag_derivation_rules")
+	config.SetEnvKeyTransformer("tag_derivation_rules", func(in string) interface{} {
+		var rules []TagDerivationRule
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"tag_derivation_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 
 	// CRI
 	config.BindEnvAndSetDefault("cri_socket_path", "")              // empty is disabled
@@ -633,6 +652,11 @@ func InitConfig(config Config) {
 	// Podman
 	config.BindEnvAndSetDefault("podman_db_path", "/var/lib/containers/storage/libpod/bolt_state.db")
 
+	// Systemd unit discovery
+	config.BindEnvAndSetDefault("systemd_unit_discovery.enabled", false)
+	config.BindEnvAndSetDefault("systemd_unit_discovery.private_socket", "")
+	config.BindEnvAndSetDefault("systemd_unit_discovery.unit_names", []string{})
+
 	// Kubernetes
 	config.BindEnvAndSetDefault("kubernetes_kubelet_host", "")
 	config.BindEnvAndSetDefault("kubernetes_kubelet_nodename", "")
@@ -734,6 +758,8 @@ func InitConfig(config Config) {
 		`^kubectl\.kubernetes\.io\/last-applied-configuration$`,
 		`^ad\.datadoghq\.com\/([[:alnum:]]+\.)?(checks|check_names|init_configs|instances)$`,
 	})
+	config.BindEnvAndSetDefault("cluster_agent.kubernetes_resources_collection.workloads_enabled", false)
+	config.BindEnvAndSetDefault("cluster_agent.kubernetes_resources_collection.namespaces_enabled", false)
 	config.BindEnvAndSetDefault("metrics_port", "5000")
 
 	// Metadata endpoints
@@ -1127,6 +1153,11 @@ func InitConfig(config Config) {
 	// Remote process collector
 	config.BindEnvAndSetDefault("workloadmeta.local_process_collector.collection_interval", DefaultLocalProcessCollectorInterval)
 
+	// Workloadmeta snapshot
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.interval", 1*time.Minute)
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.max_age", 10*time.Minute)
+
 	// SBOM configuration
 	config.BindEnvAndSetDefault("sbom.enabled", false)
 	bindEnvAndSetLogsConfigKeys(config, "sbom.")
@@ -1930,6 +1961,23 @@ func getDogstatsdMappingProfilesConfig(config Config) ([]MappingProfile, error)
 	return mappings, nil
 }
 
+// GetTagDerivationRules returns the rules used by the tagger to derive tags
+// from entity fields
+func GetTagDerivationRules() ([]TagDerivationRule, error) {
+	return getTagDerivationRulesConfig(Datadog)
+}
+
+func getTagDerivationRulesConfig(config Config) ([]TagDerivationRule, error) {
+	var rules []TagDerivationRule
+	if config.IsSet("tag_derivation_rules") {
+		err := config.UnmarshalKey("tag_derivation_rules", &rules)
+		if err != nil {
+			return []TagDerivationRule{}, log.Errorf("Could not parse tag_derivation_rules: %v", err)
+		}
+	}
+	return rules, nil
+}
+
 // IsCLCRunner returns whether the Agent is in cluster check runner mode
 func IsCLCRunner() bool {
 	if !Datadog.GetBool("clc_runner_enabled") {
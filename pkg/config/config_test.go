@@ -613,6 +613,55 @@ dogstatsd_mapper_profiles:
 	assert.Empty(t, profiles)
 }
 
+func TestTagDerivationRules(t *testing.T) {
+	datadogYaml := `
+tag_derivation_rules:
+  - tag: team
+    from:
+      - label:owner
+      - namespace_label:team
+    kinds:
+      - kubernetes_pod
+  - tag: version
+    from:
+      - image.tag
+    match: "^v?([0-9]+\\.[0-9]+)"
+    value: "$1"
+    cardinality: orchestrator
+`
+	testConfig := SetupConfFromYAML(datadogYaml)
+
+	rules, err := getTagDerivationRulesConfig(testConfig)
+
+	expectedRules := []TagDerivationRule{
+		{
+			Tag:   "team",
+			From:  []string{"label:owner", "namespace_label:team"},
+			Kinds: []string{"kubernetes_pod"},
+		},
+		{
+			Tag:         "version",
+			From:        []string{"image.tag"},
+			Match:       "^v?([0-9]+\\.[0-9]+)",
+			Value:       "$1",
+			Cardinality: "orchestrator",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, expectedRules, rules)
+}
+
+func TestTagDerivationRulesEnv(t *testing.T) {
+	t.Setenv("DD_TAG_DERIVATION_RULES", `[{"tag":"team","from":["label:owner"],"cardinality":"low"}]`)
+	expected := []TagDerivationRule{
+		{Tag: "team", From: []string{"label:owner"}, Cardinality: "low"},
+	}
+	rules, err := GetTagDerivationRules()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, rules)
+}
+
 func TestDogstatsdMappingProfilesEnv(t *testing.T) {
 	env := "DD_DOGSTATSD_MAPPER_PROFILES"
 	t.Setenv(env, `[{"name":"another_profile","prefix":"abcd","mappings":[{"match":"airflow\\.dag_processing\\.last_runtime\\.(.*)","match_type":"regex","name":"foo","tags":{"a":"$1","b":"$2"}}]},{"name":"some_other_profile","prefix":"some_other_profile.","mappings":[{"match":"some_other_profile.*","name":"some_other_profile.abc","tags":{"a":"$1"}}]}]`)
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Change is a difference between two flares for a single key, such as a
+// configuration setting or a check instance. Old is empty for additions, New
+// is empty for removals.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// Section groups the changes of one aspect of the flares.
+type Section struct {
+	Title   string
+	Changes []Change
+}
+
+// DiffReport is the structured difference between two flares.
+type DiffReport struct {
+	Old      string
+	New      string
+	Sections []Section
+}
+
+// Diff compares two flares, oldFlare being the reference.
+func Diff(oldFlare, newFlare *Flare) (*DiffReport, error) {
+	report := &DiffReport{
+		Old: oldFlare.Path,
+		New: newFlare.Path,
+	}
+
+	report.add("Versions", diffMaps(oldFlare.Versions(), newFlare.Versions()))
+
+	oldConfig, err := oldFlare.RuntimeConfig()
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := newFlare.RuntimeConfig()
+	if err != nil {
+		return nil, err
+	}
+	report.add("Runtime configuration", diffMaps(oldConfig, newConfig))
+
+	report.add("Scheduled checks", diffMaps(joinValues(oldFlare.ConfigChecks()), joinValues(newFlare.ConfigChecks())))
+
+	report.add("Check statuses", diffMaps(formatStatuses(oldFlare.CheckStatuses()), formatStatuses(newFlare.CheckStatuses())))
+
+	report.add("Workloadmeta entities", diffMaps(toSet(oldFlare.WorkloadEntities()), toSet(newFlare.WorkloadEntities())))
+
+	return report, nil
+}
+
+func (r *DiffReport) add(title string, changes []Change) {
+	r.Sections = append(r.Sections, Section{Title: title, Changes: changes})
+}
+
+// Write writes the report in a human readable form.
+func (r *DiffReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", r.Old, r.New)
+
+	for _, section := range r.Sections {
+		fmt.Fprintf(w, "\n=== %s ===\n", section.Title)
+
+		if len(section.Changes) == 0 {
+			fmt.Fprintln(w, "  no changes")
+			continue
+		}
+
+		for _, change := range section.Changes {
+			switch {
+			case change.Old == "":
+				fmt.Fprintf(w, "+ %s%s\n", change.Key, formatChangeValue(change.New))
+			case change.New == "":
+				fmt.Fprintf(w, "- %s%s\n", change.Key, formatChangeValue(change.Old))
+			default:
+				fmt.Fprintf(w, "~ %s: %s -> %s\n", change.Key, change.Old, change.New)
+			}
+		}
+	}
+}
+
+// formatChangeValue omits the values of set members, which are stored as
+// "present".
+func formatChangeValue(value string) string {
+	if value == present {
+		return ""
+	}
+
+	return ": " + value
+}
+
+const present = "present"
+
+func diffMaps(before, after map[string]string) []Change {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	var changes []Change
+	for _, key := range sortedKeys(keys) {
+		oldValue, inOld := before[key]
+		newValue, inNew := after[key]
+
+		switch {
+		case inOld && !inNew:
+			changes = append(changes, Change{Key: key, Old: nonEmpty(oldValue)})
+		case !inOld && inNew:
+			changes = append(changes, Change{Key: key, New: nonEmpty(newValue)})
+		case oldValue != newValue:
+			changes = append(changes, Change{Key: key, Old: nonEmpty(oldValue), New: nonEmpty(newValue)})
+		}
+	}
+
+	return changes
+}
+
+// nonEmpty keeps empty values distinguishable from missing ones.
+func nonEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	return value
+}
+
+func joinValues(m map[string][]string) map[string]string {
+	joined := make(map[string]string, len(m))
+	for k, v := range m {
+		joined[k] = fmt.Sprintf("%d instance(s) [%s]", len(v), strings.Join(v, ", "))
+	}
+
+	return joined
+}
+
+func formatStatuses(statuses map[string]CheckStatus) map[string]string {
+	formatted := make(map[string]string, len(statuses))
+	for id, status := range statuses {
+		formatted[id] = status.Status
+		if len(status.Messages) > 0 {
+			formatted[id] += " (" + strings.Join(status.Messages, "; ") + ")"
+		}
+	}
+
+	return formatted
+}
+
+func toSet(values []string) map[string]string {
+	set := make(map[string]string, len(values))
+	for _, v := range values {
+		set[v] = present
+	}
+
+	return set
+}
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package inspect reads flare archives to compare them and summarize the
+// errors they contain.
+package inspect
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Files of the flare, relative to its root directory, used by the inspection.
+const (
+	runtimeConfigFile = "runtime_config_dump.yaml"
+	configCheckFile   = "config-check.log"
+	statusFile        = "status.log"
+	workloadListFile  = "workload-list.log"
+	logsDir           = "logs"
+)
+
+// ansiEscape matches the color codes that may be left in text files of the
+// flare.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// Flare is a flare archive loaded in memory.
+type Flare struct {
+	// Path is the path of the archive, or directory, the flare was read from.
+	Path string
+	// Hostname is the name of the root directory of the flare.
+	Hostname string
+
+	files map[string][]byte
+}
+
+// Open reads the flare at the given path. The path is either a flare archive,
+// as created by the flare builder, or a directory it was extracted to.
+func Open(path string) (*Flare, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Flare{
+		Path:  path,
+		files: make(map[string][]byte),
+	}
+
+	if info.IsDir() {
+		err = f.readDir(path)
+	} else {
+		err = f.readZip(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read flare %s: %w", path, err)
+	}
+
+	f.stripRootDir()
+
+	return f, nil
+}
+
+func (f *Flare) readZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, file := range r.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+
+		f.files[filepath.ToSlash(file.Name)] = content
+	}
+
+	return nil
+}
+
+func (f *Flare) readDir(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		f.files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+}
+
+// stripRootDir removes the hostname directory all the files of a flare are
+// stored under.
+func (f *Flare) stripRootDir() {
+	var root string
+	for name := range f.files {
+		dir, _, found := strings.Cut(name, "/")
+		if !found || (root != "" && dir != root) {
+			return
+		}
+		root = dir
+	}
+
+	if root == "" {
+		return
+	}
+
+	files := make(map[string][]byte, len(f.files))
+	for name, content := range f.files {
+		files[strings.TrimPrefix(name, root+"/")] = content
+	}
+
+	f.Hostname = root
+	f.files = files
+}
+
+// File returns the content of a file of the flare, with color codes removed.
+func (f *Flare) File(name string) ([]byte, bool) {
+	content, ok := f.files[name]
+	if !ok {
+		return nil, false
+	}
+
+	return ansiEscape.ReplaceAll(content, nil), true
+}
+
+// LogFiles returns the names of the log files bundled in the flare.
+func (f *Flare) LogFiles() []string {
+	var names []string
+	for name := range f.files {
+		if strings.HasPrefix(name, logsDir+"/") && strings.Contains(filepath.Base(name), ".log") {
+			names = append(names, name)
+		}
+	}
+
+	return sortedCopy(names)
+}
@@ -0,0 +1,264 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const statusV1 = `===============
+Agent (v7.48.0)
+===============
+
+  Status date: 2023-10-10 10:00:00.000 UTC (1696932000000)
+  Go Version: go1.20.8
+  Python Version: 3.9.16
+  Agent flavor: agent
+
+=========
+Collector
+=========
+
+  Running Checks
+  ==============
+
+    cpu
+    ---
+      Instance ID: cpu [` + "\x1b[32mOK\x1b[0m" + `]
+      Configuration Source: file:/etc/datadog-agent/conf.d/cpu.d/conf.yaml.default
+      Total Runs: 10
+
+    redisdb (4.5.0)
+    ---------------
+      Instance ID: redisdb:6f1b2a [OK]
+      Total Runs: 10
+`
+
+const statusV2 = `===============
+Agent (v7.49.0)
+===============
+
+  Go Version: go1.20.10
+  Python Version: 3.9.16
+  Agent flavor: agent
+
+=========
+Collector
+=========
+
+  Running Checks
+  ==============
+
+    cpu
+    ---
+      Instance ID: cpu [OK]
+      Total Runs: 10
+
+    redisdb (4.5.0)
+    ---------------
+      Instance ID: redisdb:6f1b2a [ERROR]
+      Total Runs: 10
+      Error: Connection refused
+      Traceback (most recent call last):
+        File "redisdb.py", line 1, in check
+`
+
+const configCheckV1 = `
+=== cpu check ===
+Configuration provider: file
+Configuration source: file:/etc/datadog-agent/conf.d/cpu.d/conf.yaml.default
+Config for instance ID: cpu:abc
+{}
+~
+===
+
+=== redisdb check ===
+Configuration provider: docker
+Config for instance ID: redisdb:6f1b2a
+{}
+~
+===
+`
+
+const configCheckV2 = `
+=== cpu check ===
+Configuration provider: file
+Config for instance ID: cpu:abc
+{}
+~
+===
+
+=== redisdb check ===
+Config for instance ID: redisdb:6f1b2a
+{}
+~
+Config for instance ID: redisdb:e3c4d5
+{}
+~
+===
+
+=== disk check ===
+Config for instance ID: disk:123
+{}
+~
+===
+`
+
+const agentLog = `2023-10-10 10:00:00 UTC | CORE | INFO | (pkg/collector/scheduler.go:12 in Enter) | Scheduling check cpu
+2023-10-10 10:00:01 UTC | CORE | ERROR | (pkg/collector/worker/check_logger.go:69 in Error) | check:redisdb | Error running check: connection refused after 3 retries
+2023-10-10 10:00:02 UTC | CORE | ERROR | (pkg/collector/worker/check_logger.go:69 in Error) | check:redisdb | Error running check: connection refused after 4 retries
+2023-10-10 10:00:03 UTC | CORE | WARN | (pkg/util/kubernetes/kubelet/kubelet.go:100 in connect) | cannot connect to the kubelet
+`
+
+const processAgentLog = `{"agent":"process","time":"2023-10-10 10:00:00 UTC","level":"ERROR","file":"pkg/process/runner.go","line":"42","func":"run","msg":"unable to submit payload"}
+`
+
+func writeFlare(t *testing.T, files map[string]string) string {
+	path := filepath.Join(t.TempDir(), "datadog-agent.zip")
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create("my-host/" + name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+	return path
+}
+
+func TestOpen(t *testing.T) {
+	path := writeFlare(t, map[string]string{
+		"status.log":       statusV1,
+		"logs/agent.log":   agentLog,
+		"logs/agent.log.1": agentLog,
+		"etc/datadog.yaml": "api_key: ********",
+	})
+
+	f, err := Open(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-host", f.Hostname)
+	assert.Equal(t, []string{"logs/agent.log", "logs/agent.log.1"}, f.LogFiles())
+
+	_, ok := f.File("etc/datadog.yaml")
+	assert.True(t, ok)
+
+	assert.Equal(t, map[string]string{
+		"Agent":          "7.48.0",
+		"Go Version":     "go1.20.8",
+		"Python Version": "3.9.16",
+		"Agent flavor":   "agent",
+	}, f.Versions())
+
+	assert.Equal(t, map[string]CheckStatus{
+		"cpu":            {Status: "OK"},
+		"redisdb:6f1b2a": {Status: "OK"},
+	}, f.CheckStatuses())
+}
+
+func TestDiff(t *testing.T) {
+	oldFlare, err := Open(writeFlare(t, map[string]string{
+		"status.log":               statusV1,
+		"config-check.log":         configCheckV1,
+		"runtime_config_dump.yaml": "log_level: info\napm_config:\n  enabled: true\nextra_tags: [a, b]\n",
+		"workload-list.log":        "\n=== Entity container sources(merged):[runtime] id: abc ===\nfoo\n===\n\n=== Entity kubernetes_pod sources(merged):[node_orchestrator] id: p1 ===\nbar\n===\n",
+	}))
+	require.NoError(t, err)
+
+	newFlare, err := Open(writeFlare(t, map[string]string{
+		"status.log":               statusV2,
+		"config-check.log":         configCheckV2,
+		"runtime_config_dump.yaml": "log_level: debug\napm_config:\n  enabled: true\n  max_traces_per_second: 10\nextra_tags: [a, b]\n",
+		"workload-list.log":        "\n=== Entity container sources(merged):[runtime] id: abc ===\nfoo\n===\n\n=== Entity container sources(merged):[runtime] id: def ===\nfoo\n===\n",
+	}))
+	require.NoError(t, err)
+
+	report, err := Diff(oldFlare, newFlare)
+	require.NoError(t, err)
+
+	sections := make(map[string][]Change)
+	for _, section := range report.Sections {
+		sections[section.Title] = section.Changes
+	}
+
+	assert.Equal(t, []Change{
+		{Key: "Agent", Old: "7.48.0", New: "7.49.0"},
+		{Key: "Go Version", Old: "go1.20.8", New: "go1.20.10"},
+	}, sections["Versions"])
+
+	assert.Equal(t, []Change{
+		{Key: "apm_config.max_traces_per_second", New: "10"},
+		{Key: "log_level", Old: "info", New: "debug"},
+	}, sections["Runtime configuration"])
+
+	assert.Equal(t, []Change{
+		{Key: "disk", New: "1 instance(s) [disk:123]"},
+		{Key: "redisdb", Old: "1 instance(s) [redisdb:6f1b2a]", New: "2 instance(s) [redisdb:6f1b2a, redisdb:e3c4d5]"},
+	}, sections["Scheduled checks"])
+
+	assert.Equal(t, []Change{
+		{Key: "redisdb:6f1b2a", Old: "OK", New: "ERROR (Error: Connection refused)"},
+	}, sections["Check statuses"])
+
+	assert.Equal(t, []Change{
+		{Key: "container def", New: present},
+		{Key: "kubernetes_pod p1", Old: present},
+	}, sections["Workloadmeta entities"])
+
+	var out bytes.Buffer
+	report.Write(&out)
+	assert.Contains(t, out.String(), "~ log_level: info -> debug\n")
+	assert.Contains(t, out.String(), "+ container def\n")
+	assert.Contains(t, out.String(), "- kubernetes_pod p1\n")
+}
+
+func TestSummarize(t *testing.T) {
+	f, err := Open(writeFlare(t, map[string]string{
+		"status.log":             statusV2,
+		"logs/agent.log":         agentLog,
+		"logs/process-agent.log": processAgentLog,
+	}))
+	require.NoError(t, err)
+
+	summary := Summarize(f, 10)
+
+	assert.Equal(t, []LogEntry{
+		{
+			Level:    "ERROR",
+			Location: "pkg/collector/worker/check_logger.go:69 in Error",
+			Message:  "check:redisdb | Error running check: connection refused after 3 retries",
+			Count:    2,
+			Files:    []string{"logs/agent.log"},
+		},
+		{
+			Level:    "ERROR",
+			Location: "pkg/process/runner.go:42",
+			Message:  "unable to submit payload",
+			Count:    1,
+			Files:    []string{"logs/process-agent.log"},
+		},
+	}, summary.Errors)
+
+	require.Len(t, summary.Warnings, 1)
+	assert.Equal(t, "cannot connect to the kubelet", summary.Warnings[0].Message)
+
+	assert.Equal(t, map[string]CheckStatus{
+		"redisdb:6f1b2a": {Status: "ERROR", Messages: []string{"Error: Connection refused"}},
+	}, summary.CheckErrors)
+
+	assert.Len(t, Summarize(f, 1).Errors, 1)
+}
@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	statusTitle      = regexp.MustCompile(`^(\S.*) \(v(.+)\)$`)
+	statusVersion    = regexp.MustCompile(`^\s+(Go Version|Python Version|Agent flavor): (.+)$`)
+	statusInstance   = regexp.MustCompile(`^\s+Instance ID: (\S+) \[(\w+)\]`)
+	statusError      = regexp.MustCompile(`^\s+(Error|Warning): (.+)$`)
+	configCheckName  = regexp.MustCompile(`^=== (.+?) (cluster )?check ===$`)
+	configInstanceID = regexp.MustCompile(`^Config for instance ID: (.+)$`)
+	workloadEntity   = regexp.MustCompile(`^=== Entity (\S+) .*id: (.+) ===$`)
+)
+
+// Versions returns the versions reported in the status of the flare, such as
+// the Agent and Go versions.
+func (f *Flare) Versions() map[string]string {
+	versions := make(map[string]string)
+
+	content, ok := f.File(statusFile)
+	if !ok {
+		return versions
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := statusTitle.FindStringSubmatch(line); m != nil {
+			if _, found := versions[m[1]]; !found {
+				versions[m[1]] = m[2]
+			}
+		} else if m := statusVersion.FindStringSubmatch(line); m != nil {
+			versions[m[1]] = strings.TrimSpace(m[2])
+		}
+	}
+
+	return versions
+}
+
+// RuntimeConfig returns the runtime configuration of the flare, flattened to
+// dotted keys.
+func (f *Flare) RuntimeConfig() (map[string]string, error) {
+	settings := make(map[string]string)
+
+	content, ok := f.File(runtimeConfigFile)
+	if !ok {
+		return settings, nil
+	}
+
+	var raw map[interface{}]interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", runtimeConfigFile, err)
+	}
+
+	flatten("", raw, settings)
+
+	return settings, nil
+}
+
+func flatten(prefix string, value interface{}, settings map[string]string) {
+	m, ok := value.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		settings[prefix] = formatValue(value)
+		return
+	}
+
+	for k, v := range m {
+		key := fmt.Sprint(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		flatten(key, v, settings)
+	}
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case []interface{}, map[interface{}]interface{}:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.Join(strings.Fields(strings.ReplaceAll(string(out), "\n", " ")), " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ConfigChecks returns the instance IDs of each check scheduled in the
+// config-check output of the flare.
+func (f *Flare) ConfigChecks() map[string][]string {
+	checks := make(map[string][]string)
+
+	content, ok := f.File(configCheckFile)
+	if !ok {
+		return checks
+	}
+
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if m := configCheckName.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			if _, found := checks[current]; !found {
+				checks[current] = []string{}
+			}
+		} else if m := configInstanceID.FindStringSubmatch(line); m != nil && current != "" {
+			checks[current] = append(checks[current], m[1])
+		} else if line == "===" {
+			current = ""
+		}
+	}
+
+	for name, ids := range checks {
+		sort.Strings(ids)
+		checks[name] = ids
+	}
+
+	return checks
+}
+
+// CheckStatus is the status of a check instance, as reported in the status of
+// the flare.
+type CheckStatus struct {
+	// Status is one of OK, WARNING or ERROR.
+	Status string
+	// Messages are the error and warnings reported for the last run.
+	Messages []string
+}
+
+// CheckStatuses returns the status of each check instance, by instance ID.
+func (f *Flare) CheckStatuses() map[string]CheckStatus {
+	statuses := make(map[string]CheckStatus)
+
+	content, ok := f.File(statusFile)
+	if !ok {
+		return statuses
+	}
+
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := statusInstance.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			statuses[current] = CheckStatus{Status: m[2]}
+		} else if m := statusError.FindStringSubmatch(line); m != nil && current != "" {
+			status := statuses[current]
+			status.Messages = append(status.Messages, m[1]+": "+strings.TrimSpace(m[2]))
+			statuses[current] = status
+		} else if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "      ") {
+			// instances details are indented, anything else ends
+			// the current instance
+			current = ""
+		}
+	}
+
+	return statuses
+}
+
+// WorkloadEntities returns the entities of the workloadmeta dump of the
+// flare, as "<kind> <id>".
+func (f *Flare) WorkloadEntities() []string {
+	content, ok := f.File(workloadListFile)
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if m := workloadEntity.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+			seen[m[1]+" "+m[2]] = struct{}{}
+		}
+	}
+
+	return sortedKeys(seen)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedCopy(s []string) []string {
+	sorted := append([]string(nil), s...)
+	sort.Strings(sorted)
+
+	return sorted
+}
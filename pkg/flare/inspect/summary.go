@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// numbers are replaced in log messages so that messages only differing by an
+// ID, a duration or a count are grouped together.
+var numbers = regexp.MustCompile(`\d+`)
+
+// LogEntry groups the log lines sharing the same level, location and message.
+type LogEntry struct {
+	Level    string
+	Location string
+	// Message is the first message of the group, as logged.
+	Message string
+	Count   int
+	Files   []string
+}
+
+// Summary is the list of the most frequent errors and warnings logged in a
+// flare.
+type Summary struct {
+	Path     string
+	Versions map[string]string
+	Errors   []LogEntry
+	Warnings []LogEntry
+	// CheckErrors are the messages of the check instances that are not OK.
+	CheckErrors map[string]CheckStatus
+}
+
+// Summarize collects the top errors and warnings from the logs of the flare.
+// top limits the number of entries per level, 0 meaning no limit.
+func Summarize(f *Flare, top int) *Summary {
+	groups := make(map[string]*LogEntry)
+
+	for _, name := range f.LogFiles() {
+		content, _ := f.File(name)
+
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			level, location, message, ok := parseLogLine(scanner.Text())
+			if !ok || (level != "ERROR" && level != "WARN" && level != "CRITICAL") {
+				continue
+			}
+
+			key := level + "|" + location + "|" + numbers.ReplaceAllString(message, "N")
+			entry, found := groups[key]
+			if !found {
+				entry = &LogEntry{
+					Level:    level,
+					Location: location,
+					Message:  message,
+				}
+				groups[key] = entry
+			}
+
+			entry.Count++
+			if len(entry.Files) == 0 || entry.Files[len(entry.Files)-1] != name {
+				entry.Files = append(entry.Files, name)
+			}
+		}
+	}
+
+	summary := &Summary{
+		Path:        f.Path,
+		Versions:    f.Versions(),
+		CheckErrors: make(map[string]CheckStatus),
+	}
+
+	for _, entry := range groups {
+		if entry.Level == "WARN" {
+			summary.Warnings = append(summary.Warnings, *entry)
+		} else {
+			summary.Errors = append(summary.Errors, *entry)
+		}
+	}
+
+	summary.Errors = topEntries(summary.Errors, top)
+	summary.Warnings = topEntries(summary.Warnings, top)
+
+	for id, status := range f.CheckStatuses() {
+		if status.Status != "OK" {
+			summary.CheckErrors[id] = status
+		}
+	}
+
+	return summary
+}
+
+// parseLogLine parses a line written with the text or JSON log format of the
+// Agent.
+func parseLogLine(line string) (level, location, message string, ok bool) {
+	if strings.HasPrefix(line, "{") {
+		var entry struct {
+			Level string `json:"level"`
+			File  string `json:"file"`
+			Line  string `json:"line"`
+			Msg   string `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Level == "" {
+			return "", "", "", false
+		}
+
+		return strings.ToUpper(entry.Level), entry.File + ":" + entry.Line, entry.Msg, true
+	}
+
+	// <date> | <logger> | <LEVEL> | (<file>:<line> in <func>) | <message>
+	fields := strings.SplitN(line, " | ", 5)
+	if len(fields) < 4 {
+		return "", "", "", false
+	}
+
+	level = strings.TrimSpace(fields[2])
+	if len(fields) == 4 {
+		// serverless format, without location
+		return level, "", fields[3], true
+	}
+
+	return level, strings.Trim(fields[3], "()"), fields[4], true
+}
+
+func topEntries(entries []LogEntry, top int) []LogEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Message < entries[j].Message
+	})
+
+	if top > 0 && len(entries) > top {
+		entries = entries[:top]
+	}
+
+	return entries
+}
+
+// Write writes the summary in a human readable form.
+func (s *Summary) Write(w io.Writer) {
+	fmt.Fprintf(w, "Flare: %s\n", s.Path)
+	for _, name := range sortedKeys(s.Versions) {
+		fmt.Fprintf(w, "  %s: %s\n", name, s.Versions[name])
+	}
+
+	fmt.Fprintf(w, "\n=== Check errors and warnings ===\n")
+	if len(s.CheckErrors) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, id := range sortedKeys(s.CheckErrors) {
+		status := s.CheckErrors[id]
+		fmt.Fprintf(w, "  %s [%s]\n", id, status.Status)
+		for _, message := range status.Messages {
+			fmt.Fprintf(w, "    %s\n", message)
+		}
+	}
+
+	writeLogEntries(w, "Top errors", s.Errors)
+	writeLogEntries(w, "Top warnings", s.Warnings)
+}
+
+func writeLogEntries(w io.Writer, title string, entries []LogEntry) {
+	fmt.Fprintf(w, "\n=== %s ===\n", title)
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "  %6d  %s | %s\n", entry.Count, entry.Level, entry.Message)
+		if entry.Location != "" {
+			fmt.Fprintf(w, "          at %s in %s\n", entry.Location, strings.Join(entry.Files, ", "))
+		} else {
+			fmt.Fprintf(w, "          in %s\n", strings.Join(entry.Files, ", "))
+		}
+	}
+}
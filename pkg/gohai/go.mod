@@ -2,7 +2,7 @@ module github.com/DataDog/datadog-agent/pkg/gohai
 
 // we don't want to just use the agent's go version because gohai might be used outside of it
 // eg. opentelemetry
-go 1.20
+go 1.21
 
 require (
 	github.com/DataDog/datadog-agent/pkg/util/log v0.50.0-rc.4
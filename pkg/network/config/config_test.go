@@ -1187,6 +1187,102 @@ service_monitoring_config:
 	})
 }
 
+func TestEnablePostgresMonitoring(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		cfg := New()
+
+		assert.False(t, cfg.EnablePostgresMonitoring)
+	})
+
+	t.Run("via YAML", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		cfg := configurationFromYAML(t, `
+service_monitoring_config:
+  enable_postgres_monitoring: true
+`)
+
+		assert.True(t, cfg.EnablePostgresMonitoring)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_POSTGRES_MONITORING", "true")
+		cfg := New()
+
+		assert.True(t, cfg.EnablePostgresMonitoring)
+	})
+}
+
+func TestMaxPostgresStatsBuffered(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		cfg := New()
+
+		assert.Equal(t, 100000, cfg.MaxPostgresStatsBuffered)
+	})
+
+	t.Run("value set through env var", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_MAX_POSTGRES_STATS_BUFFERED", "50000")
+
+		cfg := New()
+		assert.Equal(t, 50000, cfg.MaxPostgresStatsBuffered)
+	})
+
+	t.Run("value set through yaml", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		cfg := configurationFromYAML(t, `
+service_monitoring_config:
+  max_postgres_stats_buffered: 30000
+`)
+
+		assert.Equal(t, 30000, cfg.MaxPostgresStatsBuffered)
+	})
+}
+
+func TestRedisMonitoringConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		cfg := New()
+
+		assert.False(t, cfg.EnableRedisMonitoring)
+		assert.Equal(t, 32, cfg.RedisKeyPrefixSize)
+		assert.False(t, cfg.ObfuscateRedisKeys)
+		assert.Equal(t, 100000, cfg.MaxRedisStatsBuffered)
+	})
+
+	t.Run("via YAML", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		cfg := configurationFromYAML(t, `
+service_monitoring_config:
+  enable_redis_monitoring: true
+  redis_key_prefix_size: 16
+  obfuscate_redis_keys: true
+  max_redis_stats_buffered: 30000
+`)
+
+		assert.True(t, cfg.EnableRedisMonitoring)
+		assert.Equal(t, 16, cfg.RedisKeyPrefixSize)
+		assert.True(t, cfg.ObfuscateRedisKeys)
+		assert.Equal(t, 30000, cfg.MaxRedisStatsBuffered)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		aconfig.ResetSystemProbeConfig(t)
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_REDIS_MONITORING", "true")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_REDIS_KEY_PREFIX_SIZE", "0")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_OBFUSCATE_REDIS_KEYS", "true")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_MAX_REDIS_STATS_BUFFERED", "50000")
+		cfg := New()
+
+		assert.True(t, cfg.EnableRedisMonitoring)
+		assert.Equal(t, 0, cfg.RedisKeyPrefixSize)
+		assert.True(t, cfg.ObfuscateRedisKeys)
+		assert.Equal(t, 50000, cfg.MaxRedisStatsBuffered)
+	})
+}
+
 func TestNetworkConfigEnabled(t *testing.T) {
 	ys := true
 
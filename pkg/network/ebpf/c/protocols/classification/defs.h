@@ -140,6 +140,8 @@ typedef enum {
     PROG_HTTP2_FRAME_PARSER,
     PROG_KAFKA,
     PROG_GRPC,
+    PROG_POSTGRES,
+    PROG_REDIS,
     // Add before this value.
     PROG_MAX,
 } protocol_prog_t;
@@ -125,8 +125,8 @@ static __always_inline bool validate_first_topic_name(struct __sk_buff *skb, u32
     CHECK_STRING_COMPOSED_OF_ASCII(TOPIC_NAME_MAX_STRING_SIZE_TO_VALIDATE, topic_name_size, topic_name);
 }
 
-// Getting the offset (out parameter) of the first topic name in the produce request.
-static __always_inline bool get_topic_offset_from_produce_request(const kafka_header_t *kafka_header, struct __sk_buff *skb, u32 *out_offset) {
+// Getting the offset (out parameter) of the first topic name in the produce request, and its acks (out parameter).
+static __always_inline bool get_topic_offset_from_produce_request(const kafka_header_t *kafka_header, struct __sk_buff *skb, u32 *out_offset, s16 *out_acks) {
     const s16 api_version = kafka_header->api_version;
     u32 offset = *out_offset;
     if (api_version >= 3) {
@@ -150,6 +150,7 @@ static __always_inline bool get_topic_offset_from_produce_request(const kafka_he
     }
 
     *out_offset = offset;
+    *out_acks = acks;
     return true;
 }
 
@@ -183,11 +184,13 @@ static __always_inline bool is_kafka_request(const kafka_header_t *kafka_header,
     // name in the request, and then validate the topic. We have to have shared call for validate_first_topic_name
     // as the function is huge, rather than call validate_first_topic_name for each api_key.
     switch (kafka_header->api_key) {
-    case KAFKA_PRODUCE:
-        if (!get_topic_offset_from_produce_request(kafka_header, skb, &offset)) {
+    case KAFKA_PRODUCE: {
+        s16 acks = 0;
+        if (!get_topic_offset_from_produce_request(kafka_header, skb, &offset, &acks)) {
             return false;
         }
         break;
+    }
     case KAFKA_FETCH:
         offset += get_topic_offset_from_fetch_request(kafka_header);
         break;
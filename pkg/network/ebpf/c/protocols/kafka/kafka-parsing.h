@@ -10,6 +10,7 @@
 // forward declaration
 static __always_inline bool kafka_allow_packet(kafka_transaction_t *kafka, struct __sk_buff* skb, skb_info_t *skb_info);
 static __always_inline bool kafka_process(kafka_transaction_t *kafka_transaction, struct __sk_buff* skb, __u32 offset);
+static __always_inline bool kafka_process_response(kafka_transaction_t *kafka_transaction, struct __sk_buff* skb, __u32 offset);
 
 // A template for verifying a given buffer is composed of the characters [a-z], [A-Z], [0-9], ".", "_", or "-".
 // The iterations reads up to MIN(max_buffer_size, real_size).
@@ -74,7 +75,7 @@ static __always_inline bool kafka_process(kafka_transaction_t *kafka_transaction
     log_debug("kafka: kafka_header.api_version: %d\n", kafka_header.api_version);
 
     if (!is_valid_kafka_request_header(&kafka_header)) {
-        return false;
+        return kafka_process_response(kafka_transaction, skb, offset);
     }
 
     kafka_transaction->base.request_api_key = kafka_header.api_key;
@@ -93,9 +94,10 @@ static __always_inline bool kafka_process(kafka_transaction_t *kafka_transaction
         return false;
     }
 
+    s16 acks = -1;
     switch (kafka_header.api_key) {
     case KAFKA_PRODUCE:
-        if (!get_topic_offset_from_produce_request(&kafka_header, skb, &offset)) {
+        if (!get_topic_offset_from_produce_request(&kafka_header, skb, &offset, &acks)) {
             return false;
         }
         break;
@@ -121,7 +123,84 @@ static __always_inline bool kafka_process(kafka_transaction_t *kafka_transaction
 
     log_debug("kafka: topic name is %s\n", kafka_transaction->base.topic_name);
 
-    kafka_batch_enqueue(&kafka_transaction->base);
+    kafka_transaction->base.request_started = bpf_ktime_get_ns();
+    if (acks == 0) {
+        // the broker doesn't respond to produce requests without acknowledgments
+        kafka_batch_enqueue(&kafka_transaction->base);
+        return true;
+    }
+
+    kafka_transaction_key_t key;
+    bpf_memset(&key, 0, sizeof(key));
+    key.tup = kafka_transaction->base.tup;
+    key.correlation_id = kafka_header.correlation_id;
+    bpf_map_update_with_telemetry(kafka_in_flight, &key, &kafka_transaction->base, BPF_ANY);
+    return true;
+}
+
+// Reads the error code (out parameter) of a produce or fetch response, which is the top-level error code of fetch
+// responses (version 7 and above) if set, or the error code of the first partition of the first topic otherwise.
+static __always_inline bool get_error_code_from_response(const kafka_transaction_batch_entry_t *request, struct __sk_buff *skb, u32 offset, s16 *out_error_code) {
+    switch (request->request_api_key) {
+    case KAFKA_PRODUCE:
+        break;
+    case KAFKA_FETCH:
+        if (request->request_api_version >= 1) {
+            // throttle_time_ms => INT32
+            offset += sizeof(s32);
+        }
+        if (request->request_api_version >= 7) {
+            READ_BIG_ENDIAN_WRAPPER(s16, error_code, skb, offset);
+            if (error_code != 0) {
+                *out_error_code = error_code;
+                return true;
+            }
+            // session_id => INT32
+            offset += sizeof(s32);
+        }
+        break;
+    default:
+        return false;
+    }
+
+    // Skipping number of topics
+    offset += sizeof(s32);
+    READ_BIG_ENDIAN_WRAPPER(s16, topic_name_size, skb, offset);
+    if (topic_name_size <= 0 || topic_name_size > TOPIC_NAME_MAX_ALLOWED_SIZE) {
+        return false;
+    }
+    offset += topic_name_size;
+    // Skipping number of partitions and partition index
+    offset += 2 * sizeof(s32);
+    READ_BIG_ENDIAN_WRAPPER(s16, partition_error_code, skb, offset);
+    *out_error_code = partition_error_code;
+    return true;
+}
+
+// Matches a response with its request using the correlation ID of the response header, and enqueues the transaction.
+static __always_inline bool kafka_process_response(kafka_transaction_t *kafka_transaction, struct __sk_buff* skb, __u32 offset) {
+    // Skipping message_size
+    offset += sizeof(s32);
+    READ_BIG_ENDIAN_WRAPPER(s32, correlation_id, skb, offset);
+
+    kafka_transaction_key_t key;
+    bpf_memset(&key, 0, sizeof(key));
+    key.tup = kafka_transaction->base.tup;
+    key.correlation_id = correlation_id;
+    kafka_transaction_batch_entry_t *request = bpf_map_lookup_elem(&kafka_in_flight, &key);
+    if (request == NULL) {
+        return false;
+    }
+
+    request->response_last_seen = bpf_ktime_get_ns();
+    s16 error_code = 0;
+    if (get_error_code_from_response(request, skb, offset, &error_code)) {
+        request->error_code = error_code;
+    }
+    log_debug("kafka: response to correlation id %d, error code %d\n", correlation_id, request->error_code);
+
+    kafka_batch_enqueue(request);
+    bpf_map_delete_elem(&kafka_in_flight, &key);
     return true;
 }
 
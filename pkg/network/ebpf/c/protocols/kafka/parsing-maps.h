@@ -7,5 +7,10 @@ BPF_PERCPU_ARRAY_MAP(kafka_heap, kafka_transaction_t, 1)
     It holds the last tcp sequence number for each connection.
    */
 BPF_HASH_MAP(kafka_last_tcp_seq_per_connection, conn_tuple_t, __u32, 0)
+/*
+    This map holds the requests waiting for their response, by connection and correlation ID.
+    It is an LRU map as some responses are never captured.
+   */
+BPF_LRU_MAP(kafka_in_flight, kafka_transaction_key_t, kafka_transaction_batch_entry_t, 0)
 
 #endif
@@ -20,12 +20,23 @@ typedef struct {
 
 typedef struct {
     conn_tuple_t tup;
+    __u64 request_started;
+    // zero when the request gets no response, e.g. produce requests with acks=0
+    __u64 response_last_seen;
     __u16 request_api_key;
     __u16 request_api_version;
-    char topic_name[TOPIC_NAME_MAX_STRING_SIZE];
+    // error code of the first partition of the response
+    __s16 error_code;
     __u16 topic_name_size;
+    char topic_name[TOPIC_NAME_MAX_STRING_SIZE];
 } kafka_transaction_batch_entry_t;
 
+// Identifies a request waiting for its response
+typedef struct {
+    conn_tuple_t tup;
+    __s32 correlation_id;
+} kafka_transaction_key_t;
+
 // Kafka transaction information associated to a certain socket (tuple_t)
 typedef struct {
     // this field is used to disambiguate segments in the context of keep-alives
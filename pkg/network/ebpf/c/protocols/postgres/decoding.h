@@ -0,0 +1,39 @@
+#ifndef __POSTGRES_DECODING_H
+#define __POSTGRES_DECODING_H
+
+#include "bpf_builtins.h"
+#include "map-defs.h"
+
+#include "protocols/classification/dispatcher-helpers.h"
+#include "protocols/postgres/usm-events.h"
+#include "protocols/stream/helpers.h"
+
+// A per-cpu buffer used to build the segments, as they are too large for the stack.
+BPF_PERCPU_ARRAY_MAP(postgres_heap, stream_segment_t, 1)
+
+// The segments of Postgres connections are sent as is to userspace, where the
+// messages are decoded.
+SEC("socket/postgres_filter")
+int socket__postgres_filter(struct __sk_buff* skb) {
+    const __u32 zero = 0;
+    skb_info_t skb_info;
+    stream_segment_t *segment = bpf_map_lookup_elem(&postgres_heap, &zero);
+    if (segment == NULL) {
+        log_debug("socket__postgres_filter: segment is NULL\n");
+        return 0;
+    }
+    bpf_memset(segment, 0, sizeof(stream_segment_t));
+
+    if (!fetch_dispatching_arguments(&segment->tup, &skb_info)) {
+        log_debug("socket__postgres_filter failed to fetch arguments for tail call\n");
+        return 0;
+    }
+
+    if (!read_stream_segment(segment, skb, &skb_info)) {
+        return 0;
+    }
+    postgres_batch_enqueue(segment);
+    return 0;
+}
+
+#endif
@@ -0,0 +1,37 @@
+#ifndef __STREAM_HELPERS_H
+#define __STREAM_HELPERS_H
+
+#include "bpf_builtins.h"
+#include "bpf_telemetry.h"
+#include "ip.h"
+
+#include "protocols/read_into_buffer.h"
+#include "protocols/stream/types.h"
+
+READ_INTO_BUFFER(stream_head, STREAM_HEAD_SIZE, BLK_SIZE)
+
+// Fills the segment with the TCP payload of the packet. Returns false if the
+// packet should not be sent to userspace, i.e. if it has no payload and does
+// not terminate the connection.
+static __always_inline bool read_stream_segment(stream_segment_t *segment, struct __sk_buff *skb, skb_info_t *skb_info) {
+    if (!(segment->tup.metadata & CONN_TYPE_TCP)) {
+        return false;
+    }
+
+    segment->timestamp = bpf_ktime_get_ns();
+    segment->tcp_seq = skb_info->tcp_seq;
+    segment->tcp_flags = skb_info->tcp_flags;
+
+    if (skb_info->data_end <= skb_info->data_off) {
+        return skb_info->tcp_flags & (TCPHDR_FIN | TCPHDR_RST);
+    }
+    segment->payload_size = skb_info->data_end - skb_info->data_off;
+
+    read_into_buffer_stream_head(segment->head, skb, skb_info->data_off);
+    if (segment->payload_size > STREAM_HEAD_SIZE) {
+        bpf_skb_load_bytes_with_telemetry(skb, skb_info->data_end - STREAM_TAIL_SIZE, segment->tail, STREAM_TAIL_SIZE);
+    }
+    return true;
+}
+
+#endif
@@ -0,0 +1,31 @@
+#ifndef __STREAM_TYPES_H
+#define __STREAM_TYPES_H
+
+#include "conn_tuple.h"
+
+// The number of bytes captured at the start and at the end of the payload of
+// each TCP segment. STREAM_HEAD_SIZE must be a multiple of BLK_SIZE.
+#define STREAM_HEAD_SIZE 256
+#define STREAM_TAIL_SIZE 64
+
+// This controls the number of segments read from userspace at a time
+#define STREAM_BATCH_SIZE 10
+
+// A TCP segment of a monitored connection. Only the first and the last bytes
+// of its payload are captured, the protocol decoders in userspace are fed with
+// the byte stream of the connection rebuilt from the segments.
+typedef struct {
+    // the tuple of the packet, whose source is the sender of the segment
+    conn_tuple_t tup;
+    __u64 timestamp;
+    __u32 tcp_seq;
+    // the size of the whole payload of the segment
+    __u32 payload_size;
+    __u8 tcp_flags;
+    char head[STREAM_HEAD_SIZE];
+    // the last STREAM_TAIL_SIZE bytes of the payload, only set when the
+    // payload is larger than STREAM_HEAD_SIZE
+    char tail[STREAM_TAIL_SIZE];
+} stream_segment_t;
+
+#endif
@@ -52,13 +52,15 @@ func GetMarshaler(accept string) Marshaler {
 
 // ConnectionsModeler contains all the necessary structs for modeling a connection.
 type ConnectionsModeler struct {
-	httpEncoder  *httpEncoder
-	http2Encoder *http2Encoder
-	kafkaEncoder *kafkaEncoder
-	dnsFormatter *dnsFormatter
-	ipc          ipCache
-	routeIndex   map[string]RouteIdx
-	tagsSet      *network.TagsSet
+	httpEncoder     *httpEncoder
+	http2Encoder    *http2Encoder
+	kafkaEncoder    *kafkaEncoder
+	postgresEncoder *postgresEncoder
+	redisEncoder    *redisEncoder
+	dnsFormatter    *dnsFormatter
+	ipc             ipCache
+	routeIndex      map[string]RouteIdx
+	tagsSet         *network.TagsSet
 }
 
 // NewConnectionsModeler initializes the connection modeler with encoders, dns formatter for
@@ -69,13 +71,15 @@ type ConnectionsModeler struct {
 func NewConnectionsModeler(conns *network.Connections) *ConnectionsModeler {
 	ipc := make(ipCache, len(conns.Conns)/2)
 	return &ConnectionsModeler{
-		httpEncoder:  newHTTPEncoder(conns.HTTP),
-		http2Encoder: newHTTP2Encoder(conns.HTTP2),
-		kafkaEncoder: newKafkaEncoder(conns.Kafka),
-		ipc:          ipc,
-		dnsFormatter: newDNSFormatter(conns, ipc),
-		routeIndex:   make(map[string]RouteIdx),
-		tagsSet:      network.NewTagsSet(),
+		httpEncoder:     newHTTPEncoder(conns.HTTP),
+		http2Encoder:    newHTTP2Encoder(conns.HTTP2),
+		kafkaEncoder:    newKafkaEncoder(conns.Kafka),
+		postgresEncoder: newPostgresEncoder(conns.Postgres),
+		redisEncoder:    newRedisEncoder(conns.Redis),
+		ipc:             ipc,
+		dnsFormatter:    newDNSFormatter(conns, ipc),
+		routeIndex:      make(map[string]RouteIdx),
+		tagsSet:         network.NewTagsSet(),
 	}
 }
 
@@ -84,6 +88,8 @@ func (c *ConnectionsModeler) Close() {
 	c.httpEncoder.Close()
 	c.http2Encoder.Close()
 	c.kafkaEncoder.Close()
+	c.postgresEncoder.Close()
+	c.redisEncoder.Close()
 }
 
 // GetUnmarshaler returns the appropriate Unmarshaler based on the given content type
@@ -106,7 +112,7 @@ func (c *ConnectionsModeler) modelConnections(builder *model.ConnectionsBuilder,
 
 	for _, conn := range conns.Conns {
 		builder.AddConns(func(builder *model.ConnectionBuilder) {
-			FormatConnection(builder, conn, c.routeIndex, c.httpEncoder, c.http2Encoder, c.kafkaEncoder, c.dnsFormatter, c.ipc, c.tagsSet)
+			FormatConnection(builder, conn, c.routeIndex, c.httpEncoder, c.http2Encoder, c.kafkaEncoder, c.postgresEncoder, c.redisEncoder, c.dnsFormatter, c.ipc, c.tagsSet)
 		})
 	}
 
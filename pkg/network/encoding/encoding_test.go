@@ -352,6 +352,10 @@ func testSerialization(t *testing.T, aggregateByStatusCode bool) {
 			result.Tags = nil
 		}
 		result.PrebuiltEBPFAssets = nil
+		// fixup: json marshaler encode nil map as empty
+		for _, c := range result.Conns {
+			c.TcpFailuresByErrCode = nil
+		}
 		assertConnsEqual(t, out, result)
 	})
 
@@ -376,6 +380,10 @@ func testSerialization(t *testing.T, aggregateByStatusCode bool) {
 			result.Tags = nil
 		}
 		result.PrebuiltEBPFAssets = nil
+		// fixup: json marshaler encode nil map as empty
+		for _, c := range result.Conns {
+			c.TcpFailuresByErrCode = nil
+		}
 		assertConnsEqual(t, out, result)
 	})
 
@@ -407,6 +415,10 @@ func testSerialization(t *testing.T, aggregateByStatusCode bool) {
 			result.Tags = nil
 		}
 		result.PrebuiltEBPFAssets = nil
+		// fixup: json marshaler encode nil map as empty
+		for _, c := range result.Conns {
+			c.TcpFailuresByErrCode = nil
+		}
 		assertConnsEqual(t, out, result)
 	})
 
@@ -439,6 +451,10 @@ func testSerialization(t *testing.T, aggregateByStatusCode bool) {
 			result.Tags = nil
 		}
 		result.PrebuiltEBPFAssets = nil
+		// fixup: json marshaler encode nil map as empty
+		for _, c := range result.Conns {
+			c.TcpFailuresByErrCode = nil
+		}
 		assertConnsEqual(t, out, result)
 	})
 
@@ -39,7 +39,7 @@ func (ipc ipCache) Get(addr util.Address) string {
 }
 
 // FormatConnection converts a ConnectionStats into an model.Connection
-func FormatConnection(builder *model.ConnectionBuilder, conn network.ConnectionStats, routes map[string]RouteIdx, httpEncoder *httpEncoder, http2Encoder *http2Encoder, kafkaEncoder *kafkaEncoder, dnsFormatter *dnsFormatter, ipc ipCache, tagsSet *network.TagsSet) {
+func FormatConnection(builder *model.ConnectionBuilder, conn network.ConnectionStats, routes map[string]RouteIdx, httpEncoder *httpEncoder, http2Encoder *http2Encoder, kafkaEncoder *kafkaEncoder, postgresEncoder *postgresEncoder, redisEncoder *redisEncoder, dnsFormatter *dnsFormatter, ipc ipCache, tagsSet *network.TagsSet) {
 
 	builder.SetPid(int32(conn.Pid))
 
@@ -104,6 +104,16 @@ func FormatConnection(builder *model.ConnectionBuilder, conn network.ConnectionS
 			b.Write(dsa)
 		})
 	}
+	pga := postgresEncoder.GetPostgresAggregations(conn)
+	rda := redisEncoder.GetRedisAggregations(conn)
+	if pga != nil || rda != nil {
+		builder.SetDatabaseAggregations(func(b *bytes.Buffer) {
+			// the concatenation of serialized messages is the serialization of
+			// their merge, which holds the aggregations of both protocols
+			b.Write(pga)
+			b.Write(rda)
+		})
+	}
 
 	conn.StaticTags |= staticTags
 	tags, tagChecksum := formatTags(conn, tagsSet, dynamicTags)
@@ -77,14 +77,24 @@ func (e *kafkaEncoder) encodeData(connectionData *USMConnectionData[kafka.Key, *
 
 	for _, kv := range connectionData.Data {
 		key := kv.Key
-		stats := kv.Value
+		requestStat := kv.Value
 
 		kafkaAggregation := kafkaAggregationPool.Get().(*model.KafkaAggregation)
 
 		kafkaAggregation.Header.RequestType = uint32(key.RequestAPIKey)
 		kafkaAggregation.Header.RequestVersion = uint32(key.RequestVersion)
 		kafkaAggregation.Topic = key.TopicName
-		kafkaAggregation.Count = uint32(stats.Count)
+		kafkaAggregation.Count = uint32(requestStat.Count)
+		kafkaAggregation.StatsByErrorCode = make(map[int32]*model.KafkaStats, len(requestStat.ErrorCodeToStat))
+		for errorCode, stat := range requestStat.ErrorCodeToStat {
+			kafkaStats := &model.KafkaStats{
+				Count: uint32(stat.Count),
+			}
+			if stat.Latencies != nil {
+				kafkaStats.Latencies, _ = proto.Marshal(stat.Latencies.ToProto())
+			}
+			kafkaAggregation.StatsByErrorCode[errorCode] = kafkaStats
+		}
 
 		e.aggregations.KafkaAggregations = append(e.aggregations.KafkaAggregations, kafkaAggregation)
 	}
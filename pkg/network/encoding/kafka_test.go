@@ -123,6 +123,70 @@ func (s *KafkaSuite) TestFormatKafkaStats() {
 	assert.ElementsMatch(t, out.KafkaAggregations, aggregations.KafkaAggregations)
 }
 
+func (s *KafkaSuite) TestFormatKafkaStatsByErrorCode() {
+	t := s.T()
+
+	kafkaKey := kafka.NewKey(
+		localhost,
+		localhost,
+		clientPort,
+		serverPort,
+		topicName,
+		kafka.FetchAPIKey,
+		apiVersion2,
+	)
+
+	stats := new(kafka.RequestStat)
+	// the latencies fall in the same bin, for the serialization of the
+	// sketches to be deterministic
+	stats.AddRequest(1e6, 0)
+	stats.AddRequest(1e6, 0)
+	stats.AddRequest(0, 0)
+	stats.AddRequest(2e6, 3)
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				defaultConnection,
+			},
+		},
+		Kafka: map[kafka.Key]*kafka.RequestStat{
+			kafkaKey: stats,
+		},
+	}
+
+	successLatencies, err := proto.Marshal(stats.ErrorCodeToStat[0].Latencies.ToProto())
+	require.NoError(t, err)
+	errorLatencies, err := proto.Marshal(stats.ErrorCodeToStat[3].Latencies.ToProto())
+	require.NoError(t, err)
+	out := []*model.KafkaAggregation{
+		{
+			Header: &model.KafkaRequestHeader{
+				RequestType:    kafka.FetchAPIKey,
+				RequestVersion: apiVersion2,
+			},
+			Topic: topicName,
+			Count: 4,
+			StatsByErrorCode: map[int32]*model.KafkaStats{
+				0: {
+					Count:     3,
+					Latencies: successLatencies,
+				},
+				3: {
+					Count:     1,
+					Latencies: errorLatencies,
+				},
+			},
+		},
+	}
+
+	encoder := newKafkaEncoder(in.Kafka)
+	t.Cleanup(encoder.Close)
+
+	aggregations := getKafkaAggregations(t, encoder, in.Conns[0])
+	assert.Equal(t, out, aggregations.KafkaAggregations)
+}
+
 func (s *KafkaSuite) TestKafkaIDCollisionRegression() {
 	t := s.T()
 	assert := assert.New(t)
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/postgres"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+)
+
+type postgresEncoder struct {
+	byConnection *USMConnectionIndex[postgres.Key, *postgres.RequestStat]
+
+	// cached object
+	aggregations *model.DatabaseAggregations
+}
+
+func newPostgresEncoder(postgresPayloads map[postgres.Key]*postgres.RequestStat) *postgresEncoder {
+	if len(postgresPayloads) == 0 {
+		return nil
+	}
+
+	return &postgresEncoder{
+		aggregations: &model.DatabaseAggregations{
+			Aggregations: make([]*model.DatabaseStats, 0, 10),
+		},
+		byConnection: GroupByConnection("postgres", postgresPayloads, func(key postgres.Key) types.ConnectionKey {
+			return key.ConnectionKey
+		}),
+	}
+}
+
+func (e *postgresEncoder) GetPostgresAggregations(c network.ConnectionStats) []byte {
+	if e == nil {
+		return nil
+	}
+
+	connectionData := e.byConnection.Find(c)
+	if connectionData == nil || len(connectionData.Data) == 0 || connectionData.IsPIDCollision(c) {
+		return nil
+	}
+
+	return e.encodeData(connectionData)
+}
+
+func (e *postgresEncoder) Close() {
+	if e == nil {
+		return
+	}
+
+	e.reset()
+	e.byConnection.Close()
+}
+
+func (e *postgresEncoder) encodeData(connectionData *USMConnectionData[postgres.Key, *postgres.RequestStat]) []byte {
+	e.reset()
+
+	for _, kv := range connectionData.Data {
+		key := kv.Key
+		stats := kv.Value
+
+		postgresStats := &model.PostgresStats{
+			TableName: key.TableName,
+			Operation: formatPostgresOperation(key.Operation),
+			Count:     uint32(stats.Count),
+		}
+		if stats.Latencies != nil {
+			postgresStats.Latencies, _ = proto.Marshal(stats.Latencies.ToProto())
+		} else {
+			postgresStats.FirstLatencySample = stats.FirstLatencySample
+		}
+
+		e.aggregations.Aggregations = append(e.aggregations.Aggregations, &model.DatabaseStats{
+			DbStats: &model.DatabaseStats_Postgres{
+				Postgres: postgresStats,
+			},
+		})
+	}
+
+	serializedData, _ := proto.Marshal(e.aggregations)
+	return serializedData
+}
+
+func (e *postgresEncoder) reset() {
+	if e == nil {
+		return
+	}
+
+	for i := range e.aggregations.Aggregations {
+		e.aggregations.Aggregations[i] = nil
+	}
+	e.aggregations.Aggregations = e.aggregations.Aggregations[:0]
+}
+
+// formatPostgresOperation returns the payload representation of op. The
+// operations which have none, such as TRUNCATE and SHOW, are unknown.
+func formatPostgresOperation(op postgres.Operation) model.PostgresOperation {
+	switch op {
+	case postgres.SelectOP:
+		return model.PostgresOperation_PostgresSelectOp
+	case postgres.InsertOP:
+		return model.PostgresOperation_PostgresInsertOp
+	case postgres.UpdateOP:
+		return model.PostgresOperation_PostgresUpdateOp
+	case postgres.DeleteOP:
+		return model.PostgresOperation_PostgresDeleteOp
+	case postgres.AlterTableOP:
+		return model.PostgresOperation_PostgresAlterOp
+	case postgres.CreateTableOP:
+		return model.PostgresOperation_PostgresCreateOp
+	case postgres.DropTableOP:
+		return model.PostgresOperation_PostgresDropOp
+	default:
+		return model.PostgresOperation_PostgresUnknownOp
+	}
+}
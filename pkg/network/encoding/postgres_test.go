@@ -0,0 +1,242 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/postgres"
+)
+
+const (
+	postgresPort = uint16(5432)
+	tableName    = "users"
+)
+
+type PostgresSuite struct {
+	suite.Suite
+}
+
+func TestPostgresStats(t *testing.T) {
+	skipIfNotLinux(t)
+	suite.Run(t, &PostgresSuite{})
+}
+
+func (s *PostgresSuite) TestFormatPostgresStats() {
+	t := s.T()
+
+	selectKey := postgres.NewKey(localhost, localhost, clientPort, postgresPort, postgres.SelectOP, tableName)
+	truncateKey := postgres.NewKey(localhost, localhost, clientPort, postgresPort, postgres.TruncateTableOP, tableName)
+
+	selectStats := new(postgres.RequestStat)
+	// the latencies fall in the same bin, for the serialization of the
+	// sketch to be deterministic
+	selectStats.AddRequest(1e6, false)
+	selectStats.AddRequest(1e6, true)
+	truncateStats := new(postgres.RequestStat)
+	truncateStats.AddRequest(3e6, false)
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source: localhost,
+					Dest:   localhost,
+					SPort:  clientPort,
+					DPort:  postgresPort,
+				},
+			},
+		},
+		Postgres: map[postgres.Key]*postgres.RequestStat{
+			selectKey:   selectStats,
+			truncateKey: truncateStats,
+		},
+	}
+
+	latencies, err := proto.Marshal(selectStats.Latencies.ToProto())
+	require.NoError(t, err)
+	out := []*model.DatabaseStats{
+		{
+			DbStats: &model.DatabaseStats_Postgres{
+				Postgres: &model.PostgresStats{
+					TableName: tableName,
+					Operation: model.PostgresOperation_PostgresSelectOp,
+					Latencies: latencies,
+					Count:     2,
+				},
+			},
+		},
+		{
+			DbStats: &model.DatabaseStats_Postgres{
+				Postgres: &model.PostgresStats{
+					TableName:          tableName,
+					Operation:          model.PostgresOperation_PostgresUnknownOp,
+					FirstLatencySample: 3e6,
+					Count:              1,
+				},
+			},
+		},
+	}
+
+	encoder := newPostgresEncoder(in.Postgres)
+	t.Cleanup(encoder.Close)
+
+	aggregations := getPostgresAggregations(t, encoder, in.Conns[0])
+	assert.ElementsMatch(t, out, aggregations.Aggregations)
+}
+
+func (s *PostgresSuite) TestPostgresIDCollisionRegression() {
+	t := s.T()
+	assert := assert.New(t)
+	connections := []network.ConnectionStats{
+		{
+			Source: localhost,
+			SPort:  clientPort,
+			Dest:   localhost,
+			DPort:  postgresPort,
+			Pid:    1,
+		},
+		{
+			Source: localhost,
+			SPort:  clientPort,
+			Dest:   localhost,
+			DPort:  postgresPort,
+			Pid:    2,
+		},
+	}
+
+	postgresKey := postgres.NewKey(localhost, localhost, clientPort, postgresPort, postgres.InsertOP, tableName)
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: connections,
+		},
+		Postgres: map[postgres.Key]*postgres.RequestStat{
+			postgresKey: {
+				Count:              1,
+				FirstLatencySample: 1e6,
+			},
+		},
+	}
+
+	encoder := newPostgresEncoder(in.Postgres)
+	t.Cleanup(encoder.Close)
+	aggregations := getPostgresAggregations(t, encoder, in.Conns[0])
+
+	// assert that the first connection matching the Postgres data will get back a non-nil result
+	require.Len(t, aggregations.Aggregations, 1)
+	assert.Equal(tableName, aggregations.Aggregations[0].GetPostgres().TableName)
+	assert.Equal(uint32(1), aggregations.Aggregations[0].GetPostgres().Count)
+
+	// assert that the other connections sharing the same (source,destination)
+	// addresses but different PIDs *won't* be associated with the Postgres stats
+	// object
+	assert.Nil(encoder.GetPostgresAggregations(in.Conns[1]))
+}
+
+func (s *PostgresSuite) TestPostgresSerializationWithLocalhostTraffic() {
+	t := s.T()
+
+	connections := []network.ConnectionStats{
+		{
+			Source: localhost,
+			SPort:  clientPort,
+			Dest:   localhost,
+			DPort:  postgresPort,
+			Pid:    1,
+		},
+		{
+			Source: localhost,
+			SPort:  postgresPort,
+			Dest:   localhost,
+			DPort:  clientPort,
+			Pid:    2,
+		},
+	}
+
+	postgresKey := postgres.NewKey(localhost, localhost, clientPort, postgresPort, postgres.DeleteOP, tableName)
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: connections,
+		},
+		Postgres: map[postgres.Key]*postgres.RequestStat{
+			postgresKey: {
+				Count:              1,
+				FirstLatencySample: 1e6,
+			},
+		},
+	}
+
+	postgresOut := &model.DatabaseAggregations{
+		Aggregations: []*model.DatabaseStats{
+			{
+				DbStats: &model.DatabaseStats_Postgres{
+					Postgres: &model.PostgresStats{
+						TableName:          tableName,
+						Operation:          model.PostgresOperation_PostgresDeleteOp,
+						FirstLatencySample: 1e6,
+						Count:              1,
+					},
+				},
+			},
+		},
+	}
+
+	postgresOutBlob, err := proto.Marshal(postgresOut)
+	require.NoError(t, err)
+
+	out := &model.Connections{
+		Conns: []*model.Connection{
+			{
+				Laddr:                &model.Addr{Ip: "127.0.0.1", Port: int32(clientPort)},
+				Raddr:                &model.Addr{Ip: "127.0.0.1", Port: int32(postgresPort)},
+				DatabaseAggregations: postgresOutBlob,
+				RouteIdx:             -1,
+				Protocol:             formatProtocolStack(protocols.Stack{}, 0),
+				Pid:                  1,
+			},
+			{
+				Laddr:                &model.Addr{Ip: "127.0.0.1", Port: int32(postgresPort)},
+				Raddr:                &model.Addr{Ip: "127.0.0.1", Port: int32(clientPort)},
+				DatabaseAggregations: postgresOutBlob,
+				RouteIdx:             -1,
+				Protocol:             formatProtocolStack(protocols.Stack{}, 0),
+				Pid:                  2,
+			},
+		},
+		AgentConfiguration: &model.AgentConfiguration{
+			NpmEnabled: false,
+			UsmEnabled: false,
+		},
+	}
+
+	blobWriter := getBlobWriter(t, assert.New(t), in, "application/protobuf")
+
+	unmarshaler := GetUnmarshaler("application/protobuf")
+	result, err := unmarshaler.Unmarshal(blobWriter.Bytes())
+	require.NoError(t, err)
+
+	require.Equal(t, out, result)
+}
+
+func getPostgresAggregations(t *testing.T, encoder *postgresEncoder, c network.ConnectionStats) *model.DatabaseAggregations {
+	postgresBlob := encoder.GetPostgresAggregations(c)
+	require.NotNil(t, postgresBlob)
+
+	aggregations := new(model.DatabaseAggregations)
+	err := proto.Unmarshal(postgresBlob, aggregations)
+	require.NoError(t, err)
+
+	return aggregations
+}
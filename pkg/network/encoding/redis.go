@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/redis"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+)
+
+type redisEncoder struct {
+	byConnection *USMConnectionIndex[redis.Key, *redis.RequestStats]
+
+	// cached object
+	aggregations *model.DatabaseAggregations
+}
+
+func newRedisEncoder(redisPayloads map[redis.Key]*redis.RequestStats) *redisEncoder {
+	if len(redisPayloads) == 0 {
+		return nil
+	}
+
+	return &redisEncoder{
+		aggregations: &model.DatabaseAggregations{
+			Aggregations: make([]*model.DatabaseStats, 0, 10),
+		},
+		byConnection: GroupByConnection("redis", redisPayloads, func(key redis.Key) types.ConnectionKey {
+			return key.ConnectionKey
+		}),
+	}
+}
+
+func (e *redisEncoder) GetRedisAggregations(c network.ConnectionStats) []byte {
+	if e == nil {
+		return nil
+	}
+
+	connectionData := e.byConnection.Find(c)
+	if connectionData == nil || len(connectionData.Data) == 0 || connectionData.IsPIDCollision(c) {
+		return nil
+	}
+
+	return e.encodeData(connectionData)
+}
+
+func (e *redisEncoder) Close() {
+	if e == nil {
+		return
+	}
+
+	e.reset()
+	e.byConnection.Close()
+}
+
+func (e *redisEncoder) encodeData(connectionData *USMConnectionData[redis.Key, *redis.RequestStats]) []byte {
+	e.reset()
+
+	for _, kv := range connectionData.Data {
+		key := kv.Key
+		stats := kv.Value
+
+		redisStats := &model.RedisStats{
+			Command:      formatRedisCommand(key.Command),
+			KeyName:      key.KeyPrefix,
+			Truncated:    key.Truncated,
+			ErrorToStats: make(map[int32]*model.RedisStatsEntry, len(stats.ErrorToStats)),
+		}
+		for isError, stat := range stats.ErrorToStats {
+			if stat.Count == 0 {
+				continue
+			}
+
+			entry := &model.RedisStatsEntry{
+				Count: uint32(stat.Count),
+			}
+			if stat.Latencies != nil {
+				entry.Latencies, _ = proto.Marshal(stat.Latencies.ToProto())
+			} else {
+				entry.FirstLatencySample = stat.FirstLatencySample
+			}
+			redisStats.ErrorToStats[int32(formatRedisErrorType(isError))] = entry
+		}
+
+		e.aggregations.Aggregations = append(e.aggregations.Aggregations, &model.DatabaseStats{
+			DbStats: &model.DatabaseStats_Redis{
+				Redis: redisStats,
+			},
+		})
+	}
+
+	serializedData, _ := proto.Marshal(e.aggregations)
+	return serializedData
+}
+
+func (e *redisEncoder) reset() {
+	if e == nil {
+		return
+	}
+
+	for i := range e.aggregations.Aggregations {
+		e.aggregations.Aggregations[i] = nil
+	}
+	e.aggregations.Aggregations = e.aggregations.Aggregations[:0]
+}
+
+// formatRedisCommand returns the payload representation of command. The
+// commands which have none are unknown.
+func formatRedisCommand(command string) model.RedisCommand {
+	switch command {
+	case "GET":
+		return model.RedisCommand_RedisGetCommand
+	case "SET":
+		return model.RedisCommand_RedisSetCommand
+	default:
+		return model.RedisCommand_RedisUnknownCommand
+	}
+}
+
+// formatRedisErrorType returns the payload representation of the outcome of
+// a command. The error replies are not classified further.
+func formatRedisErrorType(isError bool) model.RedisErrorType {
+	if isError {
+		return model.RedisErrorType_RedisErrorTypeUnknown
+	}
+	return model.RedisErrorType_RedisNoError
+}
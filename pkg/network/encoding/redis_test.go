@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/postgres"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/redis"
+)
+
+const (
+	redisPort = uint16(6379)
+	redisKey  = "user:1"
+)
+
+type RedisSuite struct {
+	suite.Suite
+}
+
+func TestRedisStats(t *testing.T) {
+	skipIfNotLinux(t)
+	suite.Run(t, &RedisSuite{})
+}
+
+func (s *RedisSuite) TestFormatRedisStats() {
+	t := s.T()
+
+	getKey := redis.NewKey(localhost, localhost, clientPort, redisPort, "GET", redisKey, false)
+	pingKey := redis.NewKey(localhost, localhost, clientPort, redisPort, "PING", "", false)
+	truncatedKey := redis.NewKey(localhost, localhost, clientPort, redisPort, "SET", redisKey, true)
+
+	getStats := redis.NewRequestStats()
+	// the latencies fall in the same bin, for the serialization of the
+	// sketch to be deterministic
+	getStats.AddRequest(false, 1e6)
+	getStats.AddRequest(false, 1e6)
+	getStats.AddRequest(true, 3e6)
+	pingStats := redis.NewRequestStats()
+	pingStats.AddRequest(false, 4e6)
+	truncatedStats := redis.NewRequestStats()
+	truncatedStats.AddRequest(false, 5e6)
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source: localhost,
+					Dest:   localhost,
+					SPort:  clientPort,
+					DPort:  redisPort,
+				},
+			},
+		},
+		Redis: map[redis.Key]*redis.RequestStats{
+			getKey:       getStats,
+			pingKey:      pingStats,
+			truncatedKey: truncatedStats,
+		},
+	}
+
+	latencies, err := proto.Marshal(getStats.ErrorToStats[false].Latencies.ToProto())
+	require.NoError(t, err)
+	out := []*model.DatabaseStats{
+		{
+			DbStats: &model.DatabaseStats_Redis{
+				Redis: &model.RedisStats{
+					Command: model.RedisCommand_RedisGetCommand,
+					KeyName: redisKey,
+					ErrorToStats: map[int32]*model.RedisStatsEntry{
+						int32(model.RedisErrorType_RedisNoError): {
+							Latencies: latencies,
+							Count:     2,
+						},
+						int32(model.RedisErrorType_RedisErrorTypeUnknown): {
+							FirstLatencySample: 3e6,
+							Count:              1,
+						},
+					},
+				},
+			},
+		},
+		{
+			DbStats: &model.DatabaseStats_Redis{
+				Redis: &model.RedisStats{
+					Command: model.RedisCommand_RedisUnknownCommand,
+					ErrorToStats: map[int32]*model.RedisStatsEntry{
+						int32(model.RedisErrorType_RedisNoError): {
+							FirstLatencySample: 4e6,
+							Count:              1,
+						},
+					},
+				},
+			},
+		},
+		{
+			DbStats: &model.DatabaseStats_Redis{
+				Redis: &model.RedisStats{
+					Command:   model.RedisCommand_RedisSetCommand,
+					KeyName:   redisKey,
+					Truncated: true,
+					ErrorToStats: map[int32]*model.RedisStatsEntry{
+						int32(model.RedisErrorType_RedisNoError): {
+							FirstLatencySample: 5e6,
+							Count:              1,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	encoder := newRedisEncoder(in.Redis)
+	t.Cleanup(encoder.Close)
+
+	aggregations := getRedisAggregations(t, encoder, in.Conns[0])
+	assert.ElementsMatch(t, out, aggregations.Aggregations)
+}
+
+func (s *RedisSuite) TestRedisIDCollisionRegression() {
+	t := s.T()
+	assert := assert.New(t)
+	connections := []network.ConnectionStats{
+		{
+			Source: localhost,
+			SPort:  clientPort,
+			Dest:   localhost,
+			DPort:  redisPort,
+			Pid:    1,
+		},
+		{
+			Source: localhost,
+			SPort:  clientPort,
+			Dest:   localhost,
+			DPort:  redisPort,
+			Pid:    2,
+		},
+	}
+
+	redisKey := redis.NewKey(localhost, localhost, clientPort, redisPort, "GET", redisKey, false)
+	stats := redis.NewRequestStats()
+	stats.AddRequest(false, 1e6)
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: connections,
+		},
+		Redis: map[redis.Key]*redis.RequestStats{
+			redisKey: stats,
+		},
+	}
+
+	encoder := newRedisEncoder(in.Redis)
+	t.Cleanup(encoder.Close)
+	aggregations := getRedisAggregations(t, encoder, in.Conns[0])
+
+	// assert that the first connection matching the Redis data will get back a non-nil result
+	require.Len(t, aggregations.Aggregations, 1)
+	assert.Equal(model.RedisCommand_RedisGetCommand, aggregations.Aggregations[0].GetRedis().Command)
+
+	// assert that the other connections sharing the same (source,destination)
+	// addresses but different PIDs *won't* be associated with the Redis stats
+	// object
+	assert.Nil(encoder.GetRedisAggregations(in.Conns[1]))
+}
+
+func (s *RedisSuite) TestRedisAndPostgresSerialization() {
+	t := s.T()
+
+	connections := []network.ConnectionStats{
+		{
+			Source: localhost,
+			SPort:  clientPort,
+			Dest:   localhost,
+			DPort:  redisPort,
+			Pid:    1,
+		},
+	}
+
+	stats := redis.NewRequestStats()
+	stats.AddRequest(true, 1e6)
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: connections,
+		},
+		Redis: map[redis.Key]*redis.RequestStats{
+			redis.NewKey(localhost, localhost, clientPort, redisPort, "SET", redisKey, false): stats,
+		},
+		// the stats of both protocols share the database aggregations of
+		// the connection
+		Postgres: map[postgres.Key]*postgres.RequestStat{
+			postgres.NewKey(localhost, localhost, clientPort, redisPort, postgres.SelectOP, tableName): {
+				Count:              1,
+				FirstLatencySample: 2e6,
+			},
+		},
+	}
+
+	databaseOut := &model.DatabaseAggregations{
+		Aggregations: []*model.DatabaseStats{
+			{
+				DbStats: &model.DatabaseStats_Postgres{
+					Postgres: &model.PostgresStats{
+						TableName:          tableName,
+						Operation:          model.PostgresOperation_PostgresSelectOp,
+						FirstLatencySample: 2e6,
+						Count:              1,
+					},
+				},
+			},
+			{
+				DbStats: &model.DatabaseStats_Redis{
+					Redis: &model.RedisStats{
+						Command: model.RedisCommand_RedisSetCommand,
+						KeyName: redisKey,
+						ErrorToStats: map[int32]*model.RedisStatsEntry{
+							int32(model.RedisErrorType_RedisErrorTypeUnknown): {
+								FirstLatencySample: 1e6,
+								Count:              1,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	blobWriter := getBlobWriter(t, assert.New(t), in, "application/protobuf")
+
+	unmarshaler := GetUnmarshaler("application/protobuf")
+	result, err := unmarshaler.Unmarshal(blobWriter.Bytes())
+	require.NoError(t, err)
+	require.Len(t, result.Conns, 1)
+
+	databaseAggregations := new(model.DatabaseAggregations)
+	require.NoError(t, proto.Unmarshal(result.Conns[0].DatabaseAggregations, databaseAggregations))
+	assert.Equal(t, databaseOut, databaseAggregations)
+	assert.Equal(t, formatProtocolStack(protocols.Stack{}, 0), result.Conns[0].Protocol)
+}
+
+func getRedisAggregations(t *testing.T, encoder *redisEncoder, c network.ConnectionStats) *model.DatabaseAggregations {
+	redisBlob := encoder.GetRedisAggregations(c)
+	require.NotNil(t, redisBlob)
+
+	aggregations := new(model.DatabaseAggregations)
+	err := proto.Unmarshal(redisBlob, aggregations)
+	require.NoError(t, err)
+
+	return aggregations
+}
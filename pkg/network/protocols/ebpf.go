@@ -38,6 +38,10 @@ const (
 	ProgramHTTP2FrameParser ProgramType = C.PROG_HTTP2_FRAME_PARSER
 	// ProgramKafka is the Golang representation of the C.PROG_KAFKA enum
 	ProgramKafka ProgramType = C.PROG_KAFKA
+	// ProgramPostgres is the Golang representation of the C.PROG_POSTGRES enum
+	ProgramPostgres ProgramType = C.PROG_POSTGRES
+	// ProgramRedis is the Golang representation of the C.PROG_REDIS enum
+	ProgramRedis ProgramType = C.PROG_REDIS
 )
 
 func Application(protoNum uint8) ProtocolType {
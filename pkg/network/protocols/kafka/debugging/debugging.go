@@ -17,6 +17,11 @@ type RequestSummary struct {
 	Server       Address
 	ByRequestAPI map[string]int
 	TopicName    string
+	// ErrorCodes counts the requests by the broker error code of their response
+	ErrorCodes map[int32]int `json:",omitempty"`
+	// LatencyP50 is the median latency of the successful requests, in
+	// nanoseconds
+	LatencyP50 float64 `json:",omitempty"`
 }
 
 // Address represents represents a IP:Port
@@ -59,6 +64,18 @@ func Kafka(stats map[kafka.Key]*kafka.RequestStat) []RequestSummary {
 			ByRequestAPI: byRequestAPI,
 			TopicName:    key.TopicName,
 		}
+		for errorCode, stat := range requestStat.ErrorCodeToStat {
+			if errorCode == 0 {
+				if stat.Latencies != nil {
+					debug.LatencyP50, _ = stat.Latencies.GetValueAtQuantile(0.5)
+				}
+				continue
+			}
+			if debug.ErrorCodes == nil {
+				debug.ErrorCodes = make(map[int32]int)
+			}
+			debug.ErrorCodes[errorCode] = stat.Count
+		}
 
 		all = append(all, debug)
 	}
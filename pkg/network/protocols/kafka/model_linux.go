@@ -27,3 +27,17 @@ func (tx *EbpfTx) APIKey() uint16 {
 func (tx *EbpfTx) APIVersion() uint16 {
 	return tx.Request_api_version
 }
+
+// RequestLatency returns the latency of the request in nanoseconds, or 0 if
+// the request got no response, e.g. a produce request without acknowledgments.
+func (tx *EbpfTx) RequestLatency() float64 {
+	if tx.Response_last_seen == 0 || tx.Response_last_seen < tx.Request_started {
+		return 0
+	}
+	return float64(tx.Response_last_seen - tx.Request_started)
+}
+
+// ErrorCode returns the error code of the response, 0 meaning no error.
+func (tx *EbpfTx) ErrorCode() int16 {
+	return tx.Error_code
+}
@@ -35,6 +35,7 @@ const (
 	protocolDispatcherClassificationPrograms = "dispatcher_classification_progs"
 	kafkaLastTCPSeqPerConnectionMap          = "kafka_last_tcp_seq_per_connection"
 	kafkaHeapMap                             = "kafka_heap"
+	inFlightMap                              = "kafka_in_flight"
 )
 
 var Spec = &protocols.ProtocolSpec{
@@ -49,6 +50,9 @@ var Spec = &protocols.ProtocolSpec{
 		{
 			Name: kafkaHeapMap,
 		},
+		{
+			Name: inFlightMap,
+		},
 	},
 	TailCalls: []manager.TailCallRoute{
 		{
@@ -86,6 +90,7 @@ func (p *protocol) Name() string {
 // ConfigureOptions add the necessary options for the kafka monitoring to work,
 // to be used by the manager. These are:
 // - Set the `kafka_last_tcp_seq_per_connection` map size to the value of the `max_tracked_connection` configuration variable.
+// - Set the `kafka_in_flight` map size to the value of the `max_tracked_connection` configuration variable.
 //
 // We also configure the kafka event stream with the manager and its options.
 func (p *protocol) ConfigureOptions(mgr *manager.Manager, opts *manager.Options) {
@@ -94,6 +99,10 @@ func (p *protocol) ConfigureOptions(mgr *manager.Manager, opts *manager.Options)
 		MaxEntries: p.cfg.MaxTrackedConnections,
 		EditorFlag: manager.EditMaxEntries,
 	}
+	opts.MapSpecEditors[inFlightMap] = manager.MapSpecEditor{
+		MaxEntries: p.cfg.MaxTrackedConnections,
+		EditorFlag: manager.EditMaxEntries,
+	}
 	utils.EnableOption(opts, "kafka_monitoring_enabled")
 }
 
@@ -53,7 +53,7 @@ func (statKeeper *StatKeeper) Process(tx *EbpfTx) {
 		requestStats = new(RequestStat)
 		statKeeper.stats[key] = requestStats
 	}
-	requestStats.Count++
+	requestStats.AddRequest(tx.RequestLatency(), tx.ErrorCode())
 }
 
 func (statKeeper *StatKeeper) GetAndResetAllStats() map[Key]*RequestStat {
@@ -71,16 +71,19 @@ func (statKeeper *StatKeeper) extractTopicName(tx *EbpfTx) string {
 		log.Debugf("Topic name size was changed from %d, to size: %d", tx.Topic_name_size, len(tx.Topic_name))
 		tx.Topic_name_size = uint16(len(tx.Topic_name))
 	}
-	b := tx.Topic_name[:tx.Topic_name_size]
+	return intern(statKeeper.topicNames, tx.Topic_name[:tx.Topic_name_size])
+}
 
+// intern returns the string of b stored in strings, storing it if needed.
+func intern(strings map[string]string, b []byte) string {
 	// the trick here is that the Go runtime doesn't allocate the string used in
-	// the map lookup, so if we have seen this topic name before, we don't
-	// perform any allocations
-	if v, ok := statKeeper.topicNames[string(b)]; ok {
+	// the map lookup, so if we have seen this string before, we don't perform
+	// any allocations
+	if v, ok := strings[string(b)]; ok {
 		return v
 	}
 
 	v := string(b)
-	statKeeper.topicNames[v] = v
+	strings[v] = v
 	return v
 }
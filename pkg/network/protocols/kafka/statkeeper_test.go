@@ -11,6 +11,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/DataDog/datadog-agent/pkg/network/config"
 )
 
@@ -68,3 +71,40 @@ func TestStatKeeper_extractTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestStatKeeperProcess(t *testing.T) {
+	newTx := func(latency uint64, errorCode int16) *EbpfTx {
+		tx := &EbpfTx{
+			Request_api_key:     FetchAPIKey,
+			Request_api_version: 7,
+			Request_started:     1000,
+			Error_code:          errorCode,
+			Topic_name_size:     uint16(len("topic")),
+		}
+		if latency > 0 {
+			tx.Response_last_seen = tx.Request_started + latency
+		}
+		copy(tx.Topic_name[:], "topic")
+		return tx
+	}
+	txs := []*EbpfTx{
+		newTx(100, 0),
+		newTx(200, 1),
+		newTx(0, 0),
+	}
+
+	sk := NewStatkeeper(&config.Config{MaxKafkaStatsBuffered: 100}, NewTelemetry())
+	for _, tx := range txs {
+		sk.Process(tx)
+	}
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats, 1)
+	stat := stats[Key{RequestAPIKey: FetchAPIKey, RequestVersion: 7, TopicName: "topic"}]
+	require.NotNil(t, stat)
+	assert.Equal(t, 3, stat.Count)
+	require.Len(t, stat.ErrorCodeToStat, 2)
+	assert.Equal(t, 2, stat.ErrorCodeToStat[0].Count)
+	require.NotNil(t, stat.ErrorCodeToStat[0].Latencies)
+	assert.EqualValues(t, 1, stat.ErrorCodeToStat[0].Latencies.GetCount())
+	assert.Equal(t, 1, stat.ErrorCodeToStat[1].Count)
+}
@@ -6,8 +6,11 @@
 package kafka
 
 import (
+	"github.com/DataDog/sketches-go/ddsketch"
+
 	"github.com/DataDog/datadog-agent/pkg/network/types"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 const (
@@ -15,6 +18,9 @@ const (
 	FetchAPIKey   = 1
 )
 
+// RelativeAccuracy defines the acceptable error in quantile values calculated by DDSketch.
+const RelativeAccuracy = 0.01
+
 // Key is an identifier for a group of Kafka transactions
 type Key struct {
 	RequestAPIKey  uint16
@@ -35,11 +41,68 @@ func NewKey(saddr, daddr util.Address, sport, dport uint16, topicName string, re
 
 // RequestStat stores stats for Kafka requests to a particular key
 type RequestStat struct {
-	Count int
+	// ErrorCodeToStat holds the stats of the requests by the broker error
+	// code of their response, 0 for successful requests and requests without
+	// response.
+	ErrorCodeToStat map[int32]*ErrorCodeStat
+	Count           int
+}
+
+// ErrorCodeStat stores stats for Kafka requests with the same error code
+type ErrorCodeStat struct {
+	// Latencies holds the latencies of the requests which got a response.
+	Latencies *ddsketch.DDSketch
+	Count     int
+}
+
+// AddRequest records a request with the given latency in nanoseconds, 0
+// meaning that the request got no response, and the error code of its
+// response.
+func (r *RequestStat) AddRequest(latency float64, errorCode int16) {
+	r.Count++
+	stat := r.errorCodeStat(int32(errorCode))
+	stat.Count++
+	if latency <= 0 {
+		return
+	}
+	if stat.Latencies == nil {
+		var err error
+		if stat.Latencies, err = ddsketch.NewDefaultDDSketch(RelativeAccuracy); err != nil {
+			log.Debugf("error recording kafka request latency: could not create new ddsketch: %v", err)
+			return
+		}
+	}
+	if err := stat.Latencies.Add(latency); err != nil {
+		log.Debugf("could not add kafka request latency to ddsketch: %v", err)
+	}
 }
 
 // CombineWith merges the data in 2 RequestStats objects
 // newStats is kept as it is, while the method receiver gets mutated
 func (r *RequestStat) CombineWith(newStats *RequestStat) {
 	r.Count += newStats.Count
+	for errorCode, newStat := range newStats.ErrorCodeToStat {
+		stat := r.errorCodeStat(errorCode)
+		stat.Count += newStat.Count
+		if newStat.Latencies == nil {
+			continue
+		}
+		if stat.Latencies == nil {
+			stat.Latencies = newStat.Latencies.Copy()
+		} else if err := stat.Latencies.MergeWith(newStat.Latencies); err != nil {
+			log.Debugf("error merging kafka requests: %v", err)
+		}
+	}
+}
+
+func (r *RequestStat) errorCodeStat(errorCode int32) *ErrorCodeStat {
+	if r.ErrorCodeToStat == nil {
+		r.ErrorCodeToStat = make(map[int32]*ErrorCodeStat)
+	}
+	stat, ok := r.ErrorCodeToStat[errorCode]
+	if !ok {
+		stat = new(ErrorCodeStat)
+		r.ErrorCodeToStat[errorCode] = stat
+	}
+	return stat
 }
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRequest(t *testing.T) {
+	stats := new(RequestStat)
+	stats.AddRequest(10, 0)
+	stats.AddRequest(20, 6)
+	stats.AddRequest(0, 6)
+	stats.AddRequest(0, 0)
+
+	assert.Equal(t, 4, stats.Count)
+	require.Len(t, stats.ErrorCodeToStat, 2)
+	for _, errorCode := range []int32{0, 6} {
+		stat := stats.ErrorCodeToStat[errorCode]
+		require.NotNil(t, stat)
+		assert.Equal(t, 2, stat.Count)
+		require.NotNil(t, stat.Latencies)
+		assert.EqualValues(t, 1, stat.Latencies.GetCount())
+	}
+}
+
+func TestCombineWith(t *testing.T) {
+	stats := new(RequestStat)
+	stats.CombineWith(&RequestStat{Count: 2})
+	assert.Equal(t, 2, stats.Count)
+	assert.Empty(t, stats.ErrorCodeToStat)
+
+	other := new(RequestStat)
+	other.AddRequest(10, 3)
+	other.AddRequest(20, 0)
+	other.AddRequest(0, 0)
+	stats.CombineWith(other)
+	assert.Equal(t, 5, stats.Count)
+	require.Len(t, stats.ErrorCodeToStat, 2)
+	assert.Equal(t, 2, stats.ErrorCodeToStat[0].Count)
+	assert.EqualValues(t, 1, stats.ErrorCodeToStat[0].Latencies.GetCount())
+	assert.Equal(t, 1, stats.ErrorCodeToStat[3].Count)
+
+	stats.CombineWith(other)
+	assert.Equal(t, 8, stats.Count)
+	assert.Equal(t, 4, stats.ErrorCodeToStat[0].Count)
+	assert.EqualValues(t, 2, stats.ErrorCodeToStat[0].Latencies.GetCount())
+	assert.Equal(t, 2, stats.ErrorCodeToStat[3].Count)
+	assert.EqualValues(t, 2, stats.ErrorCodeToStat[3].Latencies.GetCount())
+
+	// other is unchanged
+	assert.Equal(t, 3, other.Count)
+	assert.Equal(t, 2, other.ErrorCodeToStat[0].Count)
+	assert.EqualValues(t, 1, other.ErrorCodeToStat[0].Latencies.GetCount())
+	assert.Equal(t, 1, other.ErrorCodeToStat[3].Count)
+}
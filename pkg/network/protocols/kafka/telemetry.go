@@ -16,6 +16,7 @@ type Telemetry struct {
 	metricGroup *libtelemetry.MetricGroup
 
 	totalHits *libtelemetry.Counter
+	errors    *libtelemetry.Counter // requests with an error code in their response
 	dropped   *libtelemetry.Counter // this happens when KafkaStatKeeper reaches capacity
 }
 
@@ -26,12 +27,16 @@ func NewTelemetry() *Telemetry {
 		metricGroup: metricGroup,
 		// these metrics are also exported as statsd metrics
 		totalHits: metricGroup.NewCounter("total_hits"),
+		errors:    metricGroup.NewCounter("errors"),
 		dropped:   metricGroup.NewCounter("dropped"),
 	}
 }
 
-func (t *Telemetry) Count(_ *EbpfTx) {
+func (t *Telemetry) Count(tx *EbpfTx) {
 	t.totalHits.Add(1)
+	if tx.ErrorCode() != 0 {
+		t.errors.Add(1)
+	}
 }
 
 func (t *Telemetry) Log() {
@@ -17,9 +17,11 @@ type ConnTuple struct {
 
 type EbpfTx struct {
 	Tup                 ConnTuple
+	Request_started     uint64
+	Response_last_seen  uint64
 	Request_api_key     uint16
 	Request_api_version uint16
-	Topic_name          [80]byte
+	Error_code          int16
 	Topic_name_size     uint16
-	Pad_cgo_0           [2]byte
+	Topic_name          [80]byte
 }
@@ -0,0 +1,505 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Message types of the Postgres wire protocol (v3).
+// See https://www.postgresql.org/docs/current/protocol-message-formats.html
+const (
+	// frontend messages
+	queryMessage     = 'Q'
+	parseMessage     = 'P'
+	bindMessage      = 'B'
+	executeMessage   = 'E'
+	syncMessage      = 'S'
+	closeMessage     = 'C'
+	terminateMessage = 'X'
+
+	// backend messages
+	commandCompleteMessage    = 'C'
+	emptyQueryResponseMessage = 'I'
+	errorResponseMessage      = 'E'
+	portalSuspendedMessage    = 's'
+	readyForQueryMessage      = 'Z'
+)
+
+// Codes of the messages sent by clients before the startup is complete, which
+// have no message type.
+const (
+	protocolVersion3   = 196608 // 3.0
+	cancelRequestCode  = 80877102
+	sslRequestCode     = 80877103
+	gssEncRequestCode  = 80877104
+	startupHeaderSize  = 8
+	messageHeaderSize  = 5
+	maxMessageSize     = 1 << 30
+	maxPayloadSize     = 4096 // payload bytes kept per message, enough to parse queries
+	maxPendingRequests = 128
+)
+
+// ErrInvalidMessage is returned when decoding bytes which are not a valid
+// Postgres message.
+var ErrInvalidMessage = errors.New("invalid postgres message")
+
+// frontendMessageTypes are the types of messages a client may send.
+var frontendMessageTypes = [256]bool{
+	'B': true, 'C': true, 'd': true, 'c': true, 'f': true, 'D': true, 'E': true,
+	'H': true, 'F': true, 'P': true, 'p': true, 'Q': true, 'S': true, 'X': true,
+}
+
+// backendMessageTypes are the types of messages a server may send.
+var backendMessageTypes = [256]bool{
+	'1': true, '2': true, '3': true, 'A': true, 'c': true, 'C': true, 'd': true,
+	'D': true, 'E': true, 'G': true, 'H': true, 'I': true, 'K': true, 'n': true,
+	'N': true, 'R': true, 's': true, 'S': true, 't': true, 'T': true, 'v': true,
+	'V': true, 'W': true, 'Z': true,
+}
+
+// IsPostgres reports whether buf, the first bytes sent by a client on a
+// connection, holds a Postgres startup message or a well-formed query.
+func IsPostgres(buf []byte) bool {
+	if len(buf) >= startupHeaderSize {
+		size := binary.BigEndian.Uint32(buf)
+		switch binary.BigEndian.Uint32(buf[4:]) {
+		case protocolVersion3:
+			// the startup message is followed by "user" and its value
+			return size > startupHeaderSize && size < maxPayloadSize && bytes.HasPrefix(buf[startupHeaderSize:], []byte("user\x00"))
+		case sslRequestCode, gssEncRequestCode, cancelRequestCode:
+			return size == startupHeaderSize || size == startupHeaderSize+8
+		}
+	}
+	if len(buf) < messageHeaderSize || (buf[0] != queryMessage && buf[0] != parseMessage) {
+		return false
+	}
+	size := binary.BigEndian.Uint32(buf[1:])
+	if size <= 4 || size > maxMessageSize {
+		return false
+	}
+	payload := buf[messageHeaderSize:]
+	if int(size-4) <= len(payload) {
+		payload = payload[:size-4]
+		// queries are NUL-terminated
+		if payload[len(payload)-1] != 0 {
+			return false
+		}
+	}
+	if buf[0] == parseMessage {
+		// skip the statement name
+		i := bytes.IndexByte(payload, 0)
+		if i < 0 {
+			return false
+		}
+		payload = payload[i+1:]
+	}
+	op, _ := parseQuery(string(bytes.TrimRight(payload, "\x00")))
+	return op != UnknownOP
+}
+
+// Transaction is a request and its response on a Postgres connection.
+type Transaction struct {
+	Operation Operation
+	TableName string
+	// Latency is the time between the request and its response.
+	Latency time.Duration
+	// Error reports whether the server responded with an error.
+	Error bool
+}
+
+type pendingKind uint8
+
+const (
+	// simpleQueryRequest is a query message, completed by ReadyForQuery.
+	simpleQueryRequest pendingKind = iota
+	// executeRequest is an execute message of the extended protocol, completed by
+	// CommandComplete, EmptyQueryResponse, PortalSuspended or ErrorResponse.
+	executeRequest
+	// syncRequest is a sync message, after which the server sends ReadyForQuery.
+	syncRequest
+)
+
+type pendingRequest struct {
+	kind  pendingKind
+	query string
+	start time.Time
+	err   bool
+}
+
+// Decoder decodes the Postgres messages of a connection and pairs requests
+// with their responses. It is not safe for concurrent use.
+type Decoder struct {
+	client messageReader
+	server messageReader
+
+	// sslRequested is set after an SSLRequest or GSSENCRequest, while the
+	// server has not responded with a single byte.
+	sslRequested bool
+	// encrypted is set once the connection switches to TLS or GSSAPI, after
+	// which its messages can't be decoded.
+	encrypted bool
+
+	pending    []pendingRequest
+	statements map[string]string // prepared statement queries by name
+	portals    map[string]string // portal queries by name
+	txs        []Transaction
+}
+
+// NewDecoder returns a decoder for a new connection. Its first client message
+// may be a startup message.
+func NewDecoder() *Decoder {
+	return &Decoder{
+		client:     messageReader{untyped: true, types: &frontendMessageTypes},
+		server:     messageReader{types: &backendMessageTypes},
+		statements: make(map[string]string),
+		portals:    make(map[string]string),
+	}
+}
+
+// Encrypted reports whether the connection switched to an encrypted protocol.
+func (d *Decoder) Encrypted() bool {
+	return d.encrypted
+}
+
+// ClientData decodes the bytes sent by the client at the given time. The
+// decoder can't be used anymore once it returned an error.
+func (d *Decoder) ClientData(b []byte, ts time.Time) error {
+	if d.encrypted {
+		return nil
+	}
+	if d.client.untyped && d.client.atMessageStart() && len(b) > 0 && b[0] != 0 {
+		// the length of startup messages starts with a zero byte, so the
+		// decoder was created after the startup of the connection
+		d.client.untyped = false
+	}
+	return d.client.feed(b, func(typ byte, payload []byte) error {
+		return d.handleFrontend(typ, payload, ts)
+	})
+}
+
+// ServerData decodes the bytes sent by the server at the given time, and
+// returns the transactions completed by these bytes. The returned slice is
+// only valid until the next call. The decoder can't be used anymore once it
+// returned an error.
+func (d *Decoder) ServerData(b []byte, ts time.Time) ([]Transaction, error) {
+	d.txs = d.txs[:0]
+	if d.encrypted {
+		return nil, nil
+	}
+	if d.sslRequested && len(b) > 0 {
+		// the server accepts encryption with 'S' (SSL) or 'G' (GSSAPI)
+		d.sslRequested = false
+		if b[0] == 'S' || b[0] == 'G' {
+			d.encrypted = true
+			return nil, nil
+		}
+		b = b[1:]
+	}
+	err := d.server.feed(b, func(typ byte, payload []byte) error {
+		d.handleBackend(typ, ts)
+		return nil
+	})
+	return d.txs, err
+}
+
+// SkipClientData skips n bytes sent by the client which were not captured.
+func (d *Decoder) SkipClientData(n int, ts time.Time) error {
+	if d.encrypted {
+		return nil
+	}
+	err := d.client.skip(n, func(typ byte, payload []byte) error {
+		return d.handleFrontend(typ, payload, ts)
+	})
+	if d.client.lost {
+		// the responses to the requests that were missed can't be paired
+		d.pending = d.pending[:0]
+	}
+	return err
+}
+
+// SkipServerData skips n bytes sent by the server which were not captured,
+// and returns the transactions completed by a message ending in these bytes.
+// The returned slice is only valid until the next call.
+func (d *Decoder) SkipServerData(n int, ts time.Time) ([]Transaction, error) {
+	d.txs = d.txs[:0]
+	if d.encrypted {
+		return nil, nil
+	}
+	err := d.server.skip(n, func(typ byte, payload []byte) error {
+		d.handleBackend(typ, ts)
+		return nil
+	})
+	if d.server.lost {
+		d.pending = d.pending[:0]
+	}
+	return d.txs, err
+}
+
+func (d *Decoder) handleFrontend(typ byte, payload []byte, ts time.Time) error {
+	if d.client.untyped {
+		return d.handleStartup(payload)
+	}
+	switch typ {
+	case queryMessage:
+		d.push(pendingRequest{kind: simpleQueryRequest, query: cString(payload), start: ts})
+	case parseMessage:
+		name, rest := splitCString(payload)
+		d.statements[name] = cString(rest)
+	case bindMessage:
+		portal, rest := splitCString(payload)
+		statement, _ := splitCString(rest)
+		d.portals[portal] = d.statements[statement]
+	case executeMessage:
+		portal, _ := splitCString(payload)
+		d.push(pendingRequest{kind: executeRequest, query: d.portals[portal], start: ts})
+	case syncMessage:
+		d.push(pendingRequest{kind: syncRequest})
+	case closeMessage:
+		if len(payload) > 0 {
+			name, _ := splitCString(payload[1:])
+			if payload[0] == 'S' {
+				delete(d.statements, name)
+			} else {
+				delete(d.portals, name)
+			}
+		}
+	case terminateMessage:
+		d.pending = d.pending[:0]
+	default:
+		if !frontendMessageTypes[typ] {
+			return fmt.Errorf("%w: unknown frontend message type %q", ErrInvalidMessage, typ)
+		}
+	}
+	return nil
+}
+
+// handleStartup handles the messages sent by the client before the startup
+// is complete. payload includes the protocol version or request code.
+func (d *Decoder) handleStartup(payload []byte) error {
+	if len(payload) < 4 {
+		return fmt.Errorf("%w: startup message too short", ErrInvalidMessage)
+	}
+	switch binary.BigEndian.Uint32(payload) {
+	case protocolVersion3:
+		d.client.untyped = false
+	case sslRequestCode, gssEncRequestCode:
+		d.sslRequested = true
+	case cancelRequestCode:
+	default:
+		return fmt.Errorf("%w: unsupported protocol version %d", ErrInvalidMessage, binary.BigEndian.Uint32(payload))
+	}
+	return nil
+}
+
+func (d *Decoder) handleBackend(typ byte, ts time.Time) {
+	if len(d.pending) == 0 {
+		// e.g. authentication messages
+		return
+	}
+	head := &d.pending[0]
+	switch typ {
+	case commandCompleteMessage, emptyQueryResponseMessage, portalSuspendedMessage:
+		if head.kind == executeRequest {
+			d.complete(*head, ts)
+			d.pop()
+		}
+	case errorResponseMessage:
+		switch head.kind {
+		case executeRequest:
+			head.err = true
+			d.complete(*head, ts)
+			d.pop()
+		case simpleQueryRequest:
+			head.err = true
+		}
+	case readyForQueryMessage:
+		for len(d.pending) > 0 {
+			p := d.pending[0]
+			d.pop()
+			switch p.kind {
+			case simpleQueryRequest:
+				d.complete(p, ts)
+				return
+			case syncRequest:
+				return
+			}
+			// the execute messages following an error get no response
+		}
+	}
+}
+
+func (d *Decoder) push(p pendingRequest) {
+	if len(d.pending) >= maxPendingRequests {
+		// the responses are missing, e.g. because some of the bytes of the
+		// connection were not captured
+		d.pending = d.pending[:0]
+	}
+	d.pending = append(d.pending, p)
+}
+
+func (d *Decoder) pop() {
+	d.pending[0] = pendingRequest{}
+	d.pending = d.pending[1:]
+}
+
+func (d *Decoder) complete(p pendingRequest, ts time.Time) {
+	op, table := parseQuery(p.query)
+	d.txs = append(d.txs, Transaction{
+		Operation: op,
+		TableName: table,
+		Latency:   ts.Sub(p.start),
+		Error:     p.err,
+	})
+}
+
+// messageReader splits a stream of bytes in Postgres messages.
+type messageReader struct {
+	// untyped is set while messages have no type byte, i.e. before the
+	// startup message of the client.
+	untyped bool
+	// types are the valid message types, used to find the start of a message
+	// after bytes were missed
+	types *[256]bool
+	// lost is set when bytes of the stream were missed, until the start of a
+	// message is found
+	lost bool
+
+	header    [messageHeaderSize]byte
+	headerLen int
+	typ       byte
+	remaining int    // payload bytes of the current message not read yet
+	payload   []byte // payload of the current message, up to maxPayloadSize
+	inMessage bool
+}
+
+// atMessageStart reports whether the next byte read starts a message.
+func (r *messageReader) atMessageStart() bool {
+	return !r.inMessage && r.headerLen == 0
+}
+
+// skip skips n bytes of the stream which were not captured, calling handle if
+// they end the current message.
+func (r *messageReader) skip(n int, handle func(typ byte, payload []byte) error) error {
+	if n <= 0 || r.lost {
+		return nil
+	}
+	if r.inMessage && n <= r.remaining {
+		// the payload of the message is truncated, which is fine as only the
+		// start of the messages is parsed
+		r.remaining -= n
+		if r.remaining > 0 {
+			return nil
+		}
+		r.inMessage = false
+		return handle(r.typ, r.payload)
+	}
+	// the boundaries of the messages which follow the current one are lost
+	r.lost = true
+	r.headerLen = 0
+	if r.inMessage {
+		r.inMessage = false
+		return handle(r.typ, r.payload)
+	}
+	return nil
+}
+
+// resync reports whether b, read after bytes were missed, starts with a
+// message, i.e. whether its messages have valid types and sizes.
+func (r *messageReader) resync(b []byte) bool {
+	if len(b) < messageHeaderSize {
+		return false
+	}
+	for len(b) >= messageHeaderSize {
+		size := binary.BigEndian.Uint32(b[1:])
+		if !r.types[b[0]] || size < 4 || size > maxMessageSize {
+			return false
+		}
+		if uint32(len(b)-1) <= size {
+			// the last message continues in the next bytes
+			return true
+		}
+		b = b[1+size:]
+	}
+	return true
+}
+
+// feed reads the messages in b, calling handle for each complete message.
+func (r *messageReader) feed(b []byte, handle func(typ byte, payload []byte) error) error {
+	if r.lost {
+		if !r.resync(b) {
+			return nil
+		}
+		r.lost = false
+		r.untyped = false
+	}
+	for len(b) > 0 {
+		if !r.inMessage {
+			headerSize := messageHeaderSize
+			if r.untyped {
+				headerSize = 4
+			}
+			n := copy(r.header[r.headerLen:headerSize], b)
+			r.headerLen += n
+			b = b[n:]
+			if r.headerLen < headerSize {
+				return nil
+			}
+			if r.untyped {
+				r.typ = 0
+			} else {
+				r.typ = r.header[0]
+			}
+			size := int(binary.BigEndian.Uint32(r.header[headerSize-4:]))
+			r.headerLen = 0
+			if size < 4 || size > maxMessageSize || r.untyped && size < startupHeaderSize {
+				return fmt.Errorf("%w: invalid message size %d", ErrInvalidMessage, size)
+			}
+			r.remaining = size - 4
+			r.payload = r.payload[:0]
+			r.inMessage = true
+		}
+		n := r.remaining
+		if n > len(b) {
+			n = len(b)
+		}
+		if keep := maxPayloadSize - len(r.payload); keep > 0 {
+			if keep > n {
+				keep = n
+			}
+			r.payload = append(r.payload, b[:keep]...)
+		}
+		r.remaining -= n
+		b = b[n:]
+		if r.remaining == 0 {
+			r.inMessage = false
+			if err := handle(r.typ, r.payload); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cString returns the NUL-terminated string at the start of b.
+func cString(b []byte) string {
+	s, _ := splitCString(b)
+	return s
+}
+
+// splitCString returns the NUL-terminated string at the start of b, and the
+// bytes following it.
+func splitCString(b []byte) (string, []byte) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return string(b), nil
+	}
+	return string(b[:i]), b[i+1:]
+}
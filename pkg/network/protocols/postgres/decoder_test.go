@@ -0,0 +1,272 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// message returns a Postgres message of the given type. A zero type returns
+// an untyped startup message.
+func message(typ byte, fields ...string) []byte {
+	var payload []byte
+	for _, f := range fields {
+		payload = append(payload, f...)
+	}
+	var b []byte
+	if typ != 0 {
+		b = append(b, typ)
+	}
+	b = binary.BigEndian.AppendUint32(b, uint32(len(payload)+4))
+	return append(b, payload...)
+}
+
+func startupMessage() []byte {
+	return message(0, "\x00\x03\x00\x00", "user\x00postgres\x00database\x00test\x00\x00")
+}
+
+func sslRequest() []byte {
+	return message(0, "\x04\xd2\x16\x2f")
+}
+
+func concat(msgs ...[]byte) []byte {
+	var b []byte
+	for _, m := range msgs {
+		b = append(b, m...)
+	}
+	return b
+}
+
+// startupResponse is the response of the server to a startup message.
+var startupResponse = concat(
+	message('R', "\x00\x00\x00\x00"),
+	message('S', "server_version\x0015.4\x00"),
+	message('K', "\x00\x00\x00\x01\x00\x00\x00\x02"),
+	message('Z', "I"),
+)
+
+type exchange struct {
+	client, server []byte
+	want           []Transaction
+}
+
+func TestDecoder(t *testing.T) {
+	ms := time.Millisecond
+	for name, exchanges := range map[string][]exchange{
+		"simple_query": {
+			{client: startupMessage(), server: startupResponse},
+			{
+				client: message('Q', "SELECT * FROM users WHERE id = 1\x00"),
+				server: concat(
+					message('T', "\x00\x01id\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x17\x00\x04\xff\xff\xff\xff\x00\x00"),
+					message('D', "\x00\x01\x00\x00\x00\x011"),
+					message('C', "SELECT 1\x00"),
+					message('Z', "I"),
+				),
+				want: []Transaction{{Operation: SelectOP, TableName: "users", Latency: ms}},
+			},
+			{
+				client: message('Q', "INSERT INTO missing VALUES (1)\x00"),
+				server: concat(
+					message('E', "SERROR\x00C42P01\x00Mrelation \"missing\" does not exist\x00\x00"),
+					message('Z', "I"),
+				),
+				want: []Transaction{{Operation: InsertOP, TableName: "missing", Latency: ms, Error: true}},
+			},
+		},
+		"extended_query": {
+			{client: startupMessage(), server: startupResponse},
+			{
+				client: concat(
+					message('P', "stmt1\x00", "UPDATE accounts SET balance = $1 WHERE id = $2\x00", "\x00\x00"),
+					message('B', "\x00", "stmt1\x00", "\x00\x00\x00\x02\x00\x00\x00\x0100\x00\x00\x00\x011\x00\x00"),
+					message('D', "P\x00"),
+					message('E', "\x00", "\x00\x00\x00\x00"),
+					message('S'),
+				),
+				server: concat(
+					message('1'),
+					message('2'),
+					message('n'),
+					message('C', "UPDATE 1\x00"),
+					message('Z', "T"),
+				),
+				want: []Transaction{{Operation: UpdateOP, TableName: "accounts", Latency: ms}},
+			},
+			{
+				// the second execute gets no response after the error
+				client: concat(
+					message('B', "p1\x00", "stmt1\x00", "\x00\x00\x00\x00\x00\x00"),
+					message('E', "p1\x00", "\x00\x00\x00\x00"),
+					message('P', "\x00", "DELETE FROM sessions\x00", "\x00\x00"),
+					message('B', "\x00", "\x00", "\x00\x00\x00\x00\x00\x00"),
+					message('E', "\x00", "\x00\x00\x00\x00"),
+					message('S'),
+				),
+				server: concat(
+					message('2'),
+					message('E', "SERROR\x00C23505\x00\x00"),
+					message('Z', "E"),
+				),
+				want: []Transaction{{Operation: UpdateOP, TableName: "accounts", Latency: ms, Error: true}},
+			},
+			{
+				client: concat(
+					message('B', "\x00", "\x00", "\x00\x00\x00\x00\x00\x00"),
+					message('E', "\x00", "\x00\x00\x00\x00"),
+					message('S'),
+				),
+				server: concat(message('2'), message('C', "DELETE 3\x00"), message('Z', "I")),
+				want:   []Transaction{{Operation: DeleteOP, TableName: "sessions", Latency: ms}},
+			},
+		},
+		"ssl_refused": {
+			{client: sslRequest(), server: []byte("N")},
+			{client: startupMessage(), server: startupResponse},
+			{
+				client: message('Q', "TRUNCATE TABLE logs\x00"),
+				server: concat(message('C', "TRUNCATE TABLE\x00"), message('Z', "I")),
+				want:   []Transaction{{Operation: TruncateTableOP, TableName: "logs", Latency: ms}},
+			},
+		},
+		"ssl_accepted": {
+			{client: sslRequest(), server: []byte("S")},
+			{client: []byte("\x16\x03\x01\x02\x00\x01\x00\x01\xfc\x03\x03"), server: []byte("\x16\x03\x03\x00\x7a\x02\x00\x00\x76")},
+		},
+		"after_startup": {
+			{
+				client: message('Q', "CREATE TABLE IF NOT EXISTS items (id int)\x00"),
+				server: concat(message('C', "CREATE TABLE\x00"), message('Z', "I")),
+				want:   []Transaction{{Operation: CreateTableOP, TableName: "items", Latency: ms}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, fragmented := range []bool{false, true} {
+				d := NewDecoder()
+				now := time.Now()
+				for _, e := range exchanges {
+					var got []Transaction
+					if fragmented {
+						// the decoder receives a byte at a time
+						for i := range e.client {
+							require.NoError(t, d.ClientData(e.client[i:i+1], now))
+						}
+						for i := range e.server {
+							txs, err := d.ServerData(e.server[i:i+1], now.Add(ms))
+							require.NoError(t, err)
+							got = append(got, txs...)
+						}
+					} else {
+						require.NoError(t, d.ClientData(e.client, now))
+						txs, err := d.ServerData(e.server, now.Add(ms))
+						require.NoError(t, err)
+						got = append(got, txs...)
+					}
+					assert.Equal(t, e.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderInvalid(t *testing.T) {
+	d := NewDecoder()
+	assert.ErrorIs(t, d.ClientData(message(0, "\x00\x02\x00\x00"), time.Now()), ErrInvalidMessage)
+
+	d = NewDecoder()
+	assert.ErrorIs(t, d.ClientData([]byte("GET / HTTP/1.1\r\n"), time.Now()), ErrInvalidMessage)
+
+	d = NewDecoder()
+	require.NoError(t, d.ClientData(startupMessage(), time.Now()))
+	_, err := d.ServerData([]byte{'Z', 0, 0, 0, 1}, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestDecoderLargeMessage(t *testing.T) {
+	d := NewDecoder()
+	now := time.Now()
+	query := "SELECT * FROM large WHERE id IN (" + string(make([]byte, 2*maxPayloadSize)) + ")\x00"
+	require.NoError(t, d.ClientData(message('Q', query), now))
+	txs, err := d.ServerData(concat(
+		message('D', string(make([]byte, 3*maxPayloadSize))),
+		message('C', "SELECT 1\x00"),
+		message('Z', "I"),
+	), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{{Operation: SelectOP, TableName: "large", Latency: time.Second}}, txs)
+}
+
+func TestDecoderSkip(t *testing.T) {
+	now := time.Now()
+	response := concat(message('C', "SELECT 1\x00"), message('Z', "I"))
+
+	// the middle of a long query is missing
+	d := NewDecoder()
+	query := message('Q', "SELECT * FROM large WHERE id IN ("+strings.Repeat("1, ", 1000)+"1)\x00")
+	require.NoError(t, d.ClientData(query[:256], now))
+	require.NoError(t, d.SkipClientData(len(query)-256-64, now))
+	require.NoError(t, d.ClientData(query[len(query)-64:], now))
+	txs, err := d.SkipServerData(0, now)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	txs, err = d.ServerData(response, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{{Operation: SelectOP, TableName: "large", Latency: time.Second}}, txs)
+
+	// the messages following the current one are missing, the decoder resyncs
+	// on the next message
+	d = NewDecoder()
+	require.NoError(t, d.ClientData(message('Q', "SELECT * FROM lost\x00"), now))
+	txs, err = d.ServerData(message('D', "\x00\x01\x00\x00\x00\x01")[:6], now)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	txs, err = d.SkipServerData(1000, now)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	txs, err = d.ServerData([]byte("garbage"), now)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	txs, err = d.ServerData(response, now)
+	require.NoError(t, err)
+	assert.Empty(t, txs, "the requests are forgotten when responses are lost")
+
+	require.NoError(t, d.SkipClientData(10, now))
+	require.NoError(t, d.ClientData([]byte("\x00\x01garbage"), now))
+	require.NoError(t, d.ClientData(message('Q', "DELETE FROM found\x00"), now))
+	txs, err = d.ServerData(response, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{{Operation: DeleteOP, TableName: "found", Latency: time.Second}}, txs)
+}
+
+func TestIsPostgres(t *testing.T) {
+	for name, tt := range map[string]struct {
+		buf  []byte
+		want bool
+	}{
+		"startup":       {startupMessage(), true},
+		"ssl_request":   {sslRequest(), true},
+		"query":         {message('Q', "select 1\x00"), true},
+		"parse":         {message('P', "s1\x00", "DELETE FROM t WHERE id = $1\x00", "\x00\x00"), true},
+		"partial_query": {message('Q', "SELECT * FROM users WHERE id = 1\x00")[:20], true},
+		"unterminated":  {message('Q', "SELECT 1"), false},
+		"unknown_query": {message('Q', "VACUUM\x00"), false},
+		"http":          {[]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"), false},
+		"redis":         {[]byte("*1\r\n$4\r\nPING\r\n"), false},
+		"short":         {[]byte("Q\x00"), false},
+		"empty":         {nil, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPostgres(tt.buf))
+		})
+	}
+}
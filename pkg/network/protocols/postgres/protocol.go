@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+
+package postgres
+
+import (
+	"strings"
+	"time"
+	"unsafe"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/cilium/ebpf"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/events"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/stream"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/network/usm/buildmode"
+	"github.com/DataDog/datadog-agent/pkg/network/usm/utils"
+)
+
+const (
+	eventStreamName = "postgres"
+	filterTailCall  = "socket__postgres_filter"
+	postgresHeapMap = "postgres_heap"
+
+	// maxBufferedSegments is the number of segments buffered between two
+	// flushes of the reassembler
+	maxBufferedSegments = 10000
+	flushInterval       = time.Second
+)
+
+type protocol struct {
+	cfg            *config.Config
+	telemetry      *Telemetry
+	statkeeper     *StatKeeper
+	reassembler    *stream.Reassembler
+	eventsConsumer *events.Consumer
+	stopChannel    chan struct{}
+}
+
+// Spec is the protocol spec for the Postgres protocol.
+var Spec = &protocols.ProtocolSpec{
+	Factory: newPostgresProtocol,
+	Maps: []*manager.Map{
+		{
+			Name: postgresHeapMap,
+		},
+	},
+	TailCalls: []manager.TailCallRoute{
+		{
+			ProgArrayName: protocols.ProtocolDispatcherProgramsMap,
+			Key:           uint32(protocols.ProgramPostgres),
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFFuncName: filterTailCall,
+			},
+		},
+	},
+}
+
+func newPostgresProtocol(cfg *config.Config) (protocols.Protocol, error) {
+	if !cfg.EnablePostgresMonitoring {
+		return nil, nil
+	}
+
+	return &protocol{
+		cfg:         cfg,
+		telemetry:   NewTelemetry(),
+		stopChannel: make(chan struct{}),
+	}, nil
+}
+
+func (p *protocol) Name() string {
+	return "Postgres"
+}
+
+// ConfigureOptions configures the postgres event stream with the manager and
+// its options.
+func (p *protocol) ConfigureOptions(mgr *manager.Manager, opts *manager.Options) {
+	events.Configure(eventStreamName, mgr, opts)
+	utils.EnableOption(opts, "postgres_monitoring_enabled")
+}
+
+func (p *protocol) PreStart(mgr *manager.Manager) error {
+	var err error
+	p.eventsConsumer, err = events.NewConsumer(
+		eventStreamName,
+		mgr,
+		p.processPostgres,
+	)
+	if err != nil {
+		return err
+	}
+
+	p.statkeeper = NewStatkeeper(p.cfg, p.telemetry)
+	p.reassembler = stream.NewReassembler(IsPostgres, func(conn types.ConnectionKey) stream.Decoder {
+		return newStreamDecoder(conn, p.statkeeper)
+	}, maxBufferedSegments)
+	p.eventsConsumer.Start()
+
+	return nil
+}
+
+// PostStart starts flushing the reassembler periodically, so that the
+// segments don't pile up between two calls of GetStats.
+func (p *protocol) PostStart(_ *manager.Manager) error {
+	ticker := time.NewTicker(flushInterval)
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.reassembler.Flush()
+			case <-p.stopChannel:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *protocol) Stop(_ *manager.Manager) {
+	if p.eventsConsumer != nil {
+		p.eventsConsumer.Stop()
+	}
+	close(p.stopChannel)
+}
+
+func (p *protocol) DumpMaps(_ *strings.Builder, _ string, _ *ebpf.Map) {}
+
+func (p *protocol) processPostgres(data []byte) {
+	segment := (*stream.EbpfSegment)(unsafe.Pointer(&data[0]))
+	if !p.reassembler.Add(segment.Segment()) {
+		p.telemetry.droppedSegments.Add(1)
+	}
+}
+
+// GetStats returns a map of Postgres stats stored in the following format:
+// [source, dest tuple, operation, table name] -> RequestStats object
+func (p *protocol) GetStats() *protocols.ProtocolStats {
+	p.eventsConsumer.Sync()
+	p.reassembler.Flush()
+	p.telemetry.Log()
+	return &protocols.ProtocolStats{
+		Type:  protocols.Postgres,
+		Stats: p.statkeeper.GetAndResetAllStats(),
+	}
+}
+
+// IsBuildModeSupported returns always true, as postgres module is supported by all modes.
+func (*protocol) IsBuildModeSupported(buildmode.Type) bool {
+	return true
+}
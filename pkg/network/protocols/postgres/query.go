@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"strings"
+)
+
+// maxTableNameSize is the maximum size of the table names extracted from
+// queries. Postgres identifiers are at most 63 bytes, which leaves room for
+// schema-qualified names.
+const maxTableNameSize = 128
+
+// parseQuery returns the operation of the query and the table it targets, if
+// any. Only the first statement of the query is considered.
+func parseQuery(query string) (Operation, string) {
+	t := queryTokenizer{query: query}
+	switch strings.ToUpper(t.next()) {
+	case "SELECT":
+		return SelectOP, t.tableAfter("FROM")
+	case "INSERT":
+		return InsertOP, t.tableAfter("INTO")
+	case "UPDATE":
+		t.skipWords("ONLY")
+		return UpdateOP, t.table()
+	case "DELETE":
+		return DeleteOP, t.tableAfter("FROM")
+	case "CREATE":
+		t.skipWords("GLOBAL", "LOCAL", "TEMP", "TEMPORARY", "UNLOGGED")
+		if !t.nextIs("TABLE") {
+			return UnknownOP, ""
+		}
+		t.skipWords("IF", "NOT", "EXISTS")
+		return CreateTableOP, t.table()
+	case "DROP":
+		if !t.nextIs("TABLE") {
+			return UnknownOP, ""
+		}
+		t.skipWords("IF", "EXISTS")
+		return DropTableOP, t.table()
+	case "ALTER":
+		if !t.nextIs("TABLE") {
+			return UnknownOP, ""
+		}
+		t.skipWords("IF", "EXISTS", "ONLY")
+		return AlterTableOP, t.table()
+	case "TRUNCATE":
+		t.skipWords("TABLE", "ONLY")
+		return TruncateTableOP, t.table()
+	case "SHOW":
+		return ShowOP, ""
+	case "WITH":
+		// the operation of a query with common table expressions is the one
+		// of its main statement, which follows them
+		return t.mainStatement()
+	default:
+		return UnknownOP, ""
+	}
+}
+
+// queryTokenizer splits queries in tokens, skipping whitespace and comments.
+type queryTokenizer struct {
+	query string
+	pos   int
+	depth int // parentheses depth
+	peek  string
+}
+
+// next returns the next token, or "" at the end of the first statement.
+// Quoted identifiers are returned with their quotes.
+func (t *queryTokenizer) next() string {
+	if t.peek != "" {
+		tok := t.peek
+		t.peek = ""
+		return tok
+	}
+	t.skipSpace()
+	if t.pos >= len(t.query) {
+		return ""
+	}
+	start := t.pos
+	switch c := t.query[t.pos]; {
+	case c == ';':
+		return ""
+	case c == '"' || c == '\'':
+		t.pos++
+		for t.pos < len(t.query) {
+			if t.query[t.pos] == c {
+				if t.pos+1 < len(t.query) && t.query[t.pos+1] == c {
+					// escaped quote
+					t.pos += 2
+					continue
+				}
+				break
+			}
+			t.pos++
+		}
+		t.pos++
+		if t.pos > len(t.query) {
+			t.pos = len(t.query)
+		}
+	case isIdentChar(c):
+		for t.pos < len(t.query) && (isIdentChar(t.query[t.pos]) || t.query[t.pos] == '.' || t.query[t.pos] == '"') {
+			if t.query[t.pos] == '"' {
+				// quoted part of a qualified name, e.g. public."Users"
+				if end := strings.IndexByte(t.query[t.pos+1:], '"'); end >= 0 {
+					t.pos += end + 1
+				}
+			}
+			t.pos++
+		}
+	default:
+		switch c {
+		case '(':
+			t.depth++
+		case ')':
+			t.depth--
+		}
+		t.pos++
+	}
+	return t.query[start:t.pos]
+}
+
+func (t *queryTokenizer) skipSpace() {
+	for t.pos < len(t.query) {
+		switch {
+		case t.query[t.pos] == ' ' || t.query[t.pos] == '\t' || t.query[t.pos] == '\n' || t.query[t.pos] == '\r':
+			t.pos++
+		case strings.HasPrefix(t.query[t.pos:], "--"):
+			if end := strings.IndexByte(t.query[t.pos:], '\n'); end >= 0 {
+				t.pos += end + 1
+			} else {
+				t.pos = len(t.query)
+			}
+		case strings.HasPrefix(t.query[t.pos:], "/*"):
+			if end := strings.Index(t.query[t.pos+2:], "*/"); end >= 0 {
+				t.pos += end + 4
+			} else {
+				t.pos = len(t.query)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// nextIs consumes the next token if it is the given keyword.
+func (t *queryTokenizer) nextIs(keyword string) bool {
+	tok := t.next()
+	if strings.EqualFold(tok, keyword) {
+		return true
+	}
+	t.peek = tok
+	return false
+}
+
+// skipWords skips the following tokens which are one of the given keywords.
+func (t *queryTokenizer) skipWords(keywords ...string) {
+	for {
+		tok := t.next()
+		skip := false
+		for _, k := range keywords {
+			if strings.EqualFold(tok, k) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			t.peek = tok
+			return
+		}
+	}
+}
+
+// tableAfter returns the table following the first occurrence of keyword
+// outside of parentheses.
+func (t *queryTokenizer) tableAfter(keyword string) string {
+	depth := t.depth
+	for tok := t.next(); tok != ""; tok = t.next() {
+		if t.depth == depth && strings.EqualFold(tok, keyword) {
+			t.skipWords("ONLY")
+			return t.table()
+		}
+	}
+	return ""
+}
+
+// table returns the table name of the next token, if it is an identifier.
+func (t *queryTokenizer) table() string {
+	tok := t.next()
+	if tok == "" || !isIdentChar(tok[0]) && tok[0] != '"' {
+		return ""
+	}
+	name := strings.ReplaceAll(tok, `"`, "")
+	if len(name) > maxTableNameSize {
+		name = name[:maxTableNameSize]
+	}
+	return name
+}
+
+// mainStatement returns the operation and table of the statement following
+// the common table expressions of a WITH query.
+func (t *queryTokenizer) mainStatement() (Operation, string) {
+	depth := t.depth
+	for tok := t.next(); tok != ""; tok = t.next() {
+		if t.depth != depth {
+			continue
+		}
+		switch strings.ToUpper(tok) {
+		case "SELECT", "INSERT", "UPDATE", "DELETE":
+			// parse the main statement on its own
+			return parseQuery(t.query[t.pos-len(tok):])
+		}
+	}
+	return UnknownOP, ""
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		op    Operation
+		table string
+	}{
+		{"SELECT * FROM users WHERE id = 1", SelectOP, "users"},
+		{"select id from public.users", SelectOP, "public.users"},
+		{`SELECT "id" FROM "Users"`, SelectOP, "Users"},
+		{`SELECT * FROM public."Users" u`, SelectOP, "public.Users"},
+		{"SELECT (SELECT max(id) FROM b) FROM a", SelectOP, "a"},
+		{"SELECT * FROM (SELECT * FROM b) sub", SelectOP, ""},
+		{"SELECT 1", SelectOP, ""},
+		{"SELECT 'FROM x' AS s FROM t", SelectOP, "t"},
+		{"  -- comment\n/* block */ SELECT * FROM t; SELECT * FROM u", SelectOP, "t"},
+		{"INSERT INTO orders (id) VALUES (1)", InsertOP, "orders"},
+		{"UPDATE ONLY accounts SET a = 1", UpdateOP, "accounts"},
+		{"DELETE FROM sessions WHERE expired", DeleteOP, "sessions"},
+		{"CREATE TEMP TABLE IF NOT EXISTS tmp (id int)", CreateTableOP, "tmp"},
+		{"CREATE INDEX idx ON t (id)", UnknownOP, ""},
+		{"DROP TABLE IF EXISTS old", DropTableOP, "old"},
+		{"ALTER TABLE ONLY t ADD COLUMN c int", AlterTableOP, "t"},
+		{"TRUNCATE logs", TruncateTableOP, "logs"},
+		{"truncate table only logs", TruncateTableOP, "logs"},
+		{"SHOW search_path", ShowOP, ""},
+		{"WITH recent AS (SELECT * FROM events) DELETE FROM archive", DeleteOP, "archive"},
+		{"WITH a AS (SELECT 1), b AS (SELECT 2) SELECT * FROM a", SelectOP, "a"},
+		{"BEGIN", UnknownOP, ""},
+		{"", UnknownOP, ""},
+		{"SELECT * FROM " + strings.Repeat("t", 200), SelectOP, strings.Repeat("t", maxTableNameSize)},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			op, table := parseQuery(tt.query)
+			assert.Equal(t, tt.op, op)
+			assert.Equal(t, tt.table, table)
+		})
+	}
+}
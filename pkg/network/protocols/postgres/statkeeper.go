@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+)
+
+// StatKeeper aggregates the Postgres transactions by connection, operation and table.
+type StatKeeper struct {
+	stats      map[Key]*RequestStat
+	statsMutex sync.Mutex
+	maxEntries int
+	telemetry  *Telemetry
+
+	// tableNames stores interned versions of the all table names currently
+	// stored in the `StatKeeper`
+	tableNames map[string]string
+}
+
+// NewStatkeeper returns a new StatKeeper
+func NewStatkeeper(c *config.Config, telemetry *Telemetry) *StatKeeper {
+	return &StatKeeper{
+		stats:      make(map[Key]*RequestStat),
+		maxEntries: c.MaxPostgresStatsBuffered,
+		telemetry:  telemetry,
+		tableNames: make(map[string]string),
+	}
+}
+
+// Process records the transaction tx of the connection conn
+func (statKeeper *StatKeeper) Process(conn types.ConnectionKey, tx *Transaction) {
+	statKeeper.telemetry.Count(tx)
+
+	statKeeper.statsMutex.Lock()
+	defer statKeeper.statsMutex.Unlock()
+
+	key := Key{
+		Operation:     tx.Operation,
+		TableName:     statKeeper.internTableName(tx.TableName),
+		ConnectionKey: conn,
+	}
+	requestStats, ok := statKeeper.stats[key]
+	if !ok {
+		if len(statKeeper.stats) >= statKeeper.maxEntries {
+			statKeeper.telemetry.dropped.Add(1)
+			return
+		}
+		requestStats = new(RequestStat)
+		statKeeper.stats[key] = requestStats
+	}
+	requestStats.AddRequest(float64(tx.Latency.Nanoseconds()), tx.Error)
+}
+
+// GetAndResetAllStats returns the stats aggregated since the last call
+func (statKeeper *StatKeeper) GetAndResetAllStats() map[Key]*RequestStat {
+	statKeeper.statsMutex.Lock()
+	defer statKeeper.statsMutex.Unlock()
+	ret := statKeeper.stats // No deep copy needed since `statKeeper.stats` gets reset
+	statKeeper.stats = make(map[Key]*RequestStat)
+	statKeeper.tableNames = make(map[string]string)
+	return ret
+}
+
+func (statKeeper *StatKeeper) internTableName(name string) string {
+	if v, ok := statKeeper.tableNames[name]; ok {
+		return v
+	}
+	statKeeper.tableNames[name] = name
+	return name
+}
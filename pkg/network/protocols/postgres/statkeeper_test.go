@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestStatKeeper(t *testing.T) {
+	sk := NewStatkeeper(&config.Config{MaxPostgresStatsBuffered: 2}, NewTelemetry())
+	conn := NewKey(util.AddressFromString("10.0.0.1"), util.AddressFromString("10.0.0.2"), 43000, 5432, UnknownOP, "").ConnectionKey
+
+	for _, tx := range []Transaction{
+		{Operation: SelectOP, TableName: "users", Latency: 2 * time.Millisecond},
+		{Operation: SelectOP, TableName: "users", Latency: 4 * time.Millisecond, Error: true},
+		{Operation: SelectOP, TableName: "users", Latency: 6 * time.Millisecond},
+		{Operation: InsertOP, TableName: "users", Latency: time.Millisecond},
+		// dropped, the stat keeper is full
+		{Operation: DeleteOP, TableName: "users", Latency: time.Millisecond},
+	} {
+		tx := tx
+		sk.Process(conn, &tx)
+	}
+
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats, 2)
+	sel := stats[Key{Operation: SelectOP, TableName: "users", ConnectionKey: conn}]
+	require.NotNil(t, sel)
+	assert.Equal(t, 3, sel.Count)
+	assert.Equal(t, 1, sel.ErrorCount)
+	require.NotNil(t, sel.Latencies)
+	assert.EqualValues(t, 3, sel.Latencies.GetCount())
+	ins := stats[Key{Operation: InsertOP, TableName: "users", ConnectionKey: conn}]
+	require.NotNil(t, ins)
+	assert.Equal(t, 1, ins.Count)
+	assert.Nil(t, ins.Latencies)
+	assert.Equal(t, float64(time.Millisecond), ins.FirstLatencySample)
+	assert.EqualValues(t, 1, sk.telemetry.dropped.Get())
+
+	assert.Empty(t, sk.GetAndResetAllStats())
+}
+
+func TestRequestStatCombineWith(t *testing.T) {
+	single := func(latency float64, isError bool) *RequestStat {
+		r := new(RequestStat)
+		r.AddRequest(latency, isError)
+		return r
+	}
+	multiple := func(latencies ...float64) *RequestStat {
+		r := new(RequestStat)
+		for _, l := range latencies {
+			r.AddRequest(l, false)
+		}
+		return r
+	}
+
+	r := new(RequestStat)
+	r.CombineWith(new(RequestStat))
+	assert.Equal(t, 0, r.Count)
+
+	r.CombineWith(single(10, true))
+	assert.Equal(t, 1, r.Count)
+	assert.Equal(t, 1, r.ErrorCount)
+	assert.Equal(t, 10.0, r.FirstLatencySample)
+	assert.Nil(t, r.Latencies)
+
+	r.CombineWith(multiple(20, 30))
+	assert.Equal(t, 3, r.Count)
+	assert.Equal(t, 1, r.ErrorCount)
+	require.NotNil(t, r.Latencies)
+	assert.EqualValues(t, 3, r.Latencies.GetCount())
+
+	r.CombineWith(multiple(40, 50))
+	assert.Equal(t, 5, r.Count)
+	assert.EqualValues(t, 5, r.Latencies.GetCount())
+
+	empty := new(RequestStat)
+	empty.CombineWith(multiple(1, 2))
+	assert.Equal(t, 2, empty.Count)
+	assert.Equal(t, 1.0, empty.FirstLatencySample)
+	assert.EqualValues(t, 2, empty.Latencies.GetCount())
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/stream"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+)
+
+// streamDecoder decodes the byte stream of a connection, and records its
+// transactions in the statkeeper.
+type streamDecoder struct {
+	conn       types.ConnectionKey
+	decoder    *Decoder
+	statkeeper *StatKeeper
+}
+
+func newStreamDecoder(conn types.ConnectionKey, statkeeper *StatKeeper) stream.Decoder {
+	return &streamDecoder{
+		conn:       conn,
+		decoder:    NewDecoder(),
+		statkeeper: statkeeper,
+	}
+}
+
+// Decode implements stream.Decoder
+func (s *streamDecoder) Decode(d *stream.Data) error {
+	if d.FromClient {
+		if err := s.decoder.SkipClientData(d.Gap, d.Timestamp); err != nil {
+			return err
+		}
+		if err := s.decoder.ClientData(d.Head, d.Timestamp); err != nil {
+			return err
+		}
+		if err := s.decoder.SkipClientData(d.Skipped, d.Timestamp); err != nil {
+			return err
+		}
+		return s.decoder.ClientData(d.Tail, d.Timestamp)
+	}
+
+	if err := s.process(s.decoder.SkipServerData(d.Gap, d.Timestamp)); err != nil {
+		return err
+	}
+	if err := s.process(s.decoder.ServerData(d.Head, d.Timestamp)); err != nil {
+		return err
+	}
+	if err := s.process(s.decoder.SkipServerData(d.Skipped, d.Timestamp)); err != nil {
+		return err
+	}
+	return s.process(s.decoder.ServerData(d.Tail, d.Timestamp))
+}
+
+// process records the transactions returned by the decoder.
+func (s *streamDecoder) process(txs []Transaction, err error) error {
+	for i := range txs {
+		s.statkeeper.Process(s.conn, &txs[i])
+	}
+	return err
+}
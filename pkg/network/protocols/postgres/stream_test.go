@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/stream"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// segment returns the segment of payload captured by eBPF, i.e. its first 256
+// and last 64 bytes.
+func segment(conn types.ConnectionKey, ts time.Duration, seq uint32, payload []byte) *stream.Segment {
+	s := &stream.Segment{Conn: conn, Timestamp: uint64(ts), Seq: seq, Size: uint32(len(payload)), Head: payload}
+	if len(payload) > 256 {
+		s.Head = payload[:256]
+		s.Tail = payload[256:]
+		if len(s.Tail) > 64 {
+			s.Tail = s.Tail[len(s.Tail)-64:]
+		}
+	}
+	return s
+}
+
+func TestStreamDecoder(t *testing.T) {
+	client := NewKey(util.AddressFromString("10.0.0.1"), util.AddressFromString("10.0.0.2"), 43000, 5432, UnknownOP, "").ConnectionKey
+	server := types.ConnectionKey{
+		SrcIPHigh: client.DstIPHigh,
+		SrcIPLow:  client.DstIPLow,
+		DstIPHigh: client.SrcIPHigh,
+		DstIPLow:  client.SrcIPLow,
+		SrcPort:   client.DstPort,
+		DstPort:   client.SrcPort,
+	}
+
+	sk := NewStatkeeper(&config.Config{MaxPostgresStatsBuffered: 10}, NewTelemetry())
+	r := stream.NewReassembler(IsPostgres, func(conn types.ConnectionKey) stream.Decoder {
+		return newStreamDecoder(conn, sk)
+	}, 10)
+
+	query := message('Q', "UPDATE large SET v = '"+strings.Repeat("v", 1000)+"'\x00")
+	rows := concat(
+		message('T', "\x00\x01id\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x17\x00\x04\xff\xff\xff\xff\x00\x00"),
+		message('D', strings.Repeat("d", 2000)),
+		message('C', "SELECT 1\x00"),
+		message('Z', "I"),
+	)
+	updated := concat(message('C', "UPDATE 1\x00"), message('Z', "I"))
+	ms := time.Millisecond
+	for _, s := range []*stream.Segment{
+		segment(client, 1*ms, 0, query),
+		segment(server, 3*ms, 0, updated),
+		segment(client, 4*ms, uint32(len(query)), message('Q', "SELECT * FROM users\x00")),
+		// a retransmission
+		segment(client, 5*ms, uint32(len(query)), message('Q', "SELECT * FROM users\x00")),
+		segment(server, 10*ms, uint32(len(updated)), rows),
+	} {
+		require.True(t, r.Add(s))
+	}
+	r.Flush()
+
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats, 2)
+	update := stats[Key{Operation: UpdateOP, TableName: "large", ConnectionKey: client}]
+	require.NotNil(t, update)
+	assert.Equal(t, 1, update.Count)
+	assert.Equal(t, float64(2*ms), update.FirstLatencySample)
+	sel := stats[Key{Operation: SelectOP, TableName: "users", ConnectionKey: client}]
+	require.NotNil(t, sel)
+	assert.Equal(t, 1, sel.Count)
+	assert.Equal(t, float64(6*ms), sel.FirstLatencySample)
+}
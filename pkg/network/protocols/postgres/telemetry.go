@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Telemetry holds the counters of the Postgres monitoring
+type Telemetry struct {
+	metricGroup *libtelemetry.MetricGroup
+
+	totalHits *libtelemetry.Counter
+	errors    *libtelemetry.Counter // transactions with an error response
+	dropped   *libtelemetry.Counter // this happens when StatKeeper reaches capacity
+
+	droppedSegments *libtelemetry.Counter // segments dropped while waiting to be decoded
+}
+
+// NewTelemetry returns a new Telemetry
+func NewTelemetry() *Telemetry {
+	metricGroup := libtelemetry.NewMetricGroup("usm.postgres", libtelemetry.OptStatsd)
+
+	return &Telemetry{
+		metricGroup: metricGroup,
+		// these metrics are also exported as statsd metrics
+		totalHits: metricGroup.NewCounter("total_hits"),
+		errors:    metricGroup.NewCounter("errors"),
+		dropped:   metricGroup.NewCounter("dropped"),
+
+		droppedSegments: metricGroup.NewCounter("dropped_segments"),
+	}
+}
+
+// Count counts the transaction tx
+func (t *Telemetry) Count(tx *Transaction) {
+	t.totalHits.Add(1)
+	if tx.Error {
+		t.errors.Add(1)
+	}
+}
+
+// Log logs a summary of the counters
+func (t *Telemetry) Log() {
+	log.Debugf("postgres stats summary: %s", t.metricGroup.Summary())
+}
@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"github.com/DataDog/sketches-go/ddsketch"
+
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RelativeAccuracy defines the acceptable error in quantile values calculated by DDSketch.
+const RelativeAccuracy = 0.01
+
+// Operation is the command of a Postgres query
+type Operation uint8
+
+const (
+	// UnknownOP represents an unknown or unsupported command
+	UnknownOP Operation = iota
+	// SelectOP represents a SELECT query
+	SelectOP
+	// InsertOP represents an INSERT query
+	InsertOP
+	// UpdateOP represents an UPDATE query
+	UpdateOP
+	// DeleteOP represents a DELETE query
+	DeleteOP
+	// CreateTableOP represents a CREATE TABLE query
+	CreateTableOP
+	// DropTableOP represents a DROP TABLE query
+	DropTableOP
+	// AlterTableOP represents an ALTER TABLE query
+	AlterTableOP
+	// TruncateTableOP represents a TRUNCATE query
+	TruncateTableOP
+	// ShowOP represents a SHOW query
+	ShowOP
+)
+
+// String returns the command of the operation
+func (op Operation) String() string {
+	switch op {
+	case SelectOP:
+		return "SELECT"
+	case InsertOP:
+		return "INSERT"
+	case UpdateOP:
+		return "UPDATE"
+	case DeleteOP:
+		return "DELETE"
+	case CreateTableOP:
+		return "CREATE"
+	case DropTableOP:
+		return "DROP"
+	case AlterTableOP:
+		return "ALTER"
+	case TruncateTableOP:
+		return "TRUNCATE"
+	case ShowOP:
+		return "SHOW"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Key is an identifier for a group of Postgres transactions
+type Key struct {
+	Operation Operation
+	TableName string
+	types.ConnectionKey
+}
+
+// NewKey generates a new Key
+func NewKey(saddr, daddr util.Address, sport, dport uint16, operation Operation, tableName string) Key {
+	return Key{
+		ConnectionKey: types.NewConnectionKey(saddr, daddr, sport, dport),
+		Operation:     operation,
+		TableName:     tableName,
+	}
+}
+
+// RequestStat stores stats for Postgres requests to a particular key
+type RequestStat struct {
+	// this field order is intentional to help the GC pointer tracking
+	Latencies *ddsketch.DDSketch
+	// Count is the number of transactions, which is tracked separately from
+	// the sketch since the sketch may discard values outside of its range.
+	Count int
+	// ErrorCount is the number of transactions which got an error response.
+	ErrorCount int
+	// FirstLatencySample holds the latency (in nanoseconds) of the first
+	// transaction of the bucket, to avoid creating sketches with a single value.
+	FirstLatencySample float64
+}
+
+// AddRequest records a transaction with the given latency, in nanoseconds
+func (r *RequestStat) AddRequest(latency float64, isError bool) {
+	r.Count++
+	if isError {
+		r.ErrorCount++
+	}
+	if r.Count == 1 {
+		r.FirstLatencySample = latency
+		return
+	}
+	if r.Latencies == nil {
+		if err := r.initSketch(); err != nil {
+			return
+		}
+		r.addLatency(r.FirstLatencySample)
+	}
+	r.addLatency(latency)
+}
+
+// CombineWith merges the data in 2 RequestStats objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStat) CombineWith(newStats *RequestStat) {
+	if newStats.Count == 0 {
+		return
+	}
+	if newStats.Count == 1 {
+		r.AddRequest(newStats.FirstLatencySample, newStats.ErrorCount > 0)
+		return
+	}
+	if r.Latencies == nil {
+		r.Latencies = newStats.Latencies.Copy()
+		if r.Count == 1 {
+			r.addLatency(r.FirstLatencySample)
+		}
+	} else if err := r.Latencies.MergeWith(newStats.Latencies); err != nil {
+		log.Debugf("error merging postgres transactions: %v", err)
+	}
+	if r.Count == 0 {
+		r.FirstLatencySample = newStats.FirstLatencySample
+	}
+	r.Count += newStats.Count
+	r.ErrorCount += newStats.ErrorCount
+}
+
+func (r *RequestStat) initSketch() (err error) {
+	r.Latencies, err = ddsketch.NewDefaultDDSketch(RelativeAccuracy)
+	if err != nil {
+		log.Debugf("error recording postgres transaction latency: could not create new ddsketch: %v", err)
+	}
+	return
+}
+
+func (r *RequestStat) addLatency(latency float64) {
+	if err := r.Latencies.Add(latency); err != nil {
+		log.Debugf("could not add postgres transaction latency to ddsketch: %v", err)
+	}
+}
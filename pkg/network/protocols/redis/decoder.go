@@ -0,0 +1,500 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of the RESP2 and RESP3 values.
+// See https://redis.io/docs/reference/protocol-spec/
+const (
+	simpleStringType = '+'
+	simpleErrorType  = '-'
+	integerType      = ':'
+	bulkStringType   = '$'
+	arrayType        = '*'
+
+	// RESP3 only
+	nullType           = '_'
+	booleanType        = '#'
+	doubleType         = ','
+	bigNumberType      = '('
+	bulkErrorType      = '!'
+	verbatimStringType = '='
+	mapType            = '%'
+	attributeType      = '|'
+	setType            = '~'
+	pushType           = '>'
+)
+
+const (
+	maxLineSize        = 1024 // bytes kept per line, enough for headers and inline commands
+	maxCommandSize     = 32
+	maxKeySize         = 128
+	maxArgs            = 2 // the command and its key
+	maxDepth           = 32
+	maxElements        = 1 << 30
+	maxPendingRequests = 128
+)
+
+// ErrInvalidMessage is returned when decoding bytes which are not a valid
+// RESP value.
+var ErrInvalidMessage = errors.New("invalid redis message")
+
+var crlf = []byte("\r\n")
+
+// keylessCommands are the commands whose first argument is not a key.
+var keylessCommands = map[string]bool{
+	"ACL": true, "AUTH": true, "BGREWRITEAOF": true, "BGSAVE": true, "BLMPOP": true,
+	"BZMPOP": true, "CLIENT": true, "CLUSTER": true, "COMMAND": true, "CONFIG": true,
+	"DBSIZE": true, "DEBUG": true, "DISCARD": true, "ECHO": true, "EVAL": true,
+	"EVALSHA": true, "EVALSHA_RO": true, "EVAL_RO": true, "EXEC": true, "FAILOVER": true,
+	"FCALL": true, "FCALL_RO": true, "FLUSHALL": true, "FLUSHDB": true, "FUNCTION": true,
+	"HELLO": true, "INFO": true, "KEYS": true, "LASTSAVE": true, "LATENCY": true,
+	"LMPOP": true, "MEMORY": true, "MIGRATE": true, "MODULE": true, "MULTI": true,
+	"OBJECT": true, "PING": true, "PUBLISH": true, "PUBSUB": true, "QUIT": true,
+	"RANDOMKEY": true, "READONLY": true, "READWRITE": true, "REPLICAOF": true, "RESET": true,
+	"ROLE": true, "SAVE": true, "SCAN": true, "SCRIPT": true, "SELECT": true,
+	"SHUTDOWN": true, "SINTERCARD": true, "SLAVEOF": true, "SLOWLOG": true, "SPUBLISH": true,
+	"SWAPDB": true, "TIME": true, "UNWATCH": true, "WAIT": true, "XREAD": true,
+	"XREADGROUP": true, "ZDIFF": true, "ZINTER": true, "ZINTERCARD": true, "ZMPOP": true,
+	"ZUNION": true,
+}
+
+// IsRedis reports whether buf, the first bytes sent by a client on a
+// connection, holds a Redis command, e.g. "*2\r\n$3\r\nGET\r\n".
+func IsRedis(buf []byte) bool {
+	if len(buf) == 0 || buf[0] != arrayType {
+		return false
+	}
+	i := bytes.Index(buf, crlf)
+	if i < 2 {
+		return false
+	}
+	if n, err := strconv.Atoi(string(buf[1:i])); err != nil || n < 1 || n > maxElements {
+		return false
+	}
+	buf = buf[i+2:]
+	if len(buf) == 0 || buf[0] != bulkStringType {
+		return false
+	}
+	i = bytes.Index(buf, crlf)
+	if i < 2 {
+		return false
+	}
+	size, err := strconv.Atoi(string(buf[1:i]))
+	if err != nil || size < 1 || size > maxCommandSize {
+		return false
+	}
+	name := buf[i+2:]
+	if len(name) > size {
+		if !bytes.HasPrefix(name[size:], crlf[:minInt(2, len(name)-size)]) {
+			return false
+		}
+		name = name[:size]
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_') {
+			return false
+		}
+	}
+	return len(name) > 0
+}
+
+// Transaction is a command and its reply on a Redis connection.
+type Transaction struct {
+	// Command is the upper-cased name of the command
+	Command string
+	// Key is the first key of the command, if any, up to maxKeySize bytes
+	Key string
+	// KeyTruncated is set when Key is shorter than the key of the command
+	KeyTruncated bool
+	// Latency is the time between the command and its reply.
+	Latency time.Duration
+	// Error reports whether the server replied with an error.
+	Error bool
+}
+
+type pendingRequest struct {
+	command      string
+	key          string
+	keyTruncated bool
+	start        time.Time
+}
+
+// Decoder decodes the RESP values of a connection and pairs commands with
+// their replies. It is not safe for concurrent use.
+type Decoder struct {
+	client respReader
+	server respReader
+
+	// stopped is set once the replies can't be paired with the commands
+	// anymore, e.g. after SUBSCRIBE or MONITOR.
+	stopped bool
+
+	pending []pendingRequest
+	txs     []Transaction
+}
+
+// NewDecoder returns a decoder for a new connection.
+func NewDecoder() *Decoder {
+	return &Decoder{
+		client: respReader{client: true},
+	}
+}
+
+// Stopped reports whether the decoder stopped decoding the connection.
+func (d *Decoder) Stopped() bool {
+	return d.stopped
+}
+
+// ClientData decodes the bytes sent by the client at the given time. The
+// decoder can't be used anymore once it returned an error.
+func (d *Decoder) ClientData(b []byte, ts time.Time) error {
+	if d.stopped {
+		return nil
+	}
+	return d.client.feed(b, func(v *value) {
+		d.handleCommand(v, ts)
+	})
+}
+
+// ServerData decodes the bytes sent by the server at the given time, and
+// returns the transactions completed by these bytes. The returned slice is
+// only valid until the next call. The decoder can't be used anymore once it
+// returned an error.
+func (d *Decoder) ServerData(b []byte, ts time.Time) ([]Transaction, error) {
+	d.txs = d.txs[:0]
+	if d.stopped {
+		return nil, nil
+	}
+	err := d.server.feed(b, func(v *value) {
+		d.handleReply(v, ts)
+	})
+	return d.txs, err
+}
+
+// SkipClientData skips n bytes sent by the client which were not captured.
+func (d *Decoder) SkipClientData(n int, ts time.Time) {
+	if d.stopped {
+		return
+	}
+	d.client.skip(n, func(v *value) {
+		d.handleCommand(v, ts)
+	})
+	if d.client.lost {
+		// the replies to the commands that were missed can't be paired
+		d.pending = d.pending[:0]
+	}
+}
+
+// SkipServerData skips n bytes sent by the server which were not captured,
+// and returns the transactions completed by a value ending in these bytes.
+// The returned slice is only valid until the next call.
+func (d *Decoder) SkipServerData(n int, ts time.Time) []Transaction {
+	d.txs = d.txs[:0]
+	if d.stopped {
+		return nil
+	}
+	d.server.skip(n, func(v *value) {
+		d.handleReply(v, ts)
+	})
+	if d.server.lost {
+		d.pending = d.pending[:0]
+	}
+	return d.txs
+}
+
+func (d *Decoder) handleCommand(v *value, ts time.Time) {
+	if len(v.args) == 0 {
+		return
+	}
+	command := v.args[0]
+	if len(command) > maxCommandSize {
+		command = command[:maxCommandSize]
+	}
+	command = strings.ToUpper(command)
+	switch command {
+	case "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "MONITOR", "SYNC", "PSYNC":
+		// the server sends messages which are not replies to commands
+		d.stop()
+		return
+	case "CLIENT":
+		// CLIENT REPLY OFF and SKIP suppress the replies to commands
+		if len(v.args) > 1 && strings.EqualFold(v.args[1], "REPLY") {
+			d.stop()
+			return
+		}
+	}
+	var key string
+	var keyTruncated bool
+	if len(v.args) > 1 && !keylessCommands[command] {
+		key = v.args[1]
+		keyTruncated = v.keyTruncated
+	}
+
+	if len(d.pending) >= maxPendingRequests {
+		// the replies are missing, e.g. because some of the bytes of the
+		// connection were not captured
+		d.pending = d.pending[:0]
+	}
+	d.pending = append(d.pending, pendingRequest{command: command, key: key, keyTruncated: keyTruncated, start: ts})
+}
+
+func (d *Decoder) handleReply(v *value, ts time.Time) {
+	if v.typ == pushType || v.typ == attributeType || len(d.pending) == 0 {
+		// push and attribute values are not replies
+		return
+	}
+	p := d.pending[0]
+	d.pending[0] = pendingRequest{}
+	d.pending = d.pending[1:]
+	d.txs = append(d.txs, Transaction{
+		Command:      p.command,
+		Key:          p.key,
+		KeyTruncated: p.keyTruncated,
+		Latency:      ts.Sub(p.start),
+		Error:        v.typ == simpleErrorType || v.typ == bulkErrorType,
+	})
+}
+
+func (d *Decoder) stop() {
+	d.stopped = true
+	d.pending = nil
+}
+
+// value is a top-level RESP value.
+type value struct {
+	typ byte
+	// args are the first bulk strings of a command, up to maxArgs
+	args []string
+	// keyTruncated is set when the second argument is longer than maxKeySize
+	keyTruncated bool
+}
+
+// respReader splits a stream of bytes in RESP values.
+type respReader struct {
+	// client is set on the client side, where the arguments of commands are
+	// kept and commands may be sent inline.
+	client bool
+	// lost is set when bytes of the stream were missed, until the start of a
+	// value is found
+	lost bool
+
+	line []byte // current line, up to maxLineSize
+
+	inBulk        bool
+	bulkRemaining int    // bytes of the current bulk string not read yet, including its CRLF
+	keepBulk      bool   // set when the current bulk string is an argument to keep
+	bulkKept      int    // bytes of the current bulk string to keep
+	bulk          []byte // kept bytes of the current bulk string
+
+	stack []int // elements left to read in the aggregates being read
+	value value
+}
+
+// skip skips n bytes of the stream which were not captured, calling handle if
+// they end the current value.
+func (r *respReader) skip(n int, handle func(v *value)) {
+	if n <= 0 || r.lost {
+		return
+	}
+	if r.inBulk && n <= r.bulkRemaining {
+		// the bulk string is truncated, which is fine as only the start of
+		// the arguments of commands is kept
+		r.bulkRemaining -= n
+		if r.bulkRemaining == 0 {
+			r.bulkDone(handle)
+		}
+		return
+	}
+	// the boundaries of the values which follow are lost
+	r.lost = true
+	r.line = r.line[:0]
+	r.inBulk = false
+	r.stack = r.stack[:0]
+}
+
+// resync reports whether b, read after bytes were missed, starts with a
+// value. Commands are arrays, while replies may have any type.
+func (r *respReader) resync(b []byte) bool {
+	if r.client {
+		return IsRedis(b)
+	}
+	if len(b) == 0 {
+		return false
+	}
+	switch b[0] {
+	case simpleStringType, simpleErrorType, integerType, bulkStringType, arrayType,
+		nullType, booleanType, doubleType, bigNumberType, bulkErrorType,
+		verbatimStringType, mapType, attributeType, setType, pushType:
+		return true
+	}
+	return false
+}
+
+// feed reads the values in b, calling handle for each complete top-level
+// value.
+func (r *respReader) feed(b []byte, handle func(v *value)) error {
+	if r.lost {
+		if !r.resync(b) {
+			return nil
+		}
+		r.lost = false
+	}
+	for len(b) > 0 {
+		if r.inBulk {
+			n := minInt(r.bulkRemaining, len(b))
+			if keep := r.bulkKept - len(r.bulk); keep > 0 {
+				r.bulk = append(r.bulk, b[:minInt(keep, n)]...)
+			}
+			r.bulkRemaining -= n
+			b = b[n:]
+			if r.bulkRemaining == 0 {
+				r.bulkDone(handle)
+			}
+			continue
+		}
+
+		chunk := b
+		end := bytes.IndexByte(b, '\n')
+		if end >= 0 {
+			chunk = b[:end+1]
+		}
+		if room := maxLineSize - len(r.line); room > 0 {
+			r.line = append(r.line, chunk[:minInt(room, len(chunk))]...)
+		}
+		b = b[len(chunk):]
+		if end < 0 {
+			return nil
+		}
+		line := bytes.TrimSuffix(bytes.TrimSuffix(r.line, []byte("\n")), []byte("\r"))
+		err := r.handleLine(line, handle)
+		r.line = r.line[:0]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *respReader) handleLine(line []byte, handle func(v *value)) error {
+	if len(line) == 0 {
+		if r.client && len(r.stack) == 0 {
+			// empty inline commands are ignored by servers
+			return nil
+		}
+		return fmt.Errorf("%w: empty line", ErrInvalidMessage)
+	}
+	typ := line[0]
+	if len(r.stack) == 0 {
+		r.value = value{typ: typ, args: r.value.args[:0]}
+	}
+	switch typ {
+	case arrayType, setType, pushType, mapType, attributeType:
+		n, err := parseLength(line[1:])
+		if err != nil {
+			return err
+		}
+		if typ == mapType || typ == attributeType {
+			n *= 2
+		}
+		if n <= 0 {
+			r.elementDone(handle)
+			return nil
+		}
+		if len(r.stack) >= maxDepth {
+			return fmt.Errorf("%w: too many nested values", ErrInvalidMessage)
+		}
+		r.stack = append(r.stack, n)
+	case bulkStringType, bulkErrorType, verbatimStringType:
+		n, err := parseLength(line[1:])
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			r.elementDone(handle)
+			return nil
+		}
+		r.inBulk = true
+		r.bulkRemaining = n + len(crlf)
+		r.bulk = r.bulk[:0]
+		r.keepBulk = r.client && len(r.stack) == 1 && r.value.typ == arrayType && len(r.value.args) < maxArgs
+		r.bulkKept = 0
+		if r.keepBulk {
+			r.bulkKept = minInt(n, maxKeySize)
+			if len(r.value.args) == 1 {
+				r.value.keyTruncated = n > maxKeySize
+			}
+		}
+	case simpleStringType, simpleErrorType, integerType, nullType, booleanType, doubleType, bigNumberType:
+		r.elementDone(handle)
+	default:
+		if !r.client || len(r.stack) > 0 {
+			return fmt.Errorf("%w: unknown type %q", ErrInvalidMessage, typ)
+		}
+		// inline command, made of space-separated arguments
+		r.value.typ = arrayType
+		for _, arg := range bytes.Fields(line) {
+			if len(r.value.args) == maxArgs {
+				break
+			}
+			if len(r.value.args) == 1 {
+				r.value.keyTruncated = len(arg) > maxKeySize
+			}
+			r.value.args = append(r.value.args, string(arg[:minInt(len(arg), maxKeySize)]))
+		}
+		r.elementDone(handle)
+	}
+	return nil
+}
+
+// bulkDone is called once a bulk string is read.
+func (r *respReader) bulkDone(handle func(v *value)) {
+	r.inBulk = false
+	if r.keepBulk {
+		r.value.args = append(r.value.args, string(r.bulk))
+	}
+	r.elementDone(handle)
+}
+
+// elementDone is called once a value is read. It completes the aggregates
+// this value was the last element of, and calls handle if the top-level
+// value is complete.
+func (r *respReader) elementDone(handle func(v *value)) {
+	for len(r.stack) > 0 {
+		top := len(r.stack) - 1
+		r.stack[top]--
+		if r.stack[top] > 0 {
+			return
+		}
+		r.stack = r.stack[:top]
+	}
+	handle(&r.value)
+}
+
+// parseLength parses the length of an aggregate or bulk string, which is -1
+// for null values.
+func parseLength(b []byte) (int, error) {
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < -1 || n > maxElements {
+		return 0, fmt.Errorf("%w: invalid length %q", ErrInvalidMessage, b)
+	}
+	return n, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,267 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// command returns the RESP encoding of a command sent by a client.
+func command(args ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return b.String()
+}
+
+type exchange struct {
+	client, server string
+	want           []Transaction
+}
+
+func TestDecoder(t *testing.T) {
+	ms := time.Millisecond
+	for name, exchanges := range map[string][]exchange{
+		"resp2": {
+			{
+				client: command("SET", "user:1", "alice"),
+				server: "+OK\r\n",
+				want:   []Transaction{{Command: "SET", Key: "user:1", Latency: ms}},
+			},
+			{
+				client: command("get", "user:1"),
+				server: "$5\r\nalice\r\n",
+				want:   []Transaction{{Command: "GET", Key: "user:1", Latency: ms}},
+			},
+			{
+				client: command("GET", "missing"),
+				server: "$-1\r\n",
+				want:   []Transaction{{Command: "GET", Key: "missing", Latency: ms}},
+			},
+			{
+				client: command("INCR", "user:1"),
+				server: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+				want:   []Transaction{{Command: "INCR", Key: "user:1", Latency: ms, Error: true}},
+			},
+			{
+				client: command("PING"),
+				server: "+PONG\r\n",
+				want:   []Transaction{{Command: "PING", Latency: ms}},
+			},
+		},
+		"pipeline": {
+			{
+				client: command("MULTI") + command("LPUSH", "queue", "a", "b") + command("LRANGE", "queue", "0", "-1") + command("EXEC"),
+				server: "+OK\r\n+QUEUED\r\n+QUEUED\r\n*2\r\n:2\r\n*2\r\n$1\r\nb\r\n$1\r\na\r\n",
+				want: []Transaction{
+					{Command: "MULTI", Latency: ms},
+					{Command: "LPUSH", Key: "queue", Latency: ms},
+					{Command: "LRANGE", Key: "queue", Latency: ms},
+					{Command: "EXEC", Latency: ms},
+				},
+			},
+		},
+		"resp3": {
+			{
+				client: command("HELLO", "3"),
+				server: "%2\r\n$6\r\nserver\r\n$5\r\nredis\r\n$5\r\nproto\r\n:3\r\n",
+				want:   []Transaction{{Command: "HELLO", Latency: ms}},
+			},
+			{
+				client: command("HGETALL", "session:abc"),
+				server: "|1\r\n+ttl\r\n:3600\r\n%1\r\n+name\r\n=8\r\ntxt:test\r\n",
+				want:   []Transaction{{Command: "HGETALL", Key: "session:abc", Latency: ms}},
+			},
+			{
+				client: command("SMEMBERS", "tags"),
+				server: ">3\r\n+invalidate\r\n*1\r\n$4\r\ntags\r\n_\r\n~2\r\n#t\r\n,1.5\r\n",
+				want:   []Transaction{{Command: "SMEMBERS", Key: "tags", Latency: ms}},
+			},
+			{
+				client: command("FCALL", "fn", "0"),
+				server: "!21\r\nSYNTAX invalid syntax\r\n",
+				want:   []Transaction{{Command: "FCALL", Latency: ms, Error: true}},
+			},
+			{
+				client: command("INCRBY", "big", "1"),
+				server: "(3492890328409238509324850943850943825024385\r\n",
+				want:   []Transaction{{Command: "INCRBY", Key: "big", Latency: ms}},
+			},
+		},
+		"inline": {
+			{
+				client: "PING\r\n\r\nEXISTS  key:1 key:2\r\n",
+				server: "+PONG\r\n:1\r\n",
+				want: []Transaction{
+					{Command: "PING", Latency: ms},
+					{Command: "EXISTS", Key: "key:1", Latency: ms},
+				},
+			},
+		},
+		"empty_key": {
+			{
+				client: command("GET", ""),
+				server: "$-1\r\n",
+				want:   []Transaction{{Command: "GET", Latency: ms}},
+			},
+			{
+				client: command("GET", "k"),
+				server: "$0\r\n\r\n",
+				want:   []Transaction{{Command: "GET", Key: "k", Latency: ms}},
+			},
+		},
+		"subscribe": {
+			{
+				client: command("SUBSCRIBE", "news"),
+				server: "*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n",
+			},
+			{
+				client: command("PING"),
+				server: "*2\r\n$4\r\npong\r\n$0\r\n\r\n",
+			},
+		},
+		"client_reply": {
+			{
+				client: command("CLIENT", "REPLY", "OFF") + command("SET", "k", "v"),
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, fragmented := range []bool{false, true} {
+				d := NewDecoder()
+				now := time.Now()
+				for _, e := range exchanges {
+					var got []Transaction
+					if fragmented {
+						// the decoder receives a byte at a time
+						for i := range e.client {
+							require.NoError(t, d.ClientData([]byte(e.client[i:i+1]), now))
+						}
+						for i := range e.server {
+							txs, err := d.ServerData([]byte(e.server[i:i+1]), now.Add(ms))
+							require.NoError(t, err)
+							got = append(got, txs...)
+						}
+					} else {
+						require.NoError(t, d.ClientData([]byte(e.client), now))
+						txs, err := d.ServerData([]byte(e.server), now.Add(ms))
+						require.NoError(t, err)
+						got = append(got, txs...)
+					}
+					assert.Equal(t, e.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderLargeValues(t *testing.T) {
+	d := NewDecoder()
+	now := time.Now()
+	key := strings.Repeat("k", 2*maxKeySize)
+	value := strings.Repeat("v", 10*maxLineSize)
+	require.NoError(t, d.ClientData([]byte(command("SET", key, value)), now))
+	require.NoError(t, d.ClientData([]byte(command("GET", key)), now))
+	txs, err := d.ServerData([]byte("+OK\r\n$"+fmt.Sprint(len(value))+"\r\n"+value+"\r\n"), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{
+		{Command: "SET", Key: key[:maxKeySize], KeyTruncated: true, Latency: time.Second},
+		{Command: "GET", Key: key[:maxKeySize], KeyTruncated: true, Latency: time.Second},
+	}, txs)
+
+	// long simple strings are read entirely
+	require.NoError(t, d.ClientData([]byte(command("PING", value)), now))
+	txs, err = d.ServerData([]byte("+"+value+"\r\n"), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{{Command: "PING", Latency: time.Second}}, txs)
+}
+
+func TestDecoderSkip(t *testing.T) {
+	now := time.Now()
+
+	// the middle of a large value is missing
+	d := NewDecoder()
+	set := command("SET", "user:1", strings.Repeat("v", 1000))
+	require.NoError(t, d.ClientData([]byte(set[:256]), now))
+	d.SkipClientData(len(set)-256-64, now)
+	require.NoError(t, d.ClientData([]byte(set[len(set)-64:]), now))
+	assert.Empty(t, d.SkipServerData(0, now))
+	txs, err := d.ServerData([]byte("+OK\r\n"), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{{Command: "SET", Key: "user:1", Latency: time.Second}}, txs)
+
+	// the values following the current one are missing, the decoder resyncs
+	// on the next value
+	require.NoError(t, d.ClientData([]byte(command("GET", "user:1")), now))
+	reply := "$1000\r\n" + strings.Repeat("v", 1000) + "\r\n"
+	txs, err = d.ServerData([]byte(reply[:256]), now)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	assert.Empty(t, d.SkipServerData(2000, now))
+	txs, err = d.ServerData([]byte("vvvv\r\n"), now)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	txs, err = d.ServerData([]byte("+OK\r\n"), now)
+	require.NoError(t, err)
+	assert.Empty(t, txs, "the commands are forgotten when replies are lost")
+
+	d.SkipClientData(10, now)
+	require.NoError(t, d.ClientData([]byte("vvvv\r\n"), now))
+	require.NoError(t, d.ClientData([]byte(command("DEL", "user:1")), now))
+	txs, err = d.ServerData([]byte(":1\r\n"), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{{Command: "DEL", Key: "user:1", Latency: time.Second}}, txs)
+}
+
+func TestDecoderInvalid(t *testing.T) {
+	d := NewDecoder()
+	assert.ErrorIs(t, d.ClientData([]byte("*x\r\n"), time.Now()), ErrInvalidMessage)
+
+	d = NewDecoder()
+	assert.ErrorIs(t, d.ClientData([]byte("*1\r\n@3\r\n"), time.Now()), ErrInvalidMessage)
+
+	d = NewDecoder()
+	require.NoError(t, d.ClientData([]byte(command("GET", "k")), time.Now()))
+	_, err := d.ServerData([]byte("HTTP/1.1 200 OK\r\n"), time.Now())
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+
+	d = NewDecoder()
+	_, err = d.ServerData([]byte(strings.Repeat("*1\r\n", maxDepth+1)), time.Now())
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestIsRedis(t *testing.T) {
+	for name, tt := range map[string]struct {
+		buf  string
+		want bool
+	}{
+		"get":          {command("GET", "key"), true},
+		"lower_case":   {command("hgetall", "key"), true},
+		"partial":      {command("CLIENT", "SETNAME", "app")[:12], true},
+		"no_command":   {"*1\r\n", false},
+		"zero_args":    {"*0\r\n", false},
+		"not_bulk":     {"*1\r\n:1\r\n", false},
+		"bad_name":     {command("G3T"), false},
+		"bad_size":     {"*1\r\n$3\r\nGETS\r\n", false},
+		"long_command": {command(strings.Repeat("A", maxCommandSize+1)), false},
+		"inline":       {"PING\r\n", false},
+		"http":         {"GET / HTTP/1.1\r\nHost: localhost\r\n\r\n", false},
+		"postgres":     {"\x00\x00\x00\x08\x04\xd2\x16\x2f", false},
+		"empty":        {"", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRedis([]byte(tt.buf)))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+)
+
+// StatKeeper aggregates the Redis transactions by connection, command and key prefix.
+type StatKeeper struct {
+	stats      map[Key]*RequestStats
+	statsMutex sync.Mutex
+	maxEntries int
+	telemetry  *Telemetry
+
+	keyPrefixSize int
+	obfuscator    *obfuscate.Obfuscator // set when keys are obfuscated
+
+	// strings stores interned versions of the all commands and key prefixes
+	// currently stored in the `StatKeeper`
+	strings map[string]string
+}
+
+// NewStatkeeper returns a new StatKeeper
+func NewStatkeeper(c *config.Config, telemetry *Telemetry) *StatKeeper {
+	statKeeper := &StatKeeper{
+		stats:         make(map[Key]*RequestStats),
+		maxEntries:    c.MaxRedisStatsBuffered,
+		telemetry:     telemetry,
+		keyPrefixSize: c.RedisKeyPrefixSize,
+		strings:       make(map[string]string),
+	}
+	if c.ObfuscateRedisKeys {
+		statKeeper.obfuscator = obfuscate.NewObfuscator(obfuscate.Config{})
+	}
+	return statKeeper
+}
+
+// Process records the transaction tx of the connection conn
+func (statKeeper *StatKeeper) Process(conn types.ConnectionKey, tx *Transaction) {
+	statKeeper.telemetry.Count(tx)
+
+	statKeeper.statsMutex.Lock()
+	defer statKeeper.statsMutex.Unlock()
+
+	keyPrefix, truncated := statKeeper.keyPrefix(tx)
+	key := Key{
+		Command:       statKeeper.intern(tx.Command),
+		KeyPrefix:     statKeeper.intern(keyPrefix),
+		Truncated:     truncated,
+		ConnectionKey: conn,
+	}
+	requestStats, ok := statKeeper.stats[key]
+	if !ok {
+		if len(statKeeper.stats) >= statKeeper.maxEntries {
+			statKeeper.telemetry.dropped.Add(1)
+			return
+		}
+		requestStats = NewRequestStats()
+		statKeeper.stats[key] = requestStats
+	}
+	requestStats.AddRequest(tx.Error, float64(tx.Latency.Nanoseconds()))
+}
+
+// GetAndResetAllStats returns the stats aggregated since the last call
+func (statKeeper *StatKeeper) GetAndResetAllStats() map[Key]*RequestStats {
+	statKeeper.statsMutex.Lock()
+	defer statKeeper.statsMutex.Unlock()
+	ret := statKeeper.stats // No deep copy needed since `statKeeper.stats` gets reset
+	statKeeper.stats = make(map[Key]*RequestStats)
+	statKeeper.strings = make(map[string]string)
+	return ret
+}
+
+// keyPrefix returns the key of the transaction, obfuscated or truncated
+// according to the configuration, and whether it is shorter than the key.
+func (statKeeper *StatKeeper) keyPrefix(tx *Transaction) (string, bool) {
+	if tx.Key == "" {
+		return "", false
+	}
+	if statKeeper.obfuscator != nil {
+		// the obfuscation removes the arguments of the command, keeping its
+		// subcommand if any
+		cmd := statKeeper.obfuscator.RemoveAllRedisArgs(tx.Command + " " + tx.Key)
+		_, args, _ := strings.Cut(cmd, " ")
+		return args, false
+	}
+	if statKeeper.keyPrefixSize > 0 && len(tx.Key) > statKeeper.keyPrefixSize {
+		return tx.Key[:statKeeper.keyPrefixSize], true
+	}
+	return tx.Key, tx.KeyTruncated
+}
+
+func (statKeeper *StatKeeper) intern(s string) string {
+	if v, ok := statKeeper.strings[s]; ok {
+		return v
+	}
+	statKeeper.strings[s] = s
+	return s
+}
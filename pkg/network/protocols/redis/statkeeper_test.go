@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestStatKeeper(t *testing.T) {
+	conn := NewKey(util.AddressFromString("10.0.0.1"), util.AddressFromString("10.0.0.2"), 43000, 6379, "", "", false).ConnectionKey
+	txs := []Transaction{
+		{Command: "GET", Key: "session:1234", Latency: 2 * time.Millisecond},
+		{Command: "GET", Key: "session:5678", Latency: 4 * time.Millisecond, Error: true},
+		{Command: "GET", Key: "user:1", Latency: 6 * time.Millisecond},
+		{Command: "PING", Latency: time.Millisecond},
+	}
+
+	t.Run("truncated keys", func(t *testing.T) {
+		sk := NewStatkeeper(&config.Config{MaxRedisStatsBuffered: 100, RedisKeyPrefixSize: 8}, NewTelemetry())
+		for _, tx := range txs {
+			tx := tx
+			sk.Process(conn, &tx)
+		}
+
+		stats := sk.GetAndResetAllStats()
+		require.Len(t, stats, 3)
+		session := stats[Key{Command: "GET", KeyPrefix: "session:", Truncated: true, ConnectionKey: conn}]
+		require.NotNil(t, session)
+		require.Len(t, session.ErrorToStats, 2)
+		assert.Equal(t, 1, session.ErrorToStats[false].Count)
+		assert.Equal(t, float64(2*time.Millisecond), session.ErrorToStats[false].FirstLatencySample)
+		assert.Equal(t, 1, session.ErrorToStats[true].Count)
+		user := stats[Key{Command: "GET", KeyPrefix: "user:1", ConnectionKey: conn}]
+		require.NotNil(t, user)
+		require.Contains(t, user.ErrorToStats, false)
+		assert.Equal(t, 1, user.ErrorToStats[false].Count)
+		assert.Equal(t, float64(6*time.Millisecond), user.ErrorToStats[false].FirstLatencySample)
+		require.Contains(t, stats, Key{Command: "PING", ConnectionKey: conn})
+
+		assert.Empty(t, sk.GetAndResetAllStats())
+	})
+
+	t.Run("whole keys", func(t *testing.T) {
+		sk := NewStatkeeper(&config.Config{MaxRedisStatsBuffered: 100}, NewTelemetry())
+		for _, tx := range txs {
+			tx := tx
+			sk.Process(conn, &tx)
+		}
+		assert.Len(t, sk.GetAndResetAllStats(), 4)
+	})
+
+	t.Run("obfuscated keys", func(t *testing.T) {
+		sk := NewStatkeeper(&config.Config{MaxRedisStatsBuffered: 100, RedisKeyPrefixSize: 8, ObfuscateRedisKeys: true}, NewTelemetry())
+		for _, tx := range txs {
+			tx := tx
+			sk.Process(conn, &tx)
+		}
+
+		stats := sk.GetAndResetAllStats()
+		require.Len(t, stats, 2)
+		get := stats[Key{Command: "GET", KeyPrefix: "?", ConnectionKey: conn}]
+		require.NotNil(t, get)
+		assert.Equal(t, 2, get.ErrorToStats[false].Count)
+		require.NotNil(t, get.ErrorToStats[false].Latencies)
+		assert.EqualValues(t, 2, get.ErrorToStats[false].Latencies.GetCount())
+		assert.Equal(t, 1, get.ErrorToStats[true].Count)
+		require.Contains(t, stats, Key{Command: "PING", ConnectionKey: conn})
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		sk := NewStatkeeper(&config.Config{MaxRedisStatsBuffered: 2}, NewTelemetry())
+		for _, tx := range txs {
+			tx := tx
+			sk.Process(conn, &tx)
+		}
+		assert.Len(t, sk.GetAndResetAllStats(), 2)
+		assert.EqualValues(t, 2, sk.telemetry.dropped.Get())
+	})
+}
+
+func TestRequestStatCombineWith(t *testing.T) {
+	multiple := func(latencies ...float64) *RequestStat {
+		r := new(RequestStat)
+		for _, l := range latencies {
+			r.AddRequest(l)
+		}
+		return r
+	}
+
+	r := new(RequestStat)
+	r.AddRequest(10)
+	r.CombineWith(multiple(20, 30))
+	assert.Equal(t, 3, r.Count)
+	require.NotNil(t, r.Latencies)
+	assert.EqualValues(t, 3, r.Latencies.GetCount())
+
+	r.CombineWith(multiple(40))
+	assert.Equal(t, 4, r.Count)
+	assert.EqualValues(t, 4, r.Latencies.GetCount())
+
+	empty := new(RequestStat)
+	empty.CombineWith(multiple(1, 2))
+	assert.Equal(t, 2, empty.Count)
+	assert.Equal(t, 1.0, empty.FirstLatencySample)
+	assert.EqualValues(t, 2, empty.Latencies.GetCount())
+}
+
+func TestRequestStatsCombineWith(t *testing.T) {
+	r := NewRequestStats()
+	r.AddRequest(false, 10)
+
+	other := NewRequestStats()
+	other.AddRequest(false, 20)
+	other.AddRequest(true, 30)
+	r.CombineWith(other)
+
+	require.Len(t, r.ErrorToStats, 2)
+	assert.Equal(t, 2, r.ErrorToStats[false].Count)
+	assert.EqualValues(t, 2, r.ErrorToStats[false].Latencies.GetCount())
+	assert.Equal(t, 1, r.ErrorToStats[true].Count)
+	assert.Equal(t, 30.0, r.ErrorToStats[true].FirstLatencySample)
+	// other is not mutated
+	assert.Equal(t, 1, other.ErrorToStats[false].Count)
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/stream"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// segment returns the segment of payload captured by eBPF, i.e. its first 256
+// and last 64 bytes.
+func segment(conn types.ConnectionKey, ts time.Duration, seq uint32, payload string) *stream.Segment {
+	s := &stream.Segment{Conn: conn, Timestamp: uint64(ts), Seq: seq, Size: uint32(len(payload)), Head: []byte(payload)}
+	if len(payload) > 256 {
+		s.Head = s.Head[:256]
+		s.Tail = []byte(payload[256:])
+		if len(s.Tail) > 64 {
+			s.Tail = s.Tail[len(s.Tail)-64:]
+		}
+	}
+	return s
+}
+
+func TestStreamDecoder(t *testing.T) {
+	client := NewKey(util.AddressFromString("10.0.0.1"), util.AddressFromString("10.0.0.2"), 43000, 6379, "", "", false).ConnectionKey
+	server := types.ConnectionKey{
+		SrcIPHigh: client.DstIPHigh,
+		SrcIPLow:  client.DstIPLow,
+		DstIPHigh: client.SrcIPHigh,
+		DstIPLow:  client.SrcIPLow,
+		SrcPort:   client.DstPort,
+		DstPort:   client.SrcPort,
+	}
+
+	sk := NewStatkeeper(&config.Config{MaxRedisStatsBuffered: 10}, NewTelemetry())
+	r := stream.NewReassembler(IsRedis, func(conn types.ConnectionKey) stream.Decoder {
+		return newStreamDecoder(conn, sk)
+	}, 10)
+
+	set := command("SET", "user:1", strings.Repeat("v", 1000))
+	get := command("GET", "user:1")
+	value := "$1000\r\n" + strings.Repeat("v", 1000) + "\r\n"
+	ms := time.Millisecond
+	for _, s := range []*stream.Segment{
+		// the reply of a command sent before the capture started
+		segment(server, 1*ms, 0, "+OK\r\n"),
+		segment(client, 2*ms, 0, set),
+		segment(server, 4*ms, 5, "+OK\r\n"),
+		segment(client, 5*ms, uint32(len(set)), get),
+		// a retransmission
+		segment(client, 6*ms, uint32(len(set)), get),
+		segment(server, 10*ms, 10, value),
+	} {
+		require.True(t, r.Add(s))
+	}
+	r.Flush()
+
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats, 2)
+	setStats := stats[Key{Command: "SET", KeyPrefix: "user:1", ConnectionKey: client}]
+	require.NotNil(t, setStats)
+	require.Contains(t, setStats.ErrorToStats, false)
+	assert.Equal(t, 1, setStats.ErrorToStats[false].Count)
+	assert.Equal(t, float64(2*ms), setStats.ErrorToStats[false].FirstLatencySample)
+	getStats := stats[Key{Command: "GET", KeyPrefix: "user:1", ConnectionKey: client}]
+	require.NotNil(t, getStats)
+	require.Contains(t, getStats.ErrorToStats, false)
+	assert.Equal(t, 1, getStats.ErrorToStats[false].Count)
+	assert.Equal(t, float64(5*ms), getStats.ErrorToStats[false].FirstLatencySample)
+}
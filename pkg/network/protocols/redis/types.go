@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"github.com/DataDog/sketches-go/ddsketch"
+
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RelativeAccuracy defines the acceptable error in quantile values calculated by DDSketch.
+const RelativeAccuracy = 0.01
+
+// Key is an identifier for a group of Redis transactions
+type Key struct {
+	// Command is the upper-cased name of the command, e.g. GET
+	Command string
+	// KeyPrefix is the prefix of the first key of the command, if any
+	KeyPrefix string
+	// Truncated is set when KeyPrefix is shorter than the key of the command
+	Truncated bool
+	types.ConnectionKey
+}
+
+// NewKey generates a new Key
+func NewKey(saddr, daddr util.Address, sport, dport uint16, command, keyPrefix string, truncated bool) Key {
+	return Key{
+		ConnectionKey: types.NewConnectionKey(saddr, daddr, sport, dport),
+		Command:       command,
+		KeyPrefix:     keyPrefix,
+		Truncated:     truncated,
+	}
+}
+
+// RequestStats stores stats for Redis requests to a particular key, by
+// whether they got an error reply
+type RequestStats struct {
+	ErrorToStats map[bool]*RequestStat
+}
+
+// NewRequestStats creates a new RequestStats object
+func NewRequestStats() *RequestStats {
+	return &RequestStats{
+		ErrorToStats: make(map[bool]*RequestStat),
+	}
+}
+
+// AddRequest records a transaction with the given latency, in nanoseconds
+func (r *RequestStats) AddRequest(isError bool, latency float64) {
+	stats, ok := r.ErrorToStats[isError]
+	if !ok {
+		stats = new(RequestStat)
+		r.ErrorToStats[isError] = stats
+	}
+	stats.AddRequest(latency)
+}
+
+// CombineWith merges the data in 2 RequestStats objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStats) CombineWith(newStats *RequestStats) {
+	for isError, newRequests := range newStats.ErrorToStats {
+		stats, ok := r.ErrorToStats[isError]
+		if !ok {
+			stats = new(RequestStat)
+			r.ErrorToStats[isError] = stats
+		}
+		stats.CombineWith(newRequests)
+	}
+}
+
+// RequestStat stores stats for Redis requests with the same outcome
+type RequestStat struct {
+	// this field order is intentional to help the GC pointer tracking
+	Latencies *ddsketch.DDSketch
+	// Count is the number of transactions, which is tracked separately from
+	// the sketch since the sketch may discard values outside of its range.
+	Count int
+	// FirstLatencySample holds the latency (in nanoseconds) of the first
+	// transaction of the bucket, to avoid creating sketches with a single value.
+	FirstLatencySample float64
+}
+
+// AddRequest records a transaction with the given latency, in nanoseconds
+func (r *RequestStat) AddRequest(latency float64) {
+	r.Count++
+	if r.Count == 1 {
+		r.FirstLatencySample = latency
+		return
+	}
+	if r.Latencies == nil {
+		if err := r.initSketch(); err != nil {
+			return
+		}
+		r.addLatency(r.FirstLatencySample)
+	}
+	r.addLatency(latency)
+}
+
+// CombineWith merges the data in 2 RequestStat objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStat) CombineWith(newStats *RequestStat) {
+	if newStats.Count == 0 {
+		return
+	}
+	if newStats.Count == 1 {
+		r.AddRequest(newStats.FirstLatencySample)
+		return
+	}
+	if r.Latencies == nil {
+		r.Latencies = newStats.Latencies.Copy()
+		if r.Count == 1 {
+			r.addLatency(r.FirstLatencySample)
+		}
+	} else if err := r.Latencies.MergeWith(newStats.Latencies); err != nil {
+		log.Debugf("error merging redis transactions: %v", err)
+	}
+	if r.Count == 0 {
+		r.FirstLatencySample = newStats.FirstLatencySample
+	}
+	r.Count += newStats.Count
+}
+func (r *RequestStat) initSketch() (err error) {
+	r.Latencies, err = ddsketch.NewDefaultDDSketch(RelativeAccuracy)
+	if err != nil {
+		log.Debugf("error recording redis transaction latency: could not create new ddsketch: %v", err)
+	}
+	return
+}
+
+func (r *RequestStat) addLatency(latency float64) {
+	if err := r.Latencies.Add(latency); err != nil {
+		log.Debugf("could not add redis transaction latency to ddsketch: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+
+package stream
+
+import "github.com/DataDog/datadog-agent/pkg/network/types"
+
+const (
+	tcpFlagFIN = 0x01
+	tcpFlagRST = 0x04
+)
+
+// ConnTuple returns the tuple of the segment, whose source is its sender.
+func (s *EbpfSegment) ConnTuple() types.ConnectionKey {
+	return types.ConnectionKey{
+		SrcIPHigh: s.Tup.Saddr_h,
+		SrcIPLow:  s.Tup.Saddr_l,
+		DstIPHigh: s.Tup.Daddr_h,
+		DstIPLow:  s.Tup.Daddr_l,
+		SrcPort:   s.Tup.Sport,
+		DstPort:   s.Tup.Dport,
+	}
+}
+
+// Segment returns a copy of the segment, as the eBPF buffers are reused.
+func (s *EbpfSegment) Segment() *Segment {
+	headLen := int(s.Payload_size)
+	if headLen > headSize {
+		headLen = headSize
+	}
+	// the captured tail overlaps the head when the payload is smaller than
+	// headSize+tailSize
+	tailLen := int(s.Payload_size) - headLen
+	if tailLen > tailSize {
+		tailLen = tailSize
+	}
+	return &Segment{
+		Conn:      s.ConnTuple(),
+		Timestamp: s.Timestamp,
+		Seq:       s.Tcp_seq,
+		Size:      s.Payload_size,
+		Head:      append([]byte(nil), s.Head[:headLen]...),
+		Tail:      append([]byte(nil), s.Tail[tailSize-tailLen:]...),
+		Closed:    s.Tcp_flags&(tcpFlagFIN|tcpFlagRST) != 0,
+	}
+}
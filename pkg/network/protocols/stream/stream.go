@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package stream rebuilds the byte streams of TCP connections from the
+// segments captured by eBPF, for protocols decoded in userspace.
+package stream
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+)
+
+// maxIdleFlushes is the number of flushes after which a connection without
+// segments is forgotten.
+const maxIdleFlushes = 10
+
+// Segment is a TCP segment of a connection, of which only the first and the
+// last bytes were captured.
+type Segment struct {
+	// Conn is the tuple of the segment, whose source is its sender.
+	Conn types.ConnectionKey
+	// Timestamp is the monotonic time of the capture, in nanoseconds.
+	Timestamp uint64
+	Seq       uint32
+	// Size is the size of the whole payload of the segment.
+	Size uint32
+	Head []byte
+	// Tail holds the last bytes of the payload which are not in Head.
+	Tail []byte
+	// Closed is set when the segment terminates the connection.
+	Closed bool
+}
+
+// Data is a part of the byte stream of a connection, passed to its decoder.
+type Data struct {
+	// FromClient is set for the bytes sent by the client of the connection.
+	FromClient bool
+	// Gap is the number of bytes of the stream which were missed before Head.
+	Gap  int
+	Head []byte
+	// Skipped is the number of bytes between Head and Tail which were not
+	// captured.
+	Skipped   int
+	Tail      []byte
+	Timestamp time.Time
+}
+
+// Decoder decodes the byte stream of a connection.
+type Decoder interface {
+	// Decode decodes the next bytes of the connection. Once an error is
+	// returned, the following bytes of the connection are ignored.
+	Decode(d *Data) error
+}
+
+type direction struct {
+	seen    bool
+	nextSeq uint32
+}
+
+type connection struct {
+	// client is the tuple of the connection whose source is the client
+	client  types.ConnectionKey
+	decoder Decoder
+	failed  bool
+	closed  bool
+	// dirs holds the state of the bytes sent by the client, then by the server
+	dirs      [2]direction
+	lastFlush uint64
+}
+
+// Reassembler buffers the segments of connections, and feeds their decoders
+// with the bytes of each connection in order. It is safe for concurrent use.
+type Reassembler struct {
+	mux sync.Mutex
+	// flushMux serializes the flushes, which decode segments without holding
+	// mux so that segments can still be added
+	flushMux    sync.Mutex
+	isClient    func(head []byte) bool
+	newDecoder  func(client types.ConnectionKey) Decoder
+	maxSegments int
+	segments    []*Segment
+	conns       map[types.ConnectionKey]*connection
+	flushes     uint64
+}
+
+// NewReassembler returns a Reassembler buffering up to maxSegments segments
+// between flushes. The client of a connection is the sender of its first
+// segment for which isClient returns true, the segments seen before are
+// ignored. newDecoder returns the decoder of a connection given its tuple
+// whose source is the client.
+func NewReassembler(isClient func(head []byte) bool, newDecoder func(client types.ConnectionKey) Decoder, maxSegments int) *Reassembler {
+	return &Reassembler{
+		isClient:    isClient,
+		newDecoder:  newDecoder,
+		maxSegments: maxSegments,
+		conns:       make(map[types.ConnectionKey]*connection),
+	}
+}
+
+// Add buffers the segment s until the next flush. It returns false if the
+// segment was dropped because the buffer is full.
+func (r *Reassembler) Add(s *Segment) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.segments) >= r.maxSegments {
+		return false
+	}
+	r.segments = append(r.segments, s)
+	return true
+}
+
+// Flush feeds the decoders with the segments buffered since the last flush.
+func (r *Reassembler) Flush() {
+	r.flushMux.Lock()
+	defer r.flushMux.Unlock()
+
+	r.mux.Lock()
+	segments := r.segments
+	r.segments = make([]*Segment, 0, len(segments))
+	r.mux.Unlock()
+
+	// the segments captured on different CPUs are not read in order
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].Timestamp < segments[j].Timestamp
+	})
+
+	r.flushes++
+	for _, s := range segments {
+		r.process(s)
+	}
+	for key, c := range r.conns {
+		if c.closed || r.flushes-c.lastFlush > maxIdleFlushes {
+			delete(r.conns, key)
+		}
+	}
+}
+
+func (r *Reassembler) process(s *Segment) {
+	key := canonicalKey(s.Conn)
+	c, ok := r.conns[key]
+	if !ok {
+		c = new(connection)
+		r.conns[key] = c
+	}
+	c.lastFlush = r.flushes
+	if s.Closed {
+		c.closed = true
+	}
+	if c.failed || s.Size == 0 {
+		return
+	}
+	if c.decoder == nil {
+		if !r.isClient(s.Head) {
+			return
+		}
+		c.client = s.Conn
+		c.decoder = r.newDecoder(s.Conn)
+	}
+
+	d := Data{
+		FromClient: s.Conn == c.client,
+		Head:       s.Head,
+		Skipped:    int(s.Size) - len(s.Head) - len(s.Tail),
+		Tail:       s.Tail,
+		Timestamp:  time.Unix(0, int64(s.Timestamp)),
+	}
+	dir := &c.dirs[0]
+	if !d.FromClient {
+		dir = &c.dirs[1]
+	}
+	end := s.Seq + s.Size
+	if dir.seen {
+		if int32(end-dir.nextSeq) <= 0 {
+			// retransmission of bytes already decoded
+			return
+		}
+		if diff := int32(s.Seq - dir.nextSeq); diff > 0 {
+			d.Gap = int(diff)
+		} else {
+			d.trim(int(-diff))
+		}
+	}
+	dir.seen = true
+	dir.nextSeq = end
+
+	if err := c.decoder.Decode(&d); err != nil {
+		c.failed = true
+		c.decoder = nil
+	}
+}
+
+// trim removes the first n bytes of the data, which were already decoded.
+func (d *Data) trim(n int) {
+	if n <= len(d.Head) {
+		d.Head = d.Head[n:]
+		return
+	}
+	n -= len(d.Head)
+	d.Head = nil
+	if n <= d.Skipped {
+		d.Skipped -= n
+		return
+	}
+	n -= d.Skipped
+	d.Skipped = 0
+	d.Tail = d.Tail[n:]
+}
+
+// canonicalKey returns the same key for both directions of a connection.
+func canonicalKey(k types.ConnectionKey) types.ConnectionKey {
+	if k.SrcIPHigh > k.DstIPHigh ||
+		k.SrcIPHigh == k.DstIPHigh && (k.SrcIPLow > k.DstIPLow ||
+			k.SrcIPLow == k.DstIPLow && k.SrcPort > k.DstPort) {
+		return reverse(k)
+	}
+	return k
+}
+
+func reverse(k types.ConnectionKey) types.ConnectionKey {
+	return types.ConnectionKey{
+		SrcIPHigh: k.DstIPHigh,
+		SrcIPLow:  k.DstIPLow,
+		DstIPHigh: k.SrcIPHigh,
+		DstIPLow:  k.SrcIPLow,
+		SrcPort:   k.DstPort,
+		DstPort:   k.SrcPort,
+	}
+}
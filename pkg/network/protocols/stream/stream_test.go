@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+)
+
+var (
+	client = types.ConnectionKey{SrcIPLow: 2, DstIPLow: 1, SrcPort: 40000, DstPort: 5432}
+	server = reverse(client)
+)
+
+type recorder struct {
+	data []Data
+	err  error
+}
+
+func (r *recorder) Decode(d *Data) error {
+	r.data = append(r.data, *d)
+	return r.err
+}
+
+type fixture struct {
+	r        *Reassembler
+	decoders map[types.ConnectionKey]*recorder
+}
+
+func newFixture(maxSegments int) *fixture {
+	f := &fixture{decoders: make(map[types.ConnectionKey]*recorder)}
+	f.r = NewReassembler(
+		func(head []byte) bool { return bytes.HasPrefix(head, []byte("Q")) },
+		func(conn types.ConnectionKey) Decoder {
+			f.decoders[conn] = new(recorder)
+			return f.decoders[conn]
+		},
+		maxSegments,
+	)
+	return f
+}
+
+func segment(conn types.ConnectionKey, ts uint64, seq uint32, payload string) *Segment {
+	return &Segment{Conn: conn, Timestamp: ts, Seq: seq, Size: uint32(len(payload)), Head: []byte(payload)}
+}
+
+func TestReassembler(t *testing.T) {
+	f := newFixture(100)
+
+	// segments are processed in order of capture
+	f.r.Add(segment(server, 4, 500, "response"))
+	f.r.Add(segment(server, 1, 100, "ignored"))
+	f.r.Add(segment(client, 3, 1000, "Query"))
+	f.r.Flush()
+
+	dec := f.decoders[client]
+	require.NotNil(t, dec)
+	require.Len(t, dec.data, 2)
+	assert.True(t, dec.data[0].FromClient)
+	assert.Equal(t, "Query", string(dec.data[0].Head))
+	assert.Equal(t, int64(3), dec.data[0].Timestamp.UnixNano())
+	assert.False(t, dec.data[1].FromClient)
+	assert.Equal(t, "response", string(dec.data[1].Head))
+
+	// a retransmission, a partial retransmission and a gap
+	dec.data = nil
+	f.r.Add(segment(client, 5, 1000, "Query"))
+	f.r.Add(segment(client, 6, 1003, "ryQuery"))
+	f.r.Add(segment(server, 7, 518, "response"))
+	f.r.Flush()
+
+	require.Len(t, dec.data, 2)
+	assert.Equal(t, "Query", string(dec.data[0].Head))
+	assert.Equal(t, 0, dec.data[0].Gap)
+	assert.Equal(t, "response", string(dec.data[1].Head))
+	assert.Equal(t, 10, dec.data[1].Gap)
+}
+
+func TestReassemblerPartialSegment(t *testing.T) {
+	f := newFixture(100)
+
+	f.r.Add(&Segment{Conn: client, Timestamp: 1, Seq: 0, Size: 100, Head: []byte("Q12345"), Tail: []byte("6789")})
+	// retransmission of the last 50 bytes, followed by 10 new bytes
+	f.r.Add(&Segment{Conn: client, Timestamp: 2, Seq: 50, Size: 60, Head: []byte("abcdef"), Tail: []byte("ghij")})
+	f.r.Flush()
+
+	dec := f.decoders[client]
+	require.Len(t, dec.data, 2)
+	assert.Equal(t, 90, dec.data[0].Skipped)
+	assert.Empty(t, dec.data[1].Head)
+	assert.Equal(t, 6, dec.data[1].Skipped)
+	assert.Equal(t, "ghij", string(dec.data[1].Tail))
+}
+
+func TestReassemblerDecoderError(t *testing.T) {
+	f := newFixture(100)
+
+	f.r.Add(segment(client, 1, 0, "Query"))
+	f.r.Flush()
+	dec := f.decoders[client]
+	dec.err = errors.New("invalid")
+
+	f.r.Add(segment(server, 2, 0, "response"))
+	f.r.Add(segment(client, 3, 5, "Query"))
+	f.r.Flush()
+	assert.Len(t, dec.data, 2)
+}
+
+func TestReassemblerFull(t *testing.T) {
+	f := newFixture(1)
+
+	assert.True(t, f.r.Add(segment(client, 1, 0, "Query")))
+	assert.False(t, f.r.Add(segment(client, 2, 5, "Query")))
+	f.r.Flush()
+	assert.True(t, f.r.Add(segment(client, 3, 10, "Query")))
+}
+
+func TestReassemblerExpiration(t *testing.T) {
+	f := newFixture(100)
+
+	f.r.Add(segment(client, 1, 0, "Query"))
+	f.r.Flush()
+	require.Len(t, f.r.conns, 1)
+
+	closing := segment(server, 2, 0, "")
+	closing.Closed = true
+	f.r.Add(closing)
+	f.r.Flush()
+	assert.Empty(t, f.r.conns)
+
+	f.r.Add(segment(client, 3, 0, "Query"))
+	for i := 0; i <= maxIdleFlushes; i++ {
+		f.r.Flush()
+		assert.Len(t, f.r.conns, 1)
+	}
+	f.r.Flush()
+	assert.Empty(t, f.r.conns)
+}
@@ -0,0 +1,23 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build ignore
+
+package stream
+
+/*
+#include "../../ebpf/c/conn_tuple.h"
+#include "../../ebpf/c/protocols/stream/types.h"
+*/
+import "C"
+
+type ConnTuple C.conn_tuple_t
+
+type EbpfSegment C.stream_segment_t
+
+const (
+	headSize = C.STREAM_HEAD_SIZE
+	tailSize = C.STREAM_TAIL_SIZE
+)
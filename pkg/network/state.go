@@ -18,6 +18,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/kafka"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/postgres"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/redis"
 	"github.com/DataDog/datadog-agent/pkg/network/slice"
 	nettelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
@@ -40,6 +42,8 @@ var stateTelemetry = struct {
 	httpStatsDropped       *nettelemetry.StatCounterWrapper
 	http2StatsDropped      *nettelemetry.StatCounterWrapper
 	kafkaStatsDropped      *nettelemetry.StatCounterWrapper
+	postgresStatsDropped   *nettelemetry.StatCounterWrapper
+	redisStatsDropped      *nettelemetry.StatCounterWrapper
 	dnsPidCollisions       *nettelemetry.StatCounterWrapper
 	incomingDirectionFixes telemetry.Counter
 	outgoingDirectionFixes telemetry.Counter
@@ -53,6 +57,8 @@ var stateTelemetry = struct {
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "http_stats_dropped", []string{}, "Counter measuring the number of http stats dropped"),
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "http2_stats_dropped", []string{}, "Counter measuring the number of http2 stats dropped"),
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "kafka_stats_dropped", []string{}, "Counter measuring the number of kafka stats dropped"),
+	nettelemetry.NewStatCounterWrapper(stateModuleName, "postgres_stats_dropped", []string{}, "Counter measuring the number of postgres stats dropped"),
+	nettelemetry.NewStatCounterWrapper(stateModuleName, "redis_stats_dropped", []string{}, "Counter measuring the number of redis stats dropped"),
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "dns_pid_collisions", []string{}, "Counter measuring the number of DNS PID collisions"),
 	telemetry.NewCounter(stateModuleName, "incoming_direction_fixes", []string{}, "Counter measuring the number of udp direction fixes for incoming connections"),
 	telemetry.NewCounter(stateModuleName, "outgoing_direction_fixes", []string{}, "Counter measuring the number of udp/tcp direction fixes for outgoing connections"),
@@ -121,6 +127,8 @@ type Delta struct {
 	HTTP     map[http.Key]*http.RequestStats
 	HTTP2    map[http.Key]*http.RequestStats
 	Kafka    map[kafka.Key]*kafka.RequestStat
+	Postgres map[postgres.Key]*postgres.RequestStat
+	Redis    map[redis.Key]*redis.RequestStats
 	DNSStats dns.StatsByKeyByNameByType
 }
 
@@ -134,6 +142,8 @@ type lastStateTelemetry struct {
 	httpStatsDropped      int64
 	http2StatsDropped     int64
 	kafkaStatsDropped     int64
+	postgresStatsDropped  int64
+	redisStatsDropped     int64
 	dnsPidCollisions      int64
 }
 
@@ -230,11 +240,13 @@ type client struct {
 	closed    *closedConnections
 	stats     map[StatCookie]StatCounters
 	// maps by dns key the domain (string) to stats structure
-	dnsStats        dns.StatsByKeyByNameByType
-	httpStatsDelta  map[http.Key]*http.RequestStats
-	http2StatsDelta map[http.Key]*http.RequestStats
-	kafkaStatsDelta map[kafka.Key]*kafka.RequestStat
-	lastTelemetries map[ConnTelemetryType]int64
+	dnsStats           dns.StatsByKeyByNameByType
+	httpStatsDelta     map[http.Key]*http.RequestStats
+	http2StatsDelta    map[http.Key]*http.RequestStats
+	kafkaStatsDelta    map[kafka.Key]*kafka.RequestStat
+	postgresStatsDelta map[postgres.Key]*postgres.RequestStat
+	redisStatsDelta    map[redis.Key]*redis.RequestStats
+	lastTelemetries    map[ConnTelemetryType]int64
 }
 
 func (c *client) Reset() {
@@ -249,6 +261,8 @@ func (c *client) Reset() {
 	c.httpStatsDelta = make(map[http.Key]*http.RequestStats)
 	c.http2StatsDelta = make(map[http.Key]*http.RequestStats)
 	c.kafkaStatsDelta = make(map[kafka.Key]*kafka.RequestStat)
+	c.postgresStatsDelta = make(map[postgres.Key]*postgres.RequestStat)
+	c.redisStatsDelta = make(map[redis.Key]*redis.RequestStats)
 }
 
 type networkState struct {
@@ -261,26 +275,30 @@ type networkState struct {
 	latestTimeEpoch uint64
 
 	// Network state configuration
-	clientExpiry   time.Duration
-	maxClosedConns uint32
-	maxClientStats int
-	maxDNSStats    int
-	maxHTTPStats   int
-	maxKafkaStats  int
+	clientExpiry     time.Duration
+	maxClosedConns   uint32
+	maxClientStats   int
+	maxDNSStats      int
+	maxHTTPStats     int
+	maxKafkaStats    int
+	maxPostgresStats int
+	maxRedisStats    int
 
 	mergeStatsBuffers [2][]byte
 }
 
 // NewState creates a new network state
-func NewState(clientExpiry time.Duration, maxClosedConns uint32, maxClientStats int, maxDNSStats int, maxHTTPStats int, maxKafkaStats int) State {
+func NewState(clientExpiry time.Duration, maxClosedConns uint32, maxClientStats int, maxDNSStats int, maxHTTPStats int, maxKafkaStats int, maxPostgresStats int, maxRedisStats int) State {
 	return &networkState{
-		clients:        map[string]*client{},
-		clientExpiry:   clientExpiry,
-		maxClosedConns: maxClosedConns,
-		maxClientStats: maxClientStats,
-		maxDNSStats:    maxDNSStats,
-		maxHTTPStats:   maxHTTPStats,
-		maxKafkaStats:  maxKafkaStats,
+		clients:          map[string]*client{},
+		clientExpiry:     clientExpiry,
+		maxClosedConns:   maxClosedConns,
+		maxClientStats:   maxClientStats,
+		maxDNSStats:      maxDNSStats,
+		maxHTTPStats:     maxHTTPStats,
+		maxKafkaStats:    maxKafkaStats,
+		maxPostgresStats: maxPostgresStats,
+		maxRedisStats:    maxRedisStats,
 		mergeStatsBuffers: [2][]byte{
 			make([]byte, ConnectionByteKeyMaxLen),
 			make([]byte, ConnectionByteKeyMaxLen),
@@ -380,6 +398,12 @@ func (ns *networkState) GetDelta(
 		case protocols.HTTP2:
 			stats := protocolStats.(map[http.Key]*http.RequestStats)
 			ns.storeHTTP2Stats(stats)
+		case protocols.Postgres:
+			stats := protocolStats.(map[postgres.Key]*postgres.RequestStat)
+			ns.storePostgresStats(stats)
+		case protocols.Redis:
+			stats := protocolStats.(map[redis.Key]*redis.RequestStats)
+			ns.storeRedisStats(stats)
 		}
 	}
 
@@ -389,6 +413,8 @@ func (ns *networkState) GetDelta(
 		HTTP2:    client.http2StatsDelta,
 		DNSStats: client.dnsStats,
 		Kafka:    client.kafkaStatsDelta,
+		Postgres: client.postgresStatsDelta,
+		Redis:    client.redisStatsDelta,
 	}
 }
 
@@ -441,11 +467,14 @@ func (ns *networkState) logTelemetry() {
 	httpStatsDroppedDelta := stateTelemetry.httpStatsDropped.Load() - ns.lastTelemetry.httpStatsDropped
 	http2StatsDroppedDelta := stateTelemetry.http2StatsDropped.Load() - ns.lastTelemetry.http2StatsDropped
 	kafkaStatsDroppedDelta := stateTelemetry.kafkaStatsDropped.Load() - ns.lastTelemetry.kafkaStatsDropped
+	postgresStatsDroppedDelta := stateTelemetry.postgresStatsDropped.Load() - ns.lastTelemetry.postgresStatsDropped
+	redisStatsDroppedDelta := stateTelemetry.redisStatsDropped.Load() - ns.lastTelemetry.redisStatsDropped
 	dnsPidCollisionsDelta := stateTelemetry.dnsPidCollisions.Load() - ns.lastTelemetry.dnsPidCollisions
 
 	// Flush log line if any metric is non-zero
 	if connDroppedDelta > 0 || closedConnDroppedDelta > 0 || dnsStatsDroppedDelta > 0 ||
-		httpStatsDroppedDelta > 0 || http2StatsDroppedDelta > 0 || kafkaStatsDroppedDelta > 0 {
+		httpStatsDroppedDelta > 0 || http2StatsDroppedDelta > 0 || kafkaStatsDroppedDelta > 0 ||
+		postgresStatsDroppedDelta > 0 || redisStatsDroppedDelta > 0 {
 		s := "State telemetry: "
 		s += " [%d connections dropped due to stats]"
 		s += " [%d closed connections dropped]"
@@ -453,6 +482,8 @@ func (ns *networkState) logTelemetry() {
 		s += " [%d HTTP stats dropped]"
 		s += " [%d HTTP2 stats dropped]"
 		s += " [%d Kafka stats dropped]"
+		s += " [%d Postgres stats dropped]"
+		s += " [%d Redis stats dropped]"
 		log.Warnf(s,
 			connDroppedDelta,
 			closedConnDroppedDelta,
@@ -460,6 +491,8 @@ func (ns *networkState) logTelemetry() {
 			httpStatsDroppedDelta,
 			http2StatsDroppedDelta,
 			kafkaStatsDroppedDelta,
+			postgresStatsDroppedDelta,
+			redisStatsDroppedDelta,
 		)
 	}
 
@@ -488,6 +521,8 @@ func (ns *networkState) logTelemetry() {
 	ns.lastTelemetry.httpStatsDropped = stateTelemetry.httpStatsDropped.Load()
 	ns.lastTelemetry.http2StatsDropped = stateTelemetry.http2StatsDropped.Load()
 	ns.lastTelemetry.kafkaStatsDropped = stateTelemetry.kafkaStatsDropped.Load()
+	ns.lastTelemetry.postgresStatsDropped = stateTelemetry.postgresStatsDropped.Load()
+	ns.lastTelemetry.redisStatsDropped = stateTelemetry.redisStatsDropped.Load()
 	ns.lastTelemetry.dnsPidCollisions = stateTelemetry.dnsPidCollisions.Load()
 }
 
@@ -729,20 +764,84 @@ func (ns *networkState) storeKafkaStats(allStats map[kafka.Key]*kafka.RequestSta
 	}
 }
 
+// storePostgresStats stores the latest Postgres stats for all clients
+func (ns *networkState) storePostgresStats(allStats map[postgres.Key]*postgres.RequestStat) {
+	if len(ns.clients) == 1 {
+		for _, client := range ns.clients {
+			if len(client.postgresStatsDelta) == 0 && len(allStats) <= ns.maxPostgresStats {
+				// optimization for the common case:
+				// if there is only one client and no previous state, no memory allocation is needed
+				client.postgresStatsDelta = allStats
+				return
+			}
+		}
+	}
+
+	for key, stats := range allStats {
+		for _, client := range ns.clients {
+			prevStats, ok := client.postgresStatsDelta[key]
+			if !ok && len(client.postgresStatsDelta) >= ns.maxPostgresStats {
+				stateTelemetry.postgresStatsDropped.Inc()
+				continue
+			}
+
+			if prevStats != nil {
+				prevStats.CombineWith(stats)
+				client.postgresStatsDelta[key] = prevStats
+			} else {
+				client.postgresStatsDelta[key] = stats
+			}
+		}
+	}
+}
+
+// storeRedisStats stores the latest Redis stats for all clients
+func (ns *networkState) storeRedisStats(allStats map[redis.Key]*redis.RequestStats) {
+	if len(ns.clients) == 1 {
+		for _, client := range ns.clients {
+			if len(client.redisStatsDelta) == 0 && len(allStats) <= ns.maxRedisStats {
+				// optimization for the common case:
+				// if there is only one client and no previous state, no memory allocation is needed
+				client.redisStatsDelta = allStats
+				return
+			}
+		}
+	}
+
+	for key, stats := range allStats {
+		for _, client := range ns.clients {
+			prevStats, ok := client.redisStatsDelta[key]
+			if !ok && len(client.redisStatsDelta) >= ns.maxRedisStats {
+				stateTelemetry.redisStatsDropped.Inc()
+				continue
+			}
+
+			if prevStats != nil {
+				prevStats.CombineWith(stats)
+				client.redisStatsDelta[key] = prevStats
+			} else {
+				client.redisStatsDelta[key] = stats
+			}
+		}
+	}
+}
+
 func (ns *networkState) getClient(clientID string) *client {
 	if c, ok := ns.clients[clientID]; ok {
 		return c
 	}
 	closedConnections := &closedConnections{conns: make([]ConnectionStats, 0, minClosedCapacity), byCookie: make(map[StatCookie]int)}
 	c := &client{
-		lastFetch:       time.Now(),
-		stats:           make(map[StatCookie]StatCounters),
-		closed:          closedConnections,
-		dnsStats:        dns.StatsByKeyByNameByType{},
-		httpStatsDelta:  map[http.Key]*http.RequestStats{},
-		http2StatsDelta: map[http.Key]*http.RequestStats{},
-		kafkaStatsDelta: map[kafka.Key]*kafka.RequestStat{},
-		lastTelemetries: make(map[ConnTelemetryType]int64),
+		lastFetch:          time.Now(),
+		stats:              make(map[StatCookie]StatCounters),
+		closed:             closedConnections,
+		dnsStats:           dns.StatsByKeyByNameByType{},
+		httpStatsDelta:     map[http.Key]*http.RequestStats{},
+		http2StatsDelta:    map[http.Key]*http.RequestStats{},
+		kafkaStatsDelta:    map[kafka.Key]*kafka.RequestStat{},
+		postgresStatsDelta: map[postgres.Key]*postgres.RequestStat{},
+		redisStatsDelta:    map[redis.Key]*redis.RequestStats{},
+		lastTelemetries:    make(map[ConnTelemetryType]int64),
 	}
 	ns.clients[clientID] = c
 	return c
@@ -77,6 +77,8 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		config.MaxDNSStatsBuffered,
 		config.MaxHTTPStatsBuffered,
 		config.MaxKafkaStatsBuffered,
+		config.MaxPostgresStatsBuffered,
+		config.MaxRedisStatsBuffered,
 	)
 
 	reverseDNS := dns.NewNullReverseDNS()
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"strings"
+)
+
+// ObfuscateCQLString obfuscates and quantizes the given Cassandra CQL query.
+// Literals are replaced with "?", collection literals with a single "?" and
+// lists of values, such as the operands of IN, are collapsed to "( ? )", so
+// that queries only differing by their values are identical once obfuscated.
+// Bind markers are kept and comments are removed.
+func (o *Obfuscator) ObfuscateCQLString(query string) (string, error) {
+	tok := newCQLTokenizer(query)
+	var (
+		out    []string
+		parens []int // indexes in out of the opening parentheses
+	)
+	for {
+		kind, text, err := tok.scan()
+		if err != nil {
+			return "", err
+		}
+		switch kind {
+		case cqlEOF:
+			return joinCQLTokens(out), nil
+		case cqlString, cqlNumber, cqlUUID:
+			text = "?"
+		case cqlIdent:
+			if o.obfuscatesCQLKeyword(text) {
+				text = "?"
+			}
+		case cqlOperator:
+			switch {
+			case text == "{", text == "[" && !cqlSubscripts(out):
+				// collection literals are obfuscated as a single value
+				if err := skipCQLCollection(tok, text); err != nil {
+					return "", err
+				}
+				text = "?"
+			case text == "(":
+				parens = append(parens, len(out))
+			case text == ")" && len(parens) > 0:
+				i := parens[len(parens)-1]
+				parens = parens[:len(parens)-1]
+				if isCQLValueList(out[i+1:]) {
+					out = append(out[:i+1], "?")
+				}
+			}
+		}
+		out = append(out, text)
+	}
+}
+
+// obfuscatesCQLKeyword reports whether the given keyword is a literal that
+// should be obfuscated.
+func (o *Obfuscator) obfuscatesCQLKeyword(kw string) bool {
+	switch strings.ToLower(kw) {
+	case "true", "false":
+		return !o.opts.CQL.KeepBoolean
+	case "null":
+		return !o.opts.CQL.KeepNull
+	case "nan", "infinity":
+		return true
+	}
+	return false
+}
+
+// cqlSubscripts reports whether an opening bracket following the given tokens
+// is a subscript, as in m['key'], rather than a list literal.
+func cqlSubscripts(out []string) bool {
+	if len(out) == 0 {
+		return false
+	}
+	last := out[len(out)-1]
+	return last == "]" || last != "?" && (isCQLIdentStart(last[0]) || last[0] == '"')
+}
+
+// skipCQLCollection skips the content of a collection literal, up to the
+// bracket closing open.
+func skipCQLCollection(tok *cqlTokenizer, open string) error {
+	depth := 1
+	for depth > 0 {
+		kind, text, err := tok.scan()
+		if err != nil {
+			return err
+		}
+		switch {
+		case kind == cqlEOF:
+			return errors.New("cql: unterminated collection literal " + open)
+		case kind != cqlOperator:
+		case text == "{", text == "[", text == "(":
+			depth++
+		case text == "}", text == "]", text == ")":
+			depth--
+		}
+	}
+	return nil
+}
+
+// isCQLValueList reports whether tokens is a comma-separated list of "?".
+func isCQLValueList(tokens []string) bool {
+	if len(tokens)%2 == 0 {
+		return false
+	}
+	for i, t := range tokens {
+		if i%2 == 0 && t != "?" || i%2 == 1 && t != "," {
+			return false
+		}
+	}
+	return true
+}
+
+// joinCQLTokens joins the tokens with single spaces, except around dots and
+// before commas and semicolons.
+func joinCQLTokens(tokens []string) string {
+	var sb strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t != "," && t != ";" && t != "." && tokens[i-1] != "." {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t)
+	}
+	return sb.String()
+}
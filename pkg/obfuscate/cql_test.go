@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObfuscateCQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			"SELECT * FROM ks.users WHERE id = 123 AND name = 'bob' -- comment",
+			"SELECT * FROM ks.users WHERE id = ? AND name = ?",
+		},
+		{
+			"INSERT INTO t (a, b, c) VALUES (1, 'x''y', 123e4567-e89b-12d3-a456-426614174000) USING TTL 86400;",
+			"INSERT INTO t ( a, b, c ) VALUES ( ? ) USING TTL ?;",
+		},
+		{
+			"UPDATE t SET m['k'] = 'v', l = l + [1, 2], s = {'a', 'b'}, u = {name: 'x', tags: [1]}, c = c - 1 WHERE id IN (1, 2, 3)",
+			"UPDATE t SET m [ ? ] = ?, l = l + ?, s = ?, u = ?, c = c - ? WHERE id IN ( ? )",
+		},
+		{
+			"SELECT * FROM t WHERE id = ? AND n = :name AND b = 0xCAFE AND d > 1h30m AND f = -1.5e-3 LIMIT 10",
+			"SELECT * FROM t WHERE id = ? AND n = :name AND b = ? AND d > ? AND f = ? LIMIT ?",
+		},
+		{
+			"SELECT * FROM t WHERE id IN (?, ?, ?) AND flag = true AND v != null",
+			"SELECT * FROM t WHERE id IN ( ? ) AND flag = ? AND v != ?",
+		},
+		{
+			`SELECT "Name", count(*) FROM t WHERE (a, b) IN ((1, 2), (3, 4)) AND k = $$multi
+line$$ /* comment */`,
+			`SELECT "Name", count ( * ) FROM t WHERE ( a, b ) IN ( ( ? ), ( ? ) ) AND k = ?`,
+		},
+		{
+			"BEGIN BATCH\n  INSERT INTO t (a) VALUES (1);\n  INSERT INTO t (a) VALUES (2);\nAPPLY BATCH",
+			"BEGIN BATCH INSERT INTO t ( a ) VALUES ( ? ); INSERT INTO t ( a ) VALUES ( ? ); APPLY BATCH",
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			o := NewObfuscator(Config{})
+			out, err := o.ObfuscateCQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+
+			// obfuscated queries are stable
+			again, err := o.ObfuscateCQLString(out)
+			require.NoError(t, err)
+			assert.Equal(t, out, again)
+		})
+	}
+}
+
+func TestObfuscateCQLKeepLiterals(t *testing.T) {
+	o := NewObfuscator(Config{CQL: CQLConfig{KeepNull: true, KeepBoolean: true}})
+	out, err := o.ObfuscateCQLString("SELECT * FROM t WHERE a = NULL AND b = false AND c = 'x'")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = NULL AND b = false AND c = ?", out)
+}
+
+func TestObfuscateCQLErrors(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, in := range []string{
+		"SELECT * FROM t WHERE a = 'truncated...",
+		"UPDATE t SET s = {'a', 'b'",
+	} {
+		_, err := o.ObfuscateCQLString(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func FuzzObfuscateCQL(f *testing.F) {
+	f.Add("SELECT * FROM ks.users WHERE id = 123 AND name = 'bob'")
+	f.Add("INSERT INTO t (a, b) VALUES (1, {'k': [1, 2]}) USING TTL 86400")
+	f.Add("UPDATE t SET m['k'] = -1 WHERE id IN (?, ?) AND u = 123e4567-e89b-12d3-a456-426614174000")
+	f.Add("SELECT * FROM t WHERE k = $$a$$ /* comment */ AND b = 0xCAFE")
+
+	o := NewObfuscator(Config{})
+	f.Fuzz(func(t *testing.T, query string) {
+		out, err := o.ObfuscateCQLString(query)
+		if err != nil {
+			return
+		}
+		again, err := o.ObfuscateCQLString(out)
+		if err != nil {
+			t.Fatalf("failed to obfuscate the obfuscated query %q: %v", out, err)
+		}
+		if again != out {
+			t.Fatalf("obfuscating %q twice returned %q", out, again)
+		}
+	})
+}
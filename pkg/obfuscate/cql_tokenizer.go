@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"strings"
+)
+
+// cqlTokenKind specifies the kind of a token returned by the CQL tokenizer.
+type cqlTokenKind int
+
+const (
+	// cqlEOF is returned once the whole query was scanned.
+	cqlEOF cqlTokenKind = iota
+
+	// cqlIdent is a keyword or an unquoted identifier.
+	cqlIdent
+
+	// cqlQuotedIdent is a double-quoted identifier.
+	cqlQuotedIdent
+
+	// cqlString is a single-quoted or a $$-quoted string.
+	cqlString
+
+	// cqlNumber is an integer, a float, a duration (e.g. 1h30m) or a blob
+	// (e.g. 0xcafe).
+	cqlNumber
+
+	// cqlUUID is a UUID or a TimeUUID constant.
+	cqlUUID
+
+	// cqlBindMarker is a positional (?) or named (:name) bind marker.
+	cqlBindMarker
+
+	// cqlOperator is any other character or operator.
+	cqlOperator
+)
+
+// String implements fmt.Stringer.
+func (k cqlTokenKind) String() string {
+	return map[cqlTokenKind]string{
+		cqlEOF:         "EOF",
+		cqlIdent:       "identifier",
+		cqlQuotedIdent: "quoted identifier",
+		cqlString:      "string",
+		cqlNumber:      "number",
+		cqlUUID:        "uuid",
+		cqlBindMarker:  "bind marker",
+		cqlOperator:    "operator",
+	}[k]
+}
+
+var (
+	errCQLUnterminatedString  = errors.New("cql: unterminated string")
+	errCQLUnterminatedComment = errors.New("cql: unterminated comment")
+)
+
+// cqlTokenizer tokenizes a CQL query. Spaces and comments are skipped.
+type cqlTokenizer struct {
+	s    string
+	pos  int
+	prev cqlTokenKind // kind of the last token returned
+	last string       // text of the last token returned
+}
+
+// newCQLTokenizer returns a new tokenizer for the given query.
+func newCQLTokenizer(s string) *cqlTokenizer {
+	return &cqlTokenizer{s: s, prev: cqlEOF}
+}
+
+// scan returns the kind and the text of the next token. It returns cqlEOF at
+// the end of the query.
+func (t *cqlTokenizer) scan() (cqlTokenKind, string, error) {
+	if err := t.skipSpacesAndComments(); err != nil {
+		return cqlEOF, "", err
+	}
+	if t.pos >= len(t.s) {
+		return cqlEOF, "", nil
+	}
+	start := t.pos
+	kind, err := t.scanToken()
+	if err != nil {
+		return cqlEOF, "", err
+	}
+	t.prev, t.last = kind, t.s[start:t.pos]
+	return kind, t.last, nil
+}
+
+func (t *cqlTokenizer) scanToken() (cqlTokenKind, error) {
+	ch := t.s[t.pos]
+	switch {
+	case isCQLUUID(t.s[t.pos:]):
+		t.pos += 36
+		return cqlUUID, nil
+	case isCQLIdentStart(ch):
+		t.skipWhile(isCQLIdentChar)
+		return cqlIdent, nil
+	case isDigit(rune(ch)), ch == '-' && t.signsNumber():
+		t.scanNumber()
+		return cqlNumber, nil
+	case ch == '\'':
+		return cqlString, t.scanQuoted('\'')
+	case ch == '"':
+		return cqlQuotedIdent, t.scanQuoted('"')
+	case ch == '$' && strings.HasPrefix(t.s[t.pos:], "$$"):
+		end := strings.Index(t.s[t.pos+2:], "$$")
+		if end < 0 {
+			return cqlEOF, errCQLUnterminatedString
+		}
+		t.pos += end + 4
+		return cqlString, nil
+	case ch == '?':
+		t.pos++
+		return cqlBindMarker, nil
+	case ch == ':' && t.pos+1 < len(t.s) && isCQLIdentStart(t.s[t.pos+1]):
+		t.pos++
+		t.skipWhile(isCQLIdentChar)
+		return cqlBindMarker, nil
+	}
+	if t.pos+1 < len(t.s) {
+		switch t.s[t.pos : t.pos+2] {
+		case "<=", ">=", "!=", "+=", "-=":
+			t.pos += 2
+			return cqlOperator, nil
+		}
+	}
+	t.pos++
+	return cqlOperator, nil
+}
+
+// signsNumber reports whether the '-' at the current position is the sign of
+// a number, rather than a subtraction.
+func (t *cqlTokenizer) signsNumber() bool {
+	if t.pos+1 >= len(t.s) || !isDigit(rune(t.s[t.pos+1])) {
+		return false
+	}
+	switch t.prev {
+	case cqlIdent, cqlQuotedIdent, cqlNumber, cqlString, cqlUUID, cqlBindMarker:
+		return false
+	case cqlOperator:
+		return t.last != ")" && t.last != "]" && t.last != "}"
+	}
+	return true
+}
+
+// scanNumber scans a number, including its sign, exponent and unit suffixes.
+func (t *cqlTokenizer) scanNumber() {
+	if t.s[t.pos] == '-' {
+		t.pos++
+	}
+	for t.pos < len(t.s) {
+		ch := t.s[t.pos]
+		switch {
+		case isCQLIdentChar(ch), ch == '.':
+			t.pos++
+		case (ch == '+' || ch == '-') && (t.s[t.pos-1] == 'e' || t.s[t.pos-1] == 'E') &&
+			t.pos+1 < len(t.s) && isDigit(rune(t.s[t.pos+1])):
+			// exponent sign
+			t.pos++
+		default:
+			return
+		}
+	}
+}
+
+// scanQuoted scans a string or an identifier delimited by quote, in which
+// quote is escaped by doubling it.
+func (t *cqlTokenizer) scanQuoted(quote byte) error {
+	for i := t.pos + 1; i < len(t.s); i++ {
+		if t.s[i] != quote {
+			continue
+		}
+		if i+1 < len(t.s) && t.s[i+1] == quote {
+			i++
+			continue
+		}
+		t.pos = i + 1
+		return nil
+	}
+	return errCQLUnterminatedString
+}
+
+func (t *cqlTokenizer) skipSpacesAndComments() error {
+	for t.pos < len(t.s) {
+		switch rest := t.s[t.pos:]; {
+		case isCQLSpace(rest[0]):
+			t.pos++
+		case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			t.pos += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return errCQLUnterminatedComment
+			}
+			t.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (t *cqlTokenizer) skipWhile(fn func(byte) bool) {
+	for t.pos < len(t.s) && fn(t.s[t.pos]) {
+		t.pos++
+	}
+}
+
+// isCQLUUID reports whether s starts with a UUID constant, such as
+// 123e4567-e89b-12d3-a456-426614174000.
+func isCQLUUID(s string) bool {
+	if len(s) < 36 || (len(s) > 36 && isCQLIdentChar(s[36])) {
+		return false
+	}
+	for i := 0; i < 36; i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if digitVal(rune(s[i])) > 15 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isCQLSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f'
+}
+
+func isCQLIdentStart(ch byte) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || ch >= 0x80
+}
+
+func isCQLIdentChar(ch byte) bool { return isCQLIdentStart(ch) || isDigit(rune(ch)) }
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cqlTestToken struct {
+	kind cqlTokenKind
+	text string
+}
+
+func scanCQL(t *testing.T, query string) []cqlTestToken {
+	var tokens []cqlTestToken
+	tok := newCQLTokenizer(query)
+	for {
+		kind, text, err := tok.scan()
+		require.NoError(t, err)
+		if kind == cqlEOF {
+			return tokens
+		}
+		tokens = append(tokens, cqlTestToken{kind, text})
+	}
+}
+
+func TestCQLTokenizer(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out []cqlTestToken
+	}{
+		{
+			in: "SELECT * FROM ks.users WHERE id = 12 -- trailing comment",
+			out: []cqlTestToken{
+				{cqlIdent, "SELECT"}, {cqlOperator, "*"}, {cqlIdent, "FROM"}, {cqlIdent, "ks"}, {cqlOperator, "."},
+				{cqlIdent, "users"}, {cqlIdent, "WHERE"}, {cqlIdent, "id"}, {cqlOperator, "="}, {cqlNumber, "12"},
+			},
+		},
+		{
+			in: `UPDATE "Users" /* block */ SET name = 'O''Brien', v = $$a'b$$ WHERE id = ? AND k = :key`,
+			out: []cqlTestToken{
+				{cqlIdent, "UPDATE"}, {cqlQuotedIdent, `"Users"`}, {cqlIdent, "SET"}, {cqlIdent, "name"}, {cqlOperator, "="},
+				{cqlString, "'O''Brien'"}, {cqlOperator, ","}, {cqlIdent, "v"}, {cqlOperator, "="}, {cqlString, "$$a'b$$"},
+				{cqlIdent, "WHERE"}, {cqlIdent, "id"}, {cqlOperator, "="}, {cqlBindMarker, "?"}, {cqlIdent, "AND"},
+				{cqlIdent, "k"}, {cqlOperator, "="}, {cqlBindMarker, ":key"},
+			},
+		},
+		{
+			in: "id=123e4567-e89b-12d3-a456-426614174000 AND b>=0xCAFE AND d<1h30m AND f=-1.5e-3 AND c=c-1",
+			out: []cqlTestToken{
+				{cqlIdent, "id"}, {cqlOperator, "="}, {cqlUUID, "123e4567-e89b-12d3-a456-426614174000"},
+				{cqlIdent, "AND"}, {cqlIdent, "b"}, {cqlOperator, ">="}, {cqlNumber, "0xCAFE"},
+				{cqlIdent, "AND"}, {cqlIdent, "d"}, {cqlOperator, "<"}, {cqlNumber, "1h30m"},
+				{cqlIdent, "AND"}, {cqlIdent, "f"}, {cqlOperator, "="}, {cqlNumber, "-1.5e-3"},
+				{cqlIdent, "AND"}, {cqlIdent, "c"}, {cqlOperator, "="}, {cqlIdent, "c"}, {cqlOperator, "-"}, {cqlNumber, "1"},
+			},
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.out, scanCQL(t, tt.in))
+		})
+	}
+}
+
+func TestCQLTokenizerErrors(t *testing.T) {
+	for _, in := range []string{
+		"SELECT * FROM t WHERE a = 'unterminated",
+		`SELECT "unterminated FROM t`,
+		"SELECT * FROM t WHERE a = $$unterminated",
+		"SELECT * FROM t /* unterminated",
+	} {
+		tok := newCQLTokenizer(in)
+		var err error
+		for kind := cqlOperator; err == nil && kind != cqlEOF; {
+			kind, _, err = tok.scan()
+		}
+		assert.Error(t, err, in)
+	}
+}
+
+func FuzzCQLTokenizeIntegerStrings(f *testing.F) {
+	f.Add(int64(0))
+	f.Add(int64(-1))
+	f.Add(int64(2018))
+	f.Add(int64(math.MinInt64))
+	f.Add(int64(math.MaxInt64))
+
+	f.Fuzz(func(t *testing.T, i int64) {
+		testCQLTokenizeNumber(t, strconv.FormatInt(i, 10))
+		// blob constant
+		testCQLTokenizeNumber(t, "0x"+strconv.FormatUint(uint64(i), 16))
+	})
+}
+
+func FuzzCQLTokenizeFloatStrings(f *testing.F) {
+	f.Add(float64(0))
+	f.Add(float64(0.123456789))
+	f.Add(float64(-12.3456789))
+	f.Add(math.MaxFloat64)
+	f.Add(math.SmallestNonzeroFloat64)
+
+	f.Fuzz(func(t *testing.T, f float64) {
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return
+		}
+		for _, format := range []byte{'e', 'E', 'f'} {
+			testCQLTokenizeNumber(t, strconv.FormatFloat(f, format, -1, 64))
+		}
+	})
+}
+
+func testCQLTokenizeNumber(t *testing.T, input string) {
+	tokens := scanCQL(t, "x = "+input)
+	if len(tokens) != 3 || tokens[2].kind != cqlNumber && tokens[2].kind != cqlUUID {
+		t.Errorf("the value [%s] was not interpreted as a number: %v", input, tokens)
+	} else if tokens[2].text != input {
+		t.Errorf("the value [%s] was incorrectly parsed to [%s]", input, tokens[2].text)
+	}
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+)
+
+// ObfuscateGraphQLString obfuscates and quantizes the given GraphQL document.
+// Int, float and string values are replaced with "?", as well as boolean and
+// null values unless configured otherwise, and lists of values are collapsed
+// to a single "?". Variables, enum values and the structure of the document
+// are kept. Comments are removed and spacing is normalized, so that documents
+// only differing by their values are identical once obfuscated.
+func (o *Obfuscator) ObfuscateGraphQLString(doc string) (string, error) {
+	tok := newGraphQLTokenizer(doc)
+	var (
+		out      []string
+		brackets []int // indexes in out of the opening brackets, "(", "[" and "{"
+	)
+	for {
+		kind, text, err := tok.scan()
+		if err != nil {
+			return "", err
+		}
+		switch kind {
+		case graphQLEOF:
+			return joinGraphQLTokens(out), nil
+		case graphQLNumber, graphQLString:
+			text = "?"
+		case graphQLName:
+			if o.obfuscatesGraphQLName(text) && graphQLInValue(out, brackets) {
+				text = "?"
+			}
+		case graphQLPunctuator:
+			switch text {
+			case "(", "[", "{":
+				brackets = append(brackets, len(out))
+			case ")", "]", "}":
+				if len(brackets) == 0 {
+					break
+				}
+				i := brackets[len(brackets)-1]
+				brackets = brackets[:len(brackets)-1]
+				if text == "]" && isGraphQLValueList(out[i+1:]) {
+					// lists of values are obfuscated as a single value
+					out = append(out[:i], "?")
+					continue
+				}
+			}
+		}
+		out = append(out, text)
+	}
+}
+
+// obfuscatesGraphQLName reports whether the given name is a value that should
+// be obfuscated.
+func (o *Obfuscator) obfuscatesGraphQLName(name string) bool {
+	switch name {
+	case "true", "false":
+		return !o.opts.GraphQL.KeepBoolean
+	case "null":
+		return !o.opts.GraphQL.KeepNull
+	}
+	return false
+}
+
+// graphQLInValue reports whether a name following the given tokens is a value,
+// rather than a field, a type or a keyword.
+func graphQLInValue(out []string, brackets []int) bool {
+	if len(out) == 0 {
+		return false
+	}
+	switch out[len(out)-1] {
+	case ":", "=":
+		return true
+	}
+	return len(brackets) > 0 && out[brackets[len(brackets)-1]] == "["
+}
+
+// isGraphQLValueList reports whether tokens is a non-empty list of "?",
+// optionally separated by commas.
+func isGraphQLValueList(tokens []string) bool {
+	n := 0
+	for _, t := range tokens {
+		switch t {
+		case "?":
+			n++
+		case ",":
+		default:
+			return false
+		}
+	}
+	return n > 0
+}
+
+// joinGraphQLTokens joins the tokens, separating them with single spaces
+// except where the GraphQL printer would not.
+func joinGraphQLTokens(tokens []string) string {
+	var sb strings.Builder
+	for i, t := range tokens {
+		if i > 0 && graphQLSpaced(tokens[i-1], t) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t)
+	}
+	return sb.String()
+}
+
+// graphQLSpaced reports whether the tokens prev and next are separated by a
+// space.
+func graphQLSpaced(prev, next string) bool {
+	switch prev {
+	case "(", "[", "$", "@":
+		return false
+	case "...":
+		return next == "on"
+	}
+	switch next {
+	case ")", "]", ":", "!", ",", "(":
+		return false
+	}
+	return true
+}
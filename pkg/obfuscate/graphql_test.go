@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObfuscateGraphQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			`query GetUser($id: ID!, $first: Int = 10) {
+				user(id: $id, name: "bob") {
+					friends(first: $first, ids: [1, 2, 3], active: true, deleted: null) @include(if: $withFriends) {
+						name
+						...UserFields
+						... on Admin { role }
+					}
+				}
+			}`,
+			"query GetUser($id: ID!, $first: Int = ?) { user(id: $id, name: ?) { friends(first: $first, ids: ?, active: ?, deleted: ?) @include(if: $withFriends) { name ...UserFields ... on Admin { role } } } }",
+		},
+		{
+			"# search by filter\n{ search(filter: {name: \"x\", tags: [\"a\" \"b\"], matrix: [[1], [2]]}, color: RED) { null } }",
+			"{ search(filter: { name: ?, tags: ?, matrix: ? }, color: RED) { null } }",
+		},
+		{
+			`mutation { add(input: """block "string" """, amount: -1.5e10, ids: []) { id } }`,
+			"mutation { add(input: ?, amount: ?, ids: []) { id } }",
+		},
+		{
+			"query Q($ids: [ID!]! = [1], $flags: [[Boolean]]) { a }",
+			"query Q($ids: [ID!]! = ?, $flags: [[Boolean]]) { a }",
+		},
+		{
+			"fragment UserFields on User { id email }",
+			"fragment UserFields on User { id email }",
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			o := NewObfuscator(Config{})
+			out, err := o.ObfuscateGraphQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+
+			// obfuscated documents are stable
+			again, err := o.ObfuscateGraphQLString(out)
+			require.NoError(t, err)
+			assert.Equal(t, out, again)
+		})
+	}
+}
+
+func TestObfuscateGraphQLKeepValues(t *testing.T) {
+	o := NewObfuscator(Config{GraphQL: GraphQLConfig{KeepNull: true, KeepBoolean: true}})
+	out, err := o.ObfuscateGraphQLString(`{ users(active: true, deleted: null, flags: [false], name: "x") { id } }`)
+	require.NoError(t, err)
+	assert.Equal(t, "{ users(active: true, deleted: null, flags: [false], name: ?) { id } }", out)
+}
+
+func TestObfuscateGraphQLErrors(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, in := range []string{
+		`{ user(name: "truncated...`,
+		"graphql.execute",
+	} {
+		_, err := o.ObfuscateGraphQLString(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func FuzzObfuscateGraphQL(f *testing.F) {
+	f.Add(`query Q($id: ID! = 1) { user(id: $id, name: "bob") { friends(ids: [1, 2]) { name } } }`)
+	f.Add(`mutation { add(input: {a: """block""", b: [true, null], c: -1.5e3}) { id } }`)
+	f.Add("{ a(s: ?) @skip(if: false) { ...F ... on T { b } } } # comment")
+
+	o := NewObfuscator(Config{})
+	f.Fuzz(func(t *testing.T, doc string) {
+		out, err := o.ObfuscateGraphQLString(doc)
+		if err != nil {
+			return
+		}
+		again, err := o.ObfuscateGraphQLString(out)
+		if err != nil {
+			t.Fatalf("failed to obfuscate the obfuscated document %q: %v", out, err)
+		}
+		if again != out {
+			t.Fatalf("obfuscating %q twice returned %q", out, again)
+		}
+	})
+}
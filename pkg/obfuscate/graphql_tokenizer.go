@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// graphQLTokenKind specifies the kind of a token returned by the GraphQL
+// tokenizer.
+type graphQLTokenKind int
+
+const (
+	// graphQLEOF is returned once the whole document was scanned.
+	graphQLEOF graphQLTokenKind = iota
+
+	// graphQLName is a name, such as a field, a type or a keyword.
+	graphQLName
+
+	// graphQLNumber is an integer or a float value.
+	graphQLNumber
+
+	// graphQLString is a string or a block string value.
+	graphQLString
+
+	// graphQLPunctuator is a punctuator, such as "{", "$" or "...", or a comma.
+	graphQLPunctuator
+
+	// graphQLPlaceholder is a value which was already obfuscated ("?"). It is
+	// not part of the GraphQL syntax, but it is accepted so that obfuscating
+	// an obfuscated document is possible.
+	graphQLPlaceholder
+)
+
+// String implements fmt.Stringer.
+func (k graphQLTokenKind) String() string {
+	return map[graphQLTokenKind]string{
+		graphQLEOF:         "EOF",
+		graphQLName:        "name",
+		graphQLNumber:      "number",
+		graphQLString:      "string",
+		graphQLPunctuator:  "punctuator",
+		graphQLPlaceholder: "placeholder",
+	}[k]
+}
+
+var errGraphQLUnterminatedString = errors.New("graphql: unterminated string")
+
+// graphQLTokenizer tokenizes a GraphQL document, as described in
+// https://spec.graphql.org/October2021/#sec-Language.Source-Text. Spaces and
+// comments are skipped, but commas are returned as punctuators.
+type graphQLTokenizer struct {
+	s   string
+	pos int
+}
+
+// newGraphQLTokenizer returns a new tokenizer for the given document.
+func newGraphQLTokenizer(s string) *graphQLTokenizer {
+	return &graphQLTokenizer{s: s}
+}
+
+// scan returns the kind and the text of the next token. It returns graphQLEOF
+// at the end of the document.
+func (t *graphQLTokenizer) scan() (graphQLTokenKind, string, error) {
+	t.skipIgnored()
+	if t.pos >= len(t.s) {
+		return graphQLEOF, "", nil
+	}
+	start := t.pos
+	kind, err := t.scanToken()
+	if err != nil {
+		return graphQLEOF, "", err
+	}
+	return kind, t.s[start:t.pos], nil
+}
+
+func (t *graphQLTokenizer) scanToken() (graphQLTokenKind, error) {
+	ch := t.s[t.pos]
+	switch {
+	case isGraphQLNameStart(ch):
+		for t.pos < len(t.s) && isGraphQLNameChar(t.s[t.pos]) {
+			t.pos++
+		}
+		return graphQLName, nil
+	case isDigit(rune(ch)), ch == '-' && t.pos+1 < len(t.s) && isDigit(rune(t.s[t.pos+1])):
+		t.scanNumber()
+		return graphQLNumber, nil
+	case strings.HasPrefix(t.s[t.pos:], `"""`):
+		return graphQLString, t.scanBlockString()
+	case ch == '"':
+		return graphQLString, t.scanString()
+	case strings.HasPrefix(t.s[t.pos:], "..."):
+		t.pos += 3
+		return graphQLPunctuator, nil
+	case strings.IndexByte("!$&():=@[]{|},", ch) >= 0:
+		t.pos++
+		return graphQLPunctuator, nil
+	case ch == '?':
+		t.pos++
+		return graphQLPlaceholder, nil
+	}
+	return graphQLEOF, fmt.Errorf("graphql: unexpected character %q at offset %d", ch, t.pos)
+}
+
+// scanNumber scans an integer or a float value.
+func (t *graphQLTokenizer) scanNumber() {
+	if t.s[t.pos] == '-' {
+		t.pos++
+	}
+	for t.pos < len(t.s) {
+		ch := t.s[t.pos]
+		switch {
+		case isGraphQLNameChar(ch), ch == '.':
+			t.pos++
+		case (ch == '+' || ch == '-') && (t.s[t.pos-1] == 'e' || t.s[t.pos-1] == 'E'):
+			// exponent sign
+			t.pos++
+		default:
+			return
+		}
+	}
+}
+
+// scanString scans a string value, in which quotes can be escaped.
+func (t *graphQLTokenizer) scanString() error {
+	for i := t.pos + 1; i < len(t.s); i++ {
+		switch t.s[i] {
+		case '\\':
+			i++
+		case '"':
+			t.pos = i + 1
+			return nil
+		case '\n', '\r':
+			return errGraphQLUnterminatedString
+		}
+	}
+	return errGraphQLUnterminatedString
+}
+
+// scanBlockString scans a block string value, in which triple quotes can be
+// escaped.
+func (t *graphQLTokenizer) scanBlockString() error {
+	for i := t.pos + 3; i < len(t.s); i++ {
+		switch {
+		case strings.HasPrefix(t.s[i:], `\"""`):
+			i += 3
+		case strings.HasPrefix(t.s[i:], `"""`):
+			t.pos = i + 3
+			return nil
+		}
+	}
+	return errGraphQLUnterminatedString
+}
+
+// skipIgnored skips spaces, line terminators, comments and byte order marks.
+func (t *graphQLTokenizer) skipIgnored() {
+	for t.pos < len(t.s) {
+		switch ch := t.s[t.pos]; {
+		case ch == ' ', ch == '\t', ch == '\n', ch == '\r':
+			t.pos++
+		case ch == '#':
+			for t.pos < len(t.s) && t.s[t.pos] != '\n' && t.s[t.pos] != '\r' {
+				t.pos++
+			}
+		case strings.HasPrefix(t.s[t.pos:], "\ufeff"):
+			t.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+}
+
+func isGraphQLNameChar(ch byte) bool { return isGraphQLNameStart(ch) || isDigit(rune(ch)) }
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLTestToken struct {
+	kind graphQLTokenKind
+	text string
+}
+
+func scanGraphQL(t *testing.T, doc string) []graphQLTestToken {
+	var tokens []graphQLTestToken
+	tok := newGraphQLTokenizer(doc)
+	for {
+		kind, text, err := tok.scan()
+		require.NoError(t, err)
+		if kind == graphQLEOF {
+			return tokens
+		}
+		tokens = append(tokens, graphQLTestToken{kind, text})
+	}
+}
+
+func TestGraphQLTokenizer(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out []graphQLTestToken
+	}{
+		{
+			in: "query Q($id: ID!) { user(id: $id) { ...F } } # comment",
+			out: []graphQLTestToken{
+				{graphQLName, "query"}, {graphQLName, "Q"}, {graphQLPunctuator, "("}, {graphQLPunctuator, "$"},
+				{graphQLName, "id"}, {graphQLPunctuator, ":"}, {graphQLName, "ID"}, {graphQLPunctuator, "!"},
+				{graphQLPunctuator, ")"}, {graphQLPunctuator, "{"}, {graphQLName, "user"}, {graphQLPunctuator, "("},
+				{graphQLName, "id"}, {graphQLPunctuator, ":"}, {graphQLPunctuator, "$"}, {graphQLName, "id"},
+				{graphQLPunctuator, ")"}, {graphQLPunctuator, "{"}, {graphQLPunctuator, "..."}, {graphQLName, "F"},
+				{graphQLPunctuator, "}"}, {graphQLPunctuator, "}"},
+			},
+		},
+		{
+			in: `{ a(s: "x\"y", b: """block \""" "" string""", n: -1.5e10, i: 0, p: ?) }`,
+			out: []graphQLTestToken{
+				{graphQLPunctuator, "{"}, {graphQLName, "a"}, {graphQLPunctuator, "("},
+				{graphQLName, "s"}, {graphQLPunctuator, ":"}, {graphQLString, `"x\"y"`}, {graphQLPunctuator, ","},
+				{graphQLName, "b"}, {graphQLPunctuator, ":"}, {graphQLString, `"""block \""" "" string"""`}, {graphQLPunctuator, ","},
+				{graphQLName, "n"}, {graphQLPunctuator, ":"}, {graphQLNumber, "-1.5e10"}, {graphQLPunctuator, ","},
+				{graphQLName, "i"}, {graphQLPunctuator, ":"}, {graphQLNumber, "0"}, {graphQLPunctuator, ","},
+				{graphQLName, "p"}, {graphQLPunctuator, ":"}, {graphQLPlaceholder, "?"},
+				{graphQLPunctuator, ")"}, {graphQLPunctuator, "}"},
+			},
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.out, scanGraphQL(t, tt.in))
+		})
+	}
+}
+
+func TestGraphQLTokenizerErrors(t *testing.T) {
+	for _, in := range []string{
+		`{ a(s: "unterminated) }`,
+		"{ a(s: \"new\nline\") }",
+		`{ a(s: """unterminated) }`,
+		`{ a(s: 'single quotes') }`,
+	} {
+		tok := newGraphQLTokenizer(in)
+		var err error
+		for kind := graphQLName; err == nil && kind != graphQLEOF; {
+			kind, _, err = tok.scan()
+		}
+		assert.Error(t, err, in)
+	}
+}
+
+func FuzzGraphQLTokenizeIntegerStrings(f *testing.F) {
+	f.Add(int64(0))
+	f.Add(int64(-1))
+	f.Add(int64(2018))
+	f.Add(int64(math.MinInt64))
+	f.Add(int64(math.MaxInt64))
+
+	f.Fuzz(func(t *testing.T, i int64) {
+		testGraphQLTokenizeNumber(t, strconv.FormatInt(i, 10))
+	})
+}
+
+func FuzzGraphQLTokenizeFloatStrings(f *testing.F) {
+	f.Add(float64(0))
+	f.Add(float64(0.123456789))
+	f.Add(float64(-12.3456789))
+	f.Add(math.MaxFloat64)
+	f.Add(math.SmallestNonzeroFloat64)
+
+	f.Fuzz(func(t *testing.T, f float64) {
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return
+		}
+		for _, format := range []byte{'e', 'E', 'f'} {
+			testGraphQLTokenizeNumber(t, strconv.FormatFloat(f, format, -1, 64))
+		}
+	})
+}
+
+func testGraphQLTokenizeNumber(t *testing.T, input string) {
+	tokens := scanGraphQL(t, "x: "+input)
+	if len(tokens) != 3 || tokens[2].kind != graphQLNumber {
+		t.Errorf("the value [%s] was not interpreted as a number: %v", input, tokens)
+	} else if tokens[2].text != input {
+		t.Errorf("the value [%s] was incorrectly parsed to [%s]", input, tokens[2].text)
+	}
+}
@@ -88,6 +88,12 @@ type Config struct {
 	// Memcached holds the obfuscation settings for Memcached commands.
 	Memcached MemcachedConfig
 
+	// GraphQL holds the obfuscation settings for GraphQL documents.
+	GraphQL GraphQLConfig
+
+	// CQL holds the obfuscation settings for Cassandra CQL queries.
+	CQL CQLConfig
+
 	// Statsd specifies the statsd client to use for reporting metrics.
 	Statsd StatsClient
 
@@ -214,6 +220,30 @@ type MemcachedConfig struct {
 	KeepCommand bool `mapstructure:"keep_command"`
 }
 
+// GraphQLConfig holds the configuration settings for GraphQL obfuscation.
+type GraphQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepNull specifies whether null values should be kept.
+	KeepNull bool `mapstructure:"keep_null"`
+
+	// KeepBoolean specifies whether true and false values should be kept.
+	KeepBoolean bool `mapstructure:"keep_boolean"`
+}
+
+// CQLConfig holds the configuration settings for Cassandra CQL obfuscation.
+type CQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepNull specifies whether null literals should be kept.
+	KeepNull bool `mapstructure:"keep_null"`
+
+	// KeepBoolean specifies whether boolean literals should be kept.
+	KeepBoolean bool `mapstructure:"keep_boolean"`
+}
+
 // JSONConfig holds the obfuscation configuration for sensitive
 // data found in JSON objects.
 type JSONConfig struct {
@@ -65,6 +65,10 @@ func (p *PolicyMonitor) SetPolicies(policies []*rules.Policy, mErrs *multierror.
 			p.rules[rule.ID] = "loaded"
 		}
 
+		for _, seq := range policy.Sequences {
+			p.rules[seq.ID] = "loaded"
+		}
+
 		if mErrs != nil && mErrs.Errors != nil {
 			for _, err := range mErrs.Errors {
 				if rerr, ok := err.(*rules.ErrRuleLoad); ok {
@@ -241,6 +245,17 @@ func NewRuleSetLoadedEvent(ruleSets map[string]*rules.RuleSet, err *multierror.E
 			}
 			policyState.Rules = append(policyState.Rules, RuleStateFromDefinition(ruleDef, "loaded", ""))
 		}
+
+		for _, seq := range rs.GetSequences() {
+			ruleDef := seq.GetRule().Definition
+			policyName := ruleDef.Policy.Name
+
+			if policyState, exists = mp[policyName]; !exists {
+				policyState = PolicyStateFromRuleDefinition(ruleDef)
+				mp[policyName] = policyState
+			}
+			policyState.Rules = append(policyState.Rules, RuleStateFromDefinition(ruleDef, "loaded", ""))
+		}
 	}
 
 	// rules ignored due to errors
@@ -49,6 +49,15 @@ var (
 	// ErrNoRuleSetsInEvaluationSet is returned when no rule sets were provided to instantiate an evaluation set
 	ErrNoRuleSetsInEvaluationSet = errors.New("no rule sets provided to instantiate an evaluation set")
 
+	// ErrSequenceSteps is returned when a sequence has less than two steps
+	ErrSequenceSteps = errors.New("a sequence requires at least two steps")
+
+	// ErrSequenceWithoutWindow is returned when a sequence has no time window
+	ErrSequenceWithoutWindow = errors.New("no time window in the sequence definition")
+
+	// ErrSequenceKey is returned when the key of a step doesn't have the same number of fields as the key of its sequence
+	ErrSequenceKey = errors.New("step key doesn't match the sequence key")
+
 	// ErrCannotChangeTagAfterLoading is returned when an attempt was made to change the tag on a ruleset that already has rules loaded
 	ErrCannotChangeTagAfterLoading = errors.New("cannot change tag on a rule set that already has rules loaded")
 )
@@ -66,6 +66,7 @@ func (es *EvaluationSet) LoadPolicies(loader *PolicyLoader, opts PolicyLoaderOpt
 	var (
 		errs       *multierror.Error
 		rules      = make(map[eval.RuleSetTagValue][]*RuleDefinition)
+		sequences  = make(map[eval.RuleSetTagValue][]*SequenceDefinition)
 		allMacros  []*MacroDefinition
 		macroIndex = make(map[string]*MacroDefinition)
 		rulesIndex = make(map[ruleIndexEntry]*RuleDefinition)
@@ -108,25 +109,39 @@ func (es *EvaluationSet) LoadPolicies(loader *PolicyLoader, opts PolicyLoaderOpt
 				rules[tagValue] = append(rules[tagValue], rule)
 			}
 		}
+
+		for _, seq := range policy.Sequences {
+			tagValue, _ := seq.GetTag("ruleset")
+			if tagValue == "" {
+				tagValue = DefaultRuleSetTagValue
+			}
+			sequences[tagValue] = append(sequences[tagValue], seq)
+		}
 	}
 
 	for ruleSetTagValue, rs := range es.RuleSets {
-		for rulesIndexTagValue, ruleList := range rules {
-			if rulesIndexTagValue == ruleSetTagValue {
-				// Add the macros to the ruleset and generate macros evaluators
-				if err := rs.AddMacros(parsingContext, allMacros); err.ErrorOrNil() != nil {
-					errs = multierror.Append(errs, err)
-				}
+		ruleList, seqList := rules[ruleSetTagValue], sequences[ruleSetTagValue]
+		if len(ruleList) == 0 && len(seqList) == 0 {
+			continue
+		}
 
-				if err := rs.populateFieldsWithRuleActionsData(ruleList); err.ErrorOrNil() != nil {
-					errs = multierror.Append(errs, err)
-				}
+		// Add the macros to the ruleset and generate macros evaluators
+		if err := rs.AddMacros(parsingContext, allMacros); err.ErrorOrNil() != nil {
+			errs = multierror.Append(errs, err)
+		}
 
-				// Add rules to the ruleset and generate rules evaluators
-				if err := rs.AddRules(parsingContext, ruleList); err.ErrorOrNil() != nil {
-					errs = multierror.Append(errs, err)
-				}
-			}
+		if err := rs.populateFieldsWithRuleActionsData(ruleList); err.ErrorOrNil() != nil {
+			errs = multierror.Append(errs, err)
+		}
+
+		// Add rules to the ruleset and generate rules evaluators
+		if err := rs.AddRules(parsingContext, ruleList); err.ErrorOrNil() != nil {
+			errs = multierror.Append(errs, err)
+		}
+
+		// Add sequences to the ruleset and generate the evaluators of their steps
+		if err := rs.AddSequences(parsingContext, seqList); err.ErrorOrNil() != nil {
+			errs = multierror.Append(errs, err)
 		}
 	}
 
@@ -17,18 +17,20 @@ import (
 
 // PolicyDef represents a policy file definition
 type PolicyDef struct {
-	Version string             `yaml:"version"`
-	Rules   []*RuleDefinition  `yaml:"rules"`
-	Macros  []*MacroDefinition `yaml:"macros"`
+	Version   string                `yaml:"version"`
+	Rules     []*RuleDefinition     `yaml:"rules"`
+	Macros    []*MacroDefinition    `yaml:"macros"`
+	Sequences []*SequenceDefinition `yaml:"sequences"`
 }
 
 // Policy represents a policy file which is composed of a list of rules and macros
 type Policy struct {
-	Name    string
-	Source  string
-	Version string
-	Rules   []*RuleDefinition
-	Macros  []*MacroDefinition
+	Name      string
+	Source    string
+	Version   string
+	Rules     []*RuleDefinition
+	Macros    []*MacroDefinition
+	Sequences []*SequenceDefinition
 }
 
 // AddMacro add a macro to the policy
@@ -42,6 +44,12 @@ func (p *Policy) AddRule(def *RuleDefinition) {
 	p.Rules = append(p.Rules, def)
 }
 
+// AddSequence adds a sequence to the policy
+func (p *Policy) AddSequence(def *SequenceDefinition) {
+	def.Policy = p
+	p.Sequences = append(p.Sequences, def)
+}
+
 func parsePolicyDef(name string, source string, def *PolicyDef, macroFilters []MacroFilter, ruleFilters []RuleFilter) (*Policy, error) {
 	var errs *multierror.Error
 
@@ -126,6 +134,33 @@ RULES:
 		policy.AddRule(ruleDef)
 	}
 
+SEQUENCES:
+	for _, seqDef := range def.Sequences {
+		seqDef.Policy = policy
+
+		// sequences are filtered like the rule they report
+		for _, filter := range ruleFilters {
+			isRuleAccepted, err := filter.IsRuleAccepted(seqDef.RuleDefinition())
+			if err != nil {
+				errs = multierror.Append(errs, &ErrRuleLoad{Definition: seqDef.RuleDefinition(), Err: err})
+			}
+			if !isRuleAccepted {
+				continue SEQUENCES
+			}
+		}
+
+		if seqDef.ID == "" {
+			errs = multierror.Append(errs, &ErrRuleLoad{Definition: seqDef.RuleDefinition(), Err: ErrRuleWithoutID})
+			continue
+		}
+		if !validators.CheckRuleID(seqDef.ID) {
+			errs = multierror.Append(errs, &ErrRuleLoad{Definition: seqDef.RuleDefinition(), Err: ErrRuleIDPattern})
+			continue
+		}
+
+		policy.AddSequence(seqDef)
+	}
+
 LOOP:
 	for _, s := range skipped {
 		// For every skipped rule, if it doesn't match an ID of a policy rule, add an error.
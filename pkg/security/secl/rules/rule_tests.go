@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package rules holds rules related files
+package rules
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+// RuleTestsSuffix is the suffix of the files holding the tests of the rules of a policy directory
+const RuleTestsSuffix = ".tests.yaml"
+
+// EventFixture describes an event used to test a rule
+type EventFixture struct {
+	Name   string                 `yaml:"name"`
+	Type   eval.EventType         `yaml:"type"`
+	Values map[string]interface{} `yaml:"values"`
+}
+
+// RuleTests lists the events a rule must match and the events it must not match
+type RuleTests struct {
+	Positive []*EventFixture `yaml:"positive"`
+	Negative []*EventFixture `yaml:"negative"`
+}
+
+// RuleTestsDefinition holds the tests of a rule, defined outside of its policy
+type RuleTestsDefinition struct {
+	RuleID    RuleID `yaml:"rule_id"`
+	RuleTests `yaml:",inline"`
+}
+
+// RuleTestsFile represents a file holding rule tests
+type RuleTestsFile struct {
+	Tests []*RuleTestsDefinition `yaml:"tests"`
+}
+
+// LoadRuleTests loads rule tests from a reader
+func LoadRuleTests(reader io.Reader) ([]*RuleTestsDefinition, error) {
+	var file RuleTestsFile
+
+	decoder := yaml.NewDecoder(reader)
+	if err := decoder.Decode(&file); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	for _, def := range file.Tests {
+		if def.RuleID == "" {
+			return nil, ErrRuleWithoutID
+		}
+	}
+
+	return file.Tests, nil
+}
+
+// EventFixtureCtor returns a new event of the given type
+type EventFixtureCtor func(eventType eval.EventType) (eval.Event, error)
+
+// RuleTestResult holds the result of the evaluation of an event fixture against a rule
+type RuleTestResult struct {
+	RuleID   RuleID
+	Fixture  *EventFixture
+	Positive bool
+	Passed   bool
+	// FailedExpression is the first sub-expression of the rule that didn't match a positive fixture
+	FailedExpression string
+	Error            error
+}
+
+// Name returns a name identifying the fixture of the test
+func (r *RuleTestResult) Name(index int) string {
+	kind := "negative"
+	if r.Positive {
+		kind = "positive"
+	}
+	if r.Fixture != nil && r.Fixture.Name != "" {
+		return kind + "/" + r.Fixture.Name
+	}
+	return fmt.Sprintf("%s/%d", kind, index)
+}
+
+// RuleTestsReport holds the results of the tests of a rule set
+type RuleTestsReport struct {
+	Results map[RuleID][]*RuleTestResult
+	// Untested lists the rules without any test
+	Untested []RuleID
+	// Unknown lists the rules that have tests but are not part of the rule set
+	Unknown []RuleID
+}
+
+// RuleIDs returns the sorted list of rules that were tested
+func (r *RuleTestsReport) RuleIDs() []RuleID {
+	ids := make([]RuleID, 0, len(r.Results))
+	for id := range r.Results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Failures returns the number of failed tests
+func (r *RuleTestsReport) Failures() int {
+	var failures int
+	for _, results := range r.Results {
+		for _, result := range results {
+			if !result.Passed {
+				failures++
+			}
+		}
+	}
+	return failures
+}
+
+// RunRuleTests evaluates the event fixtures of the rules of the rule set. The tests of a rule are the ones of its
+// definition followed by the given additional ones.
+func RunRuleTests(rs *RuleSet, newEvent EventFixtureCtor, additional []*RuleTestsDefinition) *RuleTestsReport {
+	tests := make(map[RuleID][]*RuleTests)
+	for id, rule := range rs.rules {
+		if rule.Definition.Tests != nil {
+			tests[id] = append(tests[id], rule.Definition.Tests)
+		}
+	}
+
+	report := &RuleTestsReport{
+		Results: make(map[RuleID][]*RuleTestResult),
+	}
+
+	for _, def := range additional {
+		if _, exists := rs.rules[def.RuleID]; !exists {
+			report.Unknown = append(report.Unknown, def.RuleID)
+			continue
+		}
+		tests[def.RuleID] = append(tests[def.RuleID], &def.RuleTests)
+	}
+
+	for id, rule := range rs.rules {
+		for _, ruleTests := range tests[id] {
+			for _, fixture := range ruleTests.Positive {
+				report.Results[id] = append(report.Results[id], rs.runRuleTest(rule, fixture, true, newEvent))
+			}
+			for _, fixture := range ruleTests.Negative {
+				report.Results[id] = append(report.Results[id], rs.runRuleTest(rule, fixture, false, newEvent))
+			}
+		}
+
+		if len(report.Results[id]) == 0 {
+			report.Untested = append(report.Untested, id)
+		}
+	}
+
+	sort.Strings(report.Untested)
+	sort.Strings(report.Unknown)
+
+	return report
+}
+
+func newFixtureEvent(fixture *EventFixture, newEvent EventFixtureCtor) (eval.Event, error) {
+	event, err := newEvent(fixture.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	for field, value := range fixture.Values {
+		if err := event.SetFieldValue(field, value); err != nil {
+			return nil, fmt.Errorf("failed to set `%s`: %w", field, err)
+		}
+	}
+
+	return event, nil
+}
+
+func (rs *RuleSet) runRuleTest(rule *Rule, fixture *EventFixture, positive bool, newEvent EventFixtureCtor) *RuleTestResult {
+	result := &RuleTestResult{
+		RuleID:   rule.ID,
+		Fixture:  fixture,
+		Positive: positive,
+	}
+
+	event, err := newFixtureEvent(fixture, newEvent)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	eventType, err := GetRuleEventType(rule.Rule)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	// an event only gets evaluated against the rules of its type
+	matched := fixture.Type == eventType && rule.Eval(eval.NewContext(event))
+	result.Passed = matched == positive
+
+	switch {
+	case result.Passed:
+	case !positive:
+		result.Error = fmt.Errorf("rule `%s` matched a negative fixture", rule.ID)
+	case fixture.Type != eventType:
+		result.Error = fmt.Errorf("fixture of type `%s` can't match a rule on `%s` events", fixture.Type, eventType)
+	default:
+		result.Error = fmt.Errorf("rule `%s` didn't match a positive fixture", rule.ID)
+		result.FailedExpression, err = rs.firstFailedExpression(rule, event)
+		if err != nil {
+			result.Error = fmt.Errorf("%s: %w", result.Error, err)
+		}
+	}
+
+	return result
+}
+
+// firstFailedExpression returns the first operand of the top level `&&` chain of the rule that doesn't match the event
+func (rs *RuleSet) firstFailedExpression(rule *Rule, event eval.Event) (string, error) {
+	operands := andOperands(rule.GetAst())
+	if len(operands) < 2 {
+		return strings.TrimSpace(rule.Expression), nil
+	}
+
+	pc := ast.NewParsingContext()
+	ctx := eval.NewContext(event)
+	for i, operand := range operands {
+		sub := eval.NewRule(fmt.Sprintf("%s_%d", rule.ID, i), operand, rs.evalOpts)
+		if err := sub.GenEvaluator(rs.model, pc); err != nil {
+			return "", err
+		}
+		if !sub.Eval(ctx) {
+			return operand, nil
+		}
+	}
+
+	// every operand matched, the rule failed as a whole
+	return strings.TrimSpace(rule.Expression), nil
+}
+
+// andOperands splits the top level expression of a rule on its `&&` operators. SECL binary boolean operators
+// are right associative, the remaining of the expression after an `||` operator is thus a single operand.
+func andOperands(rule *ast.Rule) []string {
+	if rule == nil || rule.BooleanExpression == nil {
+		return nil
+	}
+
+	var operands []string
+	for expr := rule.BooleanExpression.Expression; expr != nil; expr = expr.Next.Expression {
+		start := expr.Pos.Offset
+
+		if expr.Op == nil || (*expr.Op != "&&" && *expr.Op != "and") || expr.Next == nil {
+			operands = append(operands, strings.TrimSpace(rule.Expr[start:]))
+			break
+		}
+
+		operand := strings.TrimSpace(rule.Expr[start:expr.Next.Pos.Offset])
+		operands = append(operands, strings.TrimSpace(strings.TrimSuffix(operand, *expr.Op)))
+	}
+
+	return operands
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package rules holds rules related files
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+const testPolicyWithTests = `
+macros:
+  - id: tmp_dirs
+    expression: '[~"/tmp/*", ~"/var/tmp/*"]'
+rules:
+  - id: tmp_exec
+    expression: exec.file.path in tmp_dirs && process.uid != 0 && exec.comm == "sh"
+    tests:
+      positive:
+        - name: sh_in_tmp
+          type: exec
+          values:
+            exec.file.path: /tmp/sh
+            process.uid: 1000
+            exec.comm: sh
+        - name: root
+          type: exec
+          values:
+            exec.file.path: /tmp/sh
+            process.uid: 0
+            exec.comm: sh
+      negative:
+        - type: exec
+          values:
+            exec.file.path: /usr/bin/sh
+            exec.comm: sh
+  - id: etc_open
+    expression: open.file.path =~ "/etc/*"
+  - id: shadow_open
+    expression: open.file.path == "/etc/shadow"
+`
+
+const testRuleTests = `
+tests:
+  - rule_id: etc_open
+    positive:
+      - type: open
+        values:
+          open.file.path: /etc/passwd
+      - name: wrong_type
+        type: exec
+        values:
+          exec.file.path: /etc/passwd
+  - rule_id: unknown_rule
+    negative:
+      - type: open
+`
+
+func newTestEvent(eventType eval.EventType) (eval.Event, error) {
+	for i := uint64(0); i != uint64(model.MaxAllEventType); i++ {
+		if model.EventType(i).String() == eventType {
+			event := (&model.Model{}).NewDefaultEventWithType(model.EventType(i))
+			event.Init()
+			return event, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown event type `%s`", eventType)
+}
+
+func TestRunRuleTests(t *testing.T) {
+	policy, err := LoadPolicy("test.policy", PolicyProviderTypeDir, strings.NewReader(testPolicyWithTests), nil, nil)
+	require.NoError(t, err)
+
+	rs := newRuleSet()
+	pc := ast.NewParsingContext()
+	require.NoError(t, rs.AddMacros(pc, policy.Macros).ErrorOrNil())
+	require.NoError(t, rs.AddRules(pc, policy.Rules).ErrorOrNil())
+
+	additional, err := LoadRuleTests(strings.NewReader(testRuleTests))
+	require.NoError(t, err)
+	require.Len(t, additional, 2)
+
+	report := RunRuleTests(rs, newTestEvent, additional)
+	assert.Equal(t, []RuleID{"etc_open", "tmp_exec"}, report.RuleIDs())
+	assert.Equal(t, []RuleID{"shadow_open"}, report.Untested)
+	assert.Equal(t, []RuleID{"unknown_rule"}, report.Unknown)
+	assert.Equal(t, 2, report.Failures())
+
+	results := report.Results["tmp_exec"]
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, "positive/sh_in_tmp", results[0].Name(0))
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "process.uid != 0", results[1].FailedExpression)
+	assert.Error(t, results[1].Error)
+	assert.True(t, results[2].Passed)
+	assert.Equal(t, "negative/2", results[2].Name(2))
+
+	results = report.Results["etc_open"]
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+	assert.Empty(t, results[1].FailedExpression)
+	assert.ErrorContains(t, results[1].Error, "can't match")
+}
+
+func TestAndOperands(t *testing.T) {
+	pc := ast.NewParsingContext()
+
+	for _, tt := range []struct {
+		expr string
+		want []string
+	}{
+		{`open.file.path == "/etc/shadow"`, []string{`open.file.path == "/etc/shadow"`}},
+		{`open.file.path == "/etc/shadow" && process.uid != 0`, []string{`open.file.path == "/etc/shadow"`, `process.uid != 0`}},
+		{`exec.comm == "sh" and (process.uid == 0 || process.gid == 0) && exec.argv in ["-c"]`, []string{`exec.comm == "sh"`, `(process.uid == 0 || process.gid == 0)`, `exec.argv in ["-c"]`}},
+		{`exec.comm == "sh" && process.uid == 0 || process.gid == 0`, []string{`exec.comm == "sh"`, `process.uid == 0 || process.gid == 0`}},
+		{`exec.comm == "sh" || process.uid == 0 && process.gid == 0`, []string{`exec.comm == "sh" || process.uid == 0 && process.gid == 0`}},
+	} {
+		rule, err := pc.ParseRule(tt.expr)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, andOperands(rule), tt.expr)
+	}
+}
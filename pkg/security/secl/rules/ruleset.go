@@ -90,6 +90,7 @@ type RuleDefinition struct {
 	Combine                CombinePolicy      `yaml:"combine"`
 	Actions                []ActionDefinition `yaml:"actions"`
 	Every                  time.Duration      `yaml:"every"`
+	Tests                  *RuleTests         `yaml:"tests"`
 	Policy                 *Policy
 	Silent                 bool
 }
@@ -108,6 +109,9 @@ func (rd *RuleDefinition) MergeWith(rd2 *RuleDefinition) error {
 	switch rd2.Combine {
 	case OverridePolicy:
 		rd.Expression = rd2.Expression
+		if rd2.Tests != nil {
+			rd.Tests = rd2.Tests
+		}
 	default:
 		if !rd2.Disabled {
 			return &ErrRuleLoad{Definition: rd2, Err: ErrDefinitionIDConflict}
@@ -179,6 +183,9 @@ type KillDefinition struct {
 type Rule struct {
 	*eval.Rule
 	Definition *RuleDefinition
+
+	// Sequence is set for the steps of a sequence and for the rule reported when it matches
+	Sequence *Sequence
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -195,6 +202,8 @@ type RuleSet struct {
 	evalOpts         *eval.Opts
 	eventRuleBuckets map[eval.EventType]*RuleBucket
 	rules            map[eval.RuleID]*Rule
+	sequences        map[eval.RuleID]*Sequence
+	sequenceList     []*Sequence
 	policies         []*Policy
 	fieldEvaluators  map[string]eval.Evaluator
 	model            eval.Model
@@ -444,6 +453,9 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, ruleDef *RuleDefi
 	if _, exists := rs.rules[ruleDef.ID]; exists {
 		return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrDefinitionIDConflict}
 	}
+	if _, exists := rs.sequences[ruleDef.ID]; exists {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrDefinitionIDConflict}
+	}
 
 	var tags []string
 	for k, v := range ruleDef.Tags {
@@ -687,6 +699,11 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 
 	result := false
 	for _, rule := range bucket.rules {
+		// the steps of the sequences are evaluated by the sequences themselves
+		if rule.Sequence != nil {
+			continue
+		}
+
 		if rule.GetEvaluator().Eval(ctx) {
 
 			if rs.logger.IsTracing() {
@@ -702,6 +719,10 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 		}
 	}
 
+	if len(rs.sequenceList) > 0 && rs.evaluateSequences(ctx, event, eventType) {
+		result = true
+	}
+
 	// no-op in the general case, only used to collect events in functional tests
 	// for debugging purposes
 	rs.eventCollector.CollectEvent(rs, event, result)
@@ -788,6 +809,7 @@ func NewRuleSet(model eval.Model, eventCtor func() eval.Event, opts *Opts, evalO
 		evalOpts:         evalOpts,
 		eventRuleBuckets: make(map[eval.EventType]*RuleBucket),
 		rules:            make(map[eval.RuleID]*Rule),
+		sequences:        make(map[eval.RuleID]*Sequence),
 		logger:           logger,
 		pool:             eval.NewContextPool(),
 		fieldEvaluators:  make(map[string]eval.Evaluator),
@@ -0,0 +1,369 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package rules holds rules related files
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const (
+	// DefaultSequenceMaxStates is the default number of in progress sequences tracked per sequence definition
+	DefaultSequenceMaxStates = 1000
+
+	// sequenceTimestampField is the field used to order the steps of a sequence
+	sequenceTimestampField = "event.timestamp"
+)
+
+// SequenceStepDefinition describes a step of a sequence
+type SequenceStepDefinition struct {
+	Expression string `yaml:"expression"`
+	// Key overrides the key of the sequence for this step, it must have the same number of fields
+	Key []eval.Field `yaml:"key"`
+}
+
+// SequenceDefinition describes a sequence of events, matching when its steps match in order, for the same key,
+// within the time window
+type SequenceDefinition struct {
+	ID                     RuleID                    `yaml:"id"`
+	Description            string                    `yaml:"description"`
+	Tags                   map[string]string         `yaml:"tags"`
+	AgentVersionConstraint string                    `yaml:"agent_version"`
+	Filters                []string                  `yaml:"filters"`
+	Disabled               bool                      `yaml:"disabled"`
+	Key                    []eval.Field              `yaml:"key"`
+	Within                 time.Duration             `yaml:"within"`
+	MaxStates              int                       `yaml:"max_states"`
+	Steps                  []*SequenceStepDefinition `yaml:"steps"`
+	Policy                 *Policy
+}
+
+// GetTag returns the tag value associated with a tag key
+func (sd *SequenceDefinition) GetTag(tagKey string) (string, bool) {
+	tagValue, ok := sd.Tags[tagKey]
+	if ok {
+		return tagValue, true
+	}
+	return "", false
+}
+
+// RuleDefinition returns the definition of the rule reported when the sequence matches
+func (sd *SequenceDefinition) RuleDefinition() *RuleDefinition {
+	return &RuleDefinition{
+		ID:                     sd.ID,
+		Description:            sd.Description,
+		Tags:                   sd.Tags,
+		AgentVersionConstraint: sd.AgentVersionConstraint,
+		Filters:                sd.Filters,
+		Disabled:               sd.Disabled,
+		Policy:                 sd.Policy,
+	}
+}
+
+type sequenceState struct {
+	// next is the index of the next step to match
+	next  int
+	start int64
+}
+
+// Sequence is a compiled sequence definition along with the state of its in progress matches
+type Sequence struct {
+	Definition *SequenceDefinition
+
+	rule       *Rule
+	steps      []*Rule
+	eventTypes []eval.EventType
+	keys       [][]eval.Evaluator
+	timestamp  eval.Evaluator
+
+	lock    sync.Mutex
+	states  *simplelru.LRU[string, *sequenceState]
+	evicted uint64
+}
+
+// GetRule returns the rule reported to the listeners when the sequence matches
+func (s *Sequence) GetRule() *Rule {
+	return s.rule
+}
+
+// GetSteps returns the rules of the steps of the sequence
+func (s *Sequence) GetSteps() []*Rule {
+	return s.steps
+}
+
+// InProgress returns the number of tracked in progress sequences
+func (s *Sequence) InProgress() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.states.Len()
+}
+
+// Evicted returns the number of in progress sequences dropped because of the state size limit
+func (s *Sequence) Evicted() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.evicted
+}
+
+func (s *Sequence) now(ctx *eval.Context) int64 {
+	if s.timestamp != nil {
+		if ts, ok := s.timestamp.Eval(ctx).(int); ok && ts != 0 {
+			return int64(ts)
+		}
+	}
+	return ctx.Now().UnixNano()
+}
+
+func (s *Sequence) key(ctx *eval.Context, step int) string {
+	var b strings.Builder
+	for i, evaluator := range s.keys[step] {
+		if i > 0 {
+			b.WriteByte(0)
+		}
+		fmt.Fprint(&b, evaluator.Eval(ctx))
+	}
+	return b.String()
+}
+
+// evaluate processes an event of the given type, it returns true when the event matches the last step
+func (s *Sequence) evaluate(ctx *eval.Context, eventType eval.EventType) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var now int64
+
+	// steps are walked backward so that a single event advances a sequence by one step at most
+	for i := len(s.steps) - 1; i >= 0; i-- {
+		step := s.steps[i]
+		if s.eventTypes[i] != eventType || !step.Eval(ctx) {
+			continue
+		}
+
+		if now == 0 {
+			now = s.now(ctx)
+		}
+
+		key := s.key(ctx, i)
+		state, exists := s.states.Get(key)
+		if exists && now-state.start > s.Definition.Within.Nanoseconds() {
+			s.states.Remove(key)
+			exists = false
+		}
+
+		if i == 0 {
+			if !exists && s.states.Add(key, &sequenceState{next: 1, start: now}) {
+				s.evicted++
+			}
+			continue
+		}
+
+		if !exists || state.next != i {
+			continue
+		}
+
+		if i == len(s.steps)-1 {
+			s.states.Remove(key)
+			return true
+		}
+		state.next++
+	}
+
+	return false
+}
+
+func (rs *RuleSet) newSequence(parsingContext *ast.ParsingContext, seqDef *SequenceDefinition) (*Sequence, error) {
+	if len(seqDef.Steps) < 2 {
+		return nil, ErrSequenceSteps
+	}
+	if seqDef.Within <= 0 {
+		return nil, ErrSequenceWithoutWindow
+	}
+
+	maxStates := seqDef.MaxStates
+	if maxStates <= 0 {
+		maxStates = DefaultSequenceMaxStates
+	}
+
+	states, err := simplelru.NewLRU[string, *sequenceState](maxStates, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := &Sequence{
+		Definition: seqDef,
+		states:     states,
+	}
+
+	var tags []string
+	for k, v := range seqDef.Tags {
+		tags = append(tags, k+":"+v)
+	}
+
+	for i, stepDef := range seqDef.Steps {
+		key := stepDef.Key
+		if key == nil {
+			key = seqDef.Key
+		}
+		if len(key) != len(seqDef.Key) {
+			return nil, fmt.Errorf("step %d: %w", i, ErrSequenceKey)
+		}
+
+		stepRule := &Rule{
+			Rule: eval.NewRule(fmt.Sprintf("%s_step_%d", seqDef.ID, i), stepDef.Expression, rs.evalOpts, tags...),
+			Definition: &RuleDefinition{
+				ID:         fmt.Sprintf("%s_step_%d", seqDef.ID, i),
+				Expression: stepDef.Expression,
+				Tags:       seqDef.Tags,
+				Policy:     seqDef.Policy,
+			},
+			Sequence: seq,
+		}
+
+		if err := stepRule.Parse(parsingContext); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, &ErrRuleSyntax{Err: err})
+		}
+
+		if err := stepRule.GenEvaluator(rs.model, parsingContext); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+
+		eventType, err := GetRuleEventType(stepRule.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+
+		var keys []eval.Evaluator
+		for _, field := range key {
+			evaluator, err := rs.model.GetEvaluator(field, "")
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid key field `%s`: %w", i, field, err)
+			}
+			keys = append(keys, evaluator)
+		}
+
+		seq.steps = append(seq.steps, stepRule)
+		seq.eventTypes = append(seq.eventTypes, eventType)
+		seq.keys = append(seq.keys, keys)
+	}
+
+	// the rule reported to the listeners evaluates like the last step
+	lastStep := *seq.steps[len(seq.steps)-1].Rule
+	lastStep.ID = seqDef.ID
+	lastStep.Expression = ""
+	lastStep.Tags = tags
+	seq.rule = &Rule{
+		Rule:       &lastStep,
+		Definition: seqDef.RuleDefinition(),
+		Sequence:   seq,
+	}
+
+	if evaluator, err := rs.model.GetEvaluator(sequenceTimestampField, ""); err == nil {
+		seq.timestamp = evaluator
+	}
+
+	return seq, nil
+}
+
+// AddSequences adds sequences to the rule set
+func (rs *RuleSet) AddSequences(parsingContext *ast.ParsingContext, sequences []*SequenceDefinition) *multierror.Error {
+	var result *multierror.Error
+
+	for _, seqDef := range sequences {
+		if _, err := rs.AddSequence(parsingContext, seqDef); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
+
+// AddSequence compiles the steps of a sequence and adds them to the buckets of their events
+func (rs *RuleSet) AddSequence(parsingContext *ast.ParsingContext, seqDef *SequenceDefinition) (*Sequence, error) {
+	if seqDef.Disabled {
+		return nil, nil
+	}
+
+	ruleDef := seqDef.RuleDefinition()
+
+	for _, id := range rs.opts.ReservedRuleIDs {
+		if id == seqDef.ID {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrInternalIDConflict}
+		}
+	}
+
+	if _, exists := rs.rules[seqDef.ID]; exists {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrDefinitionIDConflict}
+	}
+	if _, exists := rs.sequences[seqDef.ID]; exists {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrDefinitionIDConflict}
+	}
+
+	seq, err := rs.newSequence(parsingContext, seqDef)
+	if err != nil {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+	}
+
+	// ignore event types not supported
+	if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
+		for _, eventType := range seq.eventTypes {
+			if _, exists := rs.opts.EventTypeEnabled[eventType]; !exists {
+				return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrEventTypeNotEnabled}
+			}
+		}
+	}
+
+	// the steps are part of the buckets so that approvers and discarders account for them
+	for _, step := range seq.steps {
+		for _, event := range step.GetEvaluator().EventTypes {
+			bucket, exists := rs.eventRuleBuckets[event]
+			if !exists {
+				bucket = &RuleBucket{}
+				rs.eventRuleBuckets[event] = bucket
+			}
+
+			if err := bucket.AddRule(step); err != nil {
+				return nil, err
+			}
+		}
+
+		rs.AddFields(step.GetEvaluator().GetFields())
+	}
+
+	rs.sequences[seqDef.ID] = seq
+	rs.sequenceList = append(rs.sequenceList, seq)
+
+	return seq, nil
+}
+
+// GetSequences returns the sequences of the rule set
+func (rs *RuleSet) GetSequences() []*Sequence {
+	return rs.sequenceList
+}
+
+// evaluateSequences advances the sequences with the given event and notifies the listeners of the completed ones
+func (rs *RuleSet) evaluateSequences(ctx *eval.Context, event eval.Event, eventType eval.EventType) bool {
+	result := false
+	for _, seq := range rs.sequenceList {
+		if seq.evaluate(ctx, eventType) {
+			if rs.logger.IsTracing() {
+				rs.logger.Tracef("Sequence `%s` matches with event `%s`\n", seq.Definition.ID, event)
+			}
+
+			rs.NotifyRuleMatch(seq.rule, event)
+			result = true
+		}
+	}
+	return result
+}
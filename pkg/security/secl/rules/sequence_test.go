@@ -0,0 +1,281 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package rules holds rules related files
+package rules
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+const testSequencePolicy = `
+rules:
+  - id: shadow_open
+    expression: open.file.path == "/etc/shadow"
+sequences:
+  - id: tmp_write_exec
+    key: [container.id, open.file.path]
+    within: 30s
+    max_states: 2
+    steps:
+      - expression: open.file.path =~ "/tmp/*" && open.flags & O_CREAT > 0
+      - expression: exec.file.path =~ "/tmp/*"
+        key: [container.id, exec.file.path]
+  - id: shell_after_bind
+    key: [process.pid]
+    within: 1m
+    steps:
+      - expression: bind.addr.port == 4444
+      - expression: exec.file.name in ["sh", "bash"]
+        key: [process.ppid]
+      - expression: open.file.path == "/etc/passwd"
+        key: [process.ppid]
+`
+
+type sequenceTestListener struct {
+	matches []string
+}
+
+func (l *sequenceTestListener) RuleMatch(rule *Rule, event eval.Event) bool {
+	l.matches = append(l.matches, rule.ID)
+	return true
+}
+
+func (l *sequenceTestListener) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+func newSequenceRuleSet(t *testing.T, policyDef string) (*RuleSet, *sequenceTestListener) {
+	t.Helper()
+
+	policy, err := LoadPolicy("sequences.policy", PolicyProviderTypeDir, strings.NewReader(policyDef), nil, nil)
+	require.NoError(t, err)
+
+	rs := newRuleSet()
+	pc := ast.NewParsingContext()
+	require.NoError(t, rs.AddRules(pc, policy.Rules).ErrorOrNil())
+	require.NoError(t, rs.AddSequences(pc, policy.Sequences).ErrorOrNil())
+
+	listener := &sequenceTestListener{}
+	rs.AddListener(listener)
+
+	return rs, listener
+}
+
+func newSequenceEvent(t *testing.T, eventType model.EventType, ts time.Duration, values map[string]interface{}) eval.Event {
+	t.Helper()
+
+	event := (&model.Model{}).NewDefaultEventWithType(eventType)
+	event.Init()
+	// a zero timestamp stands for an unknown one
+	require.NoError(t, event.SetFieldValue("event.timestamp", int(time.Hour+ts)))
+	for field, value := range values {
+		require.NoError(t, event.SetFieldValue(field, value))
+	}
+	return event
+}
+
+func TestSequenceLoading(t *testing.T) {
+	rs, _ := newSequenceRuleSet(t, testSequencePolicy)
+
+	require.Len(t, rs.GetSequences(), 2)
+	assert.Len(t, rs.GetRules(), 1)
+	assert.ElementsMatch(t, []eval.EventType{"open", "exec", "bind"}, rs.GetEventTypes())
+
+	seq := rs.GetSequences()[0]
+	assert.Equal(t, "tmp_write_exec", seq.GetRule().ID)
+	assert.Equal(t, "tmp_write_exec", seq.GetRule().Definition.ID)
+	assert.Len(t, seq.GetSteps(), 2)
+
+	// the steps are taken into account by the approvers of their event type
+	caps := FieldCapabilities{{Field: "open.file.path", Types: eval.ScalarValueType | eval.PatternValueType | eval.GlobValueType}}
+	approvers, err := rs.GetEventApprovers("open", caps)
+	require.NoError(t, err)
+	assert.Len(t, approvers["open.file.path"], 3)
+}
+
+func TestSequenceLoadingErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		def  string
+		want error
+	}{
+		"single_step": {
+			def: `
+sequences:
+  - id: seq
+    within: 1s
+    steps:
+      - expression: exec.file.name == "sh"
+`,
+			want: ErrSequenceSteps,
+		},
+		"no_window": {
+			def: `
+sequences:
+  - id: seq
+    steps:
+      - expression: exec.file.name == "sh"
+      - expression: open.file.path == "/etc/passwd"
+`,
+			want: ErrSequenceWithoutWindow,
+		},
+		"key_mismatch": {
+			def: `
+sequences:
+  - id: seq
+    within: 1s
+    key: [process.pid]
+    steps:
+      - expression: exec.file.name == "sh"
+      - expression: open.file.path == "/etc/passwd"
+        key: [process.pid, container.id]
+`,
+			want: ErrSequenceKey,
+		},
+		"id_conflict": {
+			def: `
+rules:
+  - id: seq
+    expression: exec.file.name == "sh"
+sequences:
+  - id: seq
+    within: 1s
+    steps:
+      - expression: exec.file.name == "sh"
+      - expression: open.file.path == "/etc/passwd"
+`,
+			want: ErrDefinitionIDConflict,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			policy, err := LoadPolicy("sequences.policy", PolicyProviderTypeDir, strings.NewReader(tt.def), nil, nil)
+			require.NoError(t, err)
+
+			rs := newRuleSet()
+			pc := ast.NewParsingContext()
+			require.NoError(t, rs.AddRules(pc, policy.Rules).ErrorOrNil())
+			errs := rs.AddSequences(pc, policy.Sequences)
+			require.Error(t, errs.ErrorOrNil())
+
+			var rerr *ErrRuleLoad
+			require.True(t, errors.As(errs.Errors[0], &rerr))
+			assert.Equal(t, "seq", rerr.Definition.ID)
+			assert.ErrorIs(t, rerr.Err, tt.want)
+			assert.Empty(t, rs.GetSequences())
+		})
+	}
+}
+
+func TestSequenceEvaluation(t *testing.T) {
+	write := func(ts time.Duration, container, path string) eval.Event {
+		return newSequenceEvent(t, model.FileOpenEventType, ts, map[string]interface{}{
+			"container.id":   container,
+			"open.file.path": path,
+			"open.flags":     syscall.O_CREAT,
+		})
+	}
+	exec := func(ts time.Duration, container, path string) eval.Event {
+		return newSequenceEvent(t, model.ExecEventType, ts, map[string]interface{}{
+			"container.id":   container,
+			"exec.file.path": path,
+		})
+	}
+
+	t.Run("match", func(t *testing.T) {
+		rs, listener := newSequenceRuleSet(t, testSequencePolicy)
+
+		assert.False(t, rs.Evaluate(write(0, "c1", "/tmp/payload")))
+		assert.False(t, rs.Evaluate(exec(5*time.Second, "c1", "/tmp/other")))
+		assert.False(t, rs.Evaluate(exec(5*time.Second, "c2", "/tmp/payload")))
+		assert.True(t, rs.Evaluate(exec(10*time.Second, "c1", "/tmp/payload")))
+		assert.Equal(t, []string{"tmp_write_exec"}, listener.matches)
+
+		// the sequence is consumed once matched
+		assert.False(t, rs.Evaluate(exec(11*time.Second, "c1", "/tmp/payload")))
+		assert.Equal(t, 0, rs.GetSequences()[0].InProgress())
+	})
+
+	t.Run("out_of_order", func(t *testing.T) {
+		rs, listener := newSequenceRuleSet(t, testSequencePolicy)
+
+		assert.False(t, rs.Evaluate(exec(0, "c1", "/tmp/payload")))
+		assert.False(t, rs.Evaluate(write(time.Second, "c1", "/tmp/payload")))
+		assert.Empty(t, listener.matches)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		rs, listener := newSequenceRuleSet(t, testSequencePolicy)
+
+		assert.False(t, rs.Evaluate(write(0, "c1", "/tmp/payload")))
+		assert.False(t, rs.Evaluate(exec(31*time.Second, "c1", "/tmp/payload")))
+		assert.Empty(t, listener.matches)
+
+		// a new first step restarts the window
+		assert.False(t, rs.Evaluate(write(40*time.Second, "c1", "/tmp/payload")))
+		assert.True(t, rs.Evaluate(exec(50*time.Second, "c1", "/tmp/payload")))
+	})
+
+	t.Run("max_states", func(t *testing.T) {
+		rs, listener := newSequenceRuleSet(t, testSequencePolicy)
+		seq := rs.GetSequences()[0]
+
+		assert.False(t, rs.Evaluate(write(0, "c1", "/tmp/a")))
+		assert.False(t, rs.Evaluate(write(0, "c1", "/tmp/b")))
+		assert.False(t, rs.Evaluate(write(0, "c1", "/tmp/c")))
+		assert.Equal(t, 2, seq.InProgress())
+		assert.EqualValues(t, 1, seq.Evicted())
+
+		assert.False(t, rs.Evaluate(exec(time.Second, "c1", "/tmp/a")))
+		assert.True(t, rs.Evaluate(exec(time.Second, "c1", "/tmp/c")))
+		assert.Equal(t, []string{"tmp_write_exec"}, listener.matches)
+	})
+
+	t.Run("rules_still_match", func(t *testing.T) {
+		rs, listener := newSequenceRuleSet(t, testSequencePolicy)
+
+		assert.True(t, rs.Evaluate(newSequenceEvent(t, model.FileOpenEventType, 0, map[string]interface{}{
+			"open.file.path": "/etc/shadow",
+		})))
+		assert.Equal(t, []string{"shadow_open"}, listener.matches)
+	})
+}
+
+func TestSequenceThreeSteps(t *testing.T) {
+	rs, listener := newSequenceRuleSet(t, testSequencePolicy)
+
+	bind := newSequenceEvent(t, model.BindEventType, 0, map[string]interface{}{
+		"process.pid":    100,
+		"bind.addr.port": 4444,
+	})
+	shell := newSequenceEvent(t, model.ExecEventType, time.Second, map[string]interface{}{
+		"process.pid":    200,
+		"process.ppid":   100,
+		"exec.file.name": "sh",
+	})
+	passwd := newSequenceEvent(t, model.FileOpenEventType, 2*time.Second, map[string]interface{}{
+		"process.pid":    201,
+		"process.ppid":   100,
+		"open.file.path": "/etc/passwd",
+	})
+
+	// steps can't be skipped
+	assert.False(t, rs.Evaluate(bind))
+	assert.False(t, rs.Evaluate(passwd))
+	assert.False(t, rs.Evaluate(shell))
+	assert.True(t, rs.Evaluate(passwd))
+	assert.Equal(t, []string{"shell_after_bind"}, listener.matches)
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package activitytree holds activitytree related files
+package activitytree
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+const (
+	appArmorRead    = 1 << iota // r
+	appArmorWrite               // w
+	appArmorInherit             // ix
+)
+
+// appArmorNetworkFamilies maps the address families of the socket nodes to the AppArmor network domains
+var appArmorNetworkFamilies = map[string]string{
+	"AF_UNIX":  "unix",
+	"AF_INET":  "inet",
+	"AF_INET6": "inet6",
+}
+
+type appArmorRules struct {
+	reducer *PathsReducer
+	paths   map[string]int
+	network map[string]bool
+	binds   map[string]bool
+}
+
+func (r *appArmorRules) addPath(path string, perms int) {
+	if path == "" {
+		return
+	}
+	r.paths[path] |= perms
+}
+
+func openPermissions(open *OpenNode) int {
+	if open == nil {
+		return appArmorRead
+	}
+
+	var perms int
+	switch open.Flags & syscall.O_ACCMODE {
+	case syscall.O_WRONLY:
+		perms = appArmorWrite
+	case syscall.O_RDWR:
+		perms = appArmorRead | appArmorWrite
+	default:
+		perms = appArmorRead
+	}
+
+	if open.Flags&(syscall.O_CREAT|syscall.O_TRUNC|syscall.O_APPEND) != 0 {
+		perms |= appArmorWrite
+	}
+	return perms
+}
+
+func (r *appArmorRules) addFileNode(file *FileNode, parent string, process *ProcessNode) {
+	path := parent + "/" + file.Name
+
+	if file.File != nil || len(file.Children) == 0 {
+		reduced := path
+		if r.reducer != nil {
+			reduced = r.reducer.ReducePath(path, file.File, process)
+		}
+		r.addPath(reduced, openPermissions(file.Open))
+	}
+
+	for _, child := range file.Children {
+		r.addFileNode(child, path, process)
+	}
+}
+
+func (r *appArmorRules) addProcessNode(node *ProcessNode) {
+	r.addPath(node.Process.FileEvent.PathnameStr, appArmorInherit)
+
+	for _, file := range node.Files {
+		r.addFileNode(file, "", node)
+	}
+
+	for _, socket := range node.Sockets {
+		domain, ok := appArmorNetworkFamilies[socket.Family]
+		if !ok {
+			continue
+		}
+		r.network["network "+domain+","] = true
+		for _, bind := range socket.Bind {
+			r.binds[fmt.Sprintf("# %s bind %s port %d", domain, bind.IP, bind.Port)] = true
+		}
+	}
+
+	// name resolution happens over UDP on both IP versions
+	if len(node.DNSNames) > 0 {
+		r.network["network inet dgram,"] = true
+		r.network["network inet6 dgram,"] = true
+	}
+
+	for _, child := range node.Children {
+		r.addProcessNode(child)
+	}
+}
+
+func appArmorPermissions(perms int) string {
+	var b strings.Builder
+	if perms&appArmorRead != 0 {
+		b.WriteString("r")
+	}
+	if perms&appArmorWrite != 0 {
+		b.WriteString("w")
+	}
+	if perms&appArmorInherit != 0 {
+		b.WriteString("ix")
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ToAppArmorProfile generates the skeleton of an AppArmor profile from the activity tree: the executed binaries are
+// allowed with inherited confinement, the accessed files with the permissions of their open flags and the sockets with
+// network rules. File paths are reduced with the provided paths reducer, when set. The output is meant to be reviewed
+// before being enforced.
+func (at *ActivityTree) ToAppArmorProfile(name string, reducer *PathsReducer) *bytes.Buffer {
+	rules := &appArmorRules{
+		reducer: reducer,
+		paths:   make(map[string]int),
+		network: make(map[string]bool),
+		binds:   make(map[string]bool),
+	}
+	for _, node := range at.ProcessNodes {
+		rules.addProcessNode(node)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# AppArmor profile skeleton generated from an activity dump, review it before enforcing it\n")
+	b.WriteString("#include <tunables/global>\n\n")
+	fmt.Fprintf(&b, "profile %s flags=(attach_disconnected,mediate_deleted) {\n", name)
+	b.WriteString("  #include <abstractions/base>\n")
+
+	if len(rules.network) > 0 {
+		b.WriteString("\n")
+		for _, rule := range sortedKeys(rules.network) {
+			fmt.Fprintf(&b, "  %s\n", rule)
+		}
+		for _, bind := range sortedKeys(rules.binds) {
+			fmt.Fprintf(&b, "  %s\n", bind)
+		}
+	}
+
+	if len(rules.paths) > 0 {
+		b.WriteString("\n")
+		for _, path := range sortedKeys(rules.paths) {
+			perms := appArmorPermissions(rules.paths[path])
+			if strings.ContainsAny(path, " \t") {
+				path = `"` + path + `"`
+			}
+			fmt.Fprintf(&b, "  %s %s,\n", path, perms)
+		}
+	}
+
+	b.WriteString("}\n")
+	return &b
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package activitytree holds activitytree related files
+package activitytree
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+func newTestProfilesTree() *ActivityTree {
+	child := &ProcessNode{
+		Files: map[string]*FileNode{
+			"tmp": {
+				Name: "tmp",
+				Children: map[string]*FileNode{
+					"out.log": {
+						Name: "out.log",
+						File: &model.FileEvent{},
+						Open: &OpenNode{Flags: syscall.O_WRONLY | syscall.O_CREAT},
+					},
+				},
+			},
+		},
+		Syscalls: []int{int(model.SysRtSigaction), int(model.SysWrite)},
+	}
+	child.Process.Pid = 43
+	child.Process.FileEvent.PathnameStr = "/bin/sh"
+
+	root := &ProcessNode{
+		Files: map[string]*FileNode{
+			"etc": {
+				Name: "etc",
+				Children: map[string]*FileNode{
+					"passwd": {
+						Name: "passwd",
+						File: &model.FileEvent{},
+						Open: &OpenNode{Flags: syscall.O_RDONLY},
+					},
+				},
+			},
+			"proc": {
+				Name: "proc",
+				Children: map[string]*FileNode{
+					"1234": {
+						Name: "1234",
+						Children: map[string]*FileNode{
+							"status": {Name: "status"},
+						},
+					},
+				},
+			},
+		},
+		DNSNames: map[string]*DNSNode{"example.com": {}},
+		Sockets: []*SocketNode{
+			{Family: "AF_INET", Bind: []*BindNode{{IP: "0.0.0.0", Port: 8080}}},
+		},
+		Syscalls: []int{int(model.SysRead), int(model.SysWrite), int(model.SysEpollCreate1)},
+		Children: []*ProcessNode{child},
+	}
+	root.Process.Pid = 42
+	root.Process.FileEvent.PathnameStr = "/usr/bin/server"
+
+	return &ActivityTree{ProcessNodes: []*ProcessNode{root}}
+}
+
+func TestSeccompSyscallName(t *testing.T) {
+	for syscall, want := range map[model.Syscall]string{
+		model.SysRead:         "read",
+		model.SysRtSigaction:  "rt_sigaction",
+		model.SysPread64:      "pread64",
+		model.SysEpollCreate1: "epoll_create1",
+		model.SysIoUringSetup: "io_uring_setup",
+	} {
+		name, ok := SeccompSyscallName(syscall)
+		assert.True(t, ok)
+		assert.Equal(t, want, name)
+	}
+
+	_, ok := SeccompSyscallName(model.Syscall(-1))
+	assert.False(t, ok)
+}
+
+func TestToSeccompProfile(t *testing.T) {
+	profile, err := newTestProfilesTree().ToSeccompProfile(utils.RuntimeArch())
+	require.NoError(t, err)
+
+	assert.Equal(t, SeccompActionErrno, profile.DefaultAction)
+	assert.NotEmpty(t, profile.Architectures)
+	require.Len(t, profile.Syscalls, 1)
+	assert.Equal(t, SeccompActionAllow, profile.Syscalls[0].Action)
+	assert.Equal(t, []string{"epoll_create1", "read", "rt_sigaction", "write"}, profile.Syscalls[0].Names)
+
+	_, err = (&ActivityTree{}).ToSeccompProfile("")
+	assert.ErrorIs(t, err, ErrNoSyscalls)
+
+	_, err = newTestProfilesTree().ToSeccompProfile("unknown")
+	assert.Error(t, err)
+}
+
+func TestToAppArmorProfile(t *testing.T) {
+	profile := newTestProfilesTree().ToAppArmorProfile("server", NewPathsReducer())
+
+	assert.Equal(t, `# AppArmor profile skeleton generated from an activity dump, review it before enforcing it
+#include <tunables/global>
+
+profile server flags=(attach_disconnected,mediate_deleted) {
+  #include <abstractions/base>
+
+  network inet dgram,
+  network inet,
+  network inet6 dgram,
+  # inet bind 0.0.0.0 port 8080
+
+  /bin/sh ix,
+  /etc/passwd r,
+  /proc/*/status r,
+  /tmp/out.log w,
+  /usr/bin/server ix,
+}
+`, profile.String())
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package activitytree holds activitytree related files
+package activitytree
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+const (
+	// SeccompActionAllow is the seccomp action used for the syscalls observed in the activity tree
+	SeccompActionAllow = "SCMP_ACT_ALLOW"
+	// SeccompActionErrno is the seccomp action used for the syscalls not observed in the activity tree
+	SeccompActionErrno = "SCMP_ACT_ERRNO"
+)
+
+// ErrNoSyscalls is returned when a seccomp profile is requested for an activity tree without any syscall
+var ErrNoSyscalls = errors.New("no syscall recorded in the activity tree")
+
+// SeccompSyscalls holds a seccomp rule applying the same action to a list of syscalls
+type SeccompSyscalls struct {
+	Names  []string `json:"names"`
+	Action string   `json:"action"`
+}
+
+// SeccompProfile is a seccomp profile as expected by Docker and Kubernetes
+type SeccompProfile struct {
+	DefaultAction string            `json:"defaultAction"`
+	Architectures []string          `json:"architectures,omitempty"`
+	Syscalls      []SeccompSyscalls `json:"syscalls"`
+}
+
+// seccompArchitectures returns the seccomp architectures matching the architecture of an activity dump
+func seccompArchitectures(arch string) []string {
+	switch arch {
+	case "x64":
+		return []string{"SCMP_ARCH_X86_64", "SCMP_ARCH_X86", "SCMP_ARCH_X32"}
+	case "arm64":
+		return []string{"SCMP_ARCH_AARCH64", "SCMP_ARCH_ARM"}
+	default:
+		return nil
+	}
+}
+
+// SeccompSyscallName returns the name of a syscall as used by seccomp profiles, `SysRtSigaction` becomes `rt_sigaction`
+func SeccompSyscallName(syscall model.Syscall) (string, bool) {
+	// unknown syscalls are formatted as `Syscall(<number>)`
+	name, found := strings.CutPrefix(syscall.String(), "Sys")
+	if !found || name == "" || strings.ContainsRune(name, '(') {
+		return "", false
+	}
+
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), true
+}
+
+// ToSeccompProfile generates a seccomp profile allowing the syscalls recorded in the activity tree. The syscall
+// numbers are resolved with the syscall table of the running architecture, arch is the architecture of the activity
+// dump and must match it.
+func (at *ActivityTree) ToSeccompProfile(arch string) (*SeccompProfile, error) {
+	if arch != "" && arch != utils.RuntimeArch() {
+		return nil, fmt.Errorf("can't resolve the syscalls of a %s activity dump on %s", arch, utils.RuntimeArch())
+	}
+	if arch == "" {
+		arch = utils.RuntimeArch()
+	}
+
+	syscalls := make(map[int]bool)
+	var collect func(nodes []*ProcessNode)
+	collect = func(nodes []*ProcessNode) {
+		for _, node := range nodes {
+			for _, syscall := range node.Syscalls {
+				syscalls[syscall] = true
+			}
+			collect(node.Children)
+		}
+	}
+	collect(at.ProcessNodes)
+
+	names := make([]string, 0, len(syscalls))
+	for syscall := range syscalls {
+		if name, ok := SeccompSyscallName(model.Syscall(syscall)); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, ErrNoSyscalls
+	}
+	sort.Strings(names)
+
+	return &SeccompProfile{
+		DefaultAction: SeccompActionErrno,
+		Architectures: seccompArchitectures(arch),
+		Syscalls: []SeccompSyscalls{
+			{
+				Names:  names,
+				Action: SeccompActionAllow,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+// rootNodes are the OID roots every module can reference without importing them
+var rootNodes = map[string]OID{
+	"ccitt":           {0},
+	"iso":             {1},
+	"joint-iso-ccitt": {2},
+}
+
+// builtinModules are the modules defining the SMI itself. They are mostly made
+// of macro definitions, only their OID assignments and textual conventions are
+// kept here. They take precedence over the files of the MIB directories.
+var builtinModules = map[string]string{
+	"SNMPv2-SMI": `SNMPv2-SMI DEFINITIONS ::= BEGIN
+org            OBJECT IDENTIFIER ::= { iso 3 }
+dod            OBJECT IDENTIFIER ::= { org 6 }
+internet       OBJECT IDENTIFIER ::= { dod 1 }
+directory      OBJECT IDENTIFIER ::= { internet 1 }
+mgmt           OBJECT IDENTIFIER ::= { internet 2 }
+mib-2          OBJECT IDENTIFIER ::= { mgmt 1 }
+transmission   OBJECT IDENTIFIER ::= { mib-2 10 }
+experimental   OBJECT IDENTIFIER ::= { internet 3 }
+private        OBJECT IDENTIFIER ::= { internet 4 }
+enterprises    OBJECT IDENTIFIER ::= { private 1 }
+security       OBJECT IDENTIFIER ::= { internet 5 }
+snmpV2         OBJECT IDENTIFIER ::= { internet 6 }
+snmpDomains    OBJECT IDENTIFIER ::= { snmpV2 1 }
+snmpProxys     OBJECT IDENTIFIER ::= { snmpV2 2 }
+snmpModules    OBJECT IDENTIFIER ::= { snmpV2 3 }
+zeroDotZero    OBJECT IDENTIFIER ::= { 0 0 }
+END`,
+
+	"RFC1155-SMI": `RFC1155-SMI DEFINITIONS ::= BEGIN
+org            OBJECT IDENTIFIER ::= { iso 3 }
+dod            OBJECT IDENTIFIER ::= { org 6 }
+internet       OBJECT IDENTIFIER ::= { dod 1 }
+directory      OBJECT IDENTIFIER ::= { internet 1 }
+mgmt           OBJECT IDENTIFIER ::= { internet 2 }
+experimental   OBJECT IDENTIFIER ::= { internet 3 }
+private        OBJECT IDENTIFIER ::= { internet 4 }
+enterprises    OBJECT IDENTIFIER ::= { private 1 }
+ObjectName ::= OBJECT IDENTIFIER
+END`,
+
+	"RFC-1212": `RFC-1212 DEFINITIONS ::= BEGIN
+END`,
+
+	"RFC-1215": `RFC-1215 DEFINITIONS ::= BEGIN
+END`,
+
+	"SNMPv2-CONF": `SNMPv2-CONF DEFINITIONS ::= BEGIN
+END`,
+
+	"SNMPv2-TC": `SNMPv2-TC DEFINITIONS ::= BEGIN
+DisplayString ::= TEXTUAL-CONVENTION DISPLAY-HINT "255a" SYNTAX OCTET STRING (SIZE (0..255))
+PhysAddress ::= TEXTUAL-CONVENTION DISPLAY-HINT "1x:" SYNTAX OCTET STRING
+MacAddress ::= TEXTUAL-CONVENTION DISPLAY-HINT "1x:" SYNTAX OCTET STRING (SIZE (6))
+TruthValue ::= TEXTUAL-CONVENTION SYNTAX INTEGER { true(1), false(2) }
+TestAndIncr ::= TEXTUAL-CONVENTION SYNTAX INTEGER (0..2147483647)
+AutonomousType ::= TEXTUAL-CONVENTION SYNTAX OBJECT IDENTIFIER
+InstancePointer ::= TEXTUAL-CONVENTION SYNTAX OBJECT IDENTIFIER
+VariablePointer ::= TEXTUAL-CONVENTION SYNTAX OBJECT IDENTIFIER
+RowPointer ::= TEXTUAL-CONVENTION SYNTAX OBJECT IDENTIFIER
+RowStatus ::= TEXTUAL-CONVENTION SYNTAX INTEGER {
+    active(1), notInService(2), notReady(3), createAndGo(4), createAndWait(5), destroy(6) }
+TimeStamp ::= TEXTUAL-CONVENTION SYNTAX TimeTicks
+TimeInterval ::= TEXTUAL-CONVENTION SYNTAX INTEGER (0..2147483647)
+DateAndTime ::= TEXTUAL-CONVENTION DISPLAY-HINT "2d-1d-1d,1d:1d:1d.1d,1a1d:1d" SYNTAX OCTET STRING (SIZE (8 | 11))
+StorageType ::= TEXTUAL-CONVENTION SYNTAX INTEGER {
+    other(1), volatile(2), nonVolatile(3), permanent(4), readOnly(5) }
+TDomain ::= TEXTUAL-CONVENTION SYNTAX OBJECT IDENTIFIER
+TAddress ::= TEXTUAL-CONVENTION SYNTAX OCTET STRING (SIZE (1..255))
+END`,
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenBinString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tokenize splits the content of a MIB file in ASN.1 tokens, comments being
+// dropped. A comment starts with `--` and ends at the end of the line or at the
+// next `--`.
+func tokenize(data string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case c == '-' && i+1 < len(data) && data[i+1] == '-':
+			i += 2
+			for i < len(data) && data[i] != '\n' {
+				if data[i] == '-' && i+1 < len(data) && data[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			end := strings.IndexByte(data[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			text := data[i+1 : i+1+end]
+			tokens = append(tokens, token{kind: tokenString, text: text, line: line})
+			line += strings.Count(text, "\n")
+			i += end + 2
+		case c == '\'':
+			start := i
+			end := strings.IndexByte(data[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated binary string", line)
+			}
+			i += end + 2
+			// the radix, `H` or `B`, follows the closing quote
+			if i < len(data) && (data[i] == 'H' || data[i] == 'h' || data[i] == 'B' || data[i] == 'b') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenBinString, text: data[start:i], line: line})
+		case strings.HasPrefix(data[i:], "::="):
+			tokens = append(tokens, token{kind: tokenSymbol, text: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(data[i:], ".."):
+			tokens = append(tokens, token{kind: tokenSymbol, text: "..", line: line})
+			i += 2
+		case isDigit(c) || (c == '-' && i+1 < len(data) && isDigit(data[i+1])):
+			start := i
+			i++
+			for i < len(data) && isDigit(data[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: data[start:i], line: line})
+		case isIdentChar(c):
+			start := i
+			for i < len(data) && isIdentChar(data[i]) {
+				// identifiers can't hold a comment start
+				if data[i] == '-' && i+1 < len(data) && data[i+1] == '-' {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: data[start:i], line: line})
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), line: line})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
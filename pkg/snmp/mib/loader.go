@@ -0,0 +1,372 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxResolutionDepth bounds the resolution of OIDs and types to detect cycles
+const maxResolutionDepth = 64
+
+// maxMIBFileSize is the size above which files of the MIB directories are ignored
+const maxMIBFileSize = 16 * 1024 * 1024
+
+var moduleHeaderRegexp = regexp.MustCompile(`(?m)^\s*([A-Za-z][A-Za-z0-9-]*)\s+DEFINITIONS\s*(?:[A-Z]+\s+TAGS\s*)?::=\s*BEGIN`)
+
+// snmpOID is the OID of the SNMP group of MIB-II, the enterprise of the SMIv1
+// generic traps
+var snmpOID = OID{1, 3, 6, 1, 2, 1, 11}
+
+// snmpTrapsOID is the OID the SMIv1 generic traps are mapped to by RFC 3584
+var snmpTrapsOID = OID{1, 3, 6, 1, 6, 3, 1, 1, 5}
+
+// Syntax is the resolved syntax of an object
+type Syntax struct {
+	// Base is the SMI base type of the syntax
+	Base string
+	// Enums are the named numbers of an INTEGER or the named bits of BITS,
+	// possibly inherited from a textual convention
+	Enums []NamedNumber
+	// TextualConvention is the name of the type referenced by the syntax, if any
+	TextualConvention string
+	// DisplayHint is the display hint of the textual convention, if any
+	DisplayHint string
+	// SequenceOf is the entry type of a table
+	SequenceOf string
+}
+
+// Loader loads MIB modules from a list of directories and resolves the
+// references between them. The modules are found by their name, whatever the
+// name of the file defining them.
+type Loader struct {
+	dirs    []string
+	modules map[string]*Module
+	files   map[string]string
+}
+
+// NewLoader returns a loader looking for the MIB modules in the given
+// directories, the first ones taking precedence.
+func NewLoader(dirs ...string) *Loader {
+	return &Loader{
+		dirs:    dirs,
+		modules: make(map[string]*Module),
+	}
+}
+
+// indexFiles maps the module names to the files defining them
+func (l *Loader) indexFiles() map[string]string {
+	if l.files != nil {
+		return l.files
+	}
+	l.files = make(map[string]string)
+	for _, dir := range l.dirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err != nil || !info.Mode().IsRegular() || info.Size() > maxMIBFileSize {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			for _, match := range moduleHeaderRegexp.FindAllSubmatch(data, -1) {
+				name := string(match[1])
+				if _, ok := l.files[name]; !ok {
+					l.files[name] = path
+				}
+			}
+			return nil
+		})
+	}
+	return l.files
+}
+
+// Load loads the module of the given name along with the modules it imports
+func (l *Loader) Load(name string) (*Module, error) {
+	if module, ok := l.modules[name]; ok {
+		return module, nil
+	}
+
+	var modules []*Module
+	var err error
+	if source, ok := builtinModules[name]; ok {
+		modules, err = Parse([]byte(source), "")
+	} else if path, ok := l.indexFiles()[name]; ok {
+		modules, err = l.parseFile(path)
+	} else {
+		return nil, fmt.Errorf("MIB module %s not found", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := l.addModules(modules); err != nil {
+		return nil, err
+	}
+
+	module, ok := l.modules[name]
+	if !ok {
+		return nil, fmt.Errorf("MIB module %s not found", name)
+	}
+	return module, nil
+}
+
+// LoadFile loads the modules defined in the given file along with the modules
+// they import
+func (l *Loader) LoadFile(path string) ([]*Module, error) {
+	modules, err := l.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.addModules(modules); err != nil {
+		return nil, err
+	}
+	loaded := make([]*Module, 0, len(modules))
+	for _, module := range modules {
+		loaded = append(loaded, l.modules[module.Name])
+	}
+	return loaded, nil
+}
+
+// LoadAll loads all the modules found in the directories of the loader. The
+// modules failing to load are reported in the returned error, the other ones
+// are still loaded.
+func (l *Loader) LoadAll() ([]*Module, error) {
+	names := make([]string, 0, len(l.indexFiles()))
+	for name := range l.indexFiles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var modules []*Module
+	var errs []error
+	for _, name := range names {
+		module, err := l.Load(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not load MIB module %s: %w", name, err))
+			continue
+		}
+		modules = append(modules, module)
+	}
+	return modules, errors.Join(errs...)
+}
+
+func (l *Loader) parseFile(path string) ([]*Module, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, path)
+}
+
+func (l *Loader) addModules(modules []*Module) error {
+	var added []*Module
+	for _, module := range modules {
+		if _, ok := l.modules[module.Name]; !ok {
+			l.modules[module.Name] = module
+			added = append(added, module)
+		}
+	}
+	for _, module := range added {
+		for _, imported := range module.Imports() {
+			if _, err := l.Load(imported); err != nil {
+				delete(l.modules, module.Name)
+				return fmt.Errorf("module %s imports %s: %w", module.Name, imported, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Modules returns the loaded modules sorted by name
+func (l *Loader) Modules() []*Module {
+	modules := make([]*Module, 0, len(l.modules))
+	for _, module := range l.modules {
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Name < modules[j].Name
+	})
+	return modules
+}
+
+// Find returns the node of the given name among the loaded modules. The name
+// can be qualified with its module name, like `IF-MIB::ifTable`.
+func (l *Loader) Find(name string) (*Node, error) {
+	if moduleName, nodeName, ok := strings.Cut(name, "::"); ok {
+		module, ok := l.modules[moduleName]
+		if !ok {
+			return nil, fmt.Errorf("MIB module %s is not loaded", moduleName)
+		}
+		if node, ok := module.nodes[nodeName]; ok {
+			return node, nil
+		}
+		return nil, fmt.Errorf("%s not found in MIB module %s", nodeName, moduleName)
+	}
+	for _, module := range l.Modules() {
+		if node, ok := module.nodes[name]; ok {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not found in the loaded MIB modules", name)
+}
+
+// Lookup returns the node of the given name as seen from a module: either
+// defined by the module or imported by it.
+func (l *Loader) Lookup(module *Module, name string) (*Node, error) {
+	for depth := 0; depth < maxResolutionDepth; depth++ {
+		if node, ok := module.nodes[name]; ok {
+			return node, nil
+		}
+		from, ok := module.imports[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not defined nor imported by MIB module %s", name, module.Name)
+		}
+		if module, ok = l.modules[from]; !ok {
+			return nil, fmt.Errorf("MIB module %s is not loaded", from)
+		}
+	}
+	return nil, fmt.Errorf("too many imports resolving %s", name)
+}
+
+// OID returns the resolved OID of a node
+func (l *Loader) OID(node *Node) (OID, error) {
+	return l.resolveOID(node, 0)
+}
+
+func (l *Loader) resolveOID(node *Node, depth int) (OID, error) {
+	if node.oid != nil {
+		return node.oid, nil
+	}
+	if depth > maxResolutionDepth {
+		return nil, fmt.Errorf("cyclic OID definition of %s", node.Name)
+	}
+
+	var oid OID
+	if node.Kind == KindTrapType {
+		enterprise, err := l.Lookup(node.module, node.Enterprise)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve the enterprise of %s: %w", node.Name, err)
+		}
+		enterpriseOID, err := l.resolveOID(enterprise, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		// RFC 3584 maps the SMIv1 traps to SMIv2 notification OIDs
+		if enterpriseOID.String() == snmpOID.String() {
+			oid = append(append(oid, snmpTrapsOID...), node.TrapNumber+1)
+		} else {
+			oid = append(append(oid, enterpriseOID...), 0, node.TrapNumber)
+		}
+		node.oid = oid
+		return oid, nil
+	}
+
+	for i, component := range node.value {
+		switch {
+		case i > 0 || (component.hasNum && component.name == ""):
+			if !component.hasNum {
+				return nil, fmt.Errorf("invalid OID value of %s: missing number for %s", node.Name, component.name)
+			}
+			oid = append(oid, component.number)
+		case component.hasNum:
+			oid = append(oid, component.number)
+		default:
+			if root, ok := rootNodes[component.name]; ok {
+				oid = append(oid, root...)
+				continue
+			}
+			parent, err := l.Lookup(node.module, component.name)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve the OID of %s: %w", node.Name, err)
+			}
+			parentOID, err := l.resolveOID(parent, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			oid = append(oid, parentOID...)
+		}
+	}
+	node.oid = oid
+	return oid, nil
+}
+
+// Syntax returns the resolved syntax of a node
+func (l *Loader) Syntax(node *Node) (*Syntax, error) {
+	if node.Syntax == nil {
+		return nil, fmt.Errorf("%s has no syntax", node.Name)
+	}
+
+	syntax := &Syntax{SequenceOf: node.Syntax.SequenceOf}
+	module, typ := node.module, node.Syntax
+	for depth := 0; depth < maxResolutionDepth; depth++ {
+		if syntax.Enums == nil && len(typ.Enums) > 0 {
+			syntax.Enums = typ.Enums
+		}
+		if syntax.DisplayHint == "" {
+			syntax.DisplayHint = typ.DisplayHint
+		}
+		if base, ok := baseTypes[typ.Name]; ok {
+			syntax.Base = base
+			return syntax, nil
+		}
+		if syntax.TextualConvention == "" {
+			syntax.TextualConvention = typ.Name
+		}
+
+		name := typ.Name
+		for {
+			if t, ok := module.types[name]; ok {
+				typ = t
+				break
+			}
+			from, ok := module.imports[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown type %s in the syntax of %s", name, node.Name)
+			}
+			if module, ok = l.modules[from]; !ok {
+				return nil, fmt.Errorf("MIB module %s is not loaded", from)
+			}
+			if depth++; depth > maxResolutionDepth {
+				break
+			}
+		}
+	}
+	return nil, fmt.Errorf("too many type references in the syntax of %s", node.Name)
+}
+
+// Children returns the nodes of the loaded modules whose OID is directly
+// under the one of the given node, ordered by OID.
+func (l *Loader) Children(node *Node) ([]*Node, error) {
+	parentOID, err := l.OID(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*Node
+	for _, module := range l.Modules() {
+		for _, n := range module.Nodes {
+			oid, err := l.OID(n)
+			if err != nil || len(oid) != len(parentOID)+1 || oid[:len(parentOID)].String() != parentOID.String() {
+				continue
+			}
+			children = append(children, n)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].oid[len(parentOID)] < children[j].oid[len(parentOID)]
+	})
+	return children, nil
+}
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findOID(t *testing.T, l *Loader, name string) string {
+	node, err := l.Find(name)
+	require.NoError(t, err)
+	oid, err := l.OID(node)
+	require.NoError(t, err)
+	return oid.String()
+}
+
+func TestLoaderResolveOIDs(t *testing.T) {
+	l := NewLoader("testdata")
+	module, err := l.Load("ACME-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, "testdata/ACME-MIB.mib", module.File)
+
+	var loaded []string
+	for _, m := range l.Modules() {
+		loaded = append(loaded, m.Name)
+	}
+	assert.Equal(t, []string{"ACME-MIB", "ACME-TC", "SNMPv2-CONF", "SNMPv2-SMI", "SNMPv2-TC"}, loaded)
+
+	assert.Equal(t, "1.3.6.1.4.1.99999", findOID(t, l, "acme"))
+	assert.Equal(t, "1.3.6.1.4.1.99999.2.1.1", findOID(t, l, "acmeUptime"))
+	assert.Equal(t, "1.3.6.1.4.1.99999.2.1.10.1.6", findOID(t, l, "ACME-MIB::acmePortInOctets"))
+	assert.Equal(t, "1.3.6.1.4.1.99999.2.0.1", findOID(t, l, "acmePortDown"))
+
+	_, err = l.Find("ACME-TC::acmePortInOctets")
+	assert.Error(t, err)
+	_, err = l.Find("unknown")
+	assert.Error(t, err)
+}
+
+func TestLoaderSyntax(t *testing.T) {
+	l := NewLoader("testdata")
+	_, err := l.Load("ACME-MIB")
+	require.NoError(t, err)
+
+	for name, expected := range map[string]Syntax{
+		"acmeUptime":       {Base: TypeTimeTicks},
+		"acmeFirmware":     {Base: TypeOctetString, TextualConvention: "DisplayString", DisplayHint: "255a"},
+		"acmeStatus":       {Base: TypeInteger, TextualConvention: "AcmeOperStatus", Enums: []NamedNumber{{"ok", 1}, {"degraded", 2}, {"failed", 3}}},
+		"acmeMemoryFree":   {Base: TypeUnsigned32, TextualConvention: "AcmeKilobytes", DisplayHint: "d"},
+		"acmePortTable":    {Base: typeSequence, SequenceOf: "AcmePortEntry"},
+		"acmePortEntry":    {Base: typeSequence, TextualConvention: "AcmePortEntry"},
+		"acmePortMac":      {Base: TypeOctetString, TextualConvention: "MacAddress", DisplayHint: "1x:"},
+		"acmePortEnabled":  {Base: TypeInteger, TextualConvention: "TruthValue", Enums: []NamedNumber{{"true", 1}, {"false", 2}}},
+		"acmePortInOctets": {Base: TypeCounter64},
+	} {
+		node, err := l.Find(name)
+		require.NoError(t, err)
+		syntax, err := l.Syntax(node)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, *syntax, name)
+	}
+}
+
+func TestLoaderChildren(t *testing.T) {
+	l := NewLoader("testdata")
+	_, err := l.Load("ACME-MIB")
+	require.NoError(t, err)
+
+	entry, err := l.Find("acmePortEntry")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acmePortIndex"}, entry.Index)
+
+	columns, err := l.Children(entry)
+	require.NoError(t, err)
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	assert.Equal(t, []string{
+		"acmePortIndex", "acmePortName", "acmePortMac", "acmePortStatus", "acmePortEnabled",
+		"acmePortInOctets", "acmePortOutOctets", "acmePortErrors", "acmePortSpeed", "acmePortPeer",
+	}, names)
+}
+
+func TestLoaderSMIv1(t *testing.T) {
+	l := NewLoader("testdata")
+	modules, err := l.LoadFile("testdata/ACME-TRAP-MIB")
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+	assert.Equal(t, "ACME-TRAP-MIB", modules[0].Name)
+	assert.Equal(t, "RFC1213-MIB", modules[1].Name)
+
+	trap, err := l.Find("acmeFanFailure")
+	require.NoError(t, err)
+	assert.Equal(t, KindTrapType, trap.Kind)
+	assert.Equal(t, []string{"acmeFanName", "acmeFanState"}, trap.Objects)
+	assert.Equal(t, "1.3.6.1.4.1.99999.3.0.7", findOID(t, l, "acmeFanFailure"))
+
+	name, err := l.Find("acmeFanName")
+	require.NoError(t, err)
+	syntax, err := l.Syntax(name)
+	require.NoError(t, err)
+	assert.Equal(t, Syntax{Base: TypeOctetString, TextualConvention: "DisplayString"}, *syntax)
+}
+
+func TestLoaderGenericTrap(t *testing.T) {
+	modules, err := Parse([]byte(`GENERIC-TRAPS DEFINITIONS ::= BEGIN
+IMPORTS snmp FROM RFC1213-MIB TRAP-TYPE FROM RFC-1215;
+linkDown TRAP-TYPE
+    ENTERPRISE snmp
+    DESCRIPTION "A link went down."
+    ::= 2
+END`), "GENERIC-TRAPS")
+	require.NoError(t, err)
+
+	l := NewLoader("testdata")
+	require.NoError(t, l.addModules(modules))
+	assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", findOID(t, l, "linkDown"))
+}
+
+func TestLoaderAll(t *testing.T) {
+	l := NewLoader("testdata")
+	modules, err := l.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, modules, 4)
+
+	_, err = NewLoader("testdata").Load("MISSING-MIB")
+	assert.Error(t, err)
+}
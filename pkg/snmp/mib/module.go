@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package mib parses SMIv1 and SMIv2 MIB modules and resolves the OIDs and
+// syntaxes of their definitions.
+package mib
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NodeKind is the kind of macro a MIB node is defined with
+type NodeKind int
+
+const (
+	// KindObjectIdentifier is a plain `OBJECT IDENTIFIER` value assignment
+	KindObjectIdentifier NodeKind = iota
+	// KindObjectType is an `OBJECT-TYPE`: a scalar, a table, a table entry or a column
+	KindObjectType
+	// KindNotificationType is a SMIv2 `NOTIFICATION-TYPE`
+	KindNotificationType
+	// KindTrapType is a SMIv1 `TRAP-TYPE`
+	KindTrapType
+	// KindModuleIdentity is a `MODULE-IDENTITY`
+	KindModuleIdentity
+	// KindObjectIdentity is an `OBJECT-IDENTITY`
+	KindObjectIdentity
+	// KindConformance is any of the conformance macros, like `OBJECT-GROUP` or `MODULE-COMPLIANCE`
+	KindConformance
+)
+
+// macroKinds maps the node macros to their kind
+var macroKinds = map[string]NodeKind{
+	"OBJECT-TYPE":        KindObjectType,
+	"NOTIFICATION-TYPE":  KindNotificationType,
+	"TRAP-TYPE":          KindTrapType,
+	"MODULE-IDENTITY":    KindModuleIdentity,
+	"OBJECT-IDENTITY":    KindObjectIdentity,
+	"OBJECT-GROUP":       KindConformance,
+	"NOTIFICATION-GROUP": KindConformance,
+	"MODULE-COMPLIANCE":  KindConformance,
+	"AGENT-CAPABILITIES": KindConformance,
+}
+
+// Base types of the SMI, any syntax resolves to one of them
+const (
+	TypeInteger          = "INTEGER"
+	TypeOctetString      = "OCTET STRING"
+	TypeObjectIdentifier = "OBJECT IDENTIFIER"
+	TypeBits             = "BITS"
+	TypeInteger32        = "Integer32"
+	TypeUnsigned32       = "Unsigned32"
+	TypeCounter32        = "Counter32"
+	TypeCounter64        = "Counter64"
+	TypeGauge32          = "Gauge32"
+	TypeTimeTicks        = "TimeTicks"
+	TypeIPAddress        = "IpAddress"
+	TypeOpaque           = "Opaque"
+	// typeSequence is the syntax of table entries
+	typeSequence = "SEQUENCE"
+)
+
+// baseTypes maps the names of the SMI base types, including the SMIv1 ones,
+// to their SMIv2 equivalent
+var baseTypes = map[string]string{
+	TypeInteger:          TypeInteger,
+	TypeOctetString:      TypeOctetString,
+	TypeObjectIdentifier: TypeObjectIdentifier,
+	TypeBits:             TypeBits,
+	"BIT STRING":         TypeBits,
+	TypeInteger32:        TypeInteger32,
+	TypeUnsigned32:       TypeUnsigned32,
+	TypeCounter32:        TypeCounter32,
+	TypeCounter64:        TypeCounter64,
+	TypeGauge32:          TypeGauge32,
+	TypeTimeTicks:        TypeTimeTicks,
+	TypeIPAddress:        TypeIPAddress,
+	TypeOpaque:           TypeOpaque,
+	"Counter":            TypeCounter32,
+	"Gauge":              TypeGauge32,
+	"NetworkAddress":     TypeIPAddress,
+	typeSequence:         typeSequence,
+}
+
+// OID is an object identifier
+type OID []uint32
+
+// String returns the dotted representation of the OID
+func (o OID) String() string {
+	parts := make([]string, len(o))
+	for i, n := range o {
+		parts[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// NamedNumber is a named value of an enumeration or a named bit
+type NamedNumber struct {
+	Name  string
+	Value int64
+}
+
+// Type is a syntax as written in a MIB, referencing either a base type or a
+// named type defined by a type assignment or a textual convention.
+type Type struct {
+	// Name is the base type or the referenced type name
+	Name string
+	// Enums are the named numbers of an INTEGER or the named bits of BITS
+	Enums []NamedNumber
+	// SequenceOf is the entry type of a `SEQUENCE OF` syntax, used by tables
+	SequenceOf string
+	// DisplayHint is the display hint of a textual convention
+	DisplayHint string
+}
+
+// oidComponent is a sub-identifier of an OID value, like `ifEntry`, `2` or `org(3)`
+type oidComponent struct {
+	name   string
+	number uint32
+	hasNum bool
+}
+
+// Node is a definition of a MIB module assigned to an OID
+type Node struct {
+	Name        string
+	Kind        NodeKind
+	Syntax      *Type
+	Access      string
+	Status      string
+	Description string
+	Units       string
+	// Index lists the INDEX objects of a table entry
+	Index []string
+	// Augments is the table entry augmented by a table entry
+	Augments string
+	// Objects lists the OBJECTS of a notification or the VARIABLES of a trap
+	Objects []string
+	// Enterprise is the ENTERPRISE of a SMIv1 trap
+	Enterprise string
+	// TrapNumber is the specific trap number of a SMIv1 trap
+	TrapNumber uint32
+
+	module *Module
+	value  []oidComponent
+	oid    OID
+}
+
+// Module returns the module the node is defined in
+func (n *Node) Module() *Module {
+	return n.module
+}
+
+// Module is a MIB module
+type Module struct {
+	Name string
+	// File is the path of the file the module was read from, empty for
+	// the built-in modules
+	File string
+	// Nodes lists the OID assignments in their definition order
+	Nodes []*Node
+
+	imports map[string]string
+	nodes   map[string]*Node
+	types   map[string]*Type
+}
+
+func newModule(name string) *Module {
+	return &Module{
+		Name:    name,
+		imports: make(map[string]string),
+		nodes:   make(map[string]*Node),
+		types:   make(map[string]*Type),
+	}
+}
+
+// Imports returns the names of the modules imported by the module
+func (m *Module) Imports() []string {
+	seen := make(map[string]bool)
+	var modules []string
+	for _, module := range m.imports {
+		if !seen[module] {
+			seen[module] = true
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// Node returns the node of the given name defined in the module
+func (m *Module) Node(name string) (*Node, bool) {
+	n, ok := m.nodes[name]
+	return n, ok
+}
+
+func (m *Module) addNode(n *Node) {
+	n.module = m
+	if prev, ok := m.nodes[n.Name]; ok {
+		for i := range m.Nodes {
+			if m.Nodes[i] == prev {
+				m.Nodes[i] = n
+			}
+		}
+	} else {
+		m.Nodes = append(m.Nodes, n)
+	}
+	m.nodes[n.Name] = n
+}
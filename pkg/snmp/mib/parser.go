@@ -0,0 +1,550 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses the MIB modules defined in the given content. The file name is
+// only used to report errors and is recorded in the modules.
+func Parse(data []byte, filename string) ([]*Module, error) {
+	tokens, err := tokenize(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	p := &parser{tokens: tokens}
+
+	var modules []*Module
+	for p.peek().kind != tokenEOF {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		module.File = filename
+		modules = append(modules, module)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("%s: no MIB module found", filename)
+	}
+	return modules, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return t.kind != tokenString && t.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.kind == tokenString || t.text != text {
+		return fmt.Errorf("line %d: expected %q, found %s", t.line, text, t)
+	}
+	return nil
+}
+
+func (p *parser) expectKind(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("line %d: expected %s, found %s", t.line, what, t)
+	}
+	return t, nil
+}
+
+// skipBalanced skips a block opened by the current token, like `{ ... }` or `( ... )`
+func (p *parser) skipBalanced(open, closing string) error {
+	start := p.next()
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: unterminated %q", start.line, open)
+		case t.kind == tokenString:
+		case t.text == open:
+			depth++
+		case t.text == closing:
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseModule() (*Module, error) {
+	name, err := p.expectKind(tokenIdent, "module name")
+	if err != nil {
+		return nil, err
+	}
+	module := newModule(name.text)
+
+	if p.is("{") {
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	// tagging defaults, like `IMPLICIT TAGS`, are not relevant to the SMI
+	for !p.is("::=") && p.peek().kind != tokenEOF {
+		p.next()
+	}
+	if err := p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err := p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	for !p.accept("END") {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("module %s: missing END", module.Name)
+		case t.text == "IMPORTS":
+			p.next()
+			if err := p.parseImports(module); err != nil {
+				return nil, err
+			}
+		case t.text == "EXPORTS":
+			for !p.accept(";") && p.peek().kind != tokenEOF {
+				p.next()
+			}
+		case t.kind == tokenIdent:
+			if err := p.parseAssignment(module); err != nil {
+				return nil, fmt.Errorf("module %s: %w", module.Name, err)
+			}
+		default:
+			return nil, fmt.Errorf("module %s: line %d: unexpected %s", module.Name, t.line, t)
+		}
+	}
+	return module, nil
+}
+
+func (p *parser) parseImports(module *Module) error {
+	var symbols []string
+	for !p.accept(";") {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("module %s: unterminated IMPORTS", module.Name)
+		case t.text == ",":
+		case t.text == "FROM":
+			from, err := p.expectKind(tokenIdent, "module name")
+			if err != nil {
+				return err
+			}
+			for _, symbol := range symbols {
+				module.imports[symbol] = from.text
+			}
+			symbols = symbols[:0]
+			// an OID may follow the module name of an import
+			if p.is("{") {
+				if err := p.skipBalanced("{", "}"); err != nil {
+					return err
+				}
+			}
+		case t.kind == tokenIdent:
+			symbols = append(symbols, t.text)
+		default:
+			return fmt.Errorf("line %d: unexpected %s in IMPORTS", t.line, t)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseAssignment(module *Module) error {
+	name := p.next()
+	t := p.peek()
+
+	switch {
+	case t.text == "MACRO":
+		// macro definitions only describe the syntax of the SMI
+		for !p.accept("END") {
+			if p.next().kind == tokenEOF {
+				return fmt.Errorf("line %d: unterminated MACRO %s", name.line, name.text)
+			}
+		}
+		return nil
+
+	case t.text == "::=":
+		p.next()
+		if p.accept("TEXTUAL-CONVENTION") {
+			typ, err := p.parseTextualConvention()
+			if err != nil {
+				return err
+			}
+			module.types[name.text] = typ
+			return nil
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		module.types[name.text] = typ
+		return nil
+
+	case t.text == "OBJECT" && p.peekAt(1).text == "IDENTIFIER":
+		p.next()
+		p.next()
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		value, err := p.parseOIDValue()
+		if err != nil {
+			return err
+		}
+		module.addNode(&Node{Name: name.text, Kind: KindObjectIdentifier, value: value})
+		return nil
+	}
+
+	if kind, ok := macroKinds[t.text]; ok {
+		p.next()
+		node := &Node{Name: name.text, Kind: kind}
+		if err := p.parseClauses(node); err != nil {
+			return err
+		}
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		if kind == KindTrapType {
+			number, err := p.expectKind(tokenNumber, "trap number")
+			if err != nil {
+				return err
+			}
+			n, err := strconv.ParseUint(number.text, 10, 32)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid trap number %s", number.line, number.text)
+			}
+			node.TrapNumber = uint32(n)
+		} else {
+			value, err := p.parseOIDValue()
+			if err != nil {
+				return err
+			}
+			node.value = value
+		}
+		module.addNode(node)
+		return nil
+	}
+
+	// other value assignments, like `name Type ::= value`, are skipped
+	if _, err := p.parseType(); err != nil {
+		return err
+	}
+	if err := p.expect("::="); err != nil {
+		return err
+	}
+	return p.skipValue()
+}
+
+func (p *parser) skipValue() error {
+	switch {
+	case p.is("{"):
+		return p.skipBalanced("{", "}")
+	case p.peek().kind == tokenEOF:
+		return fmt.Errorf("line %d: missing value", p.peek().line)
+	default:
+		p.next()
+		return nil
+	}
+}
+
+// parseTextualConvention parses the clauses of a TEXTUAL-CONVENTION, which
+// end with its SYNTAX.
+func (p *parser) parseTextualConvention() (*Type, error) {
+	var displayHint string
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("line %d: missing SYNTAX in TEXTUAL-CONVENTION", t.line)
+		case t.text == "DISPLAY-HINT" && t.kind == tokenIdent:
+			hint, err := p.expectKind(tokenString, "display hint")
+			if err != nil {
+				return nil, err
+			}
+			displayHint = hint.text
+		case t.text == "SYNTAX" && t.kind == tokenIdent:
+			typ, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			typ.DisplayHint = displayHint
+			return typ, nil
+		}
+	}
+}
+
+// parseClauses parses the clauses of a macro invocation up to its value
+func (p *parser) parseClauses(node *Node) error {
+	for !p.is("::=") {
+		t := p.next()
+		if t.kind == tokenEOF {
+			return fmt.Errorf("line %d: unterminated %s definition", t.line, node.Name)
+		}
+		if t.kind != tokenIdent {
+			if t.kind == tokenSymbol && (t.text == "{" || t.text == "(") {
+				p.pos--
+				if err := p.skipBlock(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var err error
+		switch t.text {
+		case "SYNTAX":
+			var typ *Type
+			if typ, err = p.parseType(); err == nil && node.Syntax == nil {
+				node.Syntax = typ
+			}
+		case "WRITE-SYNTAX":
+			_, err = p.parseType()
+		case "MAX-ACCESS", "ACCESS":
+			var access token
+			if access, err = p.expectKind(tokenIdent, "access"); err == nil && node.Access == "" {
+				node.Access = access.text
+			}
+		case "STATUS":
+			var status token
+			if status, err = p.expectKind(tokenIdent, "status"); err == nil && node.Status == "" {
+				node.Status = status.text
+			}
+		case "DESCRIPTION":
+			// the first description is the one of the node, the next
+			// ones belong to revisions or conformance statements
+			var description token
+			if description, err = p.expectKind(tokenString, "description"); err == nil && node.Description == "" {
+				node.Description = description.text
+			}
+		case "UNITS":
+			var units token
+			if units, err = p.expectKind(tokenString, "units"); err == nil {
+				node.Units = units.text
+			}
+		case "INDEX":
+			node.Index, err = p.parseNameList()
+		case "AUGMENTS":
+			var augments []string
+			if augments, err = p.parseNameList(); err == nil && len(augments) > 0 {
+				node.Augments = augments[0]
+			}
+		case "OBJECTS", "VARIABLES":
+			node.Objects, err = p.parseNameList()
+		case "ENTERPRISE":
+			var enterprise token
+			if enterprise, err = p.expectKind(tokenIdent, "enterprise"); err == nil {
+				node.Enterprise = enterprise.text
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) skipBlock() error {
+	if p.is("{") {
+		return p.skipBalanced("{", "}")
+	}
+	return p.skipBalanced("(", ")")
+}
+
+// parseNameList parses a list of names like `{ IMPLIED ifIndex, ifType }`
+func (p *parser) parseNameList() ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for !p.accept("}") {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("line %d: unterminated list", t.line)
+		case t.text == "," || t.text == "IMPLIED":
+		case t.kind == tokenIdent:
+			names = append(names, t.text)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %s in list", t.line, t)
+		}
+	}
+	return names, nil
+}
+
+// parseOIDValue parses an OID value like `{ iso org(3) dod(6) 1 }`
+func (p *parser) parseOIDValue() ([]oidComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var components []oidComponent
+	for !p.accept("}") {
+		t := p.next()
+		switch t.kind {
+		case tokenNumber:
+			n, err := strconv.ParseUint(t.text, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid sub-identifier %s", t.line, t.text)
+			}
+			components = append(components, oidComponent{number: uint32(n), hasNum: true})
+		case tokenIdent:
+			component := oidComponent{name: t.text}
+			if p.accept("(") {
+				number, err := p.expectKind(tokenNumber, "sub-identifier")
+				if err != nil {
+					return nil, err
+				}
+				n, err := strconv.ParseUint(number.text, 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid sub-identifier %s", number.line, number.text)
+				}
+				component.number, component.hasNum = uint32(n), true
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			components = append(components, component)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %s in OID value", t.line, t)
+		}
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("line %d: empty OID value", p.peek().line)
+	}
+	return components, nil
+}
+
+// parseType parses a syntax, like `INTEGER { up(1), down(2) }`, `OCTET STRING (SIZE (0..255))`
+// or `SEQUENCE OF IfEntry`. The constraints are not kept.
+func (p *parser) parseType() (*Type, error) {
+	// tags, like `[APPLICATION 1] IMPLICIT`, are found in SMIv1 type assignments
+	if p.is("[") {
+		if err := p.skipBalanced("[", "]"); err != nil {
+			return nil, err
+		}
+		if !p.accept("IMPLICIT") {
+			p.accept("EXPLICIT")
+		}
+	}
+
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("line %d: expected a type, found %s", t.line, t)
+	}
+	typ := &Type{Name: t.text}
+
+	switch t.text {
+	case "OCTET", "BIT":
+		if err := p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		typ.Name = t.text + " STRING"
+	case "OBJECT":
+		if err := p.expect("IDENTIFIER"); err != nil {
+			return nil, err
+		}
+		typ.Name = TypeObjectIdentifier
+	case "SEQUENCE":
+		if p.accept("OF") {
+			entry, err := p.expectKind(tokenIdent, "entry type")
+			if err != nil {
+				return nil, err
+			}
+			typ.SequenceOf = entry.text
+			return typ, nil
+		}
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+		return typ, nil
+	case "CHOICE":
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+		return typ, nil
+	}
+
+	if p.is("{") {
+		enums, err := p.parseNamedNumbers()
+		if err != nil {
+			return nil, err
+		}
+		typ.Enums = enums
+	}
+	for p.is("(") {
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+	return typ, nil
+}
+
+// parseNamedNumbers parses enumerations and named bits, like `{ up(1), down(2) }`
+func (p *parser) parseNamedNumbers() ([]NamedNumber, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var enums []NamedNumber
+	for !p.accept("}") {
+		if p.accept(",") {
+			continue
+		}
+		name, err := p.expectKind(tokenIdent, "enumeration name")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		number, err := p.expectKind(tokenNumber, "enumeration value")
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseInt(number.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid enumeration value %s", number.line, number.text)
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		enums = append(enums, NamedNumber{Name: name.text, Value: value})
+	}
+	return enums, nil
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize(`foo-bar ::= { iso 3 } -- comment -- baz
+"multi
+line" 'FF'H (-1..10) ifIndex--comment
+`)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.text)
+	}
+	assert.Equal(t, []string{"foo-bar", "::=", "{", "iso", "3", "}", "baz", "multi\nline", "'FF'H", "(", "-1", "..", "10", ")", "ifIndex", ""}, texts)
+	assert.Equal(t, 3, tokens[len(tokens)-2].line)
+}
+
+func TestParse(t *testing.T) {
+	modules, err := Parse([]byte(`
+TEST-MIB DEFINITIONS IMPLICIT TAGS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, Integer32 FROM SNMPv2-SMI
+    DisplayString FROM SNMPv2-TC;
+
+OBJECT-TYPE MACRO ::=
+BEGIN
+    TYPE NOTATION ::= "SYNTAX" Syntax
+    VALUE NOTATION ::= value(VALUE ObjectName)
+END
+
+Percent ::= Integer32 (0..100)
+Status ::= INTEGER { up(1), down(2), unknown(-1) }
+Entry ::= SEQUENCE { a Integer32, b OCTET STRING }
+
+test OBJECT IDENTIFIER ::= { iso org(3) dod(6) 1 4 1 12345 }
+
+testValue Integer32 ::= 42
+
+testStatus OBJECT-TYPE
+    SYNTAX      Status
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Status."
+    REFERENCE   "RFC 0000"
+    DEFVAL      { unknown }
+    ::= { test 1 }
+
+testTable OBJECT-TYPE
+    SYNTAX      SEQUENCE OF Entry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Table."
+    ::= { test 2 }
+
+testEntry OBJECT-TYPE
+    SYNTAX      Entry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Entry."
+    INDEX       { a, IMPLIED b }
+    ::= { testTable 1 }
+END
+`), "TEST-MIB")
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+
+	module := modules[0]
+	assert.Equal(t, "TEST-MIB", module.Name)
+	assert.Equal(t, []string{"SNMPv2-SMI", "SNMPv2-TC"}, module.Imports())
+	assert.Equal(t, &Type{Name: TypeInteger, Enums: []NamedNumber{{"up", 1}, {"down", 2}, {"unknown", -1}}}, module.types["Status"])
+	assert.Equal(t, &Type{Name: "Integer32"}, module.types["Percent"])
+
+	var names []string
+	for _, node := range module.Nodes {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"test", "testStatus", "testTable", "testEntry"}, names)
+
+	status, ok := module.Node("testStatus")
+	require.True(t, ok)
+	assert.Equal(t, KindObjectType, status.Kind)
+	assert.Equal(t, "read-only", status.Access)
+	assert.Equal(t, "Status.", status.Description)
+	assert.Equal(t, &Type{Name: "Status"}, status.Syntax)
+
+	table, _ := module.Node("testTable")
+	assert.Equal(t, "Entry", table.Syntax.SequenceOf)
+	entry, _ := module.Node("testEntry")
+	assert.Equal(t, []string{"a", "b"}, entry.Index)
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"no module":         ``,
+		"missing end":       `A DEFINITIONS ::= BEGIN`,
+		"bad oid":           "A DEFINITIONS ::= BEGIN\nfoo OBJECT IDENTIFIER ::= { iso \"x\" }\nEND",
+		"unterminated list": "A DEFINITIONS ::= BEGIN\nfoo OBJECT-TYPE INDEX { a, b ::= { iso 1 }\nEND",
+		"unterminated str":  "A DEFINITIONS ::= BEGIN\nfoo OBJECT-TYPE DESCRIPTION \"abc\nEND",
+	} {
+		_, err := Parse([]byte(content), "test")
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,555 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package profilegen generates SNMP profiles from the definitions of MIB modules.
+package profilegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition/schema"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+)
+
+// Options configures the generation of a profile
+type Options struct {
+	Name         string
+	Description  string
+	SysObjectIDs []string
+	Extends      []string
+	// Objects are the names of the tables, table entries, columns, scalars
+	// or groups of objects to collect, optionally qualified with their module
+	// name like `IF-MIB::ifTable`.
+	Objects []string
+	// Modules are the modules whose tables and scalars are all collected,
+	// used when Objects is empty.
+	Modules []string
+}
+
+// objectKind is how an object is reported by a profile
+type objectKind int
+
+const (
+	objectSkipped objectKind = iota
+	objectMetric
+	objectTag
+)
+
+type object struct {
+	node   *mib.Node
+	oid    mib.OID
+	syntax *mib.Syntax
+}
+
+type table struct {
+	object
+	entry   object
+	columns map[string]bool
+	// all is set when all the columns of the table are selected
+	all bool
+}
+
+type generator struct {
+	loader   *mib.Loader
+	byOID    map[string]*mib.Node
+	tables   map[string]*table
+	scalars  map[string]object
+	warnings []string
+}
+
+// Generate generates a profile collecting the given MIB objects. The metric
+// types are inferred from the syntax of the objects, the table indexes are
+// used as tags and the enumerations are mapped to their names. The objects
+// that can't be collected are reported in the returned warnings.
+func Generate(loader *mib.Loader, opts Options) (*profiledefinition.ProfileDefinition, []string, error) {
+	g := &generator{
+		loader:  loader,
+		tables:  make(map[string]*table),
+		scalars: make(map[string]object),
+	}
+
+	if len(opts.Objects) > 0 {
+		for _, name := range opts.Objects {
+			node, err := loader.Find(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := g.addNode(node, true); err != nil {
+				return nil, nil, err
+			}
+		}
+	} else {
+		if len(opts.Modules) == 0 {
+			return nil, nil, fmt.Errorf("no MIB object nor module to generate the profile from")
+		}
+		modules := make([]*mib.Module, 0, len(opts.Modules))
+		for _, name := range opts.Modules {
+			module, err := loader.Load(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			modules = append(modules, module)
+		}
+		for _, module := range modules {
+			for _, node := range module.Nodes {
+				if node.Kind == mib.KindObjectType {
+					if err := g.addNode(node, false); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+		}
+	}
+
+	profile := profiledefinition.NewProfileDefinition()
+	profile.Name = opts.Name
+	profile.Description = opts.Description
+	profile.SysObjectIds = opts.SysObjectIDs
+	profile.Extends = opts.Extends
+
+	for _, scalar := range sortedObjects(g.scalars) {
+		g.addScalar(profile, scalar)
+	}
+
+	tables := make([]*table, 0, len(g.tables))
+	for _, t := range g.tables {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return compareOIDs(tables[i].oid, tables[j].oid) < 0
+	})
+	for _, t := range tables {
+		if err := g.addTable(profile, t); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(profile.Metrics) == 0 {
+		return nil, g.warnings, fmt.Errorf("no metric could be generated from the selected MIB objects")
+	}
+	return profile, g.warnings, nil
+}
+
+func (g *generator) newObject(node *mib.Node) (object, error) {
+	oid, err := g.loader.OID(node)
+	if err != nil {
+		return object{}, err
+	}
+	obj := object{node: node, oid: oid}
+	if node.Syntax != nil {
+		if obj.syntax, err = g.loader.Syntax(node); err != nil {
+			return object{}, err
+		}
+	}
+	return obj, nil
+}
+
+// parentNode returns the node defined at the parent OID of an object, if any
+func (g *generator) parentNode(oid mib.OID) *mib.Node {
+	if g.byOID == nil {
+		g.byOID = make(map[string]*mib.Node)
+		for _, module := range g.loader.Modules() {
+			for _, node := range module.Nodes {
+				if nodeOID, err := g.loader.OID(node); err == nil {
+					g.byOID[nodeOID.String()] = node
+				}
+			}
+		}
+	}
+	if len(oid) < 2 {
+		return nil
+	}
+	return g.byOID[oid[:len(oid)-1].String()]
+}
+
+func isEntry(node *mib.Node) bool {
+	return node.Kind == mib.KindObjectType && (len(node.Index) > 0 || node.Augments != "")
+}
+
+// addNode selects an object, explicit is set when the object was requested by the user
+func (g *generator) addNode(node *mib.Node, explicit bool) error {
+	obj, err := g.newObject(node)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case node.Kind != mib.KindObjectType:
+		// groups select all the objects under them
+		children, err := g.loader.Children(node)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := g.addNode(child, false); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case obj.syntax == nil:
+		return nil
+
+	case obj.syntax.SequenceOf != "":
+		return g.addTableColumn(node, obj, "")
+
+	case isEntry(node):
+		if !explicit {
+			// table entries are selected by their table
+			return nil
+		}
+		return g.addTableColumn(node, obj, "")
+	}
+
+	if entry := g.parentNode(obj.oid); entry != nil && isEntry(entry) {
+		if !explicit {
+			// columns are selected by their table
+			return nil
+		}
+		return g.addTableColumn(entry, obj, node.Name)
+	}
+
+	if isAccessible(node) {
+		g.scalars[node.Name] = obj
+	} else if explicit {
+		g.warn("%s is not accessible", node.Name)
+	}
+	return nil
+}
+
+// addTableColumn selects a column of a table, or all of them when the column is empty
+func (g *generator) addTableColumn(node *mib.Node, obj object, column string) error {
+	var tableNode, entryNode *mib.Node
+	if obj.syntax != nil && obj.syntax.SequenceOf != "" {
+		children, err := g.loader.Children(node)
+		if err != nil {
+			return err
+		}
+		if len(children) == 0 {
+			return fmt.Errorf("table %s has no entry", node.Name)
+		}
+		tableNode, entryNode = node, children[0]
+	} else {
+		// node is a table entry, its table is its parent
+		entryNode = node
+		entryOID, err := g.loader.OID(node)
+		if err != nil {
+			return err
+		}
+		if tableNode = g.parentNode(entryOID); tableNode == nil {
+			return fmt.Errorf("no table found for entry %s", node.Name)
+		}
+	}
+
+	t, ok := g.tables[tableNode.Name]
+	if !ok {
+		tableObj, err := g.newObject(tableNode)
+		if err != nil {
+			return err
+		}
+		entryObj, err := g.newObject(entryNode)
+		if err != nil {
+			return err
+		}
+		t = &table{object: tableObj, entry: entryObj, columns: make(map[string]bool)}
+		g.tables[tableNode.Name] = t
+	}
+	if column == "" {
+		t.all = true
+	} else {
+		t.columns[column] = true
+	}
+	return nil
+}
+
+func (g *generator) warn(format string, args ...interface{}) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+func isAccessible(node *mib.Node) bool {
+	switch node.Access {
+	case "read-only", "read-write", "read-create":
+		return true
+	default:
+		return false
+	}
+}
+
+// classify returns how an object is reported, along with the metric type of
+// metrics or the format of tags
+func classify(syntax *mib.Syntax) (objectKind, profiledefinition.ProfileMetricType, string) {
+	switch syntax.Base {
+	case mib.TypeCounter32, mib.TypeCounter64:
+		return objectMetric, profiledefinition.ProfileMetricTypeRate, ""
+	case mib.TypeInteger, mib.TypeInteger32:
+		if len(syntax.Enums) > 0 {
+			return objectTag, "", ""
+		}
+		return objectMetric, profiledefinition.ProfileMetricTypeGauge, ""
+	case mib.TypeGauge32, mib.TypeUnsigned32, mib.TypeTimeTicks:
+		return objectMetric, profiledefinition.ProfileMetricTypeGauge, ""
+	case mib.TypeIPAddress:
+		return objectTag, "", ""
+	case mib.TypeOctetString:
+		// physical addresses are displayed as hexadecimal bytes separated by colons
+		if syntax.DisplayHint == "1x:" {
+			return objectTag, "", "mac_address"
+		}
+		if syntax.TextualConvention != "" && (syntax.DisplayHint == "" || strings.HasSuffix(syntax.DisplayHint, "a")) {
+			return objectTag, "", ""
+		}
+		return objectSkipped, "", ""
+	default:
+		return objectSkipped, "", ""
+	}
+}
+
+func enumMapping(syntax *mib.Syntax) profiledefinition.ListMap[string] {
+	if len(syntax.Enums) == 0 {
+		return nil
+	}
+	mapping := make(profiledefinition.ListMap[string], len(syntax.Enums))
+	for _, enum := range syntax.Enums {
+		mapping[strconv.FormatInt(enum.Value, 10)] = enum.Name
+	}
+	return mapping
+}
+
+func (g *generator) addScalar(profile *profiledefinition.ProfileDefinition, scalar object) {
+	symbol := profiledefinition.SymbolConfig{
+		OID:  scalar.oid.String() + ".0",
+		Name: scalar.node.Name,
+	}
+	kind, metricType, format := classify(scalar.syntax)
+	switch kind {
+	case objectMetric:
+		symbol.MetricType = metricType
+		profile.Metrics = append(profile.Metrics, profiledefinition.MetricsConfig{
+			MIB:    scalar.node.Module().Name,
+			Symbol: symbol,
+		})
+	case objectTag:
+		symbol.Format = format
+		profile.MetricTags = append(profile.MetricTags, profiledefinition.MetricTagConfig{
+			Tag:     tagName(scalar.node.Name),
+			Symbol:  profiledefinition.SymbolConfigCompat(symbol),
+			Mapping: enumMapping(scalar.syntax),
+		})
+	default:
+		g.warn("%s has an unsupported syntax %s", scalar.node.Name, scalar.syntax.Base)
+	}
+}
+
+func (g *generator) addTable(profile *profiledefinition.ProfileDefinition, t *table) error {
+	columns, err := g.loader.Children(t.entry.node)
+	if err != nil {
+		return err
+	}
+
+	metric := profiledefinition.MetricsConfig{
+		MIB: t.node.Module().Name,
+		Table: profiledefinition.SymbolConfig{
+			OID:  t.oid.String(),
+			Name: t.node.Name,
+		},
+	}
+
+	indexTags, indexes, err := g.indexTags(t, columns)
+	if err != nil {
+		return err
+	}
+	metric.MetricTags = append(metric.MetricTags, indexTags...)
+
+	for _, column := range columns {
+		// the indexes are already reported as tags
+		if indexes[column.Name] || (!t.all && !t.columns[column.Name]) {
+			continue
+		}
+		if !isAccessible(column) {
+			if t.columns[column.Name] {
+				g.warn("%s is not accessible", column.Name)
+			}
+			continue
+		}
+		obj, err := g.newObject(column)
+		if err != nil {
+			return err
+		}
+		symbol := profiledefinition.SymbolConfig{
+			OID:  obj.oid.String(),
+			Name: column.Name,
+		}
+		kind, metricType, format := classify(obj.syntax)
+		switch kind {
+		case objectMetric:
+			symbol.MetricType = metricType
+			metric.Symbols = append(metric.Symbols, symbol)
+		case objectTag:
+			symbol.Format = format
+			metric.MetricTags = append(metric.MetricTags, profiledefinition.MetricTagConfig{
+				Tag:     tagName(column.Name),
+				Symbol:  profiledefinition.SymbolConfigCompat(symbol),
+				Mapping: enumMapping(obj.syntax),
+			})
+		default:
+			g.warn("%s has an unsupported syntax %s", column.Name, obj.syntax.Base)
+		}
+	}
+
+	if len(metric.Symbols) == 0 {
+		g.warn("%s has no metric column", t.node.Name)
+		return nil
+	}
+	profile.Metrics = append(profile.Metrics, metric)
+	return nil
+}
+
+// indexTags returns the tags identifying the rows of a table. The integer
+// indexes are read from the row index, the other ones from their column when
+// it is part of the table and accessible. The names of the indexes are returned
+// along with the tags.
+func (g *generator) indexTags(t *table, columns []*mib.Node) ([]profiledefinition.MetricTagConfig, map[string]bool, error) {
+	entry := t.entry.node
+	index := entry.Index
+	if entry.Augments != "" {
+		augmented, err := g.loader.Lookup(entry.Module(), entry.Augments)
+		if err != nil {
+			return nil, nil, err
+		}
+		index = augmented.Index
+		entry = augmented
+	}
+
+	var tags []profiledefinition.MetricTagConfig
+	indexes := make(map[string]bool, len(index))
+	// position is the position of the next index in the row index, it is
+	// unknown after a variable length index
+	position, known := uint(1), true
+	for _, name := range index {
+		indexes[name] = true
+		node, err := g.loader.Lookup(entry.Module(), name)
+		if err != nil {
+			return nil, nil, err
+		}
+		obj, err := g.newObject(node)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch obj.syntax.Base {
+		case mib.TypeInteger, mib.TypeInteger32, mib.TypeUnsigned32, mib.TypeGauge32, mib.TypeTimeTicks:
+			if known {
+				tags = append(tags, profiledefinition.MetricTagConfig{
+					Tag:     tagName(name),
+					Index:   position,
+					Mapping: enumMapping(obj.syntax),
+				})
+				position++
+				continue
+			}
+		case mib.TypeIPAddress:
+			position += 4
+		default:
+			known = false
+		}
+
+		if isAccessible(node) && containsNode(columns, node) {
+			kind, _, format := classify(obj.syntax)
+			if kind == objectTag {
+				tags = append(tags, profiledefinition.MetricTagConfig{
+					Tag: tagName(name),
+					Symbol: profiledefinition.SymbolConfigCompat{
+						OID:    obj.oid.String(),
+						Name:   name,
+						Format: format,
+					},
+				})
+				continue
+			}
+		}
+		g.warn("index %s of %s can't be used as a tag", name, t.node.Name)
+	}
+	return tags, indexes, nil
+}
+
+func containsNode(nodes []*mib.Node, node *mib.Node) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName converts an object name to a tag name, `ifHCInOctets` becomes `if_hc_in_octets`
+func tagName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '-' {
+			b.WriteRune('_')
+			continue
+		}
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func compareOIDs(a, b mib.OID) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func sortedObjects(objects map[string]object) []object {
+	sorted := make([]object, 0, len(objects))
+	for _, obj := range objects {
+		sorted = append(sorted, obj)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareOIDs(sorted[i].oid, sorted[j].oid) < 0
+	})
+	return sorted
+}
+
+// Validate validates a profile against the JSON schema of the profiles
+func Validate(profile *profiledefinition.ProfileDefinition) error {
+	sch, err := jsonschema.CompileString("schema.json", string(schema.GetDeviceProfileRcConfigJsonschema()))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(profiledefinition.DeviceProfileRcConfig{Profile: *profile})
+	if err != nil {
+		return err
+	}
+	var instance interface{}
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+	return sch.Validate(instance)
+}
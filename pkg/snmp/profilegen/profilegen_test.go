@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profilegen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+)
+
+const testMIBDir = "../mib/testdata"
+
+func TestGenerateModule(t *testing.T) {
+	profile, warnings, err := Generate(mib.NewLoader(testMIBDir), Options{
+		Name:         "acme",
+		SysObjectIDs: []string{"1.3.6.1.4.1.99999.*"},
+		Modules:      []string{"ACME-MIB"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.NoError(t, Validate(profile))
+
+	data, err := yaml.Marshal(profile)
+	require.NoError(t, err)
+	expected, err := os.ReadFile("testdata/acme.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(data))
+
+	// the generated profile can be loaded back
+	var loaded profiledefinition.ProfileDefinition
+	require.NoError(t, yaml.Unmarshal(data, &loaded))
+	assert.Equal(t, profile.Metrics, loaded.Metrics)
+}
+
+func TestGenerateObjects(t *testing.T) {
+	loader := mib.NewLoader(testMIBDir)
+	_, err := loader.Load("ACME-MIB")
+	require.NoError(t, err)
+
+	profile, warnings, err := Generate(loader, Options{
+		Name:    "acme-ports",
+		Objects: []string{"ACME-MIB::acmePortInOctets", "acmePortStatus", "acmePortIndex", "acmeTemperature", "acmeMIB"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.NoError(t, Validate(profile))
+
+	require.Len(t, profile.Metrics, 4)
+	assert.Equal(t, "acmeTemperature", profile.Metrics[1].Symbol.Name)
+	ports := profile.Metrics[3]
+	assert.Equal(t, "acmePortTable", ports.Table.Name)
+	// the group selected all the columns of the table
+	assert.Len(t, ports.Symbols, 4)
+
+	profile, warnings, err = Generate(loader, Options{
+		Name:    "acme-ports",
+		Objects: []string{"acmePortInOctets", "acmePortStatus", "acmePortIndex"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []profiledefinition.MetricsConfig{
+		{
+			MIB:   "ACME-MIB",
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.2.1.10", Name: "acmePortTable"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.99999.2.1.10.1.6", Name: "acmePortInOctets", MetricType: profiledefinition.ProfileMetricTypeRate},
+			},
+			MetricTags: profiledefinition.MetricTagConfigList{
+				{Tag: "acme_port_index", Index: 1},
+				{
+					Tag:     "acme_port_status",
+					Symbol:  profiledefinition.SymbolConfigCompat{OID: "1.3.6.1.4.1.99999.2.1.10.1.4", Name: "acmePortStatus"},
+					Mapping: profiledefinition.ListMap[string]{"1": "ok", "2": "degraded", "3": "failed"},
+				},
+			},
+		},
+	}, profile.Metrics)
+
+	_, warnings, err = Generate(loader, Options{Name: "acme", Objects: []string{"acmePortName"}})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"acmePortTable has no metric column"}, warnings)
+
+	_, _, err = Generate(loader, Options{Name: "acme", Objects: []string{"unknownObject"}})
+	assert.Error(t, err)
+}
+
+func TestTagName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"ifIndex":          "if_index",
+		"ifHCInOctets":     "if_hc_in_octets",
+		"cpmCPUTotal5min":  "cpm_cpu_total5min",
+		"entPhysicalDescr": "ent_physical_descr",
+		"lldp-port":        "lldp_port",
+	} {
+		assert.Equal(t, expected, tagName(name))
+	}
+}
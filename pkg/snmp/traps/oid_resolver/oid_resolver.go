@@ -47,10 +47,15 @@ type OIDResolver interface {
 // the less priority to Datadog's own database shipped with the agent.
 // Variable OIDs conflicts are fully resolved by also looking at the trap OID. A given trap OID only
 // exist in a single file (after the previous conflict resolution), meaning that we get the variable
-// metadata from that same file.
+// metadata from that same file. Variables that are not defined in that file are looked up in the
+// variables of all the files, with the same precedence as the traps.
 type MultiFilesOIDResolver struct {
-	traps  TrapSpec
-	logger log.Component
+	traps TrapSpec
+	// trapFiles maps the trap OIDs to the name of the file they are defined in
+	trapFiles map[string]string
+	// variables are the variables of all the files
+	variables variableSpec
+	logger    log.Component
 }
 
 // NewMultiFilesOIDResolver creates a new MultiFilesOIDResolver instance by loading json or yaml files
@@ -99,15 +104,27 @@ func (or *MultiFilesOIDResolver) GetVariableMetadata(trapOID string, varOID stri
 		return VariableMetadata{}, fmt.Errorf("trap OID %s is not defined", trapOID)
 	}
 
+	if varData, ok := lookupVariable(trapData.variableSpecPtr, varOID); ok {
+		return varData, nil
+	}
+	if varData, ok := lookupVariable(or.variables, varOID); ok {
+		return varData, nil
+	}
+	return VariableMetadata{}, fmt.Errorf("variable OID %s is not defined", varOID)
+}
+
+// lookupVariable returns the VariableMetadata of varOID in variables, climbing up the OID tree until
+// finding a match
+func lookupVariable(variables variableSpec, varOID string) (VariableMetadata, bool) {
 	recreatedVarOID := varOID
 	for {
-		varData, ok := trapData.variableSpecPtr[recreatedVarOID]
+		varData, ok := variables[recreatedVarOID]
 		if ok {
 			if varData.isIntermediateNode {
 				// Found a known Node while climibing up the tree, no chance of finding a match higher
-				return VariableMetadata{}, fmt.Errorf("variable OID %s is not defined", varOID)
+				return VariableMetadata{}, false
 			}
-			return varData, nil
+			return varData, true
 
 		}
 		// No match for the current varOID, climb up the tree and retry
@@ -117,7 +134,7 @@ func (or *MultiFilesOIDResolver) GetVariableMetadata(trapOID string, varOID stri
 		}
 		recreatedVarOID = varOID[:lastDot]
 	}
-	return VariableMetadata{}, fmt.Errorf("variable OID %s is not defined", varOID)
+	return VariableMetadata{}, false
 }
 
 func getSortedFileNames(files []fs.DirEntry, logger log.Component) []string {
@@ -152,6 +169,7 @@ func getSortedFileNames(files []fs.DirEntry, logger log.Component) []string {
 }
 
 func (or *MultiFilesOIDResolver) updateFromFile(filePath string) error {
+	fileName := filepath.Base(filePath)
 	var fileReader io.ReadCloser
 	fileReader, err := os.Open(filePath)
 	if err != nil {
@@ -171,10 +189,10 @@ func (or *MultiFilesOIDResolver) updateFromFile(filePath string) error {
 	if strings.HasSuffix(filePath, ".json") {
 		unmarshalMethod = json.Unmarshal
 	}
-	return or.updateFromReader(fileReader, unmarshalMethod)
+	return or.updateFromReader(fileReader, unmarshalMethod, fileName)
 }
 
-func (or *MultiFilesOIDResolver) updateFromReader(reader io.Reader, unmarshalMethod unmarshaller) error {
+func (or *MultiFilesOIDResolver) updateFromReader(reader io.Reader, unmarshalMethod unmarshaller, fileName string) error {
 	fileContent, err := io.ReadAll(reader)
 	if err != nil {
 		return err
@@ -185,11 +203,18 @@ func (or *MultiFilesOIDResolver) updateFromReader(reader io.Reader, unmarshalMet
 		return err
 	}
 
-	or.updateResolverWithData(trapData)
+	or.updateResolverWithData(trapData, fileName)
 	return nil
 }
 
-func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB TrapDBFileContent) {
+func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB TrapDBFileContent, fileName string) {
+	if or.trapFiles == nil {
+		or.trapFiles = make(map[string]string)
+	}
+	if or.variables == nil {
+		or.variables = make(variableSpec)
+	}
+
 	definedVariables := variableSpec{}
 
 	allOIDs := make([]string, 0, len(trapDB.Variables))
@@ -224,6 +249,14 @@ func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB TrapDBFileContent
 	for _, nodeOID := range nodesOIDThatShouldNeverMatch {
 		definedVariables[nodeOID] = VariableMetadata{Name: "unknown", isIntermediateNode: true}
 	}
+	for variableOID, variableData := range definedVariables {
+		// a node of the OID tree of this file must not replace the variable
+		// of a previous file
+		if _, exists := or.variables[variableOID]; exists && variableData.isIntermediateNode {
+			continue
+		}
+		or.variables[variableOID] = variableData
+	}
 
 	for trapOID, trapData := range trapDB.Traps {
 		if !IsValidOID(trapOID) {
@@ -231,9 +264,16 @@ func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB TrapDBFileContent
 			continue
 		}
 		trapOID := NormalizeOID(trapOID)
-		if _, trapConflict := or.traps[trapOID]; trapConflict {
-			or.logger.Debugf("a trap with OID %s is defined in multiple traps db files", trapOID)
+		if previousTrapData, trapConflict := or.traps[trapOID]; trapConflict {
+			previousFileName := or.trapFiles[trapOID]
+			if previousTrapData.Name != trapData.Name || previousTrapData.MIBName != trapData.MIBName {
+				or.logger.Warnf("trap OID %s is defined as %s::%s in %s and as %s::%s in %s, using the definition of %s",
+					trapOID, previousTrapData.MIBName, previousTrapData.Name, previousFileName, trapData.MIBName, trapData.Name, fileName, fileName)
+			} else {
+				or.logger.Debugf("a trap with OID %s is defined in multiple traps db files: %s and %s", trapOID, previousFileName, fileName)
+			}
 		}
+		or.trapFiles[trapOID] = fileName
 		or.traps[trapOID] = TrapMetadata{
 			Name:            trapData.Name,
 			Description:     trapData.Description,
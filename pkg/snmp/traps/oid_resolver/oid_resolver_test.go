@@ -181,6 +181,83 @@ func TestResolverWithConflictingVariables(t *testing.T) {
 	require.Equal(t, "netSnmpExampleHeartbeatRate2", data.Name)
 }
 
+func TestResolverWithVariableFromOtherFile(t *testing.T) {
+	logger := fxutil.Test[log.Component](t, log.MockModule)
+	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec), logger: logger}
+	ddTrapData := TrapDBFileContent{
+		Traps: TrapSpec{"1.3.6.1.6.3.1.1.5.4": TrapMetadata{Name: "linkUp", MIBName: "IF-MIB"}},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.1": VariableMetadata{Name: "ifIndex"},
+			"1.3.6.1.4.1.8072.2.3.2.1": VariableMetadata{
+				Name: "netSnmpExampleHeartbeatRate",
+			},
+		},
+	}
+	userTrapData := TrapDBFileContent{
+		Traps: TrapSpec{"1.3.6.1.4.1.99999.2.0.1": TrapMetadata{Name: "acmePortDown", MIBName: "ACME-MIB"}},
+		Variables: variableSpec{
+			"1.3.6.1.4.1.8072.2.3.2.1": VariableMetadata{
+				Name: "netSnmpExampleHeartbeatRate2",
+			},
+			"1.3.6.1.4.1.99999.2.1.10.1.2": VariableMetadata{Name: "acmePortName"},
+		},
+	}
+	updateResolverWithIntermediateJSONReader(t, resolver, ddTrapData)
+	updateResolverWithIntermediateYAMLReader(t, resolver, userTrapData)
+
+	data, err := resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.4.1.99999.2.1.10.1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, "acmePortName", data.Name)
+
+	// variable not defined along with the trap
+	data, err = resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.2.1.2.2.1.1.3")
+	require.NoError(t, err)
+	require.Equal(t, "ifIndex", data.Name)
+
+	// the variables of the last file take precedence
+	data, err = resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.4.1.8072.2.3.2.1")
+	require.NoError(t, err)
+	require.Equal(t, "netSnmpExampleHeartbeatRate2", data.Name)
+
+	// the variables defined along with the trap take precedence
+	data, err = resolver.GetVariableMetadata("1.3.6.1.6.3.1.1.5.4", "1.3.6.1.4.1.8072.2.3.2.1")
+	require.NoError(t, err)
+	require.Equal(t, "netSnmpExampleHeartbeatRate", data.Name)
+
+	_, err = resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.4.1.99999.3.1")
+	require.Error(t, err)
+}
+
+func TestResolverWithNodeFromOtherFile(t *testing.T) {
+	logger := fxutil.Test[log.Component](t, log.MockModule)
+	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec), logger: logger}
+	ddTrapData := TrapDBFileContent{
+		Traps: TrapSpec{"1.3.6.1.6.3.1.1.5.4": TrapMetadata{Name: "linkUp", MIBName: "IF-MIB"}},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.1": VariableMetadata{Name: "ifIndex"},
+		},
+	}
+	// 1.3.6.1.2.1.2.2.1.1 is a node of the OID tree of this file
+	userTrapData := TrapDBFileContent{
+		Traps: TrapSpec{"1.3.6.1.4.1.99999.2.0.1": TrapMetadata{Name: "acmePortDown", MIBName: "ACME-MIB"}},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.1":   VariableMetadata{Name: "acmeIfNode"},
+			"1.3.6.1.2.1.2.2.1.1.5": VariableMetadata{Name: "acmeIfIndexFive"},
+		},
+	}
+	updateResolverWithIntermediateJSONReader(t, resolver, ddTrapData)
+	updateResolverWithIntermediateYAMLReader(t, resolver, userTrapData)
+
+	// the node of the last file doesn't replace the variable of the first one
+	data, err := resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.2.1.2.2.1.1.3")
+	require.NoError(t, err)
+	require.Equal(t, "ifIndex", data.Name)
+
+	data, err = resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.2.1.2.2.1.1.5")
+	require.NoError(t, err)
+	require.Equal(t, "acmeIfIndexFive", data.Name)
+}
+
 func TestResolverWithSuffixedVariable(t *testing.T) {
 	logger := fxutil.Test[log.Component](t, log.MockModule)
 	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec), logger: logger}
@@ -278,7 +355,7 @@ func updateResolverWithIntermediateJSONReader(t *testing.T, oidResolver *MultiFi
 	require.NoError(t, err)
 
 	reader := bytes.NewReader(data)
-	err = oidResolver.updateFromReader(reader, json.Unmarshal)
+	err = oidResolver.updateFromReader(reader, json.Unmarshal, "traps_db.json")
 	require.NoError(t, err)
 }
 
@@ -287,7 +364,7 @@ func updateResolverWithIntermediateYAMLReader(t *testing.T, oidResolver *MultiFi
 	require.NoError(t, err)
 
 	reader := bytes.NewReader(data)
-	err = oidResolver.updateFromReader(reader, yaml.Unmarshal)
+	err = oidResolver.updateFromReader(reader, yaml.Unmarshal, "traps_db.yaml")
 	require.NoError(t, err)
 }
 
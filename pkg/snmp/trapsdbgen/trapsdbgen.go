@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package trapsdbgen compiles the notifications defined in MIB modules into
+// the traps DB format used to resolve the traps received by the agent.
+package trapsdbgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+	oidresolver "github.com/DataDog/datadog-agent/pkg/snmp/traps/oid_resolver"
+)
+
+type compiler struct {
+	loader    *mib.Loader
+	db        *oidresolver.TrapDBFileContent
+	variables map[string]string
+	warnings  []string
+}
+
+// Generate compiles the NOTIFICATION-TYPE and TRAP-TYPE definitions of the
+// given modules into a traps DB, along with the names, descriptions and
+// enumerations of their variables. The definitions that can't be compiled
+// are reported in the returned warnings.
+func Generate(loader *mib.Loader, modules []string) (*oidresolver.TrapDBFileContent, []string, error) {
+	if len(modules) == 0 {
+		return nil, nil, fmt.Errorf("no MIB module to compile the traps from")
+	}
+	c := &compiler{
+		loader: loader,
+		db: &oidresolver.TrapDBFileContent{
+			Traps:     make(oidresolver.TrapSpec),
+			Variables: make(map[string]oidresolver.VariableMetadata),
+		},
+		variables: make(map[string]string),
+	}
+	for _, name := range modules {
+		module, err := loader.Load(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, node := range module.Nodes {
+			if node.Kind == mib.KindNotificationType || node.Kind == mib.KindTrapType {
+				c.addTrap(module, node)
+			}
+		}
+	}
+	if len(c.db.Traps) == 0 {
+		return nil, c.warnings, fmt.Errorf("no trap defined in the MIB modules")
+	}
+	return c.db, c.warnings, nil
+}
+
+func (c *compiler) addTrap(module *mib.Module, node *mib.Node) {
+	oid, err := c.loader.OID(node)
+	if err != nil {
+		c.warn("skipping trap %s::%s: %s", module.Name, node.Name, err)
+		return
+	}
+	trapOID := oid.String()
+	if trap, ok := c.db.Traps[trapOID]; ok {
+		if trap.Name != node.Name {
+			c.warn("skipping trap %s::%s: OID %s is already defined as %s::%s", module.Name, node.Name, trapOID, trap.MIBName, trap.Name)
+		}
+		return
+	}
+
+	for _, name := range node.Objects {
+		c.addVariable(module, node, name)
+	}
+	c.db.Traps[trapOID] = oidresolver.TrapMetadata{
+		Name:        node.Name,
+		MIBName:     module.Name,
+		Description: formatDescription(node.Description),
+	}
+}
+
+func (c *compiler) addVariable(module *mib.Module, trap *mib.Node, name string) {
+	node, err := c.loader.Lookup(module, name)
+	if err != nil {
+		c.warn("skipping variable %s of trap %s::%s: %s", name, module.Name, trap.Name, err)
+		return
+	}
+	oid, err := c.loader.OID(node)
+	if err != nil {
+		c.warn("skipping variable %s of trap %s::%s: %s", name, module.Name, trap.Name, err)
+		return
+	}
+	varOID := oid.String()
+	if previous, ok := c.variables[varOID]; ok {
+		if previous != node.Name {
+			c.warn("skipping variable %s of trap %s::%s: OID %s is already defined as %s", name, module.Name, trap.Name, varOID, previous)
+		}
+		return
+	}
+
+	variable := oidresolver.VariableMetadata{
+		Name:        node.Name,
+		Description: formatDescription(node.Description),
+	}
+	if node.Syntax != nil {
+		syntax, err := c.loader.Syntax(node)
+		if err != nil {
+			c.warn("variable %s of trap %s::%s has no enumeration: %s", name, module.Name, trap.Name, err)
+		} else if len(syntax.Enums) > 0 {
+			enums := make(map[int]string, len(syntax.Enums))
+			for _, enum := range syntax.Enums {
+				enums[int(enum.Value)] = enum.Name
+			}
+			if syntax.Base == mib.TypeBits {
+				variable.Bits = enums
+			} else {
+				variable.Enumeration = enums
+			}
+		}
+	}
+	c.variables[varOID] = node.Name
+	c.db.Variables[varOID] = variable
+}
+
+func (c *compiler) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// formatDescription joins the lines of a MIB description, dropping their
+// indentation
+func formatDescription(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
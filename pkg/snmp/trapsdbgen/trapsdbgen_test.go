@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package trapsdbgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+	oidresolver "github.com/DataDog/datadog-agent/pkg/snmp/traps/oid_resolver"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const testMIBDir = "../mib/testdata"
+
+func TestGenerate(t *testing.T) {
+	db, warnings, err := Generate(mib.NewLoader("testdata", testMIBDir), []string{"ACME-ALARM-MIB", "ACME-TRAP-MIB"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"skipping variable acmeAlarmUnknown of trap ACME-ALARM-MIB::acmeAlarmRaised: acmeAlarmUnknown is not defined nor imported by MIB module ACME-ALARM-MIB",
+	}, warnings)
+
+	assert.Equal(t, oidresolver.TrapSpec{
+		"1.3.6.1.4.1.99999.4.0.1": {Name: "acmeAlarmRaised", MIBName: "ACME-ALARM-MIB", Description: "An alarm was raised on a port."},
+		"1.3.6.1.4.1.99999.4.0.2": {Name: "acmeAlarmCleared", MIBName: "ACME-ALARM-MIB", Description: "An alarm was cleared."},
+		"1.3.6.1.4.1.99999.3.0.7": {Name: "acmeFanFailure", MIBName: "ACME-TRAP-MIB", Description: "A fan stopped."},
+	}, db.Traps)
+
+	assert.Len(t, db.Variables, 5)
+	assert.Equal(t, oidresolver.VariableMetadata{
+		Name:        "acmeAlarmFlags",
+		Description: "Flags of the alarm.",
+		Bits:        map[int]string{0: "critical", 1: "acknowledged", 2: "cleared"},
+	}, db.Variables["1.3.6.1.4.1.99999.4.1.1"])
+	assert.Equal(t, oidresolver.VariableMetadata{
+		Name:        "acmeAlarmStatus",
+		Description: "Status of the component raising the alarm.",
+		Enumeration: map[int]string{1: "ok", 2: "degraded", 3: "failed"},
+	}, db.Variables["1.3.6.1.4.1.99999.4.1.2"])
+	// variables imported from other modules
+	assert.Equal(t, "acmePortName", db.Variables["1.3.6.1.4.1.99999.2.1.10.1.2"].Name)
+	assert.Equal(t, map[int]string{1: "running", 2: "stopped"}, db.Variables["1.3.6.1.4.1.99999.3.2"].Enumeration)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, _, err := Generate(mib.NewLoader(testMIBDir), nil)
+	assert.EqualError(t, err, "no MIB module to compile the traps from")
+
+	_, _, err = Generate(mib.NewLoader(testMIBDir), []string{"UNKNOWN-MIB"})
+	assert.EqualError(t, err, "MIB module UNKNOWN-MIB not found")
+
+	_, _, err = Generate(mib.NewLoader(testMIBDir), []string{"ACME-TC"})
+	assert.EqualError(t, err, "no trap defined in the MIB modules")
+}
+
+func TestGenerateLoadedByResolver(t *testing.T) {
+	db, _, err := Generate(mib.NewLoader("testdata", testMIBDir), []string{"ACME-ALARM-MIB"})
+	require.NoError(t, err)
+	data, err := yaml.Marshal(db)
+	require.NoError(t, err)
+
+	confdPath := t.TempDir()
+	trapsDBRoot := filepath.Join(confdPath, "snmp.d", "traps_db")
+	require.NoError(t, os.MkdirAll(trapsDBRoot, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(trapsDBRoot, "acme.yaml"), data, 0644))
+
+	logger := fxutil.Test[log.Component](t, log.MockModule)
+	resolver, err := oidresolver.NewMultiFilesOIDResolver(confdPath, logger)
+	require.NoError(t, err)
+
+	trap, err := resolver.GetTrapMetadata("1.3.6.1.4.1.99999.4.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "acmeAlarmRaised", trap.Name)
+	assert.Equal(t, "ACME-ALARM-MIB", trap.MIBName)
+
+	variable, err := resolver.GetVariableMetadata("1.3.6.1.4.1.99999.4.0.1", "1.3.6.1.4.1.99999.2.1.10.1.2.7")
+	require.NoError(t, err)
+	assert.Equal(t, "acmePortName", variable.Name)
+}
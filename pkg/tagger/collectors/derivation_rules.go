@@ -0,0 +1,426 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Field references usable in the `from` list of a tag derivation rule.
+// References taking a key are written `<field>:<key>`, e.g. `label:owner`.
+const (
+	fieldLabel          = "label"
+	fieldAnnotation     = "annotation"
+	fieldNamespaceLabel = "namespace_label"
+	fieldNamespaceAnnot = "namespace_annotation"
+	fieldOwnerLabel     = "owner_label"
+	fieldOwnerAnnot     = "owner_annotation"
+	fieldEnv            = "env"
+	fieldName           = "name"
+	fieldNamespace      = "namespace"
+	fieldImageName      = "image.name"
+	fieldImageShortName = "image.short_name"
+	fieldImageTag       = "image.tag"
+	fieldImageRegistry  = "image.registry"
+)
+
+var keyedFields = map[string]struct{}{
+	fieldLabel:          {},
+	fieldAnnotation:     {},
+	fieldNamespaceLabel: {},
+	fieldNamespaceAnnot: {},
+	fieldOwnerLabel:     {},
+	fieldOwnerAnnot:     {},
+	fieldEnv:            {},
+}
+
+var plainFields = map[string]struct{}{
+	fieldName:           {},
+	fieldNamespace:      {},
+	fieldImageName:      {},
+	fieldImageShortName: {},
+	fieldImageTag:       {},
+	fieldImageRegistry:  {},
+}
+
+// fieldRef references a field of a workloadmeta entity. key is only set for
+// map fields such as labels or environment variables.
+type fieldRef struct {
+	field string
+	key   string
+}
+
+// entityFields resolves field references against a single entity.
+type entityFields func(ref fieldRef) (string, bool)
+
+// derivationRule is the compiled form of a config.TagDerivationRule.
+type derivationRule struct {
+	tag         string
+	from        []fieldRef
+	match       *regexp.Regexp
+	value       string
+	cardinality string
+	kinds       map[workloadmeta.Kind]struct{}
+}
+
+// tagDerivationRules computes tags from entity fields following the rules
+// configured in `tag_derivation_rules`.
+type tagDerivationRules []*derivationRule
+
+func newTagDerivationRules(rulesConfig []config.TagDerivationRule) (tagDerivationRules, error) {
+	rules := make(tagDerivationRules, 0, len(rulesConfig))
+
+	for i, ruleConfig := range rulesConfig {
+		rule, err := compileDerivationRule(ruleConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag derivation rule #%d: %w", i, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func compileDerivationRule(ruleConfig config.TagDerivationRule) (*derivationRule, error) {
+	if ruleConfig.Tag == "" {
+		return nil, fmt.Errorf("missing tag name")
+	}
+
+	if len(ruleConfig.From) == 0 {
+		return nil, fmt.Errorf("tag %q: no field to derive from", ruleConfig.Tag)
+	}
+
+	rule := &derivationRule{
+		tag:         ruleConfig.Tag,
+		value:       ruleConfig.Value,
+		cardinality: strings.ToLower(ruleConfig.Cardinality),
+	}
+
+	switch rule.cardinality {
+	case "":
+		rule.cardinality = "low"
+	case "low", "orchestrator", "high", "standard":
+	default:
+		return nil, fmt.Errorf("tag %q: unknown cardinality %q", rule.tag, ruleConfig.Cardinality)
+	}
+
+	for _, from := range ruleConfig.From {
+		ref, err := parseFieldRef(from)
+		if err != nil {
+			return nil, fmt.Errorf("tag %q: %w", rule.tag, err)
+		}
+
+		rule.from = append(rule.from, ref)
+	}
+
+	if ruleConfig.Match != "" {
+		re, err := regexp.Compile(ruleConfig.Match)
+		if err != nil {
+			return nil, fmt.Errorf("tag %q: invalid match expression: %w", rule.tag, err)
+		}
+
+		rule.match = re
+	} else if ruleConfig.Value != "" {
+		return nil, fmt.Errorf("tag %q: value requires a match expression", rule.tag)
+	}
+
+	if len(ruleConfig.Kinds) > 0 {
+		rule.kinds = make(map[workloadmeta.Kind]struct{}, len(ruleConfig.Kinds))
+		for _, kind := range ruleConfig.Kinds {
+			rule.kinds[workloadmeta.Kind(kind)] = struct{}{}
+		}
+	}
+
+	return rule, nil
+}
+
+func parseFieldRef(from string) (fieldRef, error) {
+	field, key, hasKey := strings.Cut(from, ":")
+
+	if _, ok := keyedFields[field]; ok {
+		if !hasKey || key == "" {
+			return fieldRef{}, fmt.Errorf("field %q requires a key", field)
+		}
+
+		if field == fieldEnv {
+			return fieldRef{field: field, key: key}, nil
+		}
+
+		// labels and annotations as tags are matched in lowercase
+		// elsewhere in the tagger, do the same here
+		return fieldRef{field: field, key: strings.ToLower(key)}, nil
+	}
+
+	if _, ok := plainFields[field]; ok && !hasKey {
+		return fieldRef{field: field}, nil
+	}
+
+	return fieldRef{}, fmt.Errorf("unknown field %q", from)
+}
+
+// apply evaluates the rules that target the given kind and adds the derived
+// tags to the tag list.
+func (r tagDerivationRules) apply(kind workloadmeta.Kind, fields entityFields, tags *utils.TagList) {
+	for _, rule := range r {
+		if rule.kinds != nil {
+			if _, ok := rule.kinds[kind]; !ok {
+				continue
+			}
+		}
+
+		value, ok := rule.evaluate(fields)
+		if !ok {
+			continue
+		}
+
+		switch rule.cardinality {
+		case "low":
+			tags.AddLow(rule.tag, value)
+		case "orchestrator":
+			tags.AddOrchestrator(rule.tag, value)
+		case "high":
+			tags.AddHigh(rule.tag, value)
+		case "standard":
+			tags.AddStandard(rule.tag, value)
+		}
+	}
+}
+
+// podRulesReference returns whether any of the rules that target pods derives
+// tags from one of the given fields.
+func (r tagDerivationRules) podRulesReference(fields ...string) bool {
+	for _, rule := range r {
+		if rule.kinds != nil {
+			if _, ok := rule.kinds[workloadmeta.KindKubernetesPod]; !ok {
+				continue
+			}
+		}
+
+		for _, ref := range rule.from {
+			for _, field := range fields {
+				if ref.field == field {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// evaluate returns the value of the first referenced field that is set and,
+// if the rule has a match expression, that matches it.
+func (r *derivationRule) evaluate(fields entityFields) (string, bool) {
+	for _, ref := range r.from {
+		value, ok := fields(ref)
+		if !ok || value == "" {
+			continue
+		}
+
+		if r.match == nil {
+			return value, true
+		}
+
+		submatches := r.match.FindStringSubmatchIndex(value)
+		if submatches == nil {
+			continue
+		}
+
+		if r.value == "" {
+			return value, true
+		}
+
+		derived := string(r.match.ExpandString(nil, r.value, value, submatches))
+		if derived != "" {
+			return derived, true
+		}
+	}
+
+	return "", false
+}
+
+// lookupLowercase returns the value for the given lowercase key in a map
+// whose keys may have any case.
+func lookupLowercase(m map[string]string, key string) (string, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+
+	for k, v := range m {
+		if strings.ToLower(k) == key {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+func imageFields(image workloadmeta.ContainerImage, ref fieldRef) (string, bool) {
+	switch ref.field {
+	case fieldImageName:
+		return image.Name, true
+	case fieldImageShortName:
+		return image.ShortName, true
+	case fieldImageTag:
+		return image.Tag, true
+	case fieldImageRegistry:
+		return image.Registry, true
+	}
+
+	return "", false
+}
+
+func containerFields(container *workloadmeta.Container) entityFields {
+	return func(ref fieldRef) (string, bool) {
+		switch ref.field {
+		case fieldLabel:
+			return lookupLowercase(container.Labels, ref.key)
+		case fieldEnv:
+			v, ok := container.EnvVars[ref.key]
+			return v, ok
+		case fieldName:
+			return container.Name, true
+		}
+
+		return imageFields(container.Image, ref)
+	}
+}
+
+func podFields(pod *workloadmeta.KubernetesPod, store workloadmeta.Component) entityFields {
+	return func(ref fieldRef) (string, bool) {
+		switch ref.field {
+		case fieldLabel:
+			return lookupLowercase(pod.Labels, ref.key)
+		case fieldAnnotation:
+			return lookupLowercase(pod.Annotations, ref.key)
+		case fieldNamespaceLabel:
+			if v, ok := lookupLowercase(pod.NamespaceLabels, ref.key); ok {
+				return v, true
+			}
+			if namespace := podNamespaceMeta(pod, store); namespace != nil {
+				return lookupLowercase(namespace.Labels, ref.key)
+			}
+		case fieldNamespaceAnnot:
+			if namespace := podNamespaceMeta(pod, store); namespace != nil {
+				return lookupLowercase(namespace.Annotations, ref.key)
+			}
+		case fieldOwnerLabel:
+			if owner := podOwnerMeta(pod, store); owner != nil {
+				return lookupLowercase(owner.Labels, ref.key)
+			}
+		case fieldOwnerAnnot:
+			if owner := podOwnerMeta(pod, store); owner != nil {
+				return lookupLowercase(owner.Annotations, ref.key)
+			}
+		case fieldName:
+			return pod.Name, true
+		case fieldNamespace:
+			return pod.Namespace, true
+		}
+
+		return "", false
+	}
+}
+
+// podNamespaceMeta returns the metadata of the namespace of the pod, if it
+// is known to the workloadmeta store.
+func podNamespaceMeta(pod *workloadmeta.KubernetesPod, store workloadmeta.Component) *workloadmeta.EntityMeta {
+	namespace, err := store.GetKubernetesNamespace(pod.Namespace)
+	if err != nil {
+		return nil
+	}
+
+	return &namespace.EntityMeta
+}
+
+// podOwnerMeta returns the metadata of the first owner of the pod that is
+// known to the workloadmeta store. Jobs created by a CronJob resolve to the
+// CronJob, the same way ReplicaSets resolve to their Deployment.
+func podOwnerMeta(pod *workloadmeta.KubernetesPod, store workloadmeta.Component) *workloadmeta.EntityMeta {
+	for _, owner := range pod.Owners {
+		id := pod.Namespace + "/" + owner.Name
+
+		switch owner.Kind {
+		case kubernetes.StatefulSetKind:
+			if statefulSet, err := store.GetKubernetesStatefulSet(id); err == nil {
+				return &statefulSet.EntityMeta
+			}
+		case kubernetes.DaemonSetKind:
+			if daemonSet, err := store.GetKubernetesDaemonSet(id); err == nil {
+				return &daemonSet.EntityMeta
+			}
+		case kubernetes.JobKind:
+			if cronJobName := jobCronJobName(pod.Namespace, owner.Name, store); cronJobName != "" {
+				if cronJob, err := store.GetKubernetesCronJob(pod.Namespace + "/" + cronJobName); err == nil {
+					return &cronJob.EntityMeta
+				}
+			}
+			if job, err := store.GetKubernetesJob(id); err == nil {
+				return &job.EntityMeta
+			}
+		}
+	}
+
+	return nil
+}
+
+// jobCronJobName returns the name of the CronJob that created a Job, taken
+// from the owners of the Job when it is known to the workloadmeta store, and
+// from the name of the Job otherwise.
+func jobCronJobName(namespace string, jobName string, store workloadmeta.Component) string {
+	job, err := store.GetKubernetesJob(namespace + "/" + jobName)
+	if err != nil {
+		cronJobName, _ := kubernetes.ParseCronJobForJob(jobName)
+		return cronJobName
+	}
+
+	for _, owner := range job.Owners {
+		if owner.Kind == kubernetes.CronJobKind {
+			return owner.Name
+		}
+	}
+
+	return ""
+}
+
+func containerImageFields(image *workloadmeta.ContainerImageMetadata) entityFields {
+	return func(ref fieldRef) (string, bool) {
+		switch ref.field {
+		case fieldLabel:
+			return lookupLowercase(image.Labels, ref.key)
+		case fieldName, fieldImageName:
+			return image.Name, true
+		}
+
+		return "", false
+	}
+}
+
+// retrieveTagDerivationRules loads and compiles the tag derivation rules from
+// the configuration. Invalid rules are logged and ignored altogether.
+func retrieveTagDerivationRules() tagDerivationRules {
+	rulesConfig, err := config.GetTagDerivationRules()
+	if err != nil {
+		return nil
+	}
+
+	rules, err := newTagDerivationRules(rulesConfig)
+	if err != nil {
+		log.Errorf("tag derivation rules are disabled: %s", err)
+		return nil
+	}
+
+	return rules
+}
@@ -0,0 +1,361 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	coreconfig "github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestNewTagDerivationRulesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.TagDerivationRule
+	}{
+		{
+			name: "missing tag",
+			rule: config.TagDerivationRule{From: []string{"label:owner"}},
+		},
+		{
+			name: "missing from",
+			rule: config.TagDerivationRule{Tag: "team"},
+		},
+		{
+			name: "unknown field",
+			rule: config.TagDerivationRule{Tag: "team", From: []string{"foo"}},
+		},
+		{
+			name: "keyed field without key",
+			rule: config.TagDerivationRule{Tag: "team", From: []string{"label"}},
+		},
+		{
+			name: "plain field with key",
+			rule: config.TagDerivationRule{Tag: "team", From: []string{"name:foo"}},
+		},
+		{
+			name: "invalid match",
+			rule: config.TagDerivationRule{Tag: "team", From: []string{"name"}, Match: "("},
+		},
+		{
+			name: "value without match",
+			rule: config.TagDerivationRule{Tag: "team", From: []string{"name"}, Value: "$1"},
+		},
+		{
+			name: "unknown cardinality",
+			rule: config.TagDerivationRule{Tag: "team", From: []string{"name"}, Cardinality: "medium"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTagDerivationRules([]config.TagDerivationRule{tt.rule})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTagDerivationRulesApply(t *testing.T) {
+	store := fxutil.Test[workloadmeta.Mock](t, fx.Options(
+		log.MockModule,
+		coreconfig.MockModule,
+		fx.Supply(workloadmeta.NewParams()),
+		fx.Supply(context.Background()),
+		workloadmeta.MockModule,
+	))
+
+	store.Set(&workloadmeta.KubernetesNamespace{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesNamespace,
+			ID:   "payments",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "payments",
+			Annotations: map[string]string{
+				"example.com/team": "payments-annotated",
+			},
+		},
+	})
+
+	store.Set(&workloadmeta.KubernetesStatefulSet{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesStatefulSet,
+			ID:   "payments/web",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web",
+			Namespace: "payments",
+			Labels: map[string]string{
+				"tier": "frontend",
+			},
+		},
+	})
+
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "foobar",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web-1234",
+			Namespace: "payments",
+			Labels: map[string]string{
+				"Owner": "billing",
+			},
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{
+			{
+				Kind: "StatefulSet",
+				Name: "web",
+			},
+		},
+		NamespaceLabels: map[string]string{
+			"team": "payments-team",
+		},
+	}
+
+	unlabeledPod := *pod
+	unlabeledPod.Labels = nil
+
+	container := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   "foobar",
+		},
+		Image: workloadmeta.ContainerImage{
+			Name: "datadog/agent",
+			Tag:  "v7.49.1-jmx",
+		},
+		EnvVars: map[string]string{
+			"STAGE": "prod-eu",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		rules        []config.TagDerivationRule
+		kind         workloadmeta.Kind
+		fields       entityFields
+		expectedLow  []string
+		expectedOrch []string
+		expectedHigh []string
+	}{
+		{
+			name: "first field set wins",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"label:owner", "namespace_label:team"}},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(pod, store),
+			expectedLow: []string{"team:billing"},
+		},
+		{
+			name: "fallback to namespace label",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"label:team", "namespace_label:team"}},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(pod, store),
+			expectedLow: []string{"team:payments-team"},
+		},
+		{
+			name: "fallback to namespace annotation",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"label:team", "namespace_annotation:example.com/team"}},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(pod, store),
+			expectedLow: []string{"team:payments-annotated"},
+		},
+		{
+			name: "owner label falling back to namespace annotation, label set",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"label:owner", "namespace_annotation:example.com/team"}},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(pod, store),
+			expectedLow: []string{"team:billing"},
+		},
+		{
+			name: "owner label falling back to namespace annotation, label unset",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"label:owner", "namespace_annotation:example.com/team"}},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(&unlabeledPod, store),
+			expectedLow: []string{"team:payments-annotated"},
+		},
+		{
+			name: "owner label",
+			rules: []config.TagDerivationRule{
+				{Tag: "tier", From: []string{"owner_label:tier"}},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(pod, store),
+			expectedLow: []string{"tier:frontend"},
+		},
+		{
+			name: "version parsed from image tag",
+			rules: []config.TagDerivationRule{
+				{
+					Tag:         "version",
+					From:        []string{"image.tag"},
+					Match:       `^v?(\d+\.\d+\.\d+)`,
+					Value:       "$1",
+					Cardinality: "orchestrator",
+				},
+			},
+			kind:         workloadmeta.KindContainer,
+			fields:       containerFields(container),
+			expectedOrch: []string{"version:7.49.1"},
+		},
+		{
+			name: "regex rename",
+			rules: []config.TagDerivationRule{
+				{
+					Tag:         "stage",
+					From:        []string{"env:STAGE"},
+					Match:       `^prod-(.*)$`,
+					Value:       "production-${1}",
+					Cardinality: "high",
+				},
+			},
+			kind:         workloadmeta.KindContainer,
+			fields:       containerFields(container),
+			expectedHigh: []string{"stage:production-eu"},
+		},
+		{
+			name: "non matching value falls back to next field",
+			rules: []config.TagDerivationRule{
+				{
+					Tag:   "team",
+					From:  []string{"label:owner", "namespace"},
+					Match: `^pay`,
+				},
+			},
+			kind:        workloadmeta.KindKubernetesPod,
+			fields:      podFields(pod, store),
+			expectedLow: []string{"team:payments"},
+		},
+		{
+			name: "rule restricted to another kind",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"label:owner"}, Kinds: []string{"container"}},
+			},
+			kind:   workloadmeta.KindKubernetesPod,
+			fields: podFields(pod, store),
+		},
+		{
+			name: "no field set",
+			rules: []config.TagDerivationRule{
+				{Tag: "team", From: []string{"annotation:team"}},
+			},
+			kind:   workloadmeta.KindKubernetesPod,
+			fields: podFields(pod, store),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := newTagDerivationRules(tt.rules)
+			require.NoError(t, err)
+
+			tags := utils.NewTagList()
+			rules.apply(tt.kind, tt.fields, tags)
+
+			low, orch, high, _ := tags.Compute()
+			assert.ElementsMatch(t, tt.expectedLow, low)
+			assert.ElementsMatch(t, tt.expectedOrch, orch)
+			assert.ElementsMatch(t, tt.expectedHigh, high)
+		})
+	}
+}
+
+func TestPodOwnerMetaCronJob(t *testing.T) {
+	store := fxutil.Test[workloadmeta.Mock](t, fx.Options(
+		log.MockModule,
+		coreconfig.MockModule,
+		fx.Supply(workloadmeta.NewParams()),
+		fx.Supply(context.Background()),
+		workloadmeta.MockModule,
+	))
+
+	store.Set(&workloadmeta.KubernetesCronJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesCronJob,
+			ID:   "payments/backup",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "backup",
+			Namespace: "payments",
+			Labels:    map[string]string{"tier": "batch"},
+		},
+	})
+
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "backup-pod",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "backup-27000000-abcde",
+			Namespace: "payments",
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{
+			{
+				Kind: "Job",
+				Name: "backup-27000000",
+			},
+		},
+	}
+
+	// the job is not known, the cronjob is parsed from its name
+	owner := podOwnerMeta(pod, store)
+	require.NotNil(t, owner)
+	assert.Equal(t, "backup", owner.Name)
+
+	// the job is known, the cronjob is taken from its owners
+	store.Set(&workloadmeta.KubernetesJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesJob,
+			ID:   "payments/backup-27000000",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "backup-27000000",
+			Namespace: "payments",
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{
+			{
+				Kind: "CronJob",
+				Name: "backup",
+			},
+		},
+	})
+	owner = podOwnerMeta(pod, store)
+	require.NotNil(t, owner)
+	assert.Equal(t, "backup", owner.Name)
+
+	// the cronjob is not known, fallback to the job
+	store.Unset(&workloadmeta.KubernetesCronJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesCronJob,
+			ID:   "payments/backup",
+		},
+	})
+	owner = podOwnerMeta(pod, store)
+	require.NotNil(t, owner)
+	assert.Equal(t, "backup-27000000", owner.Name)
+}
@@ -132,6 +132,7 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 			case workloadmeta.KindContainer:
 				tagInfos = append(tagInfos, c.handleContainer(ev)...)
 			case workloadmeta.KindKubernetesPod:
+				c.indexPod(ev.Entity.(*workloadmeta.KubernetesPod))
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
 			case workloadmeta.KindKubernetesNode:
 				tagInfos = append(tagInfos, c.handleKubeNode(ev)...)
@@ -143,6 +144,16 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				// tagInfos = append(tagInfos, c.handleProcess(ev)...) No tags for now
 			case workloadmeta.KindKubernetesDeployment:
 				// tagInfos = append(tagInfos, c.handleDeployment(ev)...) No tags for now
+			case workloadmeta.KindKubernetesStatefulSet,
+				workloadmeta.KindKubernetesDaemonSet,
+				workloadmeta.KindKubernetesJob,
+				workloadmeta.KindKubernetesCronJob,
+				workloadmeta.KindKubernetesNamespace:
+				// No tags for the entity itself, but the tags of the
+				// pods it owns or contains are derived from it
+				tagInfos = append(tagInfos, c.handleKubePodDependencies(entityID)...)
+			case workloadmeta.KindSystemdUnit:
+				// No tags for now
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -161,6 +172,17 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 		case workloadmeta.EventTypeUnset:
 			tagInfos = append(tagInfos, c.handleDelete(ev)...)
 
+			switch entityID.Kind {
+			case workloadmeta.KindKubernetesPod:
+				c.unindexPod(ev.Entity.(*workloadmeta.KubernetesPod))
+			case workloadmeta.KindKubernetesStatefulSet,
+				workloadmeta.KindKubernetesDaemonSet,
+				workloadmeta.KindKubernetesJob,
+				workloadmeta.KindKubernetesCronJob,
+				workloadmeta.KindKubernetesNamespace:
+				tagInfos = append(tagInfos, c.handleKubePodDependencies(entityID)...)
+			}
+
 		default:
 			log.Errorf("cannot handle event of type %d", ev.Type)
 		}
@@ -220,6 +242,8 @@ func (c *WorkloadMetaCollector) handleContainer(ev workloadmeta.Event) []*TagInf
 		tags.AddLow(tag, value)
 	}
 
+	c.derivationRules.apply(workloadmeta.KindContainer, containerFields(container), tags)
+
 	low, orch, high, standard := tags.Compute()
 	return []*TagInfo{
 		{
@@ -270,6 +294,8 @@ func (c *WorkloadMetaCollector) handleContainerImage(ev workloadmeta.Event) []*T
 
 	c.labelsToTags(image.Labels, tags)
 
+	c.derivationRules.apply(workloadmeta.KindContainerImageMetadata, containerImageFields(image), tags)
+
 	low, orch, high, standard := tags.Compute()
 	return []*TagInfo{
 		{
@@ -321,7 +347,7 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 		utils.AddMetadataAsTags(name, value, c.annotationsAsTags, c.globAnnotations, tags)
 	}
 
-	for name, value := range pod.NamespaceLabels {
+	for name, value := range c.namespaceLabels(pod) {
 		utils.AddMetadataAsTags(name, value, c.nsLabelsAsTags, c.globNsLabels, tags)
 	}
 
@@ -374,6 +400,8 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 		tags.AddLow(tag, value)
 	}
 
+	c.derivationRules.apply(workloadmeta.KindKubernetesPod, podFields(pod, c.store), tags)
+
 	low, orch, high, standard := tags.Compute()
 	tagInfos := []*TagInfo{
 		{
@@ -399,6 +427,108 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 	return tagInfos
 }
 
+// handleKubePodDependencies re-tags the pods owned by or contained in a
+// Kubernetes entity, as their tags are derived from the metadata of that
+// entity, which may change or arrive after the pods.
+func (c *WorkloadMetaCollector) handleKubePodDependencies(entityID workloadmeta.EntityID) []*TagInfo {
+	if !c.podTagsDependOn(entityID.Kind) {
+		return nil
+	}
+
+	// namespaces are identified by their name, and owners by
+	// namespace/name
+	namespace, _, _ := strings.Cut(entityID.ID, "/")
+
+	var tagInfos []*TagInfo
+
+	for podID := range c.podsByNamespace[namespace] {
+		pod, err := c.store.GetKubernetesPod(podID)
+		if err != nil {
+			continue
+		}
+
+		if !c.podDependsOn(pod, entityID) {
+			continue
+		}
+
+		tagInfos = append(tagInfos, c.handleKubePod(workloadmeta.Event{
+			Type:   workloadmeta.EventTypeSet,
+			Entity: pod,
+		})...)
+	}
+
+	return tagInfos
+}
+
+// podTagsDependOn returns whether the tags of pods are derived from the
+// metadata of entities of the given kind with the current configuration.
+func (c *WorkloadMetaCollector) podTagsDependOn(kind workloadmeta.Kind) bool {
+	if kind == workloadmeta.KindKubernetesNamespace {
+		return len(c.nsLabelsAsTags) > 0 || len(c.globNsLabels) > 0 ||
+			c.derivationRules.podRulesReference(fieldNamespaceLabel, fieldNamespaceAnnot)
+	}
+
+	return c.derivationRules.podRulesReference(fieldOwnerLabel, fieldOwnerAnnot)
+}
+
+// indexPod keeps track of the pods of each namespace, so that the pods
+// depending on a namespace or owner can be found without listing every pod.
+func (c *WorkloadMetaCollector) indexPod(pod *workloadmeta.KubernetesPod) {
+	pods, ok := c.podsByNamespace[pod.Namespace]
+	if !ok {
+		pods = make(map[string]struct{})
+		c.podsByNamespace[pod.Namespace] = pods
+	}
+
+	pods[pod.ID] = struct{}{}
+}
+
+func (c *WorkloadMetaCollector) unindexPod(pod *workloadmeta.KubernetesPod) {
+	pods := c.podsByNamespace[pod.Namespace]
+	delete(pods, pod.ID)
+
+	if len(pods) == 0 {
+		delete(c.podsByNamespace, pod.Namespace)
+	}
+}
+
+// podDependsOn returns whether the tags of a pod are derived from the
+// metadata of a namespace or owner entity
+func (c *WorkloadMetaCollector) podDependsOn(pod *workloadmeta.KubernetesPod, entityID workloadmeta.EntityID) bool {
+	if entityID.Kind == workloadmeta.KindKubernetesNamespace {
+		return pod.Namespace == entityID.ID
+	}
+
+	// owners are identified by namespace/name
+	namespace, name, found := strings.Cut(entityID.ID, "/")
+	if !found || namespace != pod.Namespace {
+		return false
+	}
+
+	for _, owner := range pod.Owners {
+		switch entityID.Kind {
+		case workloadmeta.KindKubernetesStatefulSet:
+			if owner.Kind == kubernetes.StatefulSetKind && owner.Name == name {
+				return true
+			}
+		case workloadmeta.KindKubernetesDaemonSet:
+			if owner.Kind == kubernetes.DaemonSetKind && owner.Name == name {
+				return true
+			}
+		case workloadmeta.KindKubernetesJob:
+			if owner.Kind == kubernetes.JobKind && owner.Name == name {
+				return true
+			}
+		case workloadmeta.KindKubernetesCronJob:
+			if owner.Kind == kubernetes.JobKind && jobCronJobName(pod.Namespace, owner.Name, c.store) == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (c *WorkloadMetaCollector) handleKubeNode(ev workloadmeta.Event) []*TagInfo {
 	node := ev.Entity.(*workloadmeta.KubernetesNode)
 
@@ -529,6 +659,22 @@ func (c *WorkloadMetaCollector) extractTagsFromPodLabels(pod *workloadmeta.Kuber
 	}
 }
 
+// namespaceLabels returns the labels of the namespace of the pod. They are
+// set on the pod by the kubemetadata collector on nodes, and are otherwise
+// taken from the namespace entity collected from the API server.
+func (c *WorkloadMetaCollector) namespaceLabels(pod *workloadmeta.KubernetesPod) map[string]string {
+	if len(pod.NamespaceLabels) > 0 {
+		return pod.NamespaceLabels
+	}
+
+	namespace, err := c.store.GetKubernetesNamespace(pod.Namespace)
+	if err != nil {
+		return nil
+	}
+
+	return namespace.Labels
+}
+
 func (c *WorkloadMetaCollector) extractTagsFromPodOwner(pod *workloadmeta.KubernetesPod, owner workloadmeta.KubernetesPodOwner, tags *utils.TagList) {
 	switch owner.Kind {
 	case kubernetes.DeploymentKind:
@@ -708,6 +854,18 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 		return fmt.Sprintf("process://%s", entityID.ID)
 	case workloadmeta.KindKubernetesDeployment:
 		return fmt.Sprintf("deployment://%s", entityID.ID)
+	case workloadmeta.KindKubernetesStatefulSet:
+		return fmt.Sprintf("statefulset://%s", entityID.ID)
+	case workloadmeta.KindKubernetesDaemonSet:
+		return fmt.Sprintf("daemonset://%s", entityID.ID)
+	case workloadmeta.KindKubernetesJob:
+		return fmt.Sprintf("job://%s", entityID.ID)
+	case workloadmeta.KindKubernetesCronJob:
+		return fmt.Sprintf("cronjob://%s", entityID.ID)
+	case workloadmeta.KindKubernetesNamespace:
+		return fmt.Sprintf("namespace://%s", entityID.ID)
+	case workloadmeta.KindSystemdUnit:
+		return fmt.Sprintf("systemd_unit://%s", entityID.ID)
 	default:
 		log.Errorf("can't recognize entity %q with kind %q; trying %s://%s as tagger entity",
 			entityID.ID, entityID.Kind, entityID.ID, entityID.Kind)
@@ -49,6 +49,9 @@ type WorkloadMetaCollector struct {
 	children     map[string]map[string]struct{}
 	tagProcessor processor
 
+	// podsByNamespace holds the IDs of the pods of each namespace
+	podsByNamespace map[string]map[string]struct{}
+
 	containerEnvAsTags    map[string]string
 	containerLabelsAsTags map[string]string
 
@@ -62,6 +65,8 @@ type WorkloadMetaCollector struct {
 	globContainerLabels    map[string]glob.Glob
 	globContainerEnvLabels map[string]glob.Glob
 
+	derivationRules tagDerivationRules
+
 	collectEC2ResourceTags bool
 }
 
@@ -127,7 +132,13 @@ func (c *WorkloadMetaCollector) stream(ctx context.Context) {
 		}
 	}()
 
-	ch := c.store.Subscribe(name, workloadmeta.TaggerPriority, nil)
+	// entities restored from a snapshot keep the telemetry of running
+	// workloads tagged until the collectors resync
+	filter := workloadmeta.NewFilter(&workloadmeta.FilterParams{
+		IncludeSnapshot: true,
+	})
+
+	ch := c.store.Subscribe(name, workloadmeta.TaggerPriority, filter)
 
 	log.Infof("workloadmeta tagger collector started")
 
@@ -156,6 +167,7 @@ func NewWorkloadMetaCollector(_ context.Context, store workloadmeta.Component, p
 		tagProcessor:           p,
 		store:                  store,
 		children:               make(map[string]map[string]struct{}),
+		podsByNamespace:        make(map[string]map[string]struct{}),
 		collectEC2ResourceTags: config.Datadog.GetBool("ecs_collect_resource_tags_ec2"),
 	}
 
@@ -175,6 +187,8 @@ func NewWorkloadMetaCollector(_ context.Context, store workloadmeta.Component, p
 	nsLabelsAsTags := config.Datadog.GetStringMapString("kubernetes_namespace_labels_as_tags")
 	c.initPodMetaAsTags(labelsAsTags, annotationsAsTags, nsLabelsAsTags)
 
+	c.derivationRules = retrieveTagDerivationRules()
+
 	return c
 }
 
@@ -1258,7 +1258,8 @@ func TestHandlePodWithDeletedContainer(t *testing.T) {
 				containerToBeDeletedTaggerEntityID: struct{}{},
 			},
 		},
-		tagProcessor: &fakeProcessor{collectorCh},
+		podsByNamespace: make(map[string]map[string]struct{}),
+		tagProcessor:    &fakeProcessor{collectorCh},
 	}
 
 	eventBundle := workloadmeta.EventBundle{
@@ -1296,6 +1297,139 @@ func TestHandlePodWithDeletedContainer(t *testing.T) {
 	assert.True(t, found, "TagInfo of deleted container not returned")
 }
 
+func TestHandleKubePodDependencies(t *testing.T) {
+	store := fxutil.Test[workloadmeta.Mock](t, fx.Options(
+		log.MockModule,
+		config.MockModule,
+		fx.Supply(workloadmeta.NewParams()),
+		workloadmeta.MockModule,
+	))
+
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "backup-pod",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "backup-27000000-abcde",
+			Namespace: "payments",
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{
+			{
+				Kind: kubernetes.JobKind,
+				Name: "backup-27000000",
+			},
+		},
+	}
+	otherPod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "other-pod",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "other",
+			Namespace: "default",
+		},
+	}
+	store.Set(pod)
+	store.Set(otherPod)
+
+	namespace := &workloadmeta.KubernetesNamespace{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesNamespace,
+			ID:   "payments",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   "payments",
+			Labels: map[string]string{"team": "payments-team"},
+		},
+	}
+	cronJob := &workloadmeta.KubernetesCronJob{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesCronJob,
+			ID:   "payments/backup",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "backup",
+			Namespace: "payments",
+		},
+	}
+
+	collector := &WorkloadMetaCollector{
+		store:           store,
+		children:        make(map[string]map[string]struct{}),
+		podsByNamespace: make(map[string]map[string]struct{}),
+	}
+
+	podTaggerEntityID := fmt.Sprintf("kubernetes_pod_uid://%s", pod.ID)
+	processEvents := func(events ...workloadmeta.Event) []*TagInfo {
+		collectorCh := make(chan []*TagInfo, 10)
+		collector.tagProcessor = &fakeProcessor{collectorCh}
+		collector.processEvents(workloadmeta.EventBundle{
+			Events: events,
+			Ch:     make(chan struct{}),
+		})
+		close(collectorCh)
+
+		var tagInfos []*TagInfo
+		for evBundle := range collectorCh {
+			tagInfos = append(tagInfos, evBundle...)
+		}
+		return tagInfos
+	}
+
+	processEvents(
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: pod},
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: otherPod},
+	)
+
+	// pods don't depend on namespaces or owners unless configured to
+	store.Set(namespace)
+	store.Set(cronJob)
+	tagInfos := processEvents(
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: namespace},
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: cronJob},
+	)
+	assert.Empty(t, tagInfos)
+
+	collector.initPodMetaAsTags(nil, nil, map[string]string{"team": "team"})
+	collector.derivationRules = tagDerivationRules{
+		{tag: "owner_team", from: []fieldRef{{field: fieldOwnerLabel, key: "team"}}, cardinality: "low"},
+	}
+
+	// the namespace arrives after the pod
+	tagInfos = processEvents(workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: namespace})
+	assert.Len(t, tagInfos, 1)
+	assert.Equal(t, podTaggerEntityID, tagInfos[0].Entity)
+	assert.Contains(t, tagInfos[0].LowCardTags, "team:payments-team")
+
+	// the cronjob owning the job of the pod changes
+	tagInfos = processEvents(workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: cronJob})
+	assert.Len(t, tagInfos, 1)
+	assert.Equal(t, podTaggerEntityID, tagInfos[0].Entity)
+
+	// the namespace is deleted
+	store.Unset(namespace)
+	tagInfos = processEvents(workloadmeta.Event{Type: workloadmeta.EventTypeUnset, Entity: namespace})
+	var podTagInfo *TagInfo
+	for _, tagInfo := range tagInfos {
+		if tagInfo.Entity == podTaggerEntityID {
+			podTagInfo = tagInfo
+		}
+	}
+	if assert.NotNil(t, podTagInfo) {
+		assert.NotContains(t, podTagInfo.LowCardTags, "team:payments-team")
+	}
+
+	// deleted pods are no longer re-tagged
+	store.Unset(pod)
+	processEvents(workloadmeta.Event{Type: workloadmeta.EventTypeUnset, Entity: pod})
+	assert.NotContains(t, collector.podsByNamespace, "payments")
+	store.Set(namespace)
+	tagInfos = processEvents(workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: namespace})
+	assert.Empty(t, tagInfos)
+}
+
 func TestParseJSONValue(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -56,13 +56,17 @@ type Agent struct {
 	Concentrator          *stats.Concentrator
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
+	SpanFilter            *filters.SpanFilter
+	SpanMetrics           *filters.SpanMetrics
 	Replacer              *filters.Replacer
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
+	TailSampler           *sampler.TailSampler
 	EventProcessor        *event.Processor
 	TraceWriter           *writer.TraceWriter
+	OTLPTraceWriter       *writer.OTLPTraceWriter
 	StatsWriter           *writer.StatsWriter
 	RemoteConfigHandler   *remoteconfighandler.RemoteConfigHandler
 	TelemetryCollector    telemetry.TelemetryCollector
@@ -107,6 +111,8 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		Concentrator:          stats.NewConcentrator(conf, statsChan, time.Now()),
 		ClientStatsAggregator: stats.NewClientStatsAggregator(conf, statsChan),
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
+		SpanFilter:            filters.NewSpanFilter(conf.SpanFilters),
+		SpanMetrics:           filters.NewSpanMetrics(conf.SpanMetrics),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf),
@@ -122,9 +128,12 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		DebugServer:           api.NewDebugServer(conf),
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt, telemetryCollector)
+	agnt.DebugServer.AddRoute("/debug/capture", agnt.Receiver.CaptureHandler())
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf)
 	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
 	agnt.TraceWriter = writer.NewTraceWriter(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector)
+	agnt.OTLPTraceWriter = writer.NewOTLPTraceWriter(conf)
+	agnt.TailSampler = sampler.NewTailSampler(conf, agnt.tailSampled)
 	return agnt
 }
 
@@ -137,6 +146,7 @@ func (a *Agent) Run() {
 		a.PrioritySampler,
 		a.ErrorsSampler,
 		a.NoPrioritySampler,
+		a.TailSampler,
 		a.EventProcessor,
 		a.OTLPReceiver,
 		a.RemoteConfigHandler,
@@ -146,6 +156,7 @@ func (a *Agent) Run() {
 	}
 
 	go a.TraceWriter.Run()
+	go a.OTLPTraceWriter.Run()
 	go a.StatsWriter.Run()
 
 	// Having GOMAXPROCS/2 processor threads is
@@ -204,7 +215,9 @@ func (a *Agent) loop() {
 	for _, stopper := range []interface{ Stop() }{
 		a.Concentrator,
 		a.ClientStatsAggregator,
+		a.TailSampler, // Stop TailSampler before TraceWriter as it flushes the pending traces
 		a.TraceWriter,
+		a.OTLPTraceWriter,
 		a.StatsWriter,
 		a.PrioritySampler,
 		a.ErrorsSampler,
@@ -305,6 +318,13 @@ func (a *Agent) Process(p *api.Payload) {
 			continue
 		}
 
+		// Span metrics are reported before the spans can be dropped by span filters or samplers.
+		a.SpanMetrics.Report(chunk)
+
+		if n := a.SpanFilter.Filter(chunk, root); n > 0 {
+			ts.SpansFilteredByRules.Add(int64(n))
+		}
+
 		// Extra sanitization steps of the trace.
 		appServicesTags := traceutil.GetAppServicesTags()
 		for _, span := range chunk.Spans {
@@ -344,6 +364,18 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		if a.TailSampler.Enabled() {
+			// The chunk is sampled once its whole trace is received,
+			// see tailSampled.
+			a.TailSampler.Add(now, &sampler.TailChunk{
+				Trace:   pt,
+				Payload: payloadMetadata(p.TracerPayload),
+				Data:    ts,
+			})
+			p.RemoveChunk(i)
+			continue
+		}
+
 		keep, numEvents := a.sample(now, ts, pt)
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
@@ -369,14 +401,14 @@ func (a *Agent) Process(p *api.Payload) {
 			sampledChunks.TracerPayload = p.TracerPayload.Cut(i)
 			i = 0
 			sampledChunks.TracerPayload.Chunks = newChunksArray(sampledChunks.TracerPayload.Chunks)
-			a.TraceWriter.In <- sampledChunks
+			a.writeChunks(sampledChunks)
 			sampledChunks = new(writer.SampledChunks)
 		}
 	}
 	sampledChunks.TracerPayload = p.TracerPayload
 	sampledChunks.TracerPayload.Chunks = newChunksArray(p.TracerPayload.Chunks)
 	if sampledChunks.Size > 0 {
-		a.TraceWriter.In <- sampledChunks
+		a.writeChunks(sampledChunks)
 	}
 	if len(statsInput.Traces) > 0 {
 		a.Concentrator.In <- statsInput
@@ -408,6 +440,58 @@ func processedTrace(p *api.Payload, chunk *pb.TraceChunk, root *pb.Span) *traceu
 	}
 }
 
+// payloadMetadata returns a copy of the tracer payload without its chunks.
+func payloadMetadata(tp *pb.TracerPayload) *pb.TracerPayload {
+	return &pb.TracerPayload{
+		ContainerID:     tp.ContainerID,
+		LanguageName:    tp.LanguageName,
+		LanguageVersion: tp.LanguageVersion,
+		TracerVersion:   tp.TracerVersion,
+		RuntimeID:       tp.RuntimeID,
+		Tags:            tp.Tags,
+		Env:             tp.Env,
+		Hostname:        tp.Hostname,
+		AppVersion:      tp.AppVersion,
+	}
+}
+
+// tailSampled samples and writes the chunks of a trace decided by the
+// TailSampler. The regular samplers run on all the chunks so that they keep
+// accounting for the whole traffic, but the chunks of traces matching a tail
+// sampling policy are kept whatever their decision.
+func (a *Agent) tailSampled(chunks []*sampler.TailChunk, policy string) {
+	now := time.Now()
+	for _, c := range chunks {
+		pt := c.Trace
+		spans := pt.TraceChunk.Spans
+		keep, numEvents := a.sample(now, c.Data.(*info.TagStats), pt)
+		if policy != "" {
+			pt.TraceChunk.Spans = spans
+			pt.TraceChunk.DroppedTrace = false
+			traceutil.SetMeta(pt.Root, sampler.TailSamplingPolicyKey, policy)
+		} else if !keep && len(pt.TraceChunk.Spans) == 0 {
+			continue
+		}
+
+		sampledChunks := &writer.SampledChunks{EventCount: int64(numEvents)}
+		if !pt.TraceChunk.DroppedTrace {
+			a.setFirstTraceTags(pt.Root)
+			sampledChunks.SpanCount = int64(len(pt.TraceChunk.Spans))
+		}
+		sampledChunks.Size = pt.TraceChunk.Msgsize()
+		sampledChunks.TracerPayload = c.Payload
+		sampledChunks.TracerPayload.Chunks = []*pb.TraceChunk{pt.TraceChunk}
+		a.writeChunks(sampledChunks)
+	}
+}
+
+// writeChunks sends the sampled chunks to the trace writers. The OTLP trace
+// writer drops them rather than blocking when it can't keep up.
+func (a *Agent) writeChunks(sampledChunks *writer.SampledChunks) {
+	a.OTLPTraceWriter.Push(sampledChunks)
+	a.TraceWriter.In <- sampledChunks
+}
+
 // newChunksArray creates a new array which will point only to sampled chunks.
 
 // The underlying array behind TracePayload.Chunks points to unsampled chunks
@@ -34,6 +34,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 	"github.com/DataDog/datadog-agent/pkg/trace/stats"
 	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/teststatsd"
 	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/writer"
@@ -203,6 +204,121 @@ func TestProcess(t *testing.T) {
 		assert.EqualValues(2, want.SpansFiltered.Load())
 	})
 
+	t.Run("SpanFilters", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.SpanFilters = []config.SpanFilterRule{{Name: "cache-hits", Tags: []string{"cache.hit:true"}}}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		defer cancel()
+
+		now := time.Now()
+		span := func(spanID, parentID uint64, meta map[string]string) *pb.Span {
+			return &pb.Span{
+				TraceID:  1,
+				SpanID:   spanID,
+				ParentID: parentID,
+				Resource: "GET /",
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+				Meta:     meta,
+			}
+		}
+		want := agnt.Receiver.Stats.GetTagStats(info.Tags{})
+
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpans([]*pb.Span{
+				span(1, 0, map[string]string{"cache.hit": "true"}),
+				span(2, 1, map[string]string{"cache.hit": "true"}),
+				span(3, 2, nil),
+			})),
+			Source: want,
+		})
+		assert.EqualValues(t, 0, want.TracesFiltered.Load())
+		assert.EqualValues(t, 1, want.SpansFilteredByRules.Load())
+
+		ss := <-agnt.TraceWriter.In
+		spans := ss.TracerPayload.Chunks[0].Spans
+		require.Len(t, spans, 2)
+		assert.Equal(t, uint64(1), spans[0].SpanID)
+		assert.Equal(t, uint64(3), spans[1].SpanID)
+		assert.Equal(t, uint64(1), spans[1].ParentID)
+	})
+
+	t.Run("SpanMetrics", func(t *testing.T) {
+		statsclient := &teststatsd.Client{}
+		defer testutil.WithStatsClient(statsclient)()
+
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.SpanFilters = []config.SpanFilterRule{{Name: "cache-hits", Tags: []string{"cache.hit:true"}}}
+		cfg.SpanMetrics = []config.SpanMetric{{
+			SpanFilterRule: config.SpanFilterRule{Name: "cache.lookups", Tags: []string{"cache.hit"}},
+			Type:           "count",
+			GroupBy:        []string{"cache.hit"},
+		}}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		defer cancel()
+
+		now := time.Now()
+		span := func(spanID, parentID uint64, meta map[string]string) *pb.Span {
+			return &pb.Span{
+				TraceID:  1,
+				SpanID:   spanID,
+				ParentID: parentID,
+				Resource: "GET /",
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+				Meta:     meta,
+			}
+		}
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpans([]*pb.Span{
+				span(1, 0, nil),
+				span(2, 1, map[string]string{"cache.hit": "true"}),
+				span(3, 1, map[string]string{"cache.hit": "false"}),
+			})),
+			Source: agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+		})
+
+		// the span dropped by the span filter is counted
+		ss := <-agnt.TraceWriter.In
+		assert.Len(t, ss.TracerPayload.Chunks[0].Spans, 2)
+		counts := statsclient.GetCountSummaries()["cache.lookups"]
+		require.NotNil(t, counts)
+		assert.EqualValues(t, 2, counts.Sum)
+		assert.ElementsMatch(t, [][]string{{"cache.hit:true"}, {"cache.hit:false"}}, [][]string{counts.Calls[0].Tags, counts.Calls[1].Tags})
+	})
+
+	t.Run("OTLPTraceWriter", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.OTLPExport.Enabled = true
+		cfg.OTLPExport.Protocol = "http"
+		cfg.OTLPExport.Endpoint = "http://localhost:4318"
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		defer cancel()
+		require.NotNil(t, agnt.OTLPTraceWriter)
+
+		now := time.Now()
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(&pb.Span{
+				TraceID:  1,
+				SpanID:   1,
+				Resource: "GET /",
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+			})),
+			Source: agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+		})
+
+		// the sampled chunks are written to both writers
+		ss := <-agnt.TraceWriter.In
+		assert.Same(t, ss, <-agnt.OTLPTraceWriter.In)
+	})
+
 	t.Run("BlacklistPayload", func(t *testing.T) {
 		// Regression test for DataDog/datadog-agent#6500
 		cfg := config.New()
@@ -2329,3 +2445,60 @@ func TestSetFirstTraceTags(t *testing.T) {
 		assert.Equal(t, strconv.FormatInt(timestamp, 10), root.Meta[tagInstallTime])
 	})
 }
+
+func TestTailSampling(t *testing.T) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.TailSampling.Enabled = true
+	cfg.TailSampling.Policies = []config.TailSamplingPolicy{{Name: "vip", Type: "tag", Key: "customer.tier", Value: "gold"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+
+	now := time.Now()
+	chunk := func(traceID, spanID uint64, meta map[string]string) *pb.TraceChunk {
+		return &pb.TraceChunk{
+			Priority: int32(sampler.PriorityAutoDrop),
+			Spans: []*pb.Span{{
+				Service:  "web",
+				Name:     "http.request",
+				Resource: "GET /",
+				TraceID:  traceID,
+				SpanID:   spanID,
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: time.Millisecond.Nanoseconds(),
+				Meta:     meta,
+			}},
+		}
+	}
+	agnt.Process(&api.Payload{
+		TracerPayload: &pb.TracerPayload{
+			Env:    "prod",
+			Chunks: []*pb.TraceChunk{chunk(1, 1, nil), chunk(2, 3, nil)},
+		},
+		Source: info.NewReceiverStats().GetTagStats(info.Tags{}),
+	})
+	agnt.Process(&api.Payload{
+		TracerPayload: &pb.TracerPayload{
+			Env:    "prod",
+			Chunks: []*pb.TraceChunk{chunk(1, 2, map[string]string{"customer.tier": "gold"})},
+		},
+		Source: info.NewReceiverStats().GetTagStats(info.Tags{}),
+	})
+
+	// chunks are buffered until their trace is decided
+	assert.Len(t, agnt.TraceWriter.In, 0)
+	assert.Len(t, agnt.Concentrator.In, 2)
+
+	agnt.TailSampler.Stop()
+	require.Len(t, agnt.TraceWriter.In, 2)
+	for i := 0; i < 2; i++ {
+		sampled := <-agnt.TraceWriter.In
+		assert.Equal(t, "prod", sampled.TracerPayload.Env)
+		require.Len(t, sampled.TracerPayload.Chunks, 1)
+		c := sampled.TracerPayload.Chunks[0]
+		assert.False(t, c.DroppedTrace)
+		assert.Equal(t, uint64(1), c.Spans[0].TraceID)
+		assert.Equal(t, "vip", c.Spans[0].Meta[sampler.TailSamplingPolicyKey])
+	}
+}
@@ -22,10 +22,17 @@ const (
 	tagElasticBody      = "elasticsearch.body"
 	tagSQLQuery         = "sql.query"
 	tagHTTPURL          = "http.url"
+	tagGraphQLQuery     = "graphql.query"
+
+	// tagGraphQLVariablesPrefix prefixes the tags holding the values of the
+	// variables of GraphQL operations.
+	tagGraphQLVariablesPrefix = "graphql.variables."
 )
 
 const (
-	textNonParsable = "Non-parsable SQL query"
+	textNonParsable        = "Non-parsable SQL query"
+	textNonParsableCQL     = "Non-parsable CQL query"
+	textNonParsableGraphQL = "Non-parsable GraphQL query"
 )
 
 func (a *Agent) obfuscateSpan(span *pb.Span) {
@@ -35,6 +42,16 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 		if span.Resource == "" {
 			return
 		}
+		if span.Type == "cassandra" && a.conf.Obfuscation.CQL.Enabled {
+			q, err := o.ObfuscateCQLString(span.Resource)
+			if err != nil {
+				log.Debugf("Error parsing CQL query: %v. Resource: %q", err, span.Resource)
+				q = textNonParsableCQL
+			}
+			span.Resource = q
+			traceutil.SetMeta(span, tagSQLQuery, q)
+			return
+		}
 		oq, err := o.ObfuscateSQLString(span.Resource)
 		if err != nil {
 			// we have an error, discard the SQL to avoid polluting user resources.
@@ -69,6 +86,28 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 			return
 		}
 		span.Meta[tagMemcachedCommand] = o.ObfuscateMemcachedString(span.Meta[tagMemcachedCommand])
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		// The resource is not always a GraphQL document, e.g. when it is an
+		// operation name, so it is kept when it can't be parsed.
+		if q, err := o.ObfuscateGraphQLString(span.Resource); err == nil {
+			span.Resource = q
+		}
+		for k, v := range span.Meta {
+			switch {
+			case k == tagGraphQLQuery && v != "":
+				q, err := o.ObfuscateGraphQLString(v)
+				if err != nil {
+					log.Debugf("Error parsing GraphQL query: %v. Query: %q", err, v)
+					q = textNonParsableGraphQL
+				}
+				span.Meta[k] = q
+			case strings.HasPrefix(k, tagGraphQLVariablesPrefix):
+				span.Meta[k] = "?"
+			}
+		}
 	case "web", "http":
 		if span.Meta == nil || span.Meta[tagHTTPURL] == "" {
 			return
@@ -97,6 +136,15 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 	o := a.obfuscator
 	switch b.Type {
 	case "sql", "cassandra":
+		if b.Type == "cassandra" && a.conf.Obfuscation.CQL.Enabled {
+			q, err := o.ObfuscateCQLString(b.Resource)
+			if err != nil {
+				log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
+				q = textNonParsableCQL
+			}
+			b.Resource = q
+			return
+		}
 		oq, err := o.ObfuscateSQLString(b.Resource)
 		if err != nil {
 			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
@@ -106,6 +154,13 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		if q, err := o.ObfuscateGraphQLString(b.Resource); err == nil {
+			b.Resource = q
+		}
 	}
 }
 
@@ -65,10 +65,16 @@ func TestObfuscateStatsGroup(t *testing.T) {
 		{statsGroup("sql", "SELECT 1 FROM db"), "SELECT ? FROM db"},
 		{statsGroup("sql", "SELECT 1\nFROM Blogs AS [b\nORDER BY [b]"), textNonParsable},
 		{statsGroup("redis", "ADD 1, 2"), "ADD"},
+		{statsGroup("cassandra", "SELECT * FROM users WHERE id IN (1, 2)"), "SELECT * FROM users WHERE id IN ( ? )"},
+		{statsGroup("cassandra", "SELECT * FROM users WHERE name = 'bob"), textNonParsableCQL},
+		{statsGroup("graphql", `query { user(id: 1) { name } }`), "query { user(id: ?) { name } }"},
+		{statsGroup("graphql", "graphql.execute"), "graphql.execute"},
 		{statsGroup("other", "ADD 1, 2"), "ADD 1, 2"},
 	} {
 		agnt, stop := agentWithDefaults()
 		defer stop()
+		agnt.conf.Obfuscation.CQL.Enabled = true
+		agnt.conf.Obfuscation.GraphQL.Enabled = true
 		agnt.obfuscateStatsGroup(tt.in)
 		assert.Equal(t, tt.in.Resource, tt.out)
 	}
@@ -104,6 +110,27 @@ func TestObfuscateDefaults(t *testing.T) {
 		assert.Equal(t, "UPDATE users ( name ) SET ( ? )", span.Meta["sql.query"])
 		assert.Equal(t, "UPDATE users ( name ) SET ( ? )", span.Resource)
 	})
+
+	t.Run("cassandra", func(t *testing.T) {
+		// the resource names of cassandra spans are unchanged unless CQL
+		// obfuscation is enabled: they are still obfuscated as SQL
+		query := "INSERT INTO users (id, tags) VALUES (123e4567-e89b-12d3-a456-426614174000, {'a', 'b'})"
+		span := &pb.Span{
+			Type:     "cassandra",
+			Resource: query,
+		}
+		stats := &pb.ClientGroupedStats{
+			Type:     "cassandra",
+			Resource: query,
+		}
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		agnt.obfuscateSpan(span)
+		agnt.obfuscateStatsGroup(stats)
+		assert.Equal(t, "INSERT INTO users ( id, tags ) VALUES ( ? - e89b ? d3 - a456 ? )", span.Resource)
+		assert.Equal(t, "INSERT INTO users ( id, tags ) VALUES ( ? - e89b ? d3 - a456 ? )", span.Meta["sql.query"])
+		assert.Equal(t, span.Resource, stats.Resource)
+	})
 }
 
 func agentWithDefaults(features ...string) (agnt *Agent, stop func()) {
@@ -246,6 +273,96 @@ func TestObfuscateConfig(t *testing.T) {
 		"set key 0 0 0 noreply\r\nvalue",
 		&config.ObfuscationConfig{},
 	))
+
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 1, active: true) { name } }`,
+		"query { user(id: ?, active: ?) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/keep_boolean", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 1, active: true) { name } }`,
+		"query { user(id: ?, active: true) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{
+			Enabled:     true,
+			KeepBoolean: true,
+		}},
+	))
+
+	t.Run("graphql/variables", testConfig(
+		"graphql",
+		"graphql.variables.id",
+		"42",
+		"?",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/invalid", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(name: "bob) }`,
+		textNonParsableGraphQL,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 1) { name } }`,
+		`query { user(id: 1) { name } }`,
+		&config.ObfuscationConfig{},
+	))
+}
+
+func TestObfuscateCQL(t *testing.T) {
+	newAgent := func(ocfg *config.ObfuscationConfig) (*Agent, context.CancelFunc) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = ocfg
+		return NewAgent(ctx, cfg, telemetry.NewNoopCollector()), cancelFunc
+	}
+	cassandraSpan := func(query string) *pb.Span {
+		return &pb.Span{Type: "cassandra", Resource: query}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		agnt, stop := newAgent(&config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: true}})
+		defer stop()
+		span := cassandraSpan("INSERT INTO users (id, tags) VALUES (123e4567-e89b-12d3-a456-426614174000, {'a', 'b'})")
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "INSERT INTO users ( id, tags ) VALUES ( ? )", span.Resource)
+		assert.Equal(t, "INSERT INTO users ( id, tags ) VALUES ( ? )", span.Meta["sql.query"])
+	})
+
+	t.Run("keep_null", func(t *testing.T) {
+		agnt, stop := newAgent(&config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: true, KeepNull: true}})
+		defer stop()
+		span := cassandraSpan("UPDATE users SET name = null WHERE id = 1")
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "UPDATE users SET name = null WHERE id = ?", span.Resource)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		agnt, stop := newAgent(&config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: true}})
+		defer stop()
+		span := cassandraSpan("SELECT * FROM users WHERE name = 'bob")
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, textNonParsableCQL, span.Resource)
+		assert.Equal(t, textNonParsableCQL, span.Meta["sql.query"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		agnt, stop := newAgent(&config.ObfuscationConfig{})
+		defer stop()
+		span := cassandraSpan("SELECT * FROM users WHERE id = 1")
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "SELECT * FROM users WHERE id = ?", span.Resource)
+	})
 }
 
 func SQLSpan(query string) *pb.Span {
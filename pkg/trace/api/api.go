@@ -34,6 +34,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
@@ -67,6 +68,14 @@ type HTTPReceiver struct {
 	statsProcessor      StatsProcessor
 	containerIDProvider IDProvider
 
+	// translator converts the traces received in third-party formats, such
+	// as Zipkin or Jaeger, once translated to OTLP.
+	translator *OTLPReceiver
+
+	// capture records the received traces payloads while a capture started
+	// through CaptureHandler is in progress.
+	capture *replay.Capture
+
 	telemetryCollector telemetry.TelemetryCollector
 
 	rateLimiterResponse int // HTTP status code when refusing
@@ -107,6 +116,8 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 		conf:                conf,
 		dynConf:             dynConf,
 		containerIDProvider: NewIDProvider(conf.ContainerProcRoot),
+		translator:          NewOTLPReceiver(out, conf),
+		capture:             replay.NewCapture(conf.CapturePath),
 
 		telemetryCollector: telemetryCollector,
 
@@ -288,6 +299,7 @@ func (r *HTTPReceiver) Stop() error {
 		return err
 	}
 	r.wg.Wait()
+	r.capture.Stop()
 	close(r.out)
 	return nil
 }
@@ -305,6 +317,10 @@ func (r *HTTPReceiver) handleWithVersion(v Version, f func(Version, http.Respons
 			return
 		}
 
+		if r.capture.Active() && capturable(v, req) {
+			req.Body = r.capture.Tee(req, r.conf.MaxRequestBytes)
+		}
+
 		// TODO(x): replace with http.MaxBytesReader?
 		req.Body = apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
 
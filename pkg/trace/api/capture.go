@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
+)
+
+// defaultCaptureDuration is the duration of a capture when none is given.
+const defaultCaptureDuration = time.Minute
+
+// capturable reports whether the request req, received on an endpoint of
+// version v, is recorded by captures. Only the msgpack traces endpoints are.
+func capturable(v Version, req *http.Request) bool {
+	switch v {
+	case v04, v05, V07:
+		return strings.HasSuffix(req.URL.Path, "/traces")
+	}
+	return false
+}
+
+// CaptureHandler returns the handler starting a capture of the traces payloads
+// received by r. The duration of the capture is set by the "duration" query
+// parameter, e.g. "30s", and defaults to one minute.
+func (r *HTTPReceiver) CaptureHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		d := defaultCaptureDuration
+		if v := req.URL.Query().Get("duration"); v != "" {
+			var err error
+			if d, err = time.ParseDuration(v); err != nil {
+				http.Error(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		path, err := r.capture.Start(d)
+		switch err {
+		case nil:
+		case replay.ErrCaptureInProgress:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if d > replay.MaxCaptureDuration {
+			d = replay.MaxCaptureDuration
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(CaptureResponse{Path: path, Duration: d.String()}) //nolint:errcheck
+	})
+}
+
+// CaptureResponse is the response of the capture handler.
+type CaptureResponse struct {
+	// Path is the path of the capture file.
+	Path string `json:"path"`
+	// Duration is the duration of the capture.
+	Duration string `json:"duration"`
+}
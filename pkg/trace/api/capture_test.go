@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+)
+
+func TestCaptureHandler(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.CapturePath = t.TempDir()
+	r := newTestReceiverFromConfig(conf)
+	capture := r.CaptureHandler()
+
+	start := func(method, query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		capture.ServeHTTP(rr, httptest.NewRequest(method, "/debug/capture"+query, nil))
+		return rr
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, start(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusBadRequest, start(http.MethodPost, "?duration=abc").Code)
+	rr := start(http.MethodPost, "?duration=2h")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp CaptureResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "1h0m0s", resp.Duration)
+	assert.Equal(t, http.StatusConflict, start(http.MethodPost, "").Code)
+
+	bts, err := testutil.GetTestTraces(2, 2, true).MarshalMsg(nil)
+	require.NoError(t, err)
+	send := func(v Version, path string, f func(Version, http.ResponseWriter, *http.Request)) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bts))
+		req.Header.Set("Content-Type", "application/msgpack")
+		req.Header.Set("Datadog-Meta-Lang", "go")
+		r.handleWithVersion(v, f).ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send(v04, "/v0.4/traces", r.handleTraces)
+	<-r.out
+	send(v04, "/v0.4/services", func(_ Version, _ http.ResponseWriter, req *http.Request) {
+		io.Copy(io.Discard, req.Body) //nolint:errcheck
+		req.Body.Close()
+	})
+	r.capture.Stop()
+
+	f, err := os.Open(resp.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	rd, err := replay.NewReader(f)
+	require.NoError(t, err)
+	rec, err := rd.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "/v0.4/traces", rec.Path)
+	assert.Equal(t, "go", rec.Header.Get("Datadog-Meta-Lang"))
+	assert.Equal(t, bts, rec.Body)
+	_, err = rd.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -31,15 +31,22 @@ const (
 type DebugServer struct {
 	conf   *config.AgentConfig
 	server *http.Server
+	routes map[string]http.Handler
 }
 
 // NewDebugServer returns a debug server
 func NewDebugServer(conf *config.AgentConfig) *DebugServer {
 	return &DebugServer{
-		conf: conf,
+		conf:   conf,
+		routes: make(map[string]http.Handler),
 	}
 }
 
+// AddRoute adds a route to the debug server. It must be called before Start.
+func (ds *DebugServer) AddRoute(route string, handler http.Handler) {
+	ds.routes[route] = handler
+}
+
 // Start configures and starts the http server
 func (ds *DebugServer) Start() {
 	if ds.conf.DebugServerPort == 0 {
@@ -108,5 +115,8 @@ func (ds *DebugServer) mux() *http.ServeMux {
 		w.Header().Set("Access-Control-Allow-Origin", "http://127.0.0.1:"+ds.conf.GUIPort)
 		expvar.Handler().ServeHTTP(w, req)
 	}))
+	for route, handler := range ds.routes {
+		mux.Handle(route, handler)
+	}
 	return mux
 }
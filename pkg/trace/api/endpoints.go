@@ -144,4 +144,14 @@ var endpoints = []Endpoint{
 		Pattern: "/tracer_flare/v1",
 		Handler: func(r *HTTPReceiver) http.Handler { return r.tracerFlareHandler() },
 	},
+	{
+		Pattern:   "/api/v2/spans",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleTranslatedTraces(zipkinV2, r.decodeZipkin) },
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.ZipkinReceiverEnabled },
+	},
+	{
+		Pattern:   "/api/traces",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleTranslatedTraces(jaegerCollector, r.decodeJaeger) },
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.JaegerReceiverEnabled },
+	},
 }
@@ -0,0 +1,592 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// jaegerCollector is the endpoint version reported for the traces received on
+// the Jaeger collector endpoint.
+const jaegerCollector = "jaeger_collector"
+
+// Types of the values of Jaeger tags, as numbered in jaeger.thrift.
+const (
+	jaegerTagString = iota
+	jaegerTagDouble
+	jaegerTagBool
+	jaegerTagLong
+	jaegerTagBinary
+)
+
+// jaegerRefChildOf is the type of the references to the parent of a span.
+const jaegerRefChildOf = 0
+
+// jaegerBatch holds the spans of a Jaeger batch, decoded from Thrift or
+// protobuf.
+type jaegerBatch struct {
+	process *jaegerProcess
+	spans   []*jaegerSpan
+}
+
+type jaegerProcess struct {
+	serviceName string
+	tags        []jaegerTag
+}
+
+type jaegerSpan struct {
+	traceID       pcommon.TraceID
+	spanID        pcommon.SpanID
+	parentID      pcommon.SpanID
+	operationName string
+	refs          []jaegerRef
+	start         uint64 // ns since epoch
+	duration      uint64 // ns
+	tags          []jaegerTag
+	logs          []jaegerLog
+	// process overrides the process of the batch, if set.
+	process *jaegerProcess
+}
+
+type jaegerRef struct {
+	refType int64
+	traceID pcommon.TraceID
+	spanID  pcommon.SpanID
+}
+
+type jaegerLog struct {
+	timestamp uint64 // ns since epoch
+	fields    []jaegerTag
+}
+
+type jaegerTag struct {
+	key     string
+	vType   int64
+	vStr    string
+	vDouble float64
+	vBool   bool
+	vLong   int64
+	vBinary []byte
+}
+
+// String returns the value of the tag formatted as a string.
+func (t *jaegerTag) String() string {
+	switch t.vType {
+	case jaegerTagDouble:
+		return strconv.FormatFloat(t.vDouble, 'f', -1, 64)
+	case jaegerTagBool:
+		return strconv.FormatBool(t.vBool)
+	case jaegerTagLong:
+		return strconv.FormatInt(t.vLong, 10)
+	case jaegerTagBinary:
+		return string(t.vBinary)
+	default:
+		return t.vStr
+	}
+}
+
+// putAttribute sets the tag in attrs, keeping the type of its value.
+func (t *jaegerTag) putAttribute(attrs pcommon.Map) {
+	switch t.vType {
+	case jaegerTagDouble:
+		attrs.PutDouble(t.key, t.vDouble)
+	case jaegerTagBool:
+		attrs.PutBool(t.key, t.vBool)
+	case jaegerTagLong:
+		attrs.PutInt(t.key, t.vLong)
+	case jaegerTagBinary:
+		attrs.PutEmptyBytes(t.key).FromRaw(t.vBinary)
+	default:
+		attrs.PutStr(t.key, t.vStr)
+	}
+}
+
+// jaegerTagValue returns the value of the tag with the given key as a string.
+func jaegerTagValue(tags []jaegerTag, key string) string {
+	for i := range tags {
+		if tags[i].key == key {
+			return tags[i].String()
+		}
+	}
+	return ""
+}
+
+// decodeJaeger decodes a Jaeger batch, encoded with Thrift or protobuf
+// depending on the Content-Type of the request, and converts it to OTLP.
+func (r *HTTPReceiver) decodeJaeger(req *http.Request) (ptrace.Traces, error) {
+	mt := requestMediaType(req, "application/x-thrift")
+	if mt != "application/x-thrift" && mt != "application/x-protobuf" {
+		return ptrace.Traces{}, fmt.Errorf("unsupported media type %q", mt)
+	}
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+	var batch *jaegerBatch
+	if mt == "application/x-thrift" {
+		batch, err = decodeJaegerThriftBatch(&thriftReader{b: buf})
+	} else {
+		batch, err = decodeJaegerProtoRequest(buf)
+	}
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+	return jaegerToTraces(batch), nil
+}
+
+// jaegerToTraces converts the Jaeger batch to OTLP, with one resource per
+// process.
+func jaegerToTraces(batch *jaegerBatch) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	resources := make(map[*jaegerProcess]*scopeSpansCache)
+	for _, js := range batch.spans {
+		process := js.process
+		if process == nil {
+			process = batch.process
+		}
+		cache, ok := resources[process]
+		if !ok {
+			rspans := traces.ResourceSpans().AppendEmpty()
+			if process != nil {
+				attrs := rspans.Resource().Attributes()
+				for i := range process.tags {
+					process.tags[i].putAttribute(attrs)
+				}
+				if process.serviceName != "" {
+					attrs.PutStr(semconv.AttributeServiceName, process.serviceName)
+				}
+			}
+			cache = newScopeSpansCache(rspans)
+			resources[process] = cache
+		}
+		scopeName, scopeVersion := jaegerTagValue(js.tags, tagOTelScopeName), jaegerTagValue(js.tags, tagOTelScopeVersion)
+		if scopeName == "" {
+			scopeName, scopeVersion = jaegerTagValue(js.tags, tagOTelLibraryName), jaegerTagValue(js.tags, tagOTelLibraryVersion)
+		}
+		convertJaegerSpan(js, cache.appendSpan(scopeName, scopeVersion))
+	}
+	return traces
+}
+
+// convertJaegerSpan fills span with the fields of js.
+func convertJaegerSpan(js *jaegerSpan, span ptrace.Span) {
+	span.SetTraceID(js.traceID)
+	span.SetSpanID(js.spanID)
+	parentID := js.parentID
+	for _, ref := range js.refs {
+		if ref.refType == jaegerRefChildOf && ref.traceID == js.traceID && (parentID.IsEmpty() || parentID == ref.spanID) {
+			parentID = ref.spanID
+			continue
+		}
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(ref.traceID)
+		link.SetSpanID(ref.spanID)
+	}
+	span.SetParentSpanID(parentID)
+	span.SetName(js.operationName)
+	span.SetStartTimestamp(pcommon.Timestamp(js.start))
+	span.SetEndTimestamp(pcommon.Timestamp(js.start + js.duration))
+
+	attrs := span.Attributes()
+	for i := range js.tags {
+		tag := &js.tags[i]
+		switch tag.key {
+		case "span.kind":
+			span.SetKind(spanKindFromName(tag.String()))
+		case "error":
+			if tag.String() == "true" {
+				span.Status().SetCode(ptrace.StatusCodeError)
+			}
+		case tagOTelStatusCode:
+			setStatusFromTag(span, tag.String())
+		case tagOTelStatusDescription:
+			span.Status().SetMessage(tag.String())
+		case tagOTelScopeName, tagOTelScopeVersion, tagOTelLibraryName, tagOTelLibraryVersion:
+			// already set on the scope
+		default:
+			tag.putAttribute(attrs)
+		}
+	}
+	for _, l := range js.logs {
+		event := span.Events().AppendEmpty()
+		event.SetTimestamp(pcommon.Timestamp(l.timestamp))
+		name := jaegerTagValue(l.fields, "event")
+		if name == "error" {
+			fields := make(map[string]string, len(l.fields))
+			for i := range l.fields {
+				fields[l.fields[i].key] = l.fields[i].String()
+			}
+			setExceptionEvent(event, fields)
+			continue
+		}
+		event.SetName(name)
+		for i := range l.fields {
+			if l.fields[i].key != "event" {
+				l.fields[i].putAttribute(event.Attributes())
+			}
+		}
+	}
+}
+
+// jaegerThriftTraceID returns the trace ID made of the given halves.
+func jaegerThriftTraceID(high, low int64) pcommon.TraceID {
+	var id pcommon.TraceID
+	binary.BigEndian.PutUint64(id[:8], uint64(high))
+	binary.BigEndian.PutUint64(id[8:], uint64(low))
+	return id
+}
+
+// jaegerThriftSpanID returns the span ID with the given value.
+func jaegerThriftSpanID(v int64) pcommon.SpanID {
+	var id pcommon.SpanID
+	binary.BigEndian.PutUint64(id[:], uint64(v))
+	return id
+}
+
+// decodeJaegerThriftBatch decodes a Batch of jaeger.thrift.
+func decodeJaegerThriftBatch(r *thriftReader) (*jaegerBatch, error) {
+	batch := &jaegerBatch{}
+	err := r.readStruct(func(typ byte, id int16) error {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			p, err := decodeJaegerThriftProcess(r)
+			batch.process = p
+			return err
+		case id == 2 && typ == thriftList:
+			return r.readList(thriftStruct, func() error {
+				span, err := decodeJaegerThriftSpan(r)
+				batch.spans = append(batch.spans, span)
+				return err
+			})
+		default:
+			return r.skip(typ, 0)
+		}
+	})
+	return batch, err
+}
+
+func decodeJaegerThriftProcess(r *thriftReader) (*jaegerProcess, error) {
+	p := &jaegerProcess{}
+	err := r.readStruct(func(typ byte, id int16) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftString:
+			p.serviceName, err = r.readString()
+		case id == 2 && typ == thriftList:
+			p.tags, err = decodeJaegerThriftTags(r)
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	return p, err
+}
+
+func decodeJaegerThriftSpan(r *thriftReader) (*jaegerSpan, error) {
+	span := &jaegerSpan{}
+	var traceIDLow, traceIDHigh int64
+	err := r.readStruct(func(typ byte, id int16) error {
+		var (
+			err error
+			v   int64
+		)
+		switch {
+		case id == 1 && typ == thriftI64:
+			traceIDLow, err = r.readI64()
+		case id == 2 && typ == thriftI64:
+			traceIDHigh, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			v, err = r.readI64()
+			span.spanID = jaegerThriftSpanID(v)
+		case id == 4 && typ == thriftI64:
+			if v, err = r.readI64(); v != 0 {
+				span.parentID = jaegerThriftSpanID(v)
+			}
+		case id == 5 && typ == thriftString:
+			span.operationName, err = r.readString()
+		case id == 6 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				ref, err := decodeJaegerThriftRef(r)
+				span.refs = append(span.refs, ref)
+				return err
+			})
+		case id == 8 && typ == thriftI64:
+			v, err = r.readI64()
+			span.start = uint64(v) * 1000
+		case id == 9 && typ == thriftI64:
+			v, err = r.readI64()
+			span.duration = uint64(v) * 1000
+		case id == 10 && typ == thriftList:
+			span.tags, err = decodeJaegerThriftTags(r)
+		case id == 11 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				var l jaegerLog
+				err := r.readStruct(func(typ byte, id int16) error {
+					var err error
+					switch {
+					case id == 1 && typ == thriftI64:
+						v, err = r.readI64()
+						l.timestamp = uint64(v) * 1000
+					case id == 2 && typ == thriftList:
+						l.fields, err = decodeJaegerThriftTags(r)
+					default:
+						err = r.skip(typ, 0)
+					}
+					return err
+				})
+				span.logs = append(span.logs, l)
+				return err
+			})
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	span.traceID = jaegerThriftTraceID(traceIDHigh, traceIDLow)
+	return span, err
+}
+
+func decodeJaegerThriftRef(r *thriftReader) (jaegerRef, error) {
+	var (
+		ref          jaegerRef
+		high, low, v int64
+		refType      int32
+	)
+	err := r.readStruct(func(typ byte, id int16) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			refType, err = r.readI32()
+			ref.refType = int64(refType)
+		case id == 2 && typ == thriftI64:
+			low, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			high, err = r.readI64()
+		case id == 4 && typ == thriftI64:
+			v, err = r.readI64()
+			ref.spanID = jaegerThriftSpanID(v)
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	ref.traceID = jaegerThriftTraceID(high, low)
+	return ref, err
+}
+
+func decodeJaegerThriftTags(r *thriftReader) ([]jaegerTag, error) {
+	var tags []jaegerTag
+	err := r.readList(thriftStruct, func() error {
+		var tag jaegerTag
+		err := r.readStruct(func(typ byte, id int16) error {
+			var (
+				err   error
+				vType int32
+			)
+			switch {
+			case id == 1 && typ == thriftString:
+				tag.key, err = r.readString()
+			case id == 2 && typ == thriftI32:
+				vType, err = r.readI32()
+				tag.vType = int64(vType)
+			case id == 3 && typ == thriftString:
+				tag.vStr, err = r.readString()
+			case id == 4 && typ == thriftDouble:
+				tag.vDouble, err = r.readDouble()
+			case id == 5 && typ == thriftBool:
+				tag.vBool, err = r.readBool()
+			case id == 6 && typ == thriftI64:
+				tag.vLong, err = r.readI64()
+			case id == 7 && typ == thriftString:
+				tag.vBinary, err = r.readBinary()
+			default:
+				err = r.skip(typ, 0)
+			}
+			return err
+		})
+		tags = append(tags, tag)
+		return err
+	})
+	return tags, err
+}
+
+// jaegerProtoTagTypes maps the values of the ValueType enum of the Jaeger
+// protobuf model to the tag types of jaeger.thrift.
+var jaegerProtoTagTypes = map[uint64]int64{
+	0: jaegerTagString,
+	1: jaegerTagBool,
+	2: jaegerTagLong,
+	3: jaegerTagDouble,
+	4: jaegerTagBinary,
+}
+
+// decodeJaegerProtoRequest decodes a PostSpansRequest of the Jaeger api_v2.
+func decodeJaegerProtoRequest(b []byte) (*jaegerBatch, error) {
+	batch := &jaegerBatch{}
+	err := rangeProtoFields(b, func(f protoField) error {
+		if f.num != 1 || f.typ != protowire.BytesType {
+			return nil
+		}
+		return rangeProtoFields(f.bytes, func(f protoField) error {
+			var err error
+			switch f.num {
+			case 1:
+				var span *jaegerSpan
+				span, err = decodeJaegerProtoSpan(f.bytes)
+				batch.spans = append(batch.spans, span)
+			case 2:
+				batch.process, err = decodeJaegerProtoProcess(f.bytes)
+			}
+			return err
+		})
+	})
+	return batch, err
+}
+
+func decodeJaegerProtoProcess(b []byte) (*jaegerProcess, error) {
+	p := &jaegerProcess{}
+	err := rangeProtoFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			p.serviceName = string(f.bytes)
+		case 2:
+			tag, err := decodeJaegerProtoTag(f.bytes)
+			if err != nil {
+				return err
+			}
+			p.tags = append(p.tags, tag)
+		}
+		return nil
+	})
+	return p, err
+}
+
+func decodeJaegerProtoSpan(b []byte) (*jaegerSpan, error) {
+	span := &jaegerSpan{}
+	err := rangeProtoFields(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			span.traceID, err = jaegerProtoTraceID(f.bytes)
+		case 2:
+			span.spanID, err = jaegerProtoSpanID(f.bytes)
+		case 3:
+			span.operationName = string(f.bytes)
+		case 4:
+			var ref jaegerRef
+			err = rangeProtoFields(f.bytes, func(f protoField) error {
+				var err error
+				switch f.num {
+				case 1:
+					ref.traceID, err = jaegerProtoTraceID(f.bytes)
+				case 2:
+					ref.spanID, err = jaegerProtoSpanID(f.bytes)
+				case 3:
+					ref.refType = int64(f.value)
+				}
+				return err
+			})
+			span.refs = append(span.refs, ref)
+		case 6:
+			span.start, err = decodeProtoTimestamp(f.bytes)
+		case 7:
+			span.duration, err = decodeProtoTimestamp(f.bytes)
+		case 8:
+			var tag jaegerTag
+			tag, err = decodeJaegerProtoTag(f.bytes)
+			span.tags = append(span.tags, tag)
+		case 9:
+			var l jaegerLog
+			err = rangeProtoFields(f.bytes, func(f protoField) error {
+				var err error
+				switch f.num {
+				case 1:
+					l.timestamp, err = decodeProtoTimestamp(f.bytes)
+				case 2:
+					var tag jaegerTag
+					tag, err = decodeJaegerProtoTag(f.bytes)
+					l.fields = append(l.fields, tag)
+				}
+				return err
+			})
+			span.logs = append(span.logs, l)
+		case 10:
+			span.process, err = decodeJaegerProtoProcess(f.bytes)
+		}
+		return err
+	})
+	return span, err
+}
+
+func decodeJaegerProtoTag(b []byte) (jaegerTag, error) {
+	var tag jaegerTag
+	err := rangeProtoFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			tag.key = string(f.bytes)
+		case 2:
+			tag.vType = jaegerProtoTagTypes[f.value]
+		case 3:
+			tag.vStr = string(f.bytes)
+		case 4:
+			tag.vBool = f.value != 0
+		case 5:
+			tag.vLong = int64(f.value)
+		case 6:
+			tag.vDouble = math.Float64frombits(f.value)
+		case 7:
+			tag.vBinary = f.bytes
+		}
+		return nil
+	})
+	return tag, err
+}
+
+// decodeProtoTimestamp decodes a google.protobuf.Timestamp or Duration
+// message, returning its value in nanoseconds.
+func decodeProtoTimestamp(b []byte) (uint64, error) {
+	var seconds, nanos uint64
+	err := rangeProtoFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			seconds = f.value
+		case 2:
+			nanos = f.value
+		}
+		return nil
+	})
+	return seconds*1e9 + nanos, err
+}
+
+// jaegerProtoTraceID returns the trace ID encoded as big-endian bytes.
+func jaegerProtoTraceID(b []byte) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	if len(b) > len(id) {
+		return id, fmt.Errorf("invalid trace ID length %d", len(b))
+	}
+	copy(id[len(id)-len(b):], b)
+	return id, nil
+}
+
+// jaegerProtoSpanID returns the span ID encoded as big-endian bytes.
+func jaegerProtoSpanID(b []byte) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	if len(b) > len(id) {
+		return id, fmt.Errorf("invalid span ID length %d", len(b))
+	}
+	copy(id[len(id)-len(b):], b)
+	return id, nil
+}
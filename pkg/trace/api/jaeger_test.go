@@ -0,0 +1,245 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// thriftWriter writes values with the Thrift binary protocol.
+type thriftWriter struct {
+	b []byte
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.b = append(w.b, typ)
+	w.b = binary.BigEndian.AppendUint16(w.b, uint16(id))
+}
+
+func (w *thriftWriter) stop() { w.b = append(w.b, thriftStop) }
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	w.b = binary.BigEndian.AppendUint32(w.b, uint32(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	w.b = binary.BigEndian.AppendUint64(w.b, uint64(v))
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	w.b = binary.BigEndian.AppendUint32(w.b, uint32(len(v)))
+	w.b = append(w.b, v...)
+}
+
+func (w *thriftWriter) list(id int16, n int) {
+	w.field(thriftList, id)
+	w.b = append(w.b, thriftStruct)
+	w.b = binary.BigEndian.AppendUint32(w.b, uint32(n))
+}
+
+func (w *thriftWriter) strTag(key, v string) {
+	w.str(1, key)
+	w.i32(2, jaegerTagString)
+	w.str(3, v)
+	w.stop()
+}
+
+func (w *thriftWriter) boolTag(key string, v bool) {
+	w.str(1, key)
+	w.i32(2, jaegerTagBool)
+	w.field(thriftBool, 5)
+	if v {
+		w.b = append(w.b, 1)
+	} else {
+		w.b = append(w.b, 0)
+	}
+	w.stop()
+}
+
+func (w *thriftWriter) doubleTag(key string, v float64) {
+	w.str(1, key)
+	w.i32(2, jaegerTagDouble)
+	w.field(thriftDouble, 4)
+	w.b = binary.BigEndian.AppendUint64(w.b, math.Float64bits(v))
+	w.stop()
+}
+
+func TestJaegerThrift(t *testing.T) {
+	var w thriftWriter
+	// Batch.process
+	w.field(thriftStruct, 1)
+	w.str(1, "checkout")
+	w.list(2, 1)
+	w.strTag("deployment.environment", "prod")
+	w.stop()
+	// Batch.spans
+	w.list(2, 2)
+	// server span
+	w.i64(1, 10)
+	w.i64(2, 1)
+	w.i64(3, 100)
+	w.i64(4, 0)
+	w.str(5, "HTTP GET")
+	w.field(thriftI32, 7) // flags, skipped
+	w.b = binary.BigEndian.AppendUint32(w.b, 1)
+	w.i64(8, 1000)
+	w.i64(9, 50)
+	w.list(10, 4)
+	w.strTag("span.kind", "server")
+	w.strTag("http.method", "GET")
+	w.strTag("http.route", "/cart")
+	w.boolTag("error", true)
+	w.list(11, 1)
+	w.i64(1, 1010)
+	w.list(2, 3)
+	w.strTag("event", "error")
+	w.strTag("message", "cart not found")
+	w.strTag("error.kind", "NotFoundError")
+	w.stop()
+	w.stop()
+	// client span, with its parent in a reference
+	w.i64(1, 10)
+	w.i64(2, 1)
+	w.i64(3, 101)
+	w.str(5, "GET")
+	w.list(6, 1)
+	w.i32(1, jaegerRefChildOf)
+	w.i64(2, 10)
+	w.i64(3, 1)
+	w.i64(4, 100)
+	w.stop()
+	w.i64(8, 1010)
+	w.i64(9, 20)
+	w.list(10, 3)
+	w.strTag("span.kind", "client")
+	w.strTag("db.system", "redis")
+	w.doubleTag("cache.ratio", 0.5)
+	w.stop()
+	w.stop()
+
+	code, spans := postTranslated(t, "/api/traces", "application/x-thrift", w.b)
+	assert.Equal(t, http.StatusAccepted, code)
+	require.Len(t, spans, 2)
+
+	server := spans[100]
+	require.NotNil(t, server)
+	assert.Equal(t, uint64(10), server.TraceID)
+	assert.Equal(t, "checkout", server.Service)
+	assert.Equal(t, "opentelemetry.server", server.Name)
+	assert.Equal(t, "GET /cart", server.Resource)
+	assert.Equal(t, "web", server.Type)
+	assert.Equal(t, "prod", server.Meta["env"])
+	assert.Equal(t, int32(1), server.Error)
+	assert.Equal(t, "cart not found", server.Meta["error.msg"])
+	assert.Equal(t, "NotFoundError", server.Meta["error.type"])
+	assert.Equal(t, int64(1000000), server.Start)
+	assert.Equal(t, int64(50000), server.Duration)
+
+	client := spans[101]
+	require.NotNil(t, client)
+	assert.Equal(t, "checkout", client.Service)
+	assert.Equal(t, uint64(100), client.ParentID)
+	assert.Equal(t, "GET", client.Resource)
+	assert.Equal(t, "cache", client.Type)
+	assert.Equal(t, int32(0), client.Error)
+	assert.Equal(t, 0.5, client.Metrics["cache.ratio"])
+	assert.NotContains(t, client.Meta, "_dd.span_links")
+}
+
+func TestJaegerProto(t *testing.T) {
+	keyValue := func(key string, vType uint64, v string) []byte {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, key)
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, vType)
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+		return b
+	}
+	timestamp := func(seconds, nanos uint64) []byte {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, seconds)
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		return protowire.AppendVarint(b, nanos)
+	}
+
+	var process []byte
+	process = protowire.AppendTag(process, 1, protowire.BytesType)
+	process = protowire.AppendString(process, "payments")
+
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 7})
+	span = protowire.AppendTag(span, 2, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0, 0, 0, 0, 0, 0, 0, 8})
+	span = protowire.AppendTag(span, 3, protowire.BytesType)
+	span = protowire.AppendString(span, "charge")
+	span = protowire.AppendTag(span, 6, protowire.BytesType)
+	span = protowire.AppendBytes(span, timestamp(2, 5))
+	span = protowire.AppendTag(span, 7, protowire.BytesType)
+	span = protowire.AppendBytes(span, timestamp(0, 300))
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, keyValue("span.kind", 0, "consumer"))
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, keyValue("otel.status_code", 0, "ERROR"))
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, keyValue("otel.status_description", 0, "declined"))
+
+	var batch []byte
+	batch = protowire.AppendTag(batch, 1, protowire.BytesType)
+	batch = protowire.AppendBytes(batch, span)
+	batch = protowire.AppendTag(batch, 2, protowire.BytesType)
+	batch = protowire.AppendBytes(batch, process)
+
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, batch)
+
+	code, spans := postTranslated(t, "/api/traces", "application/x-protobuf", req)
+	assert.Equal(t, http.StatusAccepted, code)
+	require.Len(t, spans, 1)
+	s := spans[8]
+	require.NotNil(t, s)
+	assert.Equal(t, uint64(7), s.TraceID)
+	assert.Equal(t, "payments", s.Service)
+	assert.Equal(t, "opentelemetry.consumer", s.Name)
+	assert.Equal(t, "charge", s.Resource)
+	assert.Equal(t, int32(1), s.Error)
+	assert.Equal(t, "declined", s.Meta["error.msg"])
+	assert.Equal(t, int64(2000000005), s.Start)
+	assert.Equal(t, int64(300), s.Duration)
+}
+
+func TestJaegerInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		contentType string
+		body        string
+	}{
+		"short":     {"application/x-thrift", "\x0c\x00\x01\x0b\x00\x01\x00\x00\x00\x10abc"},
+		"list-size": {"application/x-thrift", "\x0f\x00\x02\x0c\x7f\xff\xff\xff"},
+		"type":      {"application/x-thrift", "\x01\x00\x01"},
+		"proto":     {"application/x-protobuf", "\x0a\xff"},
+		"media":     {"application/json", "{}"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			code, spans := postTranslated(t, "/api/traces", tt.contentType, []byte(tt.body))
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Empty(t, spans)
+		})
+	}
+}
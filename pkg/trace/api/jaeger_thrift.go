@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Types of the Thrift binary protocol.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// thriftMaxDepth bounds the nesting of skipped values.
+const thriftMaxDepth = 64
+
+var errThriftShort = errors.New("thrift: unexpected end of payload")
+
+// thriftReader reads values encoded with the Thrift binary protocol. It only
+// supports what is needed to decode the jaeger.thrift structures.
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.b) < n {
+		return nil, errThriftShort
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b, nil
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *thriftReader) readBool() (bool, error) {
+	b, err := r.readByte()
+	return b != 0, err
+}
+
+func (r *thriftReader) readI16() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *thriftReader) readDouble() (float64, error) {
+	v, err := r.readI64()
+	return math.Float64frombits(uint64(v)), err
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(n))
+}
+
+func (r *thriftReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+// readListHeader reads the header of a list or set, returning the type and
+// the number of its elements.
+func (r *thriftReader) readListHeader() (byte, int, error) {
+	typ, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := r.readI32()
+	if err != nil {
+		return 0, 0, err
+	}
+	// each element takes at least one byte, which prevents huge allocations
+	// from malformed payloads
+	if n < 0 || int(n) > len(r.b) {
+		return 0, 0, fmt.Errorf("thrift: invalid list size %d", n)
+	}
+	return typ, int(n), nil
+}
+
+// readList calls fn to read each element of a list. Elements of another type
+// than elemType are skipped.
+func (r *thriftReader) readList(elemType byte, fn func() error) error {
+	typ, n, err := r.readListHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if typ != elemType {
+			err = r.skip(typ, 0)
+		} else {
+			err = fn()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStruct calls fn with the type and ID of each field of a struct, up to
+// its end. fn must read the value of the field, or skip it.
+func (r *thriftReader) readStruct(fn func(typ byte, id int16) error) error {
+	for {
+		typ, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if typ == thriftStop {
+			return nil
+		}
+		id, err := r.readI16()
+		if err != nil {
+			return err
+		}
+		if err := fn(typ, id); err != nil {
+			return err
+		}
+	}
+}
+
+// skip skips a value of the given type.
+func (r *thriftReader) skip(typ byte, depth int) error {
+	if depth > thriftMaxDepth {
+		return errors.New("thrift: maximum depth exceeded")
+	}
+	var err error
+	switch typ {
+	case thriftBool, thriftByte:
+		_, err = r.next(1)
+	case thriftI16:
+		_, err = r.next(2)
+	case thriftI32:
+		_, err = r.next(4)
+	case thriftDouble, thriftI64:
+		_, err = r.next(8)
+	case thriftString:
+		_, err = r.readBinary()
+	case thriftStruct:
+		err = r.readStruct(func(typ byte, _ int16) error { return r.skip(typ, depth+1) })
+	case thriftMap:
+		var ktyp, vtyp byte
+		var n int32
+		if ktyp, err = r.readByte(); err != nil {
+			return err
+		}
+		if vtyp, err = r.readByte(); err != nil {
+			return err
+		}
+		if n, err = r.readI32(); err != nil {
+			return err
+		}
+		if n < 0 || int(n) > len(r.b) {
+			return fmt.Errorf("thrift: invalid map size %d", n)
+		}
+		for i := 0; i < int(n) && err == nil; i++ {
+			if err = r.skip(ktyp, depth+1); err == nil {
+				err = r.skip(vtyp, depth+1)
+			}
+		}
+	case thriftSet, thriftList:
+		var etyp byte
+		var n int
+		if etyp, n, err = r.readListHeader(); err != nil {
+			return err
+		}
+		for i := 0; i < n && err == nil; i++ {
+			err = r.skip(etyp, depth+1)
+		}
+	default:
+		err = fmt.Errorf("thrift: unknown type %d", typ)
+	}
+	return err
+}
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+)
+
+// translateFunc decodes a request carrying traces in a third-party format and
+// converts them to OTLP traces.
+type translateFunc func(req *http.Request) (ptrace.Traces, error)
+
+// handleTranslatedTraces returns a handler accepting traces in a third-party
+// format, such as Zipkin or Jaeger. The traces are converted to OTLP by
+// translate and then go through the OTLP conversion to Datadog spans, so that
+// they follow the same conventions and get the same stats and sampling.
+func (r *HTTPReceiver) handleTranslatedTraces(endpointVersion string, translate translateFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ts := r.Stats.GetTagStats(info.Tags{EndpointVersion: endpointVersion})
+		defer req.Body.Close()
+
+		select {
+		case r.recvsem <- struct{}{}:
+		case <-time.After(time.Duration(r.conf.DecoderTimeout) * time.Millisecond):
+			// this payload can not be accepted
+			io.Copy(io.Discard, req.Body) //nolint:errcheck
+			w.WriteHeader(http.StatusTooManyRequests)
+			ts.PayloadRefused.Inc()
+			return
+		}
+		defer func() { <-r.recvsem }()
+
+		start := time.Now()
+		rd := apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+		req.Body = rd
+		traces, err := translate(req)
+		metrics.Histogram("datadog.trace_agent.receiver.serve_traces_ms", float64(time.Since(start))/float64(time.Millisecond),
+			append(ts.AsTags(), fmt.Sprintf("success:%v", err == nil)), 1)
+		if err != nil {
+			httpDecodingError(err, []string{"handler:traces", "v:" + endpointVersion}, w)
+			ts.TracesDropped.DecodingError.Inc()
+			log.Errorf("Cannot decode %s traces payload: %v", endpointVersion, err)
+			return
+		}
+		ts.PayloadAccepted.Inc()
+		ts.TracesBytes.Add(rd.Count)
+
+		rspans := traces.ResourceSpans()
+		for i := 0; i < rspans.Len(); i++ {
+			r.translator.receiveResourceSpans(req.Context(), rspans.At(i), req.Header, endpointVersion)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// requestMediaType returns the media type of the request, defaulting to the
+// given one when the Content-Type header is missing.
+func requestMediaType(req *http.Request, defaultType string) string {
+	if req.Header.Get("Content-Type") == "" {
+		return defaultType
+	}
+	return getMediaType(req)
+}
+
+// scopeSpansCache groups the translated spans by instrumentation scope within
+// a resource.
+type scopeSpansCache struct {
+	rspans ptrace.ResourceSpans
+	scopes map[[2]string]ptrace.SpanSlice
+}
+
+func newScopeSpansCache(rspans ptrace.ResourceSpans) *scopeSpansCache {
+	return &scopeSpansCache{rspans: rspans, scopes: make(map[[2]string]ptrace.SpanSlice)}
+}
+
+// appendSpan adds a span to the scope with the given name and version.
+func (c *scopeSpansCache) appendSpan(name, version string) ptrace.Span {
+	key := [2]string{name, version}
+	spans, ok := c.scopes[key]
+	if !ok {
+		sspans := c.rspans.ScopeSpans().AppendEmpty()
+		sspans.Scope().SetName(name)
+		sspans.Scope().SetVersion(version)
+		spans = sspans.Spans()
+		c.scopes[key] = spans
+	}
+	return spans.AppendEmpty()
+}
+
+// Tags used by the OpenTelemetry exporters to carry the fields of OTLP spans
+// that have no equivalent in other formats.
+const (
+	tagOTelStatusCode        = "otel.status_code"
+	tagOTelStatusDescription = "otel.status_description"
+	tagOTelLibraryName       = "otel.library.name"
+	tagOTelLibraryVersion    = "otel.library.version"
+	tagOTelScopeName         = "otel.scope.name"
+	tagOTelScopeVersion      = "otel.scope.version"
+)
+
+// spanKindFromName returns the OTLP span kind for the given Zipkin kind or
+// OpenTracing span.kind tag value.
+func spanKindFromName(kind string) ptrace.SpanKind {
+	switch kind {
+	case "CLIENT", "client":
+		return ptrace.SpanKindClient
+	case "SERVER", "server":
+		return ptrace.SpanKindServer
+	case "PRODUCER", "producer":
+		return ptrace.SpanKindProducer
+	case "CONSUMER", "consumer":
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindInternal
+	}
+}
+
+// setStatusFromTag sets the status of the span from the otel.status_code tag.
+func setStatusFromTag(span ptrace.Span, code string) {
+	switch code {
+	case "ERROR":
+		span.Status().SetCode(ptrace.StatusCodeError)
+	case "OK":
+		span.Status().SetCode(ptrace.StatusCodeOk)
+	}
+}
+
+// putPeerAttributes sets the attributes describing the remote side of a span.
+func putPeerAttributes(attrs pcommon.Map, service, ip string, port int64) {
+	if service != "" {
+		attrs.PutStr(semconv.AttributePeerService, service)
+	}
+	if ip != "" {
+		attrs.PutStr(semconv.AttributeNetPeerIP, ip)
+	}
+	if port != 0 {
+		attrs.PutInt(semconv.AttributeNetPeerPort, port)
+	}
+}
+
+// setExceptionEvent converts an OpenTracing error log to an OTLP exception
+// event, from which the error details of the Datadog span are extracted.
+func setExceptionEvent(event ptrace.SpanEvent, fields map[string]string) {
+	event.SetName("exception")
+	attrs := event.Attributes()
+	if v := fields["message"]; v != "" {
+		attrs.PutStr(semconv.AttributeExceptionMessage, v)
+	}
+	if v := fields["error.kind"]; v != "" {
+		attrs.PutStr(semconv.AttributeExceptionType, v)
+	} else if v := fields["error.object"]; v != "" {
+		attrs.PutStr(semconv.AttributeExceptionType, v)
+	}
+	if v := fields["stack"]; v != "" {
+		attrs.PutStr(semconv.AttributeExceptionStacktrace, v)
+	}
+}
+
+// protoField is a field of a protobuf message, as read by rangeProtoFields.
+type protoField struct {
+	num protowire.Number
+	typ protowire.Type
+	// value holds the value of varint and fixed size fields.
+	value uint64
+	// bytes holds the value of length-delimited fields.
+	bytes []byte
+}
+
+// rangeProtoFields calls fn with each field of the protobuf message encoded in
+// b. It is used to decode the messages of formats whose generated code is not
+// a dependency of the agent.
+func rangeProtoFields(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		f := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.value = uint64(v)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// zipkinV2 is the endpoint version reported for the traces received on the
+// Zipkin v2 endpoint.
+const zipkinV2 = "zipkin_v2"
+
+// zipkinSpan is a span of the Zipkin v2 API. The protobuf encoding is decoded
+// into the same structure, with the IDs converted to hex strings.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ParentID       string             `json:"parentId"`
+	ID             string             `json:"id"`
+	Kind           string             `json:"kind"`
+	Name           string             `json:"name"`
+	Timestamp      uint64             `json:"timestamp"` // µs since epoch
+	Duration       uint64             `json:"duration"`  // µs
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int64  `json:"port"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // µs since epoch
+	Value     string `json:"value"`
+}
+
+// decodeZipkin decodes a list of Zipkin v2 spans, encoded as JSON or protobuf
+// depending on the Content-Type of the request, and converts them to OTLP.
+func (r *HTTPReceiver) decodeZipkin(req *http.Request) (ptrace.Traces, error) {
+	var spans []*zipkinSpan
+	switch mt := requestMediaType(req, "application/json"); mt {
+	case "application/json":
+		if err := json.NewDecoder(req.Body).Decode(&spans); err != nil {
+			return ptrace.Traces{}, err
+		}
+	case "application/x-protobuf":
+		buf, err := io.ReadAll(req.Body)
+		if err != nil {
+			return ptrace.Traces{}, err
+		}
+		if spans, err = decodeZipkinProto(buf); err != nil {
+			return ptrace.Traces{}, err
+		}
+	default:
+		return ptrace.Traces{}, fmt.Errorf("unsupported media type %q", mt)
+	}
+	return zipkinToTraces(spans)
+}
+
+// zipkinToTraces converts the Zipkin spans to OTLP, with one resource per
+// local service.
+func zipkinToTraces(spans []*zipkinSpan) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	resources := make(map[string]*scopeSpansCache)
+	for _, zs := range spans {
+		if zs == nil {
+			return ptrace.Traces{}, errors.New("invalid null span")
+		}
+		var service string
+		if zs.LocalEndpoint != nil {
+			service = zs.LocalEndpoint.ServiceName
+		}
+		cache, ok := resources[service]
+		if !ok {
+			rspans := traces.ResourceSpans().AppendEmpty()
+			if service != "" {
+				rspans.Resource().Attributes().PutStr(semconv.AttributeServiceName, service)
+			}
+			cache = newScopeSpansCache(rspans)
+			resources[service] = cache
+		}
+		scopeName, scopeVersion := zs.Tags[tagOTelScopeName], zs.Tags[tagOTelScopeVersion]
+		if scopeName == "" {
+			scopeName, scopeVersion = zs.Tags[tagOTelLibraryName], zs.Tags[tagOTelLibraryVersion]
+		}
+		if err := convertZipkinSpan(zs, cache.appendSpan(scopeName, scopeVersion)); err != nil {
+			return ptrace.Traces{}, err
+		}
+	}
+	return traces, nil
+}
+
+// convertZipkinSpan fills span with the fields of zs.
+func convertZipkinSpan(zs *zipkinSpan, span ptrace.Span) error {
+	traceID, err := parseZipkinTraceID(zs.TraceID)
+	if err != nil {
+		return fmt.Errorf("invalid trace ID %q: %v", zs.TraceID, err)
+	}
+	spanID, err := parseZipkinSpanID(zs.ID)
+	if err != nil {
+		return fmt.Errorf("invalid span ID %q: %v", zs.ID, err)
+	}
+	span.SetTraceID(traceID)
+	span.SetSpanID(spanID)
+	if zs.ParentID != "" {
+		parentID, err := parseZipkinSpanID(zs.ParentID)
+		if err != nil {
+			return fmt.Errorf("invalid parent ID %q: %v", zs.ParentID, err)
+		}
+		span.SetParentSpanID(parentID)
+	}
+	span.SetName(zs.Name)
+	span.SetKind(spanKindFromName(zs.Kind))
+	span.SetStartTimestamp(pcommon.Timestamp(zs.Timestamp * 1000))
+	span.SetEndTimestamp(pcommon.Timestamp((zs.Timestamp + zs.Duration) * 1000))
+
+	attrs := span.Attributes()
+	for k, v := range zs.Tags {
+		switch k {
+		case "error":
+			// Zipkin instrumentations set the error message as the value of
+			// the error tag
+			if v == "false" {
+				continue
+			}
+			span.Status().SetCode(ptrace.StatusCodeError)
+			if v != "" && v != "true" {
+				span.Status().SetMessage(v)
+			}
+		case tagOTelStatusCode:
+			setStatusFromTag(span, v)
+		case tagOTelStatusDescription:
+			span.Status().SetMessage(v)
+		case tagOTelScopeName, tagOTelScopeVersion, tagOTelLibraryName, tagOTelLibraryVersion:
+			// already set on the scope
+		default:
+			attrs.PutStr(k, v)
+		}
+	}
+	if re := zs.RemoteEndpoint; re != nil {
+		ip := re.IPv4
+		if ip == "" {
+			ip = re.IPv6
+		}
+		putPeerAttributes(attrs, re.ServiceName, ip, re.Port)
+	}
+	for _, a := range zs.Annotations {
+		event := span.Events().AppendEmpty()
+		event.SetName(a.Value)
+		event.SetTimestamp(pcommon.Timestamp(a.Timestamp * 1000))
+	}
+	return nil
+}
+
+// parseZipkinTraceID parses a 64 or 128-bit hex trace ID.
+func parseZipkinTraceID(s string) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	if len(s) == 0 || len(s) > 2*len(id) {
+		return id, errors.New("bad length")
+	}
+	b, err := hex.DecodeString(padHex(s, 2*len(id)))
+	if err != nil {
+		return id, err
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// parseZipkinSpanID parses a 64-bit hex span ID.
+func parseZipkinSpanID(s string) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	if len(s) == 0 || len(s) > 2*len(id) {
+		return id, errors.New("bad length")
+	}
+	b, err := hex.DecodeString(padHex(s, 2*len(id)))
+	if err != nil {
+		return id, err
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// padHex left-pads the hex string s with zeroes up to n characters.
+func padHex(s string, n int) string {
+	for len(s) < n {
+		s = "0" + s
+	}
+	return s
+}
+
+// zipkinProtoKinds maps the values of the Span.Kind enum of zipkin.proto3 to
+// the kinds of the JSON encoding.
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+// decodeZipkinProto decodes a zipkin.proto3 ListOfSpans message.
+func decodeZipkinProto(b []byte) ([]*zipkinSpan, error) {
+	var spans []*zipkinSpan
+	err := rangeProtoFields(b, func(f protoField) error {
+		if f.num != 1 || f.typ != protowire.BytesType {
+			return nil
+		}
+		span, err := decodeZipkinProtoSpan(f.bytes)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, span)
+		return nil
+	})
+	return spans, err
+}
+
+func decodeZipkinProtoSpan(b []byte) (*zipkinSpan, error) {
+	span := &zipkinSpan{}
+	err := rangeProtoFields(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			span.TraceID = hex.EncodeToString(f.bytes)
+		case 2:
+			span.ParentID = hex.EncodeToString(f.bytes)
+		case 3:
+			span.ID = hex.EncodeToString(f.bytes)
+		case 4:
+			span.Kind = zipkinProtoKinds[f.value]
+		case 5:
+			span.Name = string(f.bytes)
+		case 6:
+			span.Timestamp = f.value
+		case 7:
+			span.Duration = f.value
+		case 8:
+			span.LocalEndpoint, err = decodeZipkinProtoEndpoint(f.bytes)
+		case 9:
+			span.RemoteEndpoint, err = decodeZipkinProtoEndpoint(f.bytes)
+		case 10:
+			var a zipkinAnnotation
+			err = rangeProtoFields(f.bytes, func(f protoField) error {
+				switch f.num {
+				case 1:
+					a.Timestamp = f.value
+				case 2:
+					a.Value = string(f.bytes)
+				}
+				return nil
+			})
+			span.Annotations = append(span.Annotations, a)
+		case 11:
+			var k, v string
+			err = rangeProtoFields(f.bytes, func(f protoField) error {
+				switch f.num {
+				case 1:
+					k = string(f.bytes)
+				case 2:
+					v = string(f.bytes)
+				}
+				return nil
+			})
+			if span.Tags == nil {
+				span.Tags = make(map[string]string)
+			}
+			span.Tags[k] = v
+		}
+		return err
+	})
+	return span, err
+}
+
+func decodeZipkinProtoEndpoint(b []byte) (*zipkinEndpoint, error) {
+	e := &zipkinEndpoint{}
+	err := rangeProtoFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			e.ServiceName = string(f.bytes)
+		case 2:
+			if len(f.bytes) > 0 {
+				e.IPv4 = net.IP(f.bytes).String()
+			}
+		case 3:
+			if len(f.bytes) > 0 {
+				e.IPv6 = net.IP(f.bytes).String()
+			}
+		case 4:
+			e.Port = int64(f.value)
+		}
+		return nil
+	})
+	return e, err
+}
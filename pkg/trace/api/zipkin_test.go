@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+)
+
+// postTranslated posts the body to the given endpoint of a receiver with the
+// Zipkin and Jaeger endpoints enabled, and returns the response code and the
+// spans received, by span ID.
+func postTranslated(t *testing.T, path, contentType string, body []byte) (int, map[uint64]*pb.Span) {
+	conf := newTestReceiverConfig()
+	conf.ZipkinReceiverEnabled = true
+	conf.JaegerReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+
+	req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	rcv.buildMux().ServeHTTP(rec, req)
+
+	spans := make(map[uint64]*pb.Span)
+	for {
+		select {
+		case p := <-rcv.out:
+			for _, chunk := range p.TracerPayload.Chunks {
+				for _, span := range chunk.Spans {
+					spans[span.SpanID] = span
+				}
+			}
+		default:
+			return rec.Code, spans
+		}
+	}
+}
+
+func TestZipkinJSON(t *testing.T) {
+	body := []byte(`[
+	{
+		"traceId": "5af7183fb1d4cf5f",
+		"id": "352bff9a74ca9ad2",
+		"kind": "SERVER",
+		"name": "get /users",
+		"timestamp": 1556604172355737,
+		"duration": 1431,
+		"localEndpoint": {"serviceName": "frontend", "ipv4": "192.168.99.1"},
+		"tags": {"http.method": "GET", "http.route": "/users", "error": "connection reset", "otel.library.name": "zipkin.http"}
+	},
+	{
+		"traceId": "5af7183fb1d4cf5f",
+		"parentId": "352bff9a74ca9ad2",
+		"id": "6b221d5bc9e6496c",
+		"kind": "CLIENT",
+		"name": "select",
+		"timestamp": 1556604172355900,
+		"duration": 800,
+		"localEndpoint": {"serviceName": "backend"},
+		"remoteEndpoint": {"serviceName": "users-db", "ipv4": "10.0.0.2", "port": 5432},
+		"annotations": [{"timestamp": 1556604172356000, "value": "ws"}],
+		"tags": {"db.system": "postgresql"}
+	}
+]`)
+	code, spans := postTranslated(t, "/api/v2/spans", "application/json", body)
+	assert.Equal(t, http.StatusAccepted, code)
+	require.Len(t, spans, 2)
+
+	server := spans[0x352bff9a74ca9ad2]
+	require.NotNil(t, server)
+	assert.Equal(t, uint64(0x5af7183fb1d4cf5f), server.TraceID)
+	assert.Equal(t, "frontend", server.Service)
+	assert.Equal(t, "zipkin.http.server", server.Name)
+	assert.Equal(t, "GET /users", server.Resource)
+	assert.Equal(t, "web", server.Type)
+	assert.Equal(t, int32(1), server.Error)
+	assert.Equal(t, "connection reset", server.Meta["error.msg"])
+	assert.Equal(t, int64(1556604172355737000), server.Start)
+	assert.Equal(t, int64(1431000), server.Duration)
+
+	client := spans[0x6b221d5bc9e6496c]
+	require.NotNil(t, client)
+	assert.Equal(t, "backend", client.Service)
+	assert.Equal(t, server.SpanID, client.ParentID)
+	assert.Equal(t, "opentelemetry.client", client.Name)
+	assert.Equal(t, "select", client.Resource)
+	assert.Equal(t, "db", client.Type)
+	assert.Equal(t, int32(0), client.Error)
+	assert.Equal(t, "users-db", client.Meta["peer.service"])
+	assert.Equal(t, "10.0.0.2", client.Meta["net.peer.ip"])
+	assert.Equal(t, float64(5432), client.Metrics["net.peer.port"])
+	assert.Contains(t, client.Meta["events"], `"name":"ws"`)
+}
+
+func TestZipkinProto(t *testing.T) {
+	var endpoint []byte
+	endpoint = protowire.AppendTag(endpoint, 1, protowire.BytesType)
+	endpoint = protowire.AppendString(endpoint, "frontend")
+
+	var tag []byte
+	tag = protowire.AppendTag(tag, 1, protowire.BytesType)
+	tag = protowire.AppendString(tag, "rpc.method")
+	tag = protowire.AppendTag(tag, 2, protowire.BytesType)
+	tag = protowire.AppendString(tag, "GetUser")
+
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2})
+	span = protowire.AppendTag(span, 3, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0, 0, 0, 0, 0, 0, 0, 3})
+	span = protowire.AppendTag(span, 4, protowire.VarintType)
+	span = protowire.AppendVarint(span, 3) // PRODUCER
+	span = protowire.AppendTag(span, 5, protowire.BytesType)
+	span = protowire.AppendString(span, "send")
+	span = protowire.AppendTag(span, 6, protowire.Fixed64Type)
+	span = protowire.AppendFixed64(span, 1000)
+	span = protowire.AppendTag(span, 7, protowire.VarintType)
+	span = protowire.AppendVarint(span, 20)
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, endpoint)
+	span = protowire.AppendTag(span, 11, protowire.BytesType)
+	span = protowire.AppendBytes(span, tag)
+
+	var list []byte
+	list = protowire.AppendTag(list, 1, protowire.BytesType)
+	list = protowire.AppendBytes(list, span)
+
+	code, spans := postTranslated(t, "/api/v2/spans", "application/x-protobuf", list)
+	assert.Equal(t, http.StatusAccepted, code)
+	require.Len(t, spans, 1)
+	s := spans[3]
+	require.NotNil(t, s)
+	assert.Equal(t, uint64(2), s.TraceID)
+	assert.Equal(t, "frontend", s.Service)
+	assert.Equal(t, "opentelemetry.producer", s.Name)
+	assert.Equal(t, "GetUser", s.Resource)
+	assert.Equal(t, "custom", s.Type)
+	assert.Equal(t, "producer", s.Meta["span.kind"])
+	assert.Equal(t, int64(1000000), s.Start)
+	assert.Equal(t, int64(20000), s.Duration)
+}
+
+func TestZipkinInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		contentType string
+		body        string
+	}{
+		"json":     {"application/json", `{"traceId": 1}`},
+		"trace-id": {"application/json", `[{"traceId": "xyz", "id": "1"}]`},
+		"span-id":  {"application/json", `[{"traceId": "1", "id": "00000000000000001"}]`},
+		"null":     {"application/json", `[null]`},
+		"proto":    {"application/x-protobuf", "\x0a\xff"},
+		"type":     {"text/plain", `[]`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			code, spans := postTranslated(t, "/api/v2/spans", tt.contentType, []byte(tt.body))
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Empty(t, spans)
+		})
+	}
+}
+
+func TestTranslatedEndpointsDisabled(t *testing.T) {
+	rcv := newTestReceiverFromConfig(newTestReceiverConfig())
+	for _, path := range []string{"/api/v2/spans", "/api/traces"} {
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader([]byte("[]")))
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		rcv.buildMux().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+}
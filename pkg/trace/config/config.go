@@ -108,6 +108,14 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached obfuscate.MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the resource and the "graphql.query" tag
+	// of spans of type "graphql", and their "graphql.variables.*" tags.
+	GraphQL obfuscate.GraphQLConfig `mapstructure:"graphql"`
+
+	// CQL holds the configuration for obfuscating the resource of spans of type "cassandra".
+	// When disabled, their resource is obfuscated as an SQL query.
+	CQL obfuscate.CQLConfig `mapstructure:"cql"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards CreditCardsConfig `mapstructure:"credit_cards"`
 }
@@ -129,6 +137,8 @@ func (o *ObfuscationConfig) Export(conf *AgentConfig) obfuscate.Config {
 		HTTP:                 o.HTTP,
 		Redis:                o.Redis,
 		Memcached:            o.Memcached,
+		GraphQL:              o.GraphQL,
+		CQL:                  o.CQL,
 		Logger:               new(debugLogger),
 	}
 }
@@ -195,6 +205,130 @@ type WriterConfig struct {
 	FlushPeriodSeconds float64 `mapstructure:"flush_period_seconds"`
 }
 
+// OTLPExportConfig holds the configuration of the optional writer mirroring the
+// sampled traces to an OTLP endpoint, in addition to the Datadog intake.
+type OTLPExportConfig struct {
+	// Enabled enables the export of sampled traces to Endpoint.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Endpoint is the host:port of the OTLP receiver when Protocol is "grpc",
+	// and its URL when Protocol is "http". The "/v1/traces" path is appended
+	// to URLs without a path.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Protocol is the OTLP transport, "grpc" or "http".
+	Protocol string `mapstructure:"protocol"`
+
+	// Headers are added to the export requests, e.g. for authentication.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Insecure disables TLS for the gRPC protocol.
+	Insecure bool `mapstructure:"insecure"`
+
+	// Timeout is the maximum duration of an export request.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// QueueSize is the maximum number of payloads waiting to be exported.
+	// Payloads are dropped when it is reached, so that a slow endpoint never
+	// slows down the agent.
+	QueueSize int `mapstructure:"queue_size"`
+
+	// MaxRetries is the maximum number of times a failed export is retried
+	// before the traces are dropped.
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// TailSamplingConfig holds the configuration of the tail-based sampling stage,
+// which buffers the chunks of a trace for a decision window before evaluating
+// sampling policies on the whole trace.
+type TailSamplingConfig struct {
+	// Enabled enables the tail-based sampling stage.
+	Enabled bool `mapstructure:"enabled"`
+
+	// DecisionWait is the time spent buffering the chunks of a trace, starting
+	// from the first chunk received, before the policies are evaluated.
+	DecisionWait time.Duration `mapstructure:"decision_wait"`
+
+	// MaxTraces is the maximum number of traces buffered at once. When it is
+	// reached, the oldest trace is decided before its decision window ends.
+	MaxTraces int `mapstructure:"max_traces"`
+
+	// MaxSpans is the maximum number of spans buffered at once, across all
+	// traces. When it is reached, the oldest traces are decided early.
+	MaxSpans int `mapstructure:"max_spans"`
+
+	// Policies are evaluated in order on each trace, the first matching policy
+	// keeping it. Traces matching no policy go through the regular samplers.
+	Policies []TailSamplingPolicy `mapstructure:"policies"`
+}
+
+// TailSamplingPolicy is a rule keeping the traces it matches.
+type TailSamplingPolicy struct {
+	// Name identifies the policy in the telemetry and on the kept traces.
+	Name string `mapstructure:"name"`
+
+	// Type is one of "error", "latency", "tag" or "probabilistic".
+	Type string `mapstructure:"type"`
+
+	// Service and Resource, when set, restrict the spans evaluated by the
+	// "error", "latency" and "tag" policies.
+	Service  string `mapstructure:"service"`
+	Resource string `mapstructure:"resource"`
+
+	// Threshold is the minimum span duration matched by "latency" policies.
+	Threshold time.Duration `mapstructure:"threshold"`
+
+	// Key and Value are the tag matched by "tag" policies. An empty Value
+	// matches any span having the Key tag.
+	Key   string `mapstructure:"key"`
+	Value string `mapstructure:"value"`
+
+	// Rate is the fraction of traces kept by "probabilistic" policies.
+	Rate float64 `mapstructure:"rate"`
+}
+
+// SpanFilterRule describes the spans dropped by a span filter. A span is
+// dropped when it matches all the conditions set in the rule.
+type SpanFilterRule struct {
+	// Name identifies the rule in the logs.
+	Name string `mapstructure:"name"`
+
+	// Service and Operation match the service and operation name of the span.
+	Service   string `mapstructure:"service"`
+	Operation string `mapstructure:"operation"`
+
+	// Resource is a regular expression matched against the resource of the
+	// span.
+	Resource string `mapstructure:"resource"`
+
+	// Tags are matched against the tags of the span, as "key:value". A tag
+	// without value matches any span having the key.
+	Tags []string `mapstructure:"tags"`
+
+	// TagsRegex are matched against the tags of the span, as "key:regex".
+	TagsRegex []string `mapstructure:"tags_regex"`
+
+	// MaxDuration matches spans lasting less than the given duration.
+	MaxDuration time.Duration `mapstructure:"max_duration"`
+}
+
+// SpanMetric describes a metric computed from the spans received by the agent,
+// before they are sampled. The conditions of the embedded rule select the
+// measured spans, and its Name is the name of the metric.
+type SpanMetric struct {
+	SpanFilterRule `mapstructure:",squash"`
+
+	// Type is the type of the metric, "count" or "distribution".
+	Type string `mapstructure:"type"`
+
+	// Value is the numeric tag measured by a distribution. The duration of
+	// the span, in seconds, is measured when it is empty.
+	Value string `mapstructure:"value"`
+
+	// GroupBy are the keys of the span tags reported as tags of the metric.
+	GroupBy []string `mapstructure:"group_by"`
+}
+
 // FargateOrchestratorName is a Fargate orchestrator name.
 type FargateOrchestratorName string
 
@@ -295,6 +429,13 @@ type AgentConfig struct {
 	ComputeStatsBySpanKind bool          // enables/disables the computing of stats based on a span's `span.kind` field
 	PeerTags               []string      // additional tags to use for peer entity stats aggregation
 
+	// StatsCustomTags are the keys of the span tags used as additional stats aggregation dimensions,
+	// used by Concentrator and ClientStatsAggregator.
+	StatsCustomTags []string
+	// StatsCustomTagsMaxValues is the maximum number of distinct values of each custom tag in a stats
+	// bucket. Values above the limit are aggregated together.
+	StatsCustomTagsMaxValues int
+
 	// Sampler configuration
 	ExtraSampleRate float64
 	TargetTPS       float64
@@ -308,6 +449,12 @@ type AgentConfig struct {
 	RareSamplerCooldownPeriod time.Duration
 	RareSamplerCardinality    int
 
+	// TailSampling configures the optional tail-based sampling stage.
+	TailSampling TailSamplingConfig
+
+	// OTLPExport configures the optional export of sampled traces to an OTLP endpoint.
+	OTLPExport OTLPExportConfig
+
 	// Receiver
 	ReceiverHost    string
 	ReceiverPort    int
@@ -320,6 +467,9 @@ type AgentConfig struct {
 	MaxConnections  int   // specifies the maximum number of concurrent incoming connections allowed.
 	DecoderTimeout  int   // specifies the maximum time in milliseconds that the decoders will wait for a turn to accept a payload before returning 429
 
+	ZipkinReceiverEnabled bool // enables the Zipkin v2 endpoint (/api/v2/spans) on the receiver
+	JaegerReceiverEnabled bool // enables the Jaeger collector endpoint (/api/traces) on the receiver
+
 	WindowsPipeName        string
 	PipeBufferSize         int
 	PipeSecurityDescriptor string
@@ -393,6 +543,14 @@ type AgentConfig struct {
 	// RejectTagsRegex specifies a list of regexp for tags which must be absent on the root span in order for a trace to be accepted.
 	RejectTagsRegex []*TagRegex
 
+	// SpanFilters specifies rules dropping individual spans, anywhere in a chunk. The root span of a chunk is
+	// never dropped, and the children of dropped spans are attached to their closest kept ancestor.
+	SpanFilters []SpanFilterRule
+
+	// SpanMetrics specifies metrics computed from the spans, before sampling and span filters, and
+	// reported through DogStatsD.
+	SpanMetrics []SpanMetric
+
 	// OTLPReceiver holds the configuration for OpenTelemetry receiver.
 	OTLPReceiver *OTLP
 
@@ -437,6 +595,11 @@ type AgentConfig struct {
 	// DebugServerPort defines the port used by the debug server
 	DebugServerPort int
 
+	// CapturePath is the directory in which the payloads received by the
+	// agent are written when a capture is started. The system's temporary
+	// directory is used when empty.
+	CapturePath string
+
 	// Install Signature
 	InstallSignature InstallSignatureConfig
 }
@@ -471,7 +634,8 @@ func New() *AgentConfig {
 		Site:                "datadoghq.com",
 		MaxCatalogEntries:   5000,
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:           time.Duration(10) * time.Second,
+		StatsCustomTagsMaxValues: 100,
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
@@ -484,6 +648,18 @@ func New() *AgentConfig {
 		RareSamplerCooldownPeriod: 5 * time.Minute,
 		RareSamplerCardinality:    200,
 
+		TailSampling: TailSamplingConfig{
+			DecisionWait: 10 * time.Second,
+			MaxTraces:    50000,
+			MaxSpans:     1000000,
+		},
+		OTLPExport: OTLPExportConfig{
+			Protocol:   "grpc",
+			Timeout:    10 * time.Second,
+			QueueSize:  100,
+			MaxRetries: 3,
+		},
+
 		ReceiverHost:           "localhost",
 		ReceiverPort:           8126,
 		MaxRequestBytes:        25 * 1024 * 1024, // 25MB
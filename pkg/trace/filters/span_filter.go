@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+// SpanFilter drops the spans matching any of its rules from the chunks,
+// keeping the rest of the trace. The root span of a chunk is never dropped.
+type SpanFilter struct {
+	rules []*spanRule
+}
+
+type spanRule struct {
+	service     string
+	operation   string
+	resource    *regexp.Regexp
+	tags        []*config.Tag
+	tagsRegex   []*config.TagRegex
+	maxDuration int64
+}
+
+// NewSpanFilter returns a SpanFilter compiled from the given rules. Invalid
+// rules are logged and ignored.
+func NewSpanFilter(rules []config.SpanFilterRule) *SpanFilter {
+	f := &SpanFilter{}
+	for _, r := range rules {
+		rule, err := compileSpanRule(r)
+		if err == nil && rule.matchesAll() {
+			err = errors.New("the rule has no condition and would drop all spans")
+		}
+		if err != nil {
+			log.Errorf("Invalid span filter %q: %v", r.Name, err)
+			continue
+		}
+		f.rules = append(f.rules, rule)
+	}
+	return f
+}
+
+func compileSpanRule(r config.SpanFilterRule) (*spanRule, error) {
+	rule := &spanRule{
+		service:     r.Service,
+		operation:   r.Operation,
+		maxDuration: r.MaxDuration.Nanoseconds(),
+	}
+	if r.Resource != "" {
+		re, err := regexp.Compile(r.Resource)
+		if err != nil {
+			return nil, err
+		}
+		rule.resource = re
+	}
+	for _, tag := range r.Tags {
+		k, v, _ := strings.Cut(tag, ":")
+		rule.tags = append(rule.tags, &config.Tag{K: strings.TrimSpace(k), V: strings.TrimSpace(v)})
+	}
+	for _, tag := range r.TagsRegex {
+		k, v, found := strings.Cut(tag, ":")
+		if !found {
+			return nil, errors.New("tags_regex entries must be formatted as key:regex, got " + tag)
+		}
+		re, err := regexp.Compile(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		rule.tagsRegex = append(rule.tagsRegex, &config.TagRegex{K: strings.TrimSpace(k), V: re})
+	}
+	return rule, nil
+}
+
+// matchesAll returns true if the rule has no condition.
+func (r *spanRule) matchesAll() bool {
+	return r.service == "" && r.operation == "" && r.resource == nil && len(r.tags) == 0 &&
+		len(r.tagsRegex) == 0 && r.maxDuration <= 0
+}
+
+// matches returns true if the span matches all the conditions of the rule.
+func (r *spanRule) matches(span *pb.Span) bool {
+	if r.service != "" && span.Service != r.service {
+		return false
+	}
+	if r.operation != "" && span.Name != r.operation {
+		return false
+	}
+	if r.resource != nil && !r.resource.MatchString(span.Resource) {
+		return false
+	}
+	if r.maxDuration > 0 && span.Duration >= r.maxDuration {
+		return false
+	}
+	for _, tag := range r.tags {
+		v, ok := span.Meta[tag.K]
+		if !ok || (tag.V != "" && v != tag.V) {
+			return false
+		}
+	}
+	for _, tag := range r.tagsRegex {
+		v, ok := span.Meta[tag.K]
+		if !ok || !tag.V.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter removes from the chunk the spans matching any rule, except root, and
+// attaches the children of the removed spans to their closest kept ancestor.
+// It returns the number of spans removed. It is safe to call on a nil
+// SpanFilter.
+func (f *SpanFilter) Filter(chunk *pb.TraceChunk, root *pb.Span) int {
+	if f == nil || len(f.rules) == 0 {
+		return 0
+	}
+
+	// parents maps the ID of each removed span to the ID of its parent.
+	var parents map[uint64]uint64
+	for _, span := range chunk.Spans {
+		if span == root || !f.matches(span) {
+			continue
+		}
+		if parents == nil {
+			parents = make(map[uint64]uint64)
+		}
+		parents[span.SpanID] = span.ParentID
+	}
+	if len(parents) == 0 {
+		return 0
+	}
+
+	n := 0
+	for _, span := range chunk.Spans {
+		if _, removed := parents[span.SpanID]; removed && span != root {
+			continue
+		}
+		// the number of hops is bounded in case of a cycle in malformed traces
+		for i := 0; i < len(parents); i++ {
+			parentID, removed := parents[span.ParentID]
+			if !removed {
+				break
+			}
+			span.ParentID = parentID
+		}
+		chunk.Spans[n] = span
+		n++
+	}
+	removed := len(chunk.Spans) - n
+	// set everything at the back of the array to nil to avoid memory leaking
+	for i := n; i < len(chunk.Spans); i++ {
+		chunk.Spans[i] = nil
+	}
+	chunk.Spans = chunk.Spans[:n]
+	return removed
+}
+
+func (f *SpanFilter) matches(span *pb.Span) bool {
+	for _, rule := range f.rules {
+		if rule.matches(span) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+func TestSpanFilterRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        config.SpanFilterRule
+		span        *pb.Span
+		expectation bool
+	}{
+		{"service", config.SpanFilterRule{Service: "redis"}, &pb.Span{Service: "redis"}, true},
+		{"service-mismatch", config.SpanFilterRule{Service: "redis"}, &pb.Span{Service: "web"}, false},
+		{"operation", config.SpanFilterRule{Operation: "redis.command"}, &pb.Span{Name: "redis.command"}, true},
+		{"resource", config.SpanFilterRule{Resource: "^GET /health"}, &pb.Span{Resource: "GET /healthz"}, true},
+		{"resource-mismatch", config.SpanFilterRule{Resource: "^GET /health"}, &pb.Span{Resource: "GET /users"}, false},
+		{"tag", config.SpanFilterRule{Tags: []string{"cache.hit:true"}}, &pb.Span{Meta: map[string]string{"cache.hit": "true"}}, true},
+		{"tag-value-mismatch", config.SpanFilterRule{Tags: []string{"cache.hit:true"}}, &pb.Span{Meta: map[string]string{"cache.hit": "false"}}, false},
+		{"tag-key-only", config.SpanFilterRule{Tags: []string{"synthetics"}}, &pb.Span{Meta: map[string]string{"synthetics": "1"}}, true},
+		{"tag-regex", config.SpanFilterRule{TagsRegex: []string{"http.url:/health$"}}, &pb.Span{Meta: map[string]string{"http.url": "http://a/health"}}, true},
+		{"tag-regex-missing", config.SpanFilterRule{TagsRegex: []string{"http.url:/health$"}}, &pb.Span{}, false},
+		{"duration", config.SpanFilterRule{MaxDuration: time.Millisecond}, &pb.Span{Duration: 1000}, true},
+		{"duration-above", config.SpanFilterRule{MaxDuration: time.Millisecond}, &pb.Span{Duration: int64(time.Millisecond)}, false},
+		{"all-conditions", config.SpanFilterRule{Service: "redis", MaxDuration: time.Millisecond}, &pb.Span{Service: "redis", Duration: int64(time.Second)}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewSpanFilter([]config.SpanFilterRule{test.rule})
+			assert.Len(t, f.rules, 1)
+			assert.Equal(t, test.expectation, f.matches(test.span))
+		})
+	}
+}
+
+func TestSpanFilterInvalidRules(t *testing.T) {
+	f := NewSpanFilter([]config.SpanFilterRule{
+		{Name: "empty"},
+		{Name: "bad-resource", Resource: "[123"},
+		{Name: "bad-tag-regex", TagsRegex: []string{"http.url"}},
+		{Name: "valid", Service: "redis"},
+	})
+	assert.Len(t, f.rules, 1)
+}
+
+func TestSpanFilter(t *testing.T) {
+	f := NewSpanFilter([]config.SpanFilterRule{
+		{Name: "cache-hits", Service: "redis", Tags: []string{"cache.hit:true"}},
+		{Name: "fast", MaxDuration: time.Millisecond},
+	})
+
+	root := &pb.Span{SpanID: 1, Service: "web", Duration: 10}
+	chunk := &pb.TraceChunk{Spans: []*pb.Span{
+		root,
+		{SpanID: 2, ParentID: 1, Service: "redis", Duration: int64(time.Second), Meta: map[string]string{"cache.hit": "true"}},
+		{SpanID: 3, ParentID: 2, Service: "web", Duration: 100},
+		{SpanID: 4, ParentID: 3, Service: "web", Duration: int64(time.Second)},
+		{SpanID: 5, ParentID: 1, Service: "redis", Duration: int64(time.Second)},
+		{SpanID: 6, ParentID: 5, Service: "db", Duration: int64(time.Second)},
+	}}
+
+	assert.Equal(t, 2, f.Filter(chunk, root))
+
+	parents := make(map[uint64]uint64)
+	for _, span := range chunk.Spans {
+		parents[span.SpanID] = span.ParentID
+	}
+	assert.Equal(t, map[uint64]uint64{1: 0, 4: 1, 5: 1, 6: 5}, parents)
+
+	var nilFilter *SpanFilter
+	assert.Equal(t, 0, nilFilter.Filter(chunk, root))
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// Types of span metrics.
+const (
+	spanMetricCount        = "count"
+	spanMetricDistribution = "distribution"
+)
+
+// SpanMetrics reports metrics computed from the spans matching its rules
+// through DogStatsD.
+type SpanMetrics struct {
+	metrics []*spanMetric
+}
+
+type spanMetric struct {
+	*spanRule
+	name    string
+	typ     string
+	value   string
+	groupBy []string
+}
+
+// NewSpanMetrics returns SpanMetrics compiled from the given definitions.
+// Invalid definitions are logged and ignored.
+func NewSpanMetrics(defs []config.SpanMetric) *SpanMetrics {
+	m := &SpanMetrics{}
+	for _, def := range defs {
+		metric, err := compileSpanMetric(def)
+		if err != nil {
+			log.Errorf("Invalid span metric %q: %v", def.Name, err)
+			continue
+		}
+		m.metrics = append(m.metrics, metric)
+	}
+	return m
+}
+
+func compileSpanMetric(def config.SpanMetric) (*spanMetric, error) {
+	if def.Name == "" {
+		return nil, errors.New("the metric has no name")
+	}
+	switch def.Type {
+	case spanMetricCount, spanMetricDistribution:
+	default:
+		return nil, fmt.Errorf("unknown metric type %q, expected %q or %q", def.Type, spanMetricCount, spanMetricDistribution)
+	}
+	rule, err := compileSpanRule(def.SpanFilterRule)
+	if err != nil {
+		return nil, err
+	}
+	return &spanMetric{
+		spanRule: rule,
+		name:     def.Name,
+		typ:      def.Type,
+		value:    def.Value,
+		groupBy:  def.GroupBy,
+	}, nil
+}
+
+// Report reports the metrics of the spans of the chunk. It is safe to call on
+// a nil SpanMetrics.
+func (m *SpanMetrics) Report(chunk *pb.TraceChunk) {
+	if m == nil {
+		return
+	}
+	for _, metric := range m.metrics {
+		for _, span := range chunk.Spans {
+			if metric.matches(span) {
+				metric.report(span)
+			}
+		}
+	}
+}
+
+func (m *spanMetric) report(span *pb.Span) {
+	tags := make([]string, 0, len(m.groupBy))
+	for _, k := range m.groupBy {
+		if v, ok := tagValue(span, k); ok {
+			tags = append(tags, traceutil.NormalizeTag(k+":"+v))
+		}
+	}
+	if m.typ == spanMetricCount {
+		_ = metrics.Count(m.name, 1, tags, 1)
+		return
+	}
+	value := float64(span.Duration) / 1e9
+	if m.value != "" {
+		v, ok := numericValue(span, m.value)
+		if !ok {
+			return
+		}
+		value = v
+	}
+	_ = metrics.Distribution(m.name, value, tags, 1)
+}
+
+// tagValue returns the value of the tag k of the span, looking up its metrics
+// when it is not a string tag.
+func tagValue(span *pb.Span, k string) (string, bool) {
+	if v, ok := span.Meta[k]; ok {
+		return v, v != ""
+	}
+	if v, ok := span.Metrics[k]; ok {
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// numericValue returns the value of the tag k of the span as a number,
+// parsing string tags.
+func numericValue(span *pb.Span, k string) (float64, bool) {
+	if v, ok := span.Metrics[k]; ok {
+		return v, true
+	}
+	if v, ok := span.Meta[k]; ok {
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/teststatsd"
+)
+
+func TestSpanMetricsInvalid(t *testing.T) {
+	m := NewSpanMetrics([]config.SpanMetric{
+		{Type: "count"},
+		{SpanFilterRule: config.SpanFilterRule{Name: "no.type"}},
+		{SpanFilterRule: config.SpanFilterRule{Name: "bad.type"}, Type: "gauge"},
+		{SpanFilterRule: config.SpanFilterRule{Name: "bad.resource", Resource: "[123"}, Type: "count"},
+		{SpanFilterRule: config.SpanFilterRule{Name: "all.spans"}, Type: "count"},
+	})
+	assert.Len(t, m.metrics, 1)
+}
+
+func TestSpanMetrics(t *testing.T) {
+	statsd := &teststatsd.Client{}
+	defer func(old metrics.StatsClient) { metrics.Client = old }(metrics.Client)
+	metrics.Client = statsd
+
+	m := NewSpanMetrics([]config.SpanMetric{
+		{
+			SpanFilterRule: config.SpanFilterRule{Name: "checkout.requests", Service: "web", Resource: "^POST /checkout"},
+			Type:           "count",
+			GroupBy:        []string{"customer.tier", "http.status_code"},
+		},
+		{
+			SpanFilterRule: config.SpanFilterRule{Name: "payload.size"},
+			Type:           "distribution",
+			Value:          "payload.size",
+		},
+		{
+			SpanFilterRule: config.SpanFilterRule{Name: "db.duration", Service: "db"},
+			Type:           "distribution",
+			GroupBy:        []string{"db.instance"},
+		},
+	})
+	m.Report(&pb.TraceChunk{Spans: []*pb.Span{
+		{
+			Service:  "web",
+			Resource: "POST /checkout",
+			Meta:     map[string]string{"customer.tier": "Gold", "payload.size": "1024"},
+			Metrics:  map[string]float64{"http.status_code": 200},
+		},
+		{Service: "web", Resource: "POST /checkout"},
+		{Service: "web", Resource: "GET /", Metrics: map[string]float64{"payload.size": 12}},
+		{Service: "db", Duration: int64(250 * time.Millisecond), Meta: map[string]string{"db.instance": "users", "payload.size": "n/a"}},
+	}})
+	(*SpanMetrics)(nil).Report(&pb.TraceChunk{Spans: []*pb.Span{{Service: "web"}}})
+
+	assert.Equal(t, []teststatsd.MetricsArgs{
+		{Name: "checkout.requests", Value: 1, Tags: []string{"customer.tier:gold", "http.status_code:200"}, Rate: 1},
+		{Name: "checkout.requests", Value: 1, Tags: []string{}, Rate: 1},
+	}, statsd.CountCalls)
+	assert.Equal(t, []teststatsd.MetricsArgs{
+		{Name: "payload.size", Value: 1024, Tags: []string{}, Rate: 1},
+		{Name: "payload.size", Value: 12, Tags: []string{}, Rate: 1},
+		{Name: "db.duration", Value: 0.25, Tags: []string{"db.instance:users"}, Rate: 1},
+	}, statsd.DistributionCalls)
+}
@@ -464,6 +464,7 @@ func TestPublishReceiverStats(t *testing.T) {
 			SpansReceived:         atom(10),
 			SpansDropped:          atom(11),
 			SpansFiltered:         atom(12),
+			SpansFilteredByRules:  atom(17),
 			EventsExtracted:       atom(13),
 			EventsSampled:         atom(14),
 			PayloadAccepted:       atom(15),
@@ -486,6 +487,7 @@ func TestPublishReceiverStats(t *testing.T) {
 			"PayloadRefused":        16.0,
 			"SpansDropped":          11.0,
 			"SpansFiltered":         12.0,
+			"SpansFilteredByRules":  17.0,
 			"SpansMalformed": map[string]interface{}{
 				"DuplicateSpanID":       1.0,
 				"ServiceEmpty":          2.0,
@@ -141,6 +141,8 @@ func (ts *TagStats) publishAndReset() {
 		ts.SpansDropped.Swap(0), tags, 1)
 	metrics.Count("datadog.trace_agent.receiver.spans_filtered",
 		ts.SpansFiltered.Swap(0), tags, 1)
+	metrics.Count("datadog.trace_agent.receiver.spans_filtered_by_rules",
+		ts.SpansFilteredByRules.Swap(0), tags, 1)
 	metrics.Count("datadog.trace_agent.receiver.events_extracted",
 		ts.EventsExtracted.Swap(0), tags, 1)
 	metrics.Count("datadog.trace_agent.receiver.events_sampled",
@@ -390,6 +392,8 @@ type Stats struct {
 	SpansDropped atomic.Int64
 	// SpansFiltered is the number of spans filtered.
 	SpansFiltered atomic.Int64
+	// SpansFilteredByRules is the number of spans removed from kept traces by the span filters.
+	SpansFilteredByRules atomic.Int64
 	// EventsExtracted is the total number of APM events extracted from traces.
 	EventsExtracted atomic.Int64
 	// EventsSampled is the total number of APM events sampled.
@@ -444,6 +448,7 @@ func (s *Stats) update(recent *Stats) {
 	s.SpansReceived.Add(recent.SpansReceived.Load())
 	s.SpansDropped.Add(recent.SpansDropped.Load())
 	s.SpansFiltered.Add(recent.SpansFiltered.Load())
+	s.SpansFilteredByRules.Add(recent.SpansFilteredByRules.Load())
 	s.EventsExtracted.Add(recent.EventsExtracted.Load())
 	s.EventsSampled.Add(recent.EventsSampled.Load())
 	s.PayloadAccepted.Add(recent.PayloadAccepted.Load())
@@ -188,6 +188,10 @@ func (ts *testStatsClient) Histogram(name string, value float64, tags []string,
 	return nil
 }
 
+func (ts *testStatsClient) Distribution(name string, value float64, tags []string, rate float64) error {
+	return nil
+}
+
 func (ts *testStatsClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
 	return nil
 }
@@ -224,6 +228,7 @@ func TestReceiverStats(t *testing.T) {
 		stats.SpansReceived.Store(10)
 		stats.SpansDropped.Store(11)
 		stats.SpansFiltered.Store(12)
+		stats.SpansFilteredByRules.Store(17)
 		stats.EventsExtracted.Store(13)
 		stats.EventsSampled.Store(14)
 		stats.PayloadAccepted.Store(15)
@@ -265,7 +270,7 @@ func TestReceiverStats(t *testing.T) {
 	t.Run("PublishAndReset", func(t *testing.T) {
 		rs := testStats()
 		rs.PublishAndReset()
-		assert.EqualValues(t, 41, statsclient.counts.Load())
+		assert.EqualValues(t, 42, statsclient.counts.Load())
 		assertStatsAreReset(t, rs)
 	})
 
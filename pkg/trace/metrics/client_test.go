@@ -32,6 +32,11 @@ func (ts *testStatsClient) Histogram(name string, value float64, tags []string,
 	return nil
 }
 
+func (ts *testStatsClient) Distribution(name string, value float64, tags []string, rate float64) error {
+	ts.counts.Inc()
+	return nil
+}
+
 func (ts *testStatsClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
 	ts.counts.Inc()
 	return nil
@@ -49,6 +54,7 @@ func TestForwarding(t *testing.T) {
 		assert.NoError(t, Gauge("stat", 1, nil, 1))
 		assert.NoError(t, Count("stat", 1, nil, 1))
 		assert.NoError(t, Histogram("stat", 1, nil, 1))
+		assert.NoError(t, Distribution("stat", 1, nil, 1))
 		assert.NoError(t, Timing("stat", time.Second, nil, 1))
 		assert.NoError(t, Flush())
 	})
@@ -60,8 +66,9 @@ func TestForwarding(t *testing.T) {
 		assert.NoError(t, Gauge("stat", 1, nil, 1))
 		assert.NoError(t, Count("stat", 1, nil, 1))
 		assert.NoError(t, Histogram("stat", 1, nil, 1))
+		assert.NoError(t, Distribution("stat", 1, nil, 1))
 		assert.NoError(t, Timing("stat", time.Second, nil, 1))
 		assert.NoError(t, Flush())
-		assert.Equal(t, testclient.counts.Load(), int64(5))
+		assert.Equal(t, testclient.counts.Load(), int64(6))
 	})
 }
@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+const (
+	// MaxCaptureDuration is the maximum duration of a capture.
+	MaxCaptureDuration = time.Hour
+
+	// captureQueueSize is the number of records waiting to be written to the
+	// capture file, after which new records are dropped.
+	captureQueueSize = 64
+)
+
+// ErrCaptureInProgress is returned when starting a capture while another one
+// is in progress.
+var ErrCaptureInProgress = errors.New("a capture is already in progress")
+
+// Capture records the requests received by the trace agent to a file, for a
+// bounded duration. It is safe for concurrent use.
+type Capture struct {
+	dir string
+
+	active atomic.Bool
+
+	mu      sync.RWMutex
+	records chan *Record // nil when no capture is in progress
+	timer   *time.Timer
+	done    chan struct{}
+}
+
+// NewCapture returns a new Capture writing capture files to dir. The system's
+// temporary directory is used when dir is empty.
+func NewCapture(dir string) *Capture {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return &Capture{dir: dir}
+}
+
+// Start starts a capture which lasts for d, capped at MaxCaptureDuration. It
+// returns the path of the capture file.
+func (c *Capture) Start(d time.Duration) (string, error) {
+	if d <= 0 {
+		return "", fmt.Errorf("invalid capture duration: %s", d)
+	}
+	if d > MaxCaptureDuration {
+		d = MaxCaptureDuration
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.records != nil {
+		return "", ErrCaptureInProgress
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(c.dir, fmt.Sprintf("datadog-trace-capture-%d-*", time.Now().Unix()))
+	if err != nil {
+		return "", err
+	}
+	w, err := NewWriter(f)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	c.records = make(chan *Record, captureQueueSize)
+	c.done = make(chan struct{})
+	go c.write(f, w, c.records, c.done)
+	c.timer = time.AfterFunc(d, c.Stop)
+	c.active.Store(true)
+	log.Infof("Capturing the received payloads for %s to %s", d, f.Name())
+	return f.Name(), nil
+}
+
+// Stop stops the capture in progress, if any, and waits for all its records
+// to be written.
+func (c *Capture) Stop() {
+	c.mu.Lock()
+	if c.records == nil {
+		c.mu.Unlock()
+		return
+	}
+	c.active.Store(false)
+	c.timer.Stop()
+	close(c.records)
+	c.records = nil
+	done := c.done
+	c.mu.Unlock()
+	<-done
+}
+
+// Active reports whether a capture is in progress.
+func (c *Capture) Active() bool {
+	return c.active.Load()
+}
+
+// Tee returns a body reading from the body of req, which records req when
+// closed. The remainder of the body is read when closing, so that requests
+// are recorded even if their decoder stops before the end of the body. The
+// request is not recorded if reading it fails or if its body is larger than
+// limit bytes.
+func (c *Capture) Tee(req *http.Request, limit int64) io.ReadCloser {
+	return &teeBody{
+		ReadCloser: req.Body,
+		capture:    c,
+		limit:      limit,
+		record: &Record{
+			Time:   time.Now(),
+			Path:   req.URL.Path,
+			Header: req.Header.Clone(),
+		},
+	}
+}
+
+// add queues r to be written to the capture file. It is dropped if the queue
+// is full or if the capture is over.
+func (c *Capture) add(r *Record) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.records == nil {
+		return
+	}
+	select {
+	case c.records <- r:
+	default:
+		log.Debugf("Capture queue full, dropping payload received on %s", r.Path)
+	}
+}
+
+// write writes the records to f until the records channel is closed.
+func (c *Capture) write(f *os.File, w *Writer, records <-chan *Record, done chan<- struct{}) {
+	defer close(done)
+	var n int
+	for r := range records {
+		if err := w.Write(r); err != nil {
+			log.Errorf("Error writing to the capture file %s: %v", f.Name(), err)
+			continue
+		}
+		n++
+	}
+	if err := w.Flush(); err != nil {
+		log.Errorf("Error writing to the capture file %s: %v", f.Name(), err)
+	}
+	if err := f.Close(); err != nil {
+		log.Errorf("Error closing the capture file %s: %v", f.Name(), err)
+	}
+	log.Infof("Capture complete: %d payloads written to %s", n, f.Name())
+}
+
+// teeBody is a request body recording the request when closed.
+type teeBody struct {
+	io.ReadCloser
+	capture *Capture
+	limit   int64
+	record  *Record // nil once handled
+	buf     bytes.Buffer
+}
+
+// Read implements io.Reader.
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if t.record != nil {
+		t.buf.Write(p[:n])
+		if err != nil && err != io.EOF || int64(t.buf.Len()) > t.limit {
+			t.record = nil
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (t *teeBody) Close() error {
+	if t.record != nil {
+		// a byte past the limit tells whether the body is too large
+		rest := io.LimitReader(t.ReadCloser, t.limit-int64(t.buf.Len())+1)
+		if _, err := t.buf.ReadFrom(rest); err == nil && int64(t.buf.Len()) <= t.limit {
+			t.record.Body = t.buf.Bytes()
+			t.capture.add(t.record)
+		}
+		t.record = nil
+	}
+	return t.ReadCloser.Close()
+}
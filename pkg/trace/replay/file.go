@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package replay implements the capture of the payloads received by the trace
+// agent to a file, and their replay against a trace agent.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// fileHeader starts capture files. Its last byte is the version of the format.
+var fileHeader = []byte{'D', 'D', 'T', 'R', 'C', 'A', 'P', fileVersion}
+
+const fileVersion = 1
+
+// maxFieldSize is the maximum size of a field of a record, which protects
+// readers against corrupted files.
+const maxFieldSize = 256 * 1024 * 1024
+
+// ErrInvalidFile is returned when reading a file which is not a capture file,
+// or a capture file of an unsupported version.
+var ErrInvalidFile = errors.New("not a trace capture file")
+
+// Record is a request captured by the trace agent.
+type Record struct {
+	// Time is the time at which the request was received.
+	Time time.Time
+	// Path is the path of the endpoint which received the request, e.g. "/v0.4/traces".
+	Path string
+	// Header holds the headers of the request.
+	Header http.Header
+	// Body is the body of the request.
+	Body []byte
+}
+
+// Writer writes records to a capture file.
+type Writer struct {
+	w       *bufio.Writer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+// NewWriter returns a new Writer writing to w. It writes the file header.
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(fileHeader); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw}, nil
+}
+
+// Write writes the record r.
+func (w *Writer) Write(r *Record) error {
+	binary.BigEndian.PutUint64(w.scratch[:8], uint64(r.Time.UnixNano()))
+	w.w.Write(w.scratch[:8]) //nolint:errcheck
+	w.writeBytes([]byte(r.Path))
+	keys := make([]string, 0, len(r.Header))
+	n := 0
+	for k, vs := range r.Header {
+		keys = append(keys, k)
+		n += len(vs)
+	}
+	sort.Strings(keys)
+	w.writeUvarint(uint64(n))
+	for _, k := range keys {
+		for _, v := range r.Header[k] {
+			w.writeBytes([]byte(k))
+			w.writeBytes([]byte(v))
+		}
+	}
+	// errors are sticky in bufio.Writer
+	_, err := w.writeBytes(r.Body)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) writeUvarint(v uint64) {
+	n := binary.PutUvarint(w.scratch[:], v)
+	w.w.Write(w.scratch[:n]) //nolint:errcheck
+}
+
+func (w *Writer) writeBytes(b []byte) (int, error) {
+	w.writeUvarint(uint64(len(b)))
+	return w.w.Write(b)
+}
+
+// Reader reads the records of a capture file.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a new Reader reading from r. It returns ErrInvalidFile if
+// r doesn't start with the header of a supported capture file.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	hdr := make([]byte, len(fileHeader))
+	if _, err := io.ReadFull(br, hdr); err != nil || !bytes.Equal(hdr, fileHeader) {
+		return nil, ErrInvalidFile
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next record. It returns io.EOF once all the records were
+// read.
+func (r *Reader) Next() (*Record, error) {
+	var ts [8]byte
+	if _, err := io.ReadFull(r.r, ts[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated record: %w", err)
+		}
+		return nil, err
+	}
+	rec := &Record{
+		Time:   time.Unix(0, int64(binary.BigEndian.Uint64(ts[:]))),
+		Header: make(http.Header),
+	}
+	path, err := r.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	rec.Path = string(path)
+	n, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		k, err := r.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		v, err := r.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		rec.Header[string(k)] = append(rec.Header[string(k)], string(v))
+	}
+	if rec.Body, err = r.readBytes(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (r *Reader) readUvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, fmt.Errorf("truncated record: %w", err)
+	}
+	return v, nil
+}
+
+func (r *Reader) readBytes() ([]byte, error) {
+	n, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > maxFieldSize {
+		return nil, fmt.Errorf("invalid record: field of %d bytes", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return nil, fmt.Errorf("truncated record: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Replayer sends the records of capture files to a trace agent.
+type Replayer struct {
+	// Target is the base URL of the trace agent, e.g. "http://localhost:8126".
+	Target string
+	// Speed is the rate at which records are sent, relative to the rate at
+	// which they were captured: 1 replays them at the original rate, 2 twice
+	// as fast. Records are sent as fast as possible when Speed is 0.
+	Speed float64
+	// Client is the HTTP client used to send the records. http.DefaultClient
+	// is used when nil.
+	Client *http.Client
+}
+
+// Stats holds the outcome of a replay.
+type Stats struct {
+	// Sent is the number of records sent.
+	Sent int
+	// Failed is the number of records which the trace agent failed to accept.
+	Failed int
+}
+
+// Replay sends the records read from r, stopping early if ctx is done.
+func (rp *Replayer) Replay(ctx context.Context, r *Reader) (Stats, error) {
+	var (
+		stats Stats
+		first time.Time // time of the first record
+		start time.Time // time at which the first record was sent
+	)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		if first.IsZero() {
+			first, start = rec.Time, time.Now()
+		} else if rp.Speed > 0 {
+			at := start.Add(time.Duration(float64(rec.Time.Sub(first)) / rp.Speed))
+			select {
+			case <-time.After(time.Until(at)):
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			}
+		}
+		if err := rp.send(ctx, rec); err != nil {
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			stats.Failed++
+		}
+		stats.Sent++
+	}
+}
+
+func (rp *Replayer) send(ctx context.Context, rec *Record) error {
+	url := strings.TrimSuffix(rp.Target, "/") + rec.Path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(rec.Body))
+	if err != nil {
+		return err
+	}
+	req.Header = rec.Header.Clone()
+	// the length of the replayed body is set by the client
+	req.Header.Del("Content-Length")
+	client := rp.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s: %s", rec.Path, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	now := time.Unix(0, time.Now().UnixNano())
+	records := []*Record{
+		{
+			Time:   now,
+			Path:   "/v0.4/traces",
+			Header: http.Header{"Content-Type": {"application/msgpack"}, "X-Datadog-Trace-Count": {"2"}},
+			Body:   []byte{0x92, 0x90, 0x90},
+		},
+		{
+			Time:   now.Add(time.Second),
+			Path:   "/v0.7/traces",
+			Header: http.Header{"Accept": {"a", "b"}},
+			Body:   []byte{},
+		},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	for _, r := range records {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.Flush())
+
+	t.Run("read", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		for _, want := range records {
+			got, err := r.Next()
+			require.NoError(t, err)
+			assert.True(t, want.Time.Equal(got.Time))
+			assert.Equal(t, want.Path, got.Path)
+			assert.Equal(t, want.Header, got.Header)
+			assert.Equal(t, want.Body, got.Body)
+		}
+		_, err = r.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+		require.NoError(t, err)
+		_, err = r.Next()
+		require.NoError(t, err)
+		_, err = r.Next()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("not a capture file"))
+		assert.Equal(t, ErrInvalidFile, err)
+		_, err = NewReader(strings.NewReader(""))
+		assert.Equal(t, ErrInvalidFile, err)
+	})
+}
+
+func TestCapture(t *testing.T) {
+	c := NewCapture(t.TempDir())
+	assert.False(t, c.Active())
+	path, err := c.Start(time.Minute)
+	require.NoError(t, err)
+	assert.True(t, c.Active())
+	_, err = c.Start(time.Minute)
+	assert.Equal(t, ErrCaptureInProgress, err)
+
+	send := func(path, body string, read int) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/msgpack")
+		rc := c.Tee(req, 10)
+		// decoders may stop reading before the end of the body
+		io.CopyN(io.Discard, rc, int64(read)) //nolint:errcheck
+		require.NoError(t, rc.Close())
+	}
+	send("/v0.4/traces", "payload", 0)
+	send("/v0.5/traces", "payload", 3)
+	send("/v0.7/traces", "too large payload", 0)
+	send("/v0.7/traces", "too large payload", 15)
+	c.Stop()
+	assert.False(t, c.Active())
+	send("/v0.4/traces", "stopped", 7)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := NewReader(f)
+	require.NoError(t, err)
+	for _, path := range []string{"/v0.4/traces", "/v0.5/traces"} {
+		rec, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, path, rec.Path)
+		assert.Equal(t, "payload", string(rec.Body))
+		assert.Equal(t, "application/msgpack", rec.Header.Get("Content-Type"))
+	}
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCaptureDuration(t *testing.T) {
+	c := NewCapture(t.TempDir())
+	_, err := c.Start(0)
+	assert.Error(t, err)
+	_, err = c.Start(10 * time.Millisecond)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !c.Active() }, time.Second, 5*time.Millisecond)
+	_, err = c.Start(time.Minute)
+	assert.NoError(t, err)
+	c.Stop()
+}
+
+func TestReplay(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "go", req.Header.Get("Datadog-Meta-Lang"))
+		mu.Lock()
+		received = append(received, req.URL.Path+" "+string(body))
+		mu.Unlock()
+		if req.URL.Path == "/v0.5/traces" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	now := time.Now()
+	for i, path := range []string{"/v0.4/traces", "/v0.5/traces", "/v0.7/traces"} {
+		require.NoError(t, w.Write(&Record{
+			Time:   now.Add(time.Duration(i) * 100 * time.Millisecond),
+			Path:   path,
+			Header: http.Header{"Datadog-Meta-Lang": {"go"}, "Content-Length": {"1"}},
+			Body:   []byte(path[1:5]),
+		}))
+	}
+	require.NoError(t, w.Flush())
+
+	for name, tt := range map[string]struct {
+		speed    float64
+		min, max time.Duration
+	}{
+		"original":    {speed: 1, min: 200 * time.Millisecond, max: 2 * time.Second},
+		"accelerated": {speed: 10, min: 20 * time.Millisecond, max: 150 * time.Millisecond},
+	} {
+		t.Run(name, func(t *testing.T) {
+			received = nil
+			r, err := NewReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			start := time.Now()
+			stats, err := (&Replayer{Target: srv.URL + "/", Speed: tt.speed}).Replay(context.Background(), r)
+			elapsed := time.Since(start)
+			require.NoError(t, err)
+			assert.Equal(t, Stats{Sent: 3, Failed: 1}, stats)
+			assert.Equal(t, []string{"/v0.4/traces v0.4", "/v0.5/traces v0.5", "/v0.7/traces v0.7"}, received)
+			assert.GreaterOrEqual(t, elapsed, tt.min)
+			assert.Less(t, elapsed, tt.max)
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		stats, err := (&Replayer{Target: srv.URL, Speed: 1}).Replay(ctx, r)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, 1, stats.Sent)
+	})
+}
@@ -0,0 +1,344 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+const (
+	// TailSamplingPolicyKey is the tag set on the root span of the chunks kept
+	// by a tail sampling policy, holding the name of the policy.
+	TailSamplingPolicyKey = "_dd.tail_sampling.policy"
+
+	// tailSamplerTick is the frequency at which expired decision windows are
+	// evaluated.
+	tailSamplerTick = 500 * time.Millisecond
+)
+
+// Tail sampling policy types.
+const (
+	tailPolicyError         = "error"
+	tailPolicyLatency       = "latency"
+	tailPolicyTag           = "tag"
+	tailPolicyProbabilistic = "probabilistic"
+)
+
+// TailChunk is a chunk buffered by the TailSampler until the trace it belongs
+// to is decided.
+type TailChunk struct {
+	// Trace is the processed chunk.
+	Trace *traceutil.ProcessedTrace
+	// Payload holds the metadata of the tracer payload the chunk was received
+	// in, without its chunks.
+	Payload *pb.TracerPayload
+	// Data is passed back untouched with the decision.
+	Data interface{}
+}
+
+// TailDecisionFunc receives the chunks of a decided trace. policy is the name
+// of the policy that matched the trace, or empty if none did.
+type TailDecisionFunc func(chunks []*TailChunk, policy string)
+
+// TailSampler buffers the chunks of each trace for a decision window and then
+// evaluates policies on all the spans received for the trace. It lets the
+// agent keep a trace because of a span seen after its first chunks, such as an
+// error deep in the call tree or a slow downstream call. Only the chunks that
+// reach this agent are evaluated.
+type TailSampler struct {
+	enabled  bool
+	wait     time.Duration
+	maxTrace int
+	maxSpans int
+	policies []tailPolicy
+	decide   TailDecisionFunc
+
+	mu      sync.Mutex
+	pending map[uint64]*tailTrace
+	// queue holds the pending traces by arrival order, and so by decision
+	// deadline.
+	queue []*tailTrace
+	spans int
+	// decided holds the decisions taken for the last decision window, so that
+	// chunks received late follow the decision taken for their trace. It
+	// holds up to maxTrace decisions.
+	decided map[uint64]tailDecision
+	// decidedQueue holds the decided traces by decision order, and so
+	// by expiration.
+	decidedQueue []decidedTrace
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+type tailTrace struct {
+	traceID  uint64
+	deadline time.Time
+	spans    int
+	chunks   []*TailChunk
+	// policy is the name of the policy that matched the trace, once decided.
+	policy string
+}
+
+type tailDecision struct {
+	policy  string
+	expires time.Time
+}
+
+type decidedTrace struct {
+	traceID uint64
+	expires time.Time
+}
+
+// NewTailSampler returns a TailSampler calling decide with each decided trace.
+// The returned sampler does nothing unless tail sampling is enabled in conf.
+func NewTailSampler(conf *config.AgentConfig, decide TailDecisionFunc) *TailSampler {
+	s := &TailSampler{
+		enabled:  conf.TailSampling.Enabled,
+		wait:     conf.TailSampling.DecisionWait,
+		maxTrace: conf.TailSampling.MaxTraces,
+		maxSpans: conf.TailSampling.MaxSpans,
+		decide:   decide,
+		pending:  make(map[uint64]*tailTrace),
+		decided:  make(map[uint64]tailDecision),
+		exit:     make(chan struct{}),
+	}
+	for _, p := range conf.TailSampling.Policies {
+		if err := validateTailPolicy(p); err != nil {
+			log.Errorf("Ignoring tail sampling policy %q: %v", p.Name, err)
+			continue
+		}
+		s.policies = append(s.policies, tailPolicy(p))
+	}
+	return s
+}
+
+// Enabled reports whether chunks should be handed to the sampler. It is safe
+// to call on a nil TailSampler.
+func (s *TailSampler) Enabled() bool {
+	return s != nil && s.enabled
+}
+
+// Start starts evaluating the traces whose decision window ended.
+func (s *TailSampler) Start() {
+	if !s.enabled {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		decideTicker := time.NewTicker(tailSamplerTick)
+		defer decideTicker.Stop()
+		statsTicker := time.NewTicker(10 * time.Second)
+		defer statsTicker.Stop()
+		for {
+			select {
+			case now := <-decideTicker.C:
+				s.flush(now, false)
+			case <-statsTicker.C:
+				s.report()
+			case <-s.exit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sampler, deciding all the pending traces.
+func (s *TailSampler) Stop() {
+	if !s.enabled {
+		return
+	}
+	close(s.exit)
+	s.wg.Wait()
+	s.flush(time.Now(), true)
+}
+
+// Add buffers the chunk until its trace is decided. Chunks of a trace that
+// was already decided are decided right away, following the same decision.
+func (s *TailSampler) Add(now time.Time, chunk *TailChunk) {
+	if len(chunk.Trace.TraceChunk.Spans) == 0 {
+		return
+	}
+	traceID := chunk.Trace.TraceChunk.Spans[0].TraceID
+	nspans := len(chunk.Trace.TraceChunk.Spans)
+
+	s.mu.Lock()
+	if d, ok := s.decided[traceID]; ok && now.Before(d.expires) {
+		s.mu.Unlock()
+		metrics.Count("datadog.trace_agent.tail_sampler.late_chunks", 1, nil, 1)
+		s.decide([]*TailChunk{chunk}, d.policy)
+		return
+	}
+	t, ok := s.pending[traceID]
+	if !ok {
+		t = &tailTrace{
+			traceID:  traceID,
+			deadline: now.Add(s.wait),
+		}
+		s.pending[traceID] = t
+		s.queue = append(s.queue, t)
+	}
+	t.chunks = append(t.chunks, chunk)
+	t.spans += nspans
+	s.spans += nspans
+
+	// decide the oldest traces early when the buffers are full
+	var evicted []*tailTrace
+	for len(s.queue) > 0 && (len(s.pending) > s.maxTrace || s.spans > s.maxSpans) {
+		evicted = append(evicted, s.pop(now))
+	}
+	s.mu.Unlock()
+
+	if len(evicted) > 0 {
+		metrics.Count("datadog.trace_agent.tail_sampler.evicted", int64(len(evicted)), nil, 1)
+	}
+	for _, t := range evicted {
+		s.release(t)
+	}
+}
+
+// flush decides the traces whose decision window ended, or all the pending
+// traces if all is true.
+func (s *TailSampler) flush(now time.Time, all bool) {
+	s.mu.Lock()
+	var expired []*tailTrace
+	for len(s.queue) > 0 && (all || !now.Before(s.queue[0].deadline)) {
+		expired = append(expired, s.pop(now))
+	}
+	for len(s.decidedQueue) > 0 && !now.Before(s.decidedQueue[0].expires) {
+		s.forgetOldestDecision()
+	}
+	s.mu.Unlock()
+
+	for _, t := range expired {
+		s.release(t)
+	}
+}
+
+// pop removes the oldest trace from the buffers and runs the policies on it.
+// s.mu must be held.
+func (s *TailSampler) pop(now time.Time) *tailTrace {
+	t := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	delete(s.pending, t.traceID)
+	s.spans -= t.spans
+	for i := range s.policies {
+		if s.policies[i].matches(t) {
+			t.policy = s.policies[i].Name
+			break
+		}
+	}
+	// forget the oldest decisions when too many traces were decided during
+	// the last decision window
+	for len(s.decidedQueue) > 0 && len(s.decided) >= s.maxTrace {
+		s.forgetOldestDecision()
+	}
+	expires := now.Add(s.wait)
+	s.decided[t.traceID] = tailDecision{policy: t.policy, expires: expires}
+	s.decidedQueue = append(s.decidedQueue, decidedTrace{traceID: t.traceID, expires: expires})
+	return t
+}
+
+// forgetOldestDecision removes the oldest decision. s.mu must be held.
+func (s *TailSampler) forgetOldestDecision() {
+	d := s.decidedQueue[0]
+	s.decidedQueue[0] = decidedTrace{}
+	s.decidedQueue = s.decidedQueue[1:]
+	// the trace may have been decided again since, once its decision expired
+	if s.decided[d.traceID].expires.Equal(d.expires) {
+		delete(s.decided, d.traceID)
+	}
+}
+
+// release hands the decided trace to the decision function.
+func (s *TailSampler) release(t *tailTrace) {
+	if t.policy != "" {
+		metrics.Count("datadog.trace_agent.tail_sampler.kept", 1, []string{"policy:" + t.policy}, 1)
+	} else {
+		metrics.Count("datadog.trace_agent.tail_sampler.unmatched", 1, nil, 1)
+	}
+	s.decide(t.chunks, t.policy)
+}
+
+func (s *TailSampler) report() {
+	s.mu.Lock()
+	traces, spans := len(s.pending), s.spans
+	s.mu.Unlock()
+	metrics.Gauge("datadog.trace_agent.tail_sampler.pending_traces", float64(traces), nil, 1)
+	metrics.Gauge("datadog.trace_agent.tail_sampler.pending_spans", float64(spans), nil, 1)
+}
+
+// tailPolicy is a validated TailSamplingPolicy.
+type tailPolicy config.TailSamplingPolicy
+
+func validateTailPolicy(p config.TailSamplingPolicy) error {
+	if p.Name == "" {
+		return errors.New("missing name")
+	}
+	switch p.Type {
+	case tailPolicyError:
+	case tailPolicyLatency:
+		if p.Threshold <= 0 {
+			return errors.New("latency policies require a positive threshold")
+		}
+	case tailPolicyTag:
+		if p.Key == "" {
+			return errors.New("tag policies require a key")
+		}
+	case tailPolicyProbabilistic:
+		if p.Rate <= 0 || p.Rate > 1 {
+			return errors.New("probabilistic policies require a rate in (0, 1]")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+	return nil
+}
+
+// matches reports whether the policy keeps the trace.
+func (p *tailPolicy) matches(t *tailTrace) bool {
+	if p.Type == tailPolicyProbabilistic {
+		return SampleByRate(t.traceID, p.Rate)
+	}
+	for _, c := range t.chunks {
+		for _, span := range c.Trace.TraceChunk.Spans {
+			if p.matchesSpan(span) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *tailPolicy) matchesSpan(span *pb.Span) bool {
+	if p.Service != "" && span.Service != p.Service {
+		return false
+	}
+	if p.Resource != "" && span.Resource != p.Resource {
+		return false
+	}
+	switch p.Type {
+	case tailPolicyError:
+		return span.Error != 0
+	case tailPolicyLatency:
+		return time.Duration(span.Duration) >= p.Threshold
+	case tailPolicyTag:
+		v, ok := span.Meta[p.Key]
+		return ok && (p.Value == "" || v == p.Value)
+	}
+	return false
+}
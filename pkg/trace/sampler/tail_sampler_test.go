@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+type tailDecisions map[uint64][]string
+
+func newTestTailSampler(policies ...config.TailSamplingPolicy) (*TailSampler, tailDecisions) {
+	conf := config.New()
+	conf.TailSampling.Enabled = true
+	conf.TailSampling.MaxTraces = 3
+	conf.TailSampling.MaxSpans = 10
+	conf.TailSampling.Policies = policies
+
+	decisions := make(tailDecisions)
+	s := NewTailSampler(conf, func(chunks []*TailChunk, policy string) {
+		for _, c := range chunks {
+			traceID := c.Trace.TraceChunk.Spans[0].TraceID
+			decisions[traceID] = append(decisions[traceID], policy)
+		}
+	})
+	return s, decisions
+}
+
+func tailChunk(spans ...*pb.Span) *TailChunk {
+	return &TailChunk{
+		Trace: &traceutil.ProcessedTrace{
+			TraceChunk: &pb.TraceChunk{Spans: spans},
+			Root:       spans[0],
+		},
+	}
+}
+
+func TestTailSamplerPolicies(t *testing.T) {
+	s, decisions := newTestTailSampler(
+		config.TailSamplingPolicy{Name: "invalid", Type: "latency"},
+		config.TailSamplingPolicy{Name: "errors", Type: "error", Service: "db"},
+		config.TailSamplingPolicy{Name: "slow", Type: "latency", Resource: "GET /users", Threshold: time.Second},
+		config.TailSamplingPolicy{Name: "vip", Type: "tag", Key: "customer.tier", Value: "gold"},
+	)
+	assert.Len(t, s.policies, 3)
+
+	now := time.Now()
+	s.Add(now, tailChunk(&pb.Span{TraceID: 1, Service: "web", Error: 1}))
+	s.Add(now, tailChunk(&pb.Span{TraceID: 2, Service: "web"}))
+	s.Add(now, tailChunk(&pb.Span{TraceID: 2, Service: "db", Error: 1}))
+	s.Add(now, tailChunk(&pb.Span{TraceID: 3, Resource: "GET /users", Duration: int64(2 * time.Second)}))
+	s.flush(now.Add(time.Minute), true)
+	s.Add(now, tailChunk(&pb.Span{TraceID: 4, Meta: map[string]string{"customer.tier": "gold"}}))
+	s.Add(now, tailChunk(&pb.Span{TraceID: 5, Resource: "GET /orders", Duration: int64(2 * time.Second)}))
+	s.flush(now.Add(time.Minute), true)
+
+	assert.Equal(t, tailDecisions{
+		1: {""},
+		2: {"errors", "errors"},
+		3: {"slow"},
+		4: {"vip"},
+		5: {""},
+	}, decisions)
+}
+
+func TestTailSamplerDecisionWindow(t *testing.T) {
+	s, decisions := newTestTailSampler(config.TailSamplingPolicy{Name: "errors", Type: "error"})
+
+	now := time.Now()
+	s.Add(now, tailChunk(&pb.Span{TraceID: 1}))
+	s.Add(now.Add(5*time.Second), tailChunk(&pb.Span{TraceID: 2}))
+
+	s.flush(now.Add(9*time.Second), false)
+	assert.Empty(t, decisions)
+
+	s.Add(now.Add(9*time.Second), tailChunk(&pb.Span{TraceID: 1, Error: 1}))
+	s.flush(now.Add(10*time.Second), false)
+	assert.Equal(t, tailDecisions{1: {"errors", "errors"}}, decisions)
+
+	// late chunks follow the decision taken for their trace
+	s.Add(now.Add(11*time.Second), tailChunk(&pb.Span{TraceID: 1}))
+	assert.Equal(t, tailDecisions{1: {"errors", "errors", "errors"}}, decisions)
+
+	s.flush(now.Add(15*time.Second), false)
+	assert.Equal(t, tailDecisions{1: {"errors", "errors", "errors"}, 2: {""}}, decisions)
+	assert.Empty(t, s.pending)
+	assert.Empty(t, s.queue)
+}
+
+func TestTailSamplerMemoryBounds(t *testing.T) {
+	s, decisions := newTestTailSampler()
+
+	now := time.Now()
+	for i := uint64(1); i <= 4; i++ {
+		s.Add(now, tailChunk(&pb.Span{TraceID: i}))
+	}
+	assert.Equal(t, tailDecisions{1: {""}}, decisions)
+
+	// 4 pending traces would hold 12 spans
+	spans := make([]*pb.Span, 9)
+	for i := range spans {
+		spans[i] = &pb.Span{TraceID: 5}
+	}
+	s.Add(now, tailChunk(spans...))
+	assert.Equal(t, tailDecisions{1: {""}, 2: {""}, 3: {""}}, decisions)
+	assert.Equal(t, 10, s.spans)
+	assert.Len(t, s.pending, 2)
+}
+
+func TestTailSamplerDecidedBounds(t *testing.T) {
+	s, decisions := newTestTailSampler()
+
+	now := time.Now()
+	for i := uint64(1); i <= 5; i++ {
+		s.Add(now, tailChunk(&pb.Span{TraceID: i}))
+	}
+	s.flush(now, true)
+	// only the decisions of the last maxTrace traces are kept
+	assert.Len(t, s.decided, 3)
+	assert.Len(t, s.decidedQueue, 3)
+	assert.NotContains(t, s.decided, uint64(1))
+
+	// a late chunk of a forgotten trace starts a new decision window
+	s.Add(now, tailChunk(&pb.Span{TraceID: 1}))
+	assert.Len(t, s.pending, 1)
+	s.Add(now, tailChunk(&pb.Span{TraceID: 5}))
+	assert.Equal(t, []string{"", ""}, decisions[5])
+
+	s.flush(now.Add(time.Minute), false)
+	assert.Equal(t, []string{"", ""}, decisions[1])
+	s.flush(now.Add(2*time.Minute), false)
+	assert.Empty(t, s.decided)
+	assert.Empty(t, s.decidedQueue)
+}
+
+func TestTailSamplerProbabilistic(t *testing.T) {
+	s, decisions := newTestTailSampler(config.TailSamplingPolicy{Name: "sample", Type: "probabilistic", Rate: 0.5})
+	s.maxTrace = 1000
+
+	now := time.Now()
+	for i := uint64(1); i <= 1000; i++ {
+		s.Add(now, tailChunk(&pb.Span{TraceID: i * 0x9E3779B97F4A7C15}))
+	}
+	s.flush(now.Add(time.Minute), true)
+
+	var kept int
+	for _, d := range decisions {
+		if d[0] == "sample" {
+			kept++
+		}
+	}
+	assert.InDelta(t, 500, kept, 100)
+}
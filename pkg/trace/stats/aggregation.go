@@ -30,14 +30,15 @@ type Aggregation struct {
 
 // BucketsAggregationKey specifies the key by which a bucket is aggregated.
 type BucketsAggregationKey struct {
-	Service      string
-	Name         string
-	Resource     string
-	Type         string
-	SpanKind     string
-	StatusCode   uint32
-	Synthetics   bool
-	PeerTagsHash uint64
+	Service        string
+	Name           string
+	Resource       string
+	Type           string
+	SpanKind       string
+	StatusCode     uint32
+	Synthetics     bool
+	PeerTagsHash   uint64
+	CustomTagsHash uint64
 }
 
 // PayloadAggregationKey specifies the key by which a payload is aggregated.
@@ -89,7 +90,7 @@ func NewAggregationFromSpan(s *pb.Span, origin string, aggKey PayloadAggregation
 	var peerTags []string
 	if clientOrProducer(agg.SpanKind) && enablePeerTagsAgg {
 		peerTags = matchingPeerTags(s, peerTagKeys)
-		agg.PeerTagsHash = peerTagsHash(peerTags)
+		agg.PeerTagsHash = tagsHash(peerTags)
 	}
 	return agg, peerTags
 }
@@ -107,7 +108,8 @@ func matchingPeerTags(s *pb.Span, peerTagKeys []string) []string {
 	return pt
 }
 
-func peerTagsHash(tags []string) uint64 {
+// tagsHash returns a hash of the given tags, sorting them in place.
+func tagsHash(tags []string) uint64 {
 	if len(tags) == 0 {
 		return 0
 	}
@@ -128,13 +130,14 @@ func peerTagsHash(tags []string) uint64 {
 func NewAggregationFromGroup(g *pb.ClientGroupedStats) Aggregation {
 	return Aggregation{
 		BucketsAggregationKey: BucketsAggregationKey{
-			Resource:     g.Resource,
-			Service:      g.Service,
-			Name:         g.Name,
-			SpanKind:     g.SpanKind,
-			StatusCode:   g.HTTPStatusCode,
-			Synthetics:   g.Synthetics,
-			PeerTagsHash: peerTagsHash(g.PeerTags),
+			Resource:       g.Resource,
+			Service:        g.Service,
+			Name:           g.Name,
+			SpanKind:       g.SpanKind,
+			StatusCode:     g.HTTPStatusCode,
+			Synthetics:     g.Synthetics,
+			PeerTagsHash:   tagsHash(g.PeerTags),
+			CustomTagsHash: tagsHash(g.CustomTags),
 		},
 	}
 }
@@ -44,7 +44,9 @@ type ClientStatsAggregator struct {
 	agentEnv            string
 	agentHostname       string
 	agentVersion        string
-	peerTagsAggregation bool // flag to enable aggregation over peer tags
+	peerTagsAggregation bool     // flag to enable aggregation over peer tags
+	customTagKeys       []string // keys for the tags used as additional aggregation dimensions
+	customTagsMaxValues int      // maximum number of distinct values of each custom tag per bucket
 
 	exit chan struct{}
 	done chan struct{}
@@ -61,6 +63,8 @@ func NewClientStatsAggregator(conf *config.AgentConfig, out chan *pb.StatsPayloa
 		agentHostname:       conf.Hostname,
 		agentVersion:        conf.AgentVersion,
 		peerTagsAggregation: conf.PeerServiceAggregation || conf.PeerTagsAggregation,
+		customTagKeys:       preparePeerTags(conf.StatsCustomTags...),
+		customTagsMaxValues: conf.StatsCustomTagsMaxValues,
 		oldestTs:            alignAggTs(time.Now().Add(bucketDuration - oldestBucketStart)),
 		exit:                make(chan struct{}),
 		done:                make(chan struct{}),
@@ -134,7 +138,7 @@ func (a *ClientStatsAggregator) add(now time.Time, p *pb.ClientStatsPayload) {
 		}
 		b, ok := a.buckets[ts.Unix()]
 		if !ok {
-			b = &bucket{ts: ts}
+			b = &bucket{ts: ts, customTags: newCustomTagsGuard(a.customTagKeys, a.customTagsMaxValues)}
 			a.buckets[ts.Unix()] = b
 		}
 		p.Stats = []*pb.ClientStatsBucket{clientBucket}
@@ -175,9 +179,19 @@ type bucket struct {
 	n int
 	// agg contains the aggregated Hits/Errors/Duration counts
 	agg map[PayloadAggregationKey]map[BucketsAggregationKey]*aggregatedCounts
+	// customTags limits the custom aggregation tags of the payloads to the
+	// configured keys and values
+	customTags *customTagsGuard
 }
 
 func (b *bucket) add(p *pb.ClientStatsPayload, enablePeerSvcAgg bool) []*pb.ClientStatsPayload {
+	for _, s := range p.Stats {
+		for _, sb := range s.Stats {
+			if sb != nil {
+				sb.CustomTags = b.customTags.filter(sb.CustomTags)
+			}
+		}
+	}
 	b.n++
 	if b.n == 1 {
 		b.first = &pb.ClientStatsPayload{
@@ -233,6 +247,7 @@ func (b *bucket) aggregateCounts(p *pb.ClientStatsPayload, enablePeerTagsAgg boo
 				if enablePeerTagsAgg {
 					agg.peerTags = sb.PeerTags
 				}
+				agg.customTags = sb.CustomTags
 			}
 			agg.hits += sb.Hits
 			agg.errors += sb.Errors
@@ -262,6 +277,7 @@ func (b *bucket) aggregationToPayloads() []*pb.ClientStatsPayload {
 				Type:           aggrKey.Type,
 				Synthetics:     aggrKey.Synthetics,
 				PeerTags:       counts.peerTags,
+				CustomTags:     counts.customTags,
 				Hits:           counts.hits,
 				Errors:         counts.errors,
 				Duration:       counts.duration,
@@ -299,8 +315,9 @@ func newBucketAggregationKey(b *pb.ClientGroupedStats, enablePeerTagsAgg bool) B
 		StatusCode: b.HTTPStatusCode,
 	}
 	if enablePeerTagsAgg {
-		k.PeerTagsHash = peerTagsHash(b.GetPeerTags())
+		k.PeerTagsHash = tagsHash(b.GetPeerTags())
 	}
+	k.CustomTagsHash = tagsHash(b.GetCustomTags())
 	return k
 }
 
@@ -325,4 +342,5 @@ func trimCounts(p *pb.ClientStatsPayload) *pb.ClientStatsPayload {
 type aggregatedCounts struct {
 	hits, errors, duration uint64
 	peerTags               []string
+	customTags             []string
 }
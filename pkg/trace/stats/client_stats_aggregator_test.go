@@ -6,6 +6,7 @@
 package stats
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -74,6 +75,13 @@ func getTestStatsWithStart(start time.Time) *proto.ClientStatsPayload {
 	b := &proto.ClientStatsBucket{}
 	fuzzer.Fuzz(b)
 	b.Start = uint64(start.UnixNano())
+	for _, s := range b.Stats {
+		// the test aggregators have no custom tags configured, and drop
+		// the ones of the payloads
+		if s != nil {
+			s.CustomTags = nil
+		}
+	}
 	p := &proto.ClientStatsPayload{}
 	fuzzer.Fuzz(p)
 	p.Tags = nil
@@ -454,6 +462,49 @@ func TestNewBucketAggregationKeyPeerTags(t *testing.T) {
 	})
 }
 
+func TestBucketCustomTags(t *testing.T) {
+	payload := func(customTags ...string) *proto.ClientStatsPayload {
+		return &proto.ClientStatsPayload{
+			Env: "test-env",
+			Stats: []*proto.ClientStatsBucket{{
+				Stats: []*proto.ClientGroupedStats{{Service: "s", Name: "op", Hits: 1, CustomTags: customTags}},
+			}},
+		}
+	}
+	flushHits := func(b *bucket) map[string]uint64 {
+		b.add(payload("customer_tier:gold", "region:us-east-1"), false)
+		b.add(payload("customer_tier:gold"), false)
+		b.add(payload("customer_tier:silver"), false)
+		b.add(payload("customer_tier:bronze"), false)
+		b.add(payload("customer_tier:platinum", "customer_tier"), false)
+		b.add(payload(), false)
+
+		res := b.flush()
+		assert.Len(t, res, 1)
+		hits := make(map[string]uint64)
+		for _, gs := range res[0].Stats[0].Stats {
+			assert.Empty(t, gs.PeerTags)
+			hits[strings.Join(gs.CustomTags, ",")] += gs.Hits
+		}
+		return hits
+	}
+
+	t.Run("configured", func(t *testing.T) {
+		b := &bucket{customTags: newCustomTagsGuard([]string{"customer_tier"}, 2)}
+		assert.Equal(t, map[string]uint64{
+			"customer_tier:gold":         2,
+			"customer_tier:silver":       1,
+			"customer_tier:_dd.overflow": 2,
+			"":                           1,
+		}, flushHits(b))
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		b := &bucket{}
+		assert.Equal(t, map[string]uint64{"": 6}, flushHits(b))
+	})
+}
+
 func deepCopy(p *proto.ClientStatsPayload) *proto.ClientStatsPayload {
 	new := &proto.ClientStatsPayload{
 		Hostname:         p.GetHostname(),
@@ -513,6 +564,7 @@ func deepCopyGroupedStats(s []*proto.ClientGroupedStats) []*proto.ClientGroupedS
 			TopLevelHits:   b.GetTopLevelHits(),
 			SpanKind:       b.GetSpanKind(),
 			PeerTags:       b.GetPeerTags(),
+			CustomTags:     b.GetCustomTags(),
 		}
 		if b.OkSummary != nil {
 			new[i].OkSummary = make([]byte, len(b.OkSummary))
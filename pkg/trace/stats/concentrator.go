@@ -50,6 +50,8 @@ type Concentrator struct {
 	peerTagsAggregation    bool     // flag to enable aggregation of peer tags
 	computeStatsBySpanKind bool     // flag to enable computation of stats through checking the span.kind field
 	peerTagKeys            []string // keys for supplementary tags that describe peer.service entities
+	customTagKeys          []string // keys for the tags used as additional aggregation dimensions
+	customTagsMaxValues    int      // maximum number of distinct values of each custom tag per bucket
 }
 
 var defaultPeerTags = []string{
@@ -119,6 +121,8 @@ func NewConcentrator(conf *config.AgentConfig, out chan *pb.StatsPayload, now ti
 		agentVersion:           conf.AgentVersion,
 		peerTagsAggregation:    conf.PeerServiceAggregation || conf.PeerTagsAggregation,
 		computeStatsBySpanKind: conf.ComputeStatsBySpanKind,
+		customTagKeys:          preparePeerTags(conf.StatsCustomTags...),
+		customTagsMaxValues:    conf.StatsCustomTagsMaxValues,
 	}
 	// NOTE: maintain backwards-compatibility with old peer service flag that will eventually be deprecated.
 	if conf.PeerServiceAggregation || conf.PeerTagsAggregation {
@@ -250,6 +254,7 @@ func (c *Concentrator) addNow(pt *traceutil.ProcessedTrace, containerID string)
 		b, ok := c.buckets[btime]
 		if !ok {
 			b = NewRawBucket(uint64(btime), uint64(c.bsize))
+			b.customTags = newCustomTagsGuard(c.customTagKeys, c.customTagsMaxValues)
 			c.buckets[btime] = b
 		}
 		b.HandleSpan(s, weight, isTop, pt.TraceChunk.Origin, aggKey, c.peerTagsAggregation, c.peerTagKeys)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -730,6 +731,73 @@ func TestPeerTags(t *testing.T) {
 	})
 }
 
+func TestCustomTags(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	span := func(id uint64, meta map[string]string) *pb.Span {
+		return &pb.Span{
+			SpanID:   id,
+			Service:  "myservice",
+			Name:     "http.server.request",
+			Resource: "GET /users",
+			Duration: 100,
+			Meta:     meta,
+		}
+	}
+	flushGroups := func(c *Concentrator, spans ...*pb.Span) map[string]uint64 {
+		traceutil.ComputeTopLevel(spans)
+		for _, sp := range spans {
+			c.addNow(toProcessedTrace([]*pb.Span{sp}, "none", ""), "")
+		}
+		stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
+		groups := make(map[string]uint64)
+		for _, st := range stats.Stats[0].Stats[0].Stats {
+			// custom tags are not peer tags
+			assert.Empty(st.PeerTags)
+			groups[strings.Join(st.CustomTags, ",")] += st.Hits
+		}
+		return groups
+	}
+	spans := []*pb.Span{
+		span(1, map[string]string{"customer_tier": "gold", "region": "us1"}),
+		span(2, map[string]string{"customer_tier": "gold", "region": "eu1"}),
+		span(3, map[string]string{"customer_tier": "silver", "region": "us1", "other": "x"}),
+		span(4, map[string]string{"customer_tier": "bronze"}),
+		span(5, nil),
+	}
+	t.Run("not configured", func(t *testing.T) {
+		c := NewTestConcentrator(now)
+		assert.Equal(map[string]uint64{"": 5}, flushGroups(c, spans...))
+	})
+	t.Run("configured", func(t *testing.T) {
+		cfg := config.New()
+		cfg.BucketInterval = time.Duration(testBucketInterval)
+		cfg.StatsCustomTags = []string{"region", "customer_tier", "region"}
+		c := NewConcentrator(cfg, nil, now)
+		assert.Equal([]string{"customer_tier", "region"}, c.customTagKeys)
+		assert.Equal(map[string]uint64{
+			"customer_tier:gold,region:us1":   1,
+			"customer_tier:gold,region:eu1":   1,
+			"customer_tier:silver,region:us1": 1,
+			"customer_tier:bronze":            1,
+			"":                                1,
+		}, flushGroups(c, spans...))
+	})
+	t.Run("overflow", func(t *testing.T) {
+		cfg := config.New()
+		cfg.BucketInterval = time.Duration(testBucketInterval)
+		cfg.StatsCustomTags = []string{"customer_tier"}
+		cfg.StatsCustomTagsMaxValues = 2
+		c := NewConcentrator(cfg, nil, now)
+		assert.Equal(map[string]uint64{
+			"customer_tier:gold":         2,
+			"customer_tier:silver":       1,
+			"customer_tier:_dd.overflow": 1,
+			"":                           1,
+		}, flushGroups(c, spans...))
+	})
+}
+
 // TestComputeStatsThroughSpanKindCheck ensures that we generate stats for spans that have an eligible span.kind.
 func TestComputeStatsThroughSpanKindCheck(t *testing.T) {
 	assert := assert.New(t)
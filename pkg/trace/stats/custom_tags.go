@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+)
+
+// customTagOverflow replaces the values of a custom tag once the limit of
+// distinct values for its key is reached in a stats bucket.
+const customTagOverflow = "_dd.overflow"
+
+// customTagsGuard extracts the custom aggregation tags from spans and client
+// stats, limiting the number of distinct values of each tag. A guard is used
+// for a single stats bucket, so that the limit applies per bucket.
+type customTagsGuard struct {
+	keys      []string // sorted
+	maxValues int
+	values    map[string]map[string]struct{}
+}
+
+// newCustomTagsGuard returns a guard for the given sorted keys, or nil if
+// there are none. All the methods of customTagsGuard are safe to call on nil.
+func newCustomTagsGuard(keys []string, maxValues int) *customTagsGuard {
+	if len(keys) == 0 {
+		return nil
+	}
+	return &customTagsGuard{
+		keys:      keys,
+		maxValues: maxValues,
+		values:    make(map[string]map[string]struct{}, len(keys)),
+	}
+}
+
+// fromSpan returns the custom tags of the span, formatted as key:value.
+func (g *customTagsGuard) fromSpan(s *pb.Span) []string {
+	if g == nil {
+		return nil
+	}
+	var tags []string
+	for _, k := range g.keys {
+		if v, ok := s.Meta[k]; ok && v != "" {
+			tags = append(tags, k+":"+g.value(k, v))
+		}
+	}
+	return tags
+}
+
+// filter returns the tags whose key is a custom tag key, with their value
+// collapsed when over the limit. No tags are returned when no custom tags are
+// configured.
+func (g *customTagsGuard) filter(tags []string) []string {
+	if g == nil || len(tags) == 0 {
+		return nil
+	}
+	var out []string
+	for _, t := range tags {
+		k, v, ok := strings.Cut(t, ":")
+		if !ok || v == "" {
+			continue
+		}
+		if i := sort.SearchStrings(g.keys, k); i == len(g.keys) || g.keys[i] != k {
+			continue
+		}
+		out = append(out, k+":"+g.value(k, v))
+	}
+	return out
+}
+
+// value returns v if it is one of the first maxValues distinct values seen
+// for the key k, and customTagOverflow otherwise.
+func (g *customTagsGuard) value(k, v string) string {
+	seen, ok := g.values[k]
+	if !ok {
+		seen = make(map[string]struct{})
+		g.values[k] = seen
+	}
+	if _, ok := seen[v]; ok {
+		return v
+	}
+	if len(seen) >= g.maxValues {
+		return customTagOverflow
+	}
+	seen[v] = struct{}{}
+	return v
+}
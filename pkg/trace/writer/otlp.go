@@ -0,0 +1,366 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+)
+
+// pathOTLPTraces is the path of the OTLP/HTTP traces endpoint.
+const pathOTLPTraces = "/v1/traces"
+
+// maxOTLPBatchSpans is the number of buffered spans above which the OTLP
+// trace writer exports them before its next flush.
+const maxOTLPBatchSpans = 8192
+
+// otlpExporter exports OTLP traces to an endpoint.
+type otlpExporter interface {
+	// export exports td. It returns a *retriableError when the export
+	// failed and can be retried.
+	export(ctx context.Context, td ptrace.Traces) error
+	// shutdown releases the resources held by the exporter.
+	shutdown()
+}
+
+// OTLPTraceWriter mirrors the sampled traces to an OTLP endpoint, in addition
+// to the TraceWriter. It has its own queue and retries: payloads are dropped
+// when its queue is full, so that a slow endpoint never blocks the agent.
+type OTLPTraceWriter struct {
+	// In receives the sampled chunks to be exported. Payloads should be
+	// sent using Push, which never blocks.
+	In chan *SampledChunks
+
+	exporter   otlpExporter
+	converter  *otlpConverter
+	timeout    time.Duration // export request timeout
+	maxRetries int
+	tick       time.Duration // flush frequency
+
+	payloads []*pb.TracerPayload // tracer payloads buffered
+	spans    int64               // number of spans buffered
+
+	stop   chan struct{} // closed to stop the writer
+	exited chan struct{} // closed once the writer has stopped
+
+	stats   otlpWriterStats
+	easylog *log.ThrottledLogger
+}
+
+// otlpWriterStats holds the telemetry of the OTLP trace writer.
+type otlpWriterStats struct {
+	Spans          atomic.Int64 // spans exported
+	Payloads       atomic.Int64 // export requests sent successfully
+	Retries        atomic.Int64 // export requests retried
+	Errors         atomic.Int64 // export requests failed
+	DroppedSpans   atomic.Int64 // spans dropped after failures or because the queue was full
+	QueueOverflows atomic.Int64 // payloads dropped because the queue was full
+}
+
+// NewOTLPTraceWriter returns a new OTLPTraceWriter exporting traces as configured in
+// cfg.OTLPExport. It returns nil when the export is disabled or misconfigured. All the
+// methods of OTLPTraceWriter are safe to call on nil.
+func NewOTLPTraceWriter(cfg *config.AgentConfig) *OTLPTraceWriter {
+	ocfg := cfg.OTLPExport
+	if !ocfg.Enabled {
+		return nil
+	}
+	exp, err := newOTLPExporter(&ocfg)
+	if err != nil {
+		log.Errorf("Invalid OTLP trace export configuration, traces won't be exported to %q: %v", ocfg.Endpoint, err)
+		return nil
+	}
+	qsize := ocfg.QueueSize
+	if qsize <= 0 {
+		qsize = 1
+	}
+	tick := 5 * time.Second
+	if s := cfg.TraceWriter.FlushPeriodSeconds; s != 0 {
+		tick = time.Duration(s*1000) * time.Millisecond
+	}
+	log.Infof("OTLP trace writer initialized (endpoint=%s protocol=%s qsize=%d)", ocfg.Endpoint, ocfg.Protocol, qsize)
+	return &OTLPTraceWriter{
+		In:         make(chan *SampledChunks, qsize),
+		exporter:   exp,
+		converter:  &otlpConverter{hostname: cfg.Hostname, env: cfg.DefaultEnv},
+		timeout:    ocfg.Timeout,
+		maxRetries: ocfg.MaxRetries,
+		tick:       tick,
+		stop:       make(chan struct{}),
+		exited:     make(chan struct{}),
+		easylog:    log.NewThrottled(5, 10*time.Second), // no more than 5 messages every 10 seconds
+	}
+}
+
+// Push queues pkg to be exported. The payload is dropped if the queue is full.
+// The payload must not be modified afterwards.
+func (w *OTLPTraceWriter) Push(pkg *SampledChunks) {
+	if w == nil {
+		return
+	}
+	select {
+	case w.In <- pkg:
+	default:
+		w.stats.QueueOverflows.Inc()
+		w.stats.DroppedSpans.Add(pkg.SpanCount)
+		w.easylog.Warn("OTLP trace writer queue is full, payload dropped (%d spans).", pkg.SpanCount)
+	}
+}
+
+// Run starts the OTLPTraceWriter.
+func (w *OTLPTraceWriter) Run() {
+	if w == nil {
+		return
+	}
+	defer close(w.exited)
+	t := time.NewTicker(w.tick)
+	defer t.Stop()
+	for {
+		select {
+		case pkg := <-w.In:
+			w.add(pkg)
+		case <-w.stop:
+			w.drainAndFlush()
+			return
+		case <-t.C:
+			w.report()
+			w.flush()
+		}
+	}
+}
+
+// Stop stops the OTLPTraceWriter, exporting whatever is left in its queue
+// without retrying.
+func (w *OTLPTraceWriter) Stop() {
+	if w == nil {
+		return
+	}
+	log.Debug("Exiting OTLP trace writer. Trying to flush whatever is left...")
+	close(w.stop)
+	<-w.exited
+	w.report()
+	w.exporter.shutdown()
+}
+
+func (w *OTLPTraceWriter) add(pkg *SampledChunks) {
+	if len(pkg.TracerPayload.Chunks) == 0 {
+		return
+	}
+	w.payloads = append(w.payloads, pkg.TracerPayload)
+	w.spans += pkg.SpanCount
+	if w.spans >= maxOTLPBatchSpans {
+		w.flush()
+	}
+}
+
+func (w *OTLPTraceWriter) drainAndFlush() {
+	for {
+		select {
+		case pkg := <-w.In:
+			w.add(pkg)
+		default:
+			w.flush()
+			return
+		}
+	}
+}
+
+func (w *OTLPTraceWriter) flush() {
+	if len(w.payloads) == 0 {
+		return
+	}
+	td := w.converter.convert(w.payloads)
+	w.payloads = w.payloads[:0]
+	w.spans = 0
+	if td.SpanCount() == 0 {
+		return
+	}
+	w.export(td)
+}
+
+// export exports td, retrying up to maxRetries times with a backoff. Retries
+// are abandoned once the writer is stopped.
+func (w *OTLPTraceWriter) export(td ptrace.Traces) {
+	n := int64(td.SpanCount())
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+		err := w.exporter.export(ctx, td)
+		cancel()
+		if err == nil {
+			w.stats.Payloads.Inc()
+			w.stats.Spans.Add(n)
+			return
+		}
+		var rerr *retriableError
+		if !errors.As(err, &rerr) || attempt >= w.maxRetries {
+			w.stats.Errors.Inc()
+			w.stats.DroppedSpans.Add(n)
+			w.easylog.Warn("Failed to export %d spans to OTLP endpoint, dropping them: %v", n, err)
+			return
+		}
+		w.stats.Retries.Inc()
+		log.Debugf("Retrying OTLP trace export; error: %v", err)
+		select {
+		case <-w.stop:
+			w.stats.DroppedSpans.Add(n)
+			return
+		case <-time.After(backoffDuration(attempt + 1)):
+		}
+	}
+}
+
+func (w *OTLPTraceWriter) report() {
+	metrics.Count("datadog.trace_agent.otlp_trace_writer.spans", w.stats.Spans.Swap(0), nil, 1)
+	metrics.Count("datadog.trace_agent.otlp_trace_writer.payloads", w.stats.Payloads.Swap(0), nil, 1)
+	metrics.Count("datadog.trace_agent.otlp_trace_writer.retries", w.stats.Retries.Swap(0), nil, 1)
+	metrics.Count("datadog.trace_agent.otlp_trace_writer.errors", w.stats.Errors.Swap(0), nil, 1)
+	metrics.Count("datadog.trace_agent.otlp_trace_writer.dropped_spans", w.stats.DroppedSpans.Swap(0), nil, 1)
+	metrics.Count("datadog.trace_agent.otlp_trace_writer.queue_overflows", w.stats.QueueOverflows.Swap(0), nil, 1)
+}
+
+// newOTLPExporter returns the exporter for the protocol set in cfg.
+func newOTLPExporter(cfg *config.OTLPExportConfig) (otlpExporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("no endpoint")
+	}
+	switch cfg.Protocol {
+	case "grpc", "":
+		return newOTLPGRPCExporter(cfg)
+	case "http":
+		return newOTLPHTTPExporter(cfg)
+	}
+	return nil, fmt.Errorf("unknown protocol %q, must be \"grpc\" or \"http\"", cfg.Protocol)
+}
+
+// otlpGRPCExporter exports traces using OTLP/gRPC.
+type otlpGRPCExporter struct {
+	conn    *grpc.ClientConn
+	client  ptraceotlp.GRPCClient
+	headers metadata.MD
+}
+
+func newOTLPGRPCExporter(cfg *config.OTLPExportConfig) (*otlpGRPCExporter, error) {
+	creds := insecure.NewCredentials()
+	if !cfg.Insecure {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &otlpGRPCExporter{
+		conn:    conn,
+		client:  ptraceotlp.NewGRPCClient(conn),
+		headers: metadata.New(cfg.Headers),
+	}, nil
+}
+
+func (e *otlpGRPCExporter) export(ctx context.Context, td ptrace.Traces) error {
+	ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	_, err := e.client.Export(ctx, ptraceotlp.NewExportRequestFromTraces(td))
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		// retryable codes, as per the OTLP specification
+		return &retriableError{err}
+	}
+	return err
+}
+
+func (e *otlpGRPCExporter) shutdown() {
+	if err := e.conn.Close(); err != nil {
+		log.Debugf("Error closing OTLP gRPC connection: %v", err)
+	}
+}
+
+// otlpHTTPExporter exports traces using OTLP/HTTP with the binary protobuf
+// encoding.
+type otlpHTTPExporter struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func newOTLPHTTPExporter(cfg *config.OTLPExportConfig) (*otlpHTTPExporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid endpoint URL %q", cfg.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = pathOTLPTraces
+	}
+	return &otlpHTTPExporter{
+		client:  &http.Client{},
+		url:     u.String(),
+		headers: cfg.Headers,
+	}, nil
+}
+
+func (e *otlpHTTPExporter) export(ctx context.Context, td ptrace.Traces) error {
+	body, err := ptraceotlp.NewExportRequestFromTraces(td).MarshalProto()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		// request errors include timeouts or name resolution errors and
+		// should thus be retried.
+		return &retriableError{err}
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		log.Debugf("Error discarding response body: %v", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// retryable status codes, as per the OTLP specification
+		return &retriableError{fmt.Errorf("server responded with %q", resp.Status)}
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+func (e *otlpHTTPExporter) shutdown() {
+	e.client.CloseIdleConnections()
+}
@@ -0,0 +1,287 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+// Span tags holding OTLP span fields, as set by the OTLP receiver. They are
+// converted back to the span fields rather than exported as attributes.
+const (
+	tagOTelTraceID     = "otel.trace_id"
+	tagSpanKind        = "span.kind"
+	tagSpanLinks       = "_dd.span_links"
+	tagTraceState      = "w3c.tracestate"
+	tagTraceIDHigh     = "_dd.p.tid"
+	tagErrorMsg        = "error.msg"
+	tagEnv             = "env"
+	tagVersion         = "version"
+	metricSamplingPrio = "_sampling_priority_v1"
+)
+
+// otlpSpanTags are the span tags which are not exported as attributes.
+var otlpSpanTags = map[string]struct{}{
+	tagOTelTraceID:                {},
+	tagSpanKind:                   {},
+	tagSpanLinks:                  {},
+	tagTraceState:                 {},
+	tagTraceIDHigh:                {},
+	tagEnv:                        {},
+	tagVersion:                    {},
+	semconv.OtelLibraryName:       {},
+	semconv.OtelLibraryVersion:    {},
+	semconv.OtelStatusCode:        {},
+	semconv.OtelStatusDescription: {},
+}
+
+var otlpSpanKinds = map[string]ptrace.SpanKind{
+	"internal": ptrace.SpanKindInternal,
+	"server":   ptrace.SpanKindServer,
+	"client":   ptrace.SpanKindClient,
+	"producer": ptrace.SpanKindProducer,
+	"consumer": ptrace.SpanKindConsumer,
+}
+
+// otlpResourceKey identifies the resource of a span within a tracer payload.
+type otlpResourceKey struct{ service, env, version string }
+
+// otlpScopeKey identifies the instrumentation scope of a span.
+type otlpScopeKey struct{ name, version string }
+
+// otlpConverter converts the sampled chunks of tracer payloads to OTLP traces.
+type otlpConverter struct {
+	hostname string // agent hostname, used when the payload has none
+	env      string // agent default env, used when the payload and the span have none
+}
+
+// convert returns the OTLP traces holding the spans of the kept chunks of the
+// given payloads. The spans of each payload are grouped in a resource per
+// service, env and version, and in a scope per instrumentation library.
+func (c *otlpConverter) convert(payloads []*pb.TracerPayload) ptrace.Traces {
+	td := ptrace.NewTraces()
+	for _, tp := range payloads {
+		resources := make(map[otlpResourceKey]ptrace.ResourceSpans)
+		scopes := make(map[otlpResourceKey]map[otlpScopeKey]ptrace.ScopeSpans)
+		for _, chunk := range tp.Chunks {
+			if chunk.DroppedTrace {
+				continue
+			}
+			tid := traceIDHigh(chunk)
+			for _, s := range chunk.Spans {
+				rk := c.resourceKey(tp, s)
+				rs, ok := resources[rk]
+				if !ok {
+					rs = td.ResourceSpans().AppendEmpty()
+					c.setResource(rs.Resource(), tp, rk)
+					resources[rk] = rs
+					scopes[rk] = make(map[otlpScopeKey]ptrace.ScopeSpans)
+				}
+				sk := otlpScopeKey{s.Meta[semconv.OtelLibraryName], s.Meta[semconv.OtelLibraryVersion]}
+				ss, ok := scopes[rk][sk]
+				if !ok {
+					ss = rs.ScopeSpans().AppendEmpty()
+					ss.Scope().SetName(sk.name)
+					ss.Scope().SetVersion(sk.version)
+					scopes[rk][sk] = ss
+				}
+				convertSpan(s, tid, ss.Spans().AppendEmpty())
+			}
+		}
+	}
+	return td
+}
+
+func (c *otlpConverter) resourceKey(tp *pb.TracerPayload, s *pb.Span) otlpResourceKey {
+	rk := otlpResourceKey{service: s.Service, env: tp.Env, version: tp.AppVersion}
+	if rk.env == "" {
+		rk.env = s.Meta[tagEnv]
+	}
+	if rk.env == "" {
+		rk.env = c.env
+	}
+	if rk.version == "" {
+		rk.version = s.Meta[tagVersion]
+	}
+	return rk
+}
+
+func (c *otlpConverter) setResource(r pcommon.Resource, tp *pb.TracerPayload, rk otlpResourceKey) {
+	attr := r.Attributes()
+	put := func(k, v string) {
+		if v != "" {
+			attr.PutStr(k, v)
+		}
+	}
+	put(semconv.AttributeServiceName, rk.service)
+	put(semconv.AttributeDeploymentEnvironment, rk.env)
+	put(semconv.AttributeServiceVersion, rk.version)
+	if tp.Hostname != "" {
+		put(semconv.AttributeHostName, tp.Hostname)
+	} else {
+		put(semconv.AttributeHostName, c.hostname)
+	}
+	put(semconv.AttributeContainerID, tp.ContainerID)
+	put(semconv.AttributeTelemetrySDKLanguage, tp.LanguageName)
+	put(semconv.AttributeTelemetrySDKVersion, tp.TracerVersion)
+	put(semconv.AttributeProcessRuntimeVersion, tp.LanguageVersion)
+}
+
+// convertSpan converts s to the OTLP span out. tid holds the hex-encoded
+// high 64 bits of the trace ID, if known.
+func convertSpan(s *pb.Span, tid string, out ptrace.Span) {
+	out.SetTraceID(otlpTraceID(s, tid))
+	out.SetSpanID(otlpSpanID(s.SpanID))
+	if s.ParentID != 0 {
+		out.SetParentSpanID(otlpSpanID(s.ParentID))
+	}
+	out.SetName(s.Resource)
+	if s.Resource == "" {
+		out.SetName(s.Name)
+	}
+	out.SetKind(otlpSpanKinds[s.Meta[tagSpanKind]])
+	out.SetStartTimestamp(pcommon.Timestamp(s.Start))
+	out.SetEndTimestamp(pcommon.Timestamp(s.Start + s.Duration))
+	out.TraceState().FromRaw(s.Meta[tagTraceState])
+	setStatus(s, out.Status())
+	if v, ok := s.Meta[tagSpanLinks]; ok {
+		if err := convertSpanLinks(v, out.Links()); err != nil {
+			log.Debugf("Error converting the span links of span %d to OTLP: %v", s.SpanID, err)
+		}
+	}
+
+	attr := out.Attributes()
+	attr.EnsureCapacity(len(s.Meta) + len(s.Metrics) + 3)
+	// the OTLP receiver maps these attributes back to the span fields
+	attr.PutStr("operation.name", s.Name)
+	attr.PutStr("resource.name", s.Resource)
+	if s.Type != "" {
+		attr.PutStr("span.type", s.Type)
+	}
+	for k, v := range s.Meta {
+		if _, ok := otlpSpanTags[k]; !ok {
+			attr.PutStr(k, v)
+		}
+	}
+	for k, v := range s.Metrics {
+		if k == metricSamplingPrio {
+			k = "sampling.priority"
+		}
+		attr.PutDouble(k, v)
+	}
+}
+
+// setStatus sets the OTLP status of the span s. Spans flagged as errors
+// always have the error status.
+func setStatus(s *pb.Span, status ptrace.Status) {
+	switch {
+	case s.Error != 0:
+		status.SetCode(ptrace.StatusCodeError)
+		if msg := s.Meta[semconv.OtelStatusDescription]; msg != "" {
+			status.SetMessage(msg)
+		} else {
+			status.SetMessage(s.Meta[tagErrorMsg])
+		}
+	case s.Meta[semconv.OtelStatusCode] == ptrace.StatusCodeOk.String():
+		status.SetCode(ptrace.StatusCodeOk)
+	}
+}
+
+// traceIDHigh returns the hex-encoded high 64 bits of the trace ID of the
+// chunk, which are propagated in a tag of one of its spans.
+func traceIDHigh(chunk *pb.TraceChunk) string {
+	for _, s := range chunk.Spans {
+		if v, ok := s.Meta[tagTraceIDHigh]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// otlpTraceID returns the 128-bit trace ID of s. The full trace ID of spans
+// received through OTLP is kept in a tag.
+func otlpTraceID(s *pb.Span, tid string) pcommon.TraceID {
+	var id pcommon.TraceID
+	if v, err := hex.DecodeString(s.Meta[tagOTelTraceID]); err == nil && len(v) == len(id) {
+		copy(id[:], v)
+		if binary.BigEndian.Uint64(id[8:]) == s.TraceID {
+			return id
+		}
+		id = pcommon.TraceID{}
+	}
+	if v, err := hex.DecodeString(tid); err == nil && len(v) == 8 {
+		copy(id[:8], v)
+	}
+	binary.BigEndian.PutUint64(id[8:], s.TraceID)
+	return id
+}
+
+func otlpSpanID(id uint64) pcommon.SpanID {
+	var sid pcommon.SpanID
+	binary.BigEndian.PutUint64(sid[:], id)
+	return sid
+}
+
+// spanLink is a span link, as encoded in the "_dd.span_links" tag.
+type spanLink struct {
+	TraceID                string            `json:"trace_id"`
+	SpanID                 string            `json:"span_id"`
+	TraceState             string            `json:"trace_state"`
+	Attributes             map[string]string `json:"attributes"`
+	DroppedAttributesCount uint32            `json:"dropped_attributes_count"`
+}
+
+// convertSpanLinks appends the span links encoded in v to out.
+func convertSpanLinks(v string, out ptrace.SpanLinkSlice) error {
+	var links []spanLink
+	if err := json.Unmarshal([]byte(v), &links); err != nil {
+		return err
+	}
+	for _, l := range links {
+		var (
+			tid pcommon.TraceID
+			sid pcommon.SpanID
+		)
+		if err := decodeHexID(l.TraceID, tid[:]); err != nil {
+			return err
+		}
+		if err := decodeHexID(l.SpanID, sid[:]); err != nil {
+			return err
+		}
+		link := out.AppendEmpty()
+		link.SetTraceID(tid)
+		link.SetSpanID(sid)
+		link.TraceState().FromRaw(l.TraceState)
+		for k, v := range l.Attributes {
+			link.Attributes().PutStr(k, v)
+		}
+		link.SetDroppedAttributesCount(l.DroppedAttributesCount)
+	}
+	return nil
+}
+
+// decodeHexID decodes the hex-encoded ID s into the end of id, so that 64-bit
+// trace IDs are converted to 128-bit trace IDs.
+func decodeHexID(s string, id []byte) error {
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(v) > len(id) {
+		return hex.ErrLength
+	}
+	copy(id[len(id)-len(v):], v)
+	return nil
+}
@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+)
+
+func TestOTLPConvert(t *testing.T) {
+	c := &otlpConverter{hostname: "agent-host", env: "agent-env"}
+	root := &pb.Span{
+		Service:  "web",
+		Name:     "http.request",
+		Resource: "GET /users",
+		Type:     "web",
+		TraceID:  0x1122334455667788,
+		SpanID:   1,
+		Start:    1000,
+		Duration: 500,
+		Meta: map[string]string{
+			"_dd.p.tid":         "0102030405060708",
+			"span.kind":         "server",
+			"env":               "prod",
+			"http.method":       "GET",
+			"otel.library.name": "net/http",
+			"w3c.tracestate":    "dd=s:1",
+		},
+		Metrics: map[string]float64{"_sampling_priority_v1": 2, "http.status_code": 200},
+	}
+	child := &pb.Span{
+		Service:  "db",
+		Name:     "postgres.query",
+		Resource: "SELECT ?",
+		TraceID:  0x1122334455667788,
+		SpanID:   2,
+		ParentID: 1,
+		Start:    1100,
+		Duration: 100,
+		Error:    1,
+		Meta: map[string]string{
+			"span.kind":      "client",
+			"error.msg":      "timeout",
+			"_dd.span_links": `[{"trace_id":"0a0b","span_id":"000000000000000c","trace_state":"k=v","attributes":{"link.name":"retry"},"dropped_attributes_count":1}]`,
+		},
+	}
+	dropped := &pb.Span{Service: "web", SpanID: 3, TraceID: 3}
+	td := c.convert([]*pb.TracerPayload{{
+		Hostname:      "tracer-host",
+		ContainerID:   "cid",
+		LanguageName:  "go",
+		TracerVersion: "1.55.0",
+		AppVersion:    "v1",
+		Chunks: []*pb.TraceChunk{
+			{Spans: []*pb.Span{root, child}},
+			{Spans: []*pb.Span{dropped}, DroppedTrace: true},
+		},
+	}})
+
+	require.Equal(t, 2, td.SpanCount())
+	require.Equal(t, 2, td.ResourceSpans().Len())
+
+	webRS := td.ResourceSpans().At(0)
+	assert.Equal(t, map[string]any{
+		"service.name":           "web",
+		"deployment.environment": "prod",
+		"service.version":        "v1",
+		"host.name":              "tracer-host",
+		"container.id":           "cid",
+		"telemetry.sdk.language": "go",
+		"telemetry.sdk.version":  "1.55.0",
+	}, webRS.Resource().Attributes().AsRaw())
+	scope := webRS.ScopeSpans().At(0).Scope()
+	assert.Equal(t, "net/http", scope.Name())
+
+	span := webRS.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}, span.TraceID())
+	assert.Equal(t, pcommon.SpanID{0, 0, 0, 0, 0, 0, 0, 1}, span.SpanID())
+	assert.True(t, span.ParentSpanID().IsEmpty())
+	assert.Equal(t, "GET /users", span.Name())
+	assert.Equal(t, ptrace.SpanKindServer, span.Kind())
+	assert.EqualValues(t, 1000, span.StartTimestamp())
+	assert.EqualValues(t, 1500, span.EndTimestamp())
+	assert.Equal(t, "dd=s:1", span.TraceState().AsRaw())
+	assert.Equal(t, ptrace.StatusCodeUnset, span.Status().Code())
+	assert.Equal(t, map[string]any{
+		"operation.name":    "http.request",
+		"resource.name":     "GET /users",
+		"span.type":         "web",
+		"http.method":       "GET",
+		"sampling.priority": 2.0,
+		"http.status_code":  200.0,
+	}, span.Attributes().AsRaw())
+
+	dbRS := td.ResourceSpans().At(1)
+	assert.Equal(t, "db", dbRS.Resource().Attributes().AsRaw()["service.name"])
+	span = dbRS.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.SpanID{0, 0, 0, 0, 0, 0, 0, 1}, span.ParentSpanID())
+	assert.Equal(t, ptrace.SpanKindClient, span.Kind())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "timeout", span.Status().Message())
+	require.Equal(t, 1, span.Links().Len())
+	link := span.Links().At(0)
+	assert.Equal(t, pcommon.TraceID{14: 0x0a, 15: 0x0b}, link.TraceID())
+	assert.Equal(t, pcommon.SpanID{7: 0x0c}, link.SpanID())
+	assert.Equal(t, "k=v", link.TraceState().AsRaw())
+	assert.Equal(t, map[string]any{"link.name": "retry"}, link.Attributes().AsRaw())
+	assert.EqualValues(t, 1, link.DroppedAttributesCount())
+}
+
+func TestOTLPConvertDefaults(t *testing.T) {
+	c := &otlpConverter{hostname: "agent-host", env: "agent-env"}
+	td := c.convert([]*pb.TracerPayload{{
+		Chunks: []*pb.TraceChunk{{Spans: []*pb.Span{{
+			Service: "svc",
+			Name:    "op",
+			TraceID: 42,
+			SpanID:  42,
+			Meta: map[string]string{
+				"otel.trace_id":      "0000000000000001000000000000002a",
+				"otel.status_code":   "Ok",
+				"otel.library.name":  "lib",
+				"version":            "v2",
+				"_dd.span_links":     "not json",
+				"otel.library.other": "kept",
+			},
+		}}}},
+	}})
+
+	require.Equal(t, 1, td.SpanCount())
+	rs := td.ResourceSpans().At(0)
+	assert.Equal(t, map[string]any{
+		"service.name":           "svc",
+		"deployment.environment": "agent-env",
+		"service.version":        "v2",
+		"host.name":              "agent-host",
+	}, rs.Resource().Attributes().AsRaw())
+	span := rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{7: 1, 15: 42}, span.TraceID())
+	assert.Equal(t, "op", span.Name())
+	assert.Equal(t, ptrace.SpanKindUnspecified, span.Kind())
+	assert.Equal(t, ptrace.StatusCodeOk, span.Status().Code())
+	assert.Equal(t, 0, span.Links().Len())
+	assert.Equal(t, map[string]any{
+		"operation.name":     "op",
+		"resource.name":      "",
+		"otel.library.other": "kept",
+	}, span.Attributes().AsRaw())
+}
+
+func TestOTLPTraceID(t *testing.T) {
+	span := &pb.Span{TraceID: 42, Meta: map[string]string{
+		// the low bits don't match the trace ID of the span
+		"otel.trace_id": "0000000000000001000000000000002b",
+	}}
+	assert.Equal(t, pcommon.TraceID{7: 2, 15: 42}, otlpTraceID(span, "0000000000000002"))
+	assert.Equal(t, pcommon.TraceID{15: 42}, otlpTraceID(span, "invalid"))
+}
@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// otlpTestServer is an OTLP/HTTP server responding with the given status codes
+// in turn, and with 200 once they are all used.
+type otlpTestServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	codes    []int
+	requests int
+	spans    int
+	headers  http.Header
+}
+
+func newOTLPTestServer(t *testing.T, codes ...int) *otlpTestServer {
+	srv := &otlpTestServer{codes: codes}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := ptraceotlp.NewExportRequest()
+		assert.NoError(t, req.UnmarshalProto(body))
+		srv.requests++
+		srv.headers = r.Header
+		code := http.StatusOK
+		if len(srv.codes) > 0 {
+			code, srv.codes = srv.codes[0], srv.codes[1:]
+		}
+		if code == http.StatusOK {
+			srv.spans += req.Traces().SpanCount()
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *otlpTestServer) stats() (requests, spans int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests, s.spans
+}
+
+func otlpExportConfig(endpoint, protocol string) *config.AgentConfig {
+	cfg := config.New()
+	cfg.OTLPExport.Enabled = true
+	cfg.OTLPExport.Endpoint = endpoint
+	cfg.OTLPExport.Protocol = protocol
+	cfg.OTLPExport.Headers = map[string]string{"x-api-key": "secret"}
+	cfg.OTLPExport.Insecure = true
+	return cfg
+}
+
+func TestOTLPTraceWriter(t *testing.T) {
+	t.Run("http", func(t *testing.T) {
+		srv := newOTLPTestServer(t)
+		w := NewOTLPTraceWriter(otlpExportConfig(srv.URL, "http"))
+		require.NotNil(t, w)
+		go w.Run()
+		for _, n := range []int{20, 10, 5} {
+			w.Push(randomSampledSpans(n, 0))
+		}
+		w.Stop()
+
+		requests, spans := srv.stats()
+		assert.Equal(t, 1, requests)
+		assert.Equal(t, 35, spans)
+		assert.Equal(t, "secret", srv.headers.Get("x-api-key"))
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		var (
+			spans atomic.Int64
+			key   atomic.String
+		)
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer()
+		ptraceotlp.RegisterGRPCServer(srv, &otlpGRPCServer{export: func(ctx context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+			if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-api-key")) > 0 {
+				key.Store(md.Get("x-api-key")[0])
+			}
+			spans.Add(int64(req.Traces().SpanCount()))
+			return ptraceotlp.NewExportResponse(), nil
+		}})
+		go srv.Serve(ln) //nolint:errcheck
+		defer srv.Stop()
+
+		w := NewOTLPTraceWriter(otlpExportConfig(ln.Addr().String(), "grpc"))
+		require.NotNil(t, w)
+		go w.Run()
+		w.Push(randomSampledSpans(12, 0))
+		w.Stop()
+
+		assert.EqualValues(t, 12, spans.Load())
+		assert.Equal(t, "secret", key.Load())
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		w := NewOTLPTraceWriter(otlpExportConfig("http://localhost:4318", "http"))
+		require.NotNil(t, w)
+		for i := 0; i < 101; i++ {
+			// the writer doesn't run; pushing never blocks
+			w.Push(randomSampledSpans(2, 0))
+		}
+		assert.EqualValues(t, 1, w.stats.QueueOverflows.Load())
+		assert.EqualValues(t, 2, w.stats.DroppedSpans.Load())
+	})
+}
+
+func TestOTLPTraceWriterRetries(t *testing.T) {
+	defer useBackoffDuration(time.Millisecond)()
+	export := func(srv *otlpTestServer, maxRetries int) *OTLPTraceWriter {
+		cfg := otlpExportConfig(srv.URL, "http")
+		cfg.OTLPExport.MaxRetries = maxRetries
+		w := NewOTLPTraceWriter(cfg)
+		require.NotNil(t, w)
+		w.add(randomSampledSpans(10, 0))
+		w.flush()
+		return w
+	}
+
+	t.Run("retried", func(t *testing.T) {
+		srv := newOTLPTestServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		w := export(srv, 3)
+		requests, spans := srv.stats()
+		assert.Equal(t, 3, requests)
+		assert.Equal(t, 10, spans)
+		assert.EqualValues(t, 2, w.stats.Retries.Load())
+		assert.EqualValues(t, 0, w.stats.DroppedSpans.Load())
+	})
+
+	t.Run("max_retries", func(t *testing.T) {
+		srv := newOTLPTestServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+		w := export(srv, 1)
+		requests, spans := srv.stats()
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 0, spans)
+		assert.EqualValues(t, 1, w.stats.Errors.Load())
+		assert.EqualValues(t, 10, w.stats.DroppedSpans.Load())
+	})
+
+	t.Run("not_retriable", func(t *testing.T) {
+		srv := newOTLPTestServer(t, http.StatusBadRequest)
+		w := export(srv, 3)
+		requests, _ := srv.stats()
+		assert.Equal(t, 1, requests)
+		assert.EqualValues(t, 0, w.stats.Retries.Load())
+		assert.EqualValues(t, 10, w.stats.DroppedSpans.Load())
+	})
+}
+
+func TestNewOTLPTraceWriter(t *testing.T) {
+	for name, cfg := range map[string]*config.AgentConfig{
+		"disabled":    config.New(),
+		"no_endpoint": otlpExportConfig("", "grpc"),
+		"protocol":    otlpExportConfig("localhost:4317", "thrift"),
+		"http_url":    otlpExportConfig("localhost:4318", "http"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := NewOTLPTraceWriter(cfg)
+			assert.Nil(t, w)
+			// all methods are safe to call on nil
+			go w.Run()
+			w.Push(randomSampledSpans(1, 0))
+			w.Stop()
+		})
+	}
+}
+
+// otlpGRPCServer implements ptraceotlp.GRPCServer using the export function.
+type otlpGRPCServer struct {
+	ptraceotlp.UnimplementedGRPCServer
+	export func(context.Context, ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error)
+}
+
+func (s *otlpGRPCServer) Export(ctx context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	return s.export(ctx, req)
+}
@@ -1,6 +1,6 @@
 module github.com/DataDog/datadog-agent/pkg/util/backoff
 
-go 1.20
+go 1.21
 
 require github.com/stretchr/testify v1.8.4
 
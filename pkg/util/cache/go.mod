@@ -1,6 +1,6 @@
 module github.com/DataDog/datadog-agent/pkg/util/cache
 
-go 1.20
+go 1.21
 
 require (
 	github.com/patrickmn/go-cache v2.1.0+incompatible
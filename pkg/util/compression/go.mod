@@ -1,5 +1,5 @@
 module github.com/DataDog/datadog-agent/pkg/util/compression
 
-go 1.20
+go 1.21
 
 require github.com/DataDog/zstd_0 v0.0.0-20210310093942-586c1286621f
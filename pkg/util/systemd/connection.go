@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"github.com/coreos/go-systemd/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// DefaultPrivateSocket is the path of the systemd private socket
+const DefaultPrivateSocket = "/run/systemd/private"
+
+// Connect returns a connection to systemd. If privateSocket is empty, the
+// system bus is used and the default private socket is used as a fallback.
+// When the Agent is containerized, the private socket of the host is used.
+func Connect(privateSocket string) (*dbus.Conn, error) {
+	if privateSocket != "" {
+		return NewSystemdConnection(privateSocket)
+	}
+
+	if config.IsContainerized() {
+		return NewSystemdConnection("/host" + DefaultPrivateSocket)
+	}
+
+	conn, err := dbus.NewSystemConnection()
+	if err == nil {
+		return conn, nil
+	}
+	log.Debugf("Error getting new connection using system bus socket: %v", err)
+
+	return NewSystemdConnection(DefaultPrivateSocket)
+}
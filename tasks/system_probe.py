@@ -379,6 +379,10 @@ def ninja_cgo_type_files(nw, windows):
                 "pkg/network/ebpf/c/tracer/tracer.h",
                 "pkg/network/ebpf/c/protocols/kafka/types.h",
             ],
+            "pkg/network/protocols/stream/types.go": [
+                "pkg/network/ebpf/c/conn_tuple.h",
+                "pkg/network/ebpf/c/protocols/stream/types.h",
+            ],
             "pkg/network/telemetry/telemetry_types.go": [
                 "pkg/ebpf/c/telemetry_types.h",
             ],